@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,10 +16,13 @@ import (
 
 	"erp-access-control-go/internal/config"
 	"erp-access-control-go/internal/handlers"
+	"erp-access-control-go/internal/jobs"
 	"erp-access-control-go/internal/middleware"
 	"erp-access-control-go/internal/services"
 	"erp-access-control-go/pkg/jwt"
 	"erp-access-control-go/pkg/logger"
+	"erp-access-control-go/pkg/mailer"
+	"erp-access-control-go/pkg/redis"
 )
 
 func main() {
@@ -33,8 +41,18 @@ func main() {
 		log.Fatalf("❌ データベース接続エラー: %v", err)
 	}
 
+	// シャットダウン（SIGINT/SIGTERM）で終了するコンテキスト
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// サービス初期化
-	services := initServices(db, cfg)
+	services := initServices(ctx, db, cfg)
+
+	// 定期ジョブ開始
+	if cfg.Jobs.Enabled {
+		services.Jobs.Start(ctx)
+		log.Printf("✅ 定期ジョブ開始")
+	}
 
 	// ミドルウェア初期化
 	middlewares := initMiddlewares(services, appLogger)
@@ -42,8 +60,13 @@ func main() {
 	// Ginルーター初期化
 	router := setupRoutes(services, middlewares, appLogger)
 
-	// サーバー起動
-	startServer(router, cfg.Server.Port)
+	// サーバー起動（シャットダウンまでブロック）
+	startServer(ctx, router, cfg.Server.Port)
+
+	// 実行中のジョブ・送信中の再設定メールの終了を待機
+	services.Jobs.Wait()
+	services.PasswordReset.Wait()
+	log.Printf("👋 サーバー停止")
 }
 
 // initLogger ロガーを初期化
@@ -77,7 +100,7 @@ func initDatabase(cfg *config.Config) (*gorm.DB, error) {
 }
 
 // initServices 全サービスを初期化
-func initServices(db *gorm.DB, cfg *config.Config) *ServiceContainer {
+func initServices(ctx context.Context, db *gorm.DB, cfg *config.Config) *ServiceContainer {
 	// ロガー初期化
 	var minLevel logger.LogLevel
 	switch cfg.Environment {
@@ -95,15 +118,69 @@ func initServices(db *gorm.DB, cfg *config.Config) *ServiceContainer {
 	)
 
 	// JWT サービス
-	jwtService := jwt.NewService(cfg.JWT.Secret, cfg.JWT.AccessTokenDuration)
+	jwtService, err := initJWTService(cfg)
+	if err != nil {
+		log.Fatalf("❌ JWT署名鍵の読み込みエラー: %v", err)
+	}
 
 	// 基本サービス
 	permissionService := services.NewPermissionService(db, appLogger)
 	revocationService := services.NewTokenRevocationService(db)
+	if cfg.Security.Revocation.CacheEnabled {
+		revocationService.SetCache(initRevocationCache(ctx, db, appLogger, cfg.Security.Revocation.PollInterval))
+	}
+	refreshTokenService := services.NewRefreshTokenService(db, cfg.JWT.RefreshTokenDuration)
 	userRoleService := services.NewUserRoleService(db)
+	passwordPolicy := services.PasswordPolicy{
+		MinLength:        cfg.Security.Password.MinLength,
+		MaxLength:        cfg.Security.Password.MaxLength,
+		RequireUppercase: cfg.Security.Password.RequireUppercase,
+		RequireLowercase: cfg.Security.Password.RequireLowercase,
+		RequireDigit:     cfg.Security.Password.RequireDigit,
+		RequireSymbol:    cfg.Security.Password.RequireSymbol,
+		HistoryDepth:     cfg.Security.Password.HistoryDepth,
+		MaxAge:           cfg.Security.Password.MaxAge,
+		BcryptCost:       cfg.Security.Password.BcryptCost,
+	}
+	if cfg.Security.Password.BannedListFile != "" {
+		banned, err := services.LoadBannedPasswords(cfg.Security.Password.BannedListFile)
+		if err != nil {
+			log.Fatalf("❌ 使用禁止パスワード一覧の読み込みエラー: %v", err)
+		}
+		passwordPolicy.BannedPasswords = banned
+	}
+	passwordPolicyService := services.NewPasswordPolicyService(db, passwordPolicy)
 	userService := services.NewUserService(db, appLogger)
+	userService.SetPasswordPolicyService(passwordPolicyService)
 	departmentService := services.NewDepartmentService(db, appLogger)
 	roleService := services.NewRoleService(db, appLogger)
+	timeRestrictionService := services.NewTimeRestrictionService(db, appLogger)
+	timeRestrictionService.SetPermissionService(permissionService)
+
+	// 実効権限のキャッシュ（権限に影響する変更を行うサービスで無効化）
+	permissionCache, err := initPermissionCache(cfg, appLogger)
+	if err != nil {
+		log.Fatalf("❌ 権限キャッシュ設定エラー: %v", err)
+	}
+	if permissionCache != nil {
+		permissionService.SetPermissionCache(permissionCache, cfg.Security.PermissionCache.TTL)
+		roleService.SetPermissionCache(permissionCache)
+		userRoleService.SetPermissionCache(permissionCache)
+		userService.SetPermissionCache(permissionCache)
+	}
+
+	userScopeService := services.NewUserScopeService(db, appLogger)
+	approvalService := services.NewApprovalService(db, appLogger)
+	userRoleService.SetApprovalService(approvalService) // 承認が必要なロールの付与を承認フロー経由にする
+	approvalFlowService := services.NewApprovalFlowService(db, appLogger)
+	auditService := services.NewAuditService(db, appLogger)
+	if cfg.Audit.CheckpointKeyFile != "" {
+		checkpointKey, err := services.LoadCheckpointSigningKey(cfg.Audit.CheckpointKeyFile)
+		if err != nil {
+			log.Fatalf("❌ 監査チェックポイント署名鍵の読み込みエラー: %v", err)
+		}
+		auditService.SetCheckpointSigningKey(checkpointKey)
+	}
 
 	// 認証サービス
 	authService := services.NewAuthService(
@@ -111,17 +188,175 @@ func initServices(db *gorm.DB, cfg *config.Config) *ServiceContainer {
 		jwtService,
 		permissionService,
 		revocationService,
+		refreshTokenService,
+	)
+	authService.SetAuditService(auditService) // ログイン試行（成功・失敗）を監査ログに記録
+	authService.SetPasswordPolicyService(passwordPolicyService)
+	mfaService := services.NewMFAService(db, appLogger, cfg.Security.MFA.Issuer, cfg.Security.MFA.ChallengeDuration)
+	authService.SetMFAService(mfaService) // 登録済み・MFA必須ロールのユーザーは二段階ログイン
+	authService.SetLockoutPolicy(services.LockoutPolicy{
+		Threshold:   cfg.Security.Lockout.Threshold,
+		Duration:    cfg.Security.Lockout.Duration,
+		MaxDuration: cfg.Security.Lockout.MaxDuration,
+	})
+
+	// パスワード再設定（メール送付）
+	mailSender, err := initMailer(cfg)
+	if err != nil {
+		log.Fatalf("❌ メール送信設定エラー: %v", err)
+	}
+	passwordResetService := services.NewPasswordResetService(
+		db,
+		appLogger,
+		mailSender,
+		revocationService,
+		cfg.Security.PasswordReset.TokenDuration,
+		cfg.Security.PasswordReset.URL,
 	)
+	passwordResetService.SetPasswordPolicyService(passwordPolicyService)
+
+	// ログインセッション管理（端末ごとの一覧・無効化）
+	sessionService := services.NewSessionService(db, appLogger, revocationService, refreshTokenService)
+	authService.SetSessionService(sessionService)               // ログイン・リフレッシュ時にセッションを記録
+	refreshTokenService.SetRevocationService(revocationService) // ログアウト・再利用検知時にセッションのアクセストークンも失効
+
+	// トークンイントロスペクション（下流サービス向け）
+	introspectionClients, err := cfg.Security.Introspection.ClientSecrets()
+	if err != nil {
+		log.Fatalf("❌ イントロスペクションクライアント設定エラー: %v", err)
+	}
+	introspectionService := services.NewTokenIntrospectionService(jwtService, revocationService, introspectionClients)
+
+	// 定期ジョブ（期限切れデータの整理）
+	scheduler := jobs.NewScheduler(db, appLogger)
+	scheduler.SetLocker(jobs.NewAdvisoryLocker(db)) // 複数レプリカでは1台のみが実行
+	scheduler.Register(jobs.Job{
+		Name:     "revoked_token_cleanup",
+		Interval: cfg.Jobs.RevokedTokenCleanupInterval,
+		Run: func(ctx context.Context) (int64, error) {
+			return revocationService.PurgeExpiredTokens()
+		},
+	})
+	scheduler.Register(jobs.Job{
+		Name:     "expired_role_cleanup",
+		Interval: cfg.Jobs.ExpiredRoleCleanupInterval,
+		Run: func(ctx context.Context) (int64, error) {
+			return userRoleService.CleanupExpiredRoles()
+		},
+	})
+	scheduler.Register(jobs.Job{
+		Name:     "auth_token_cleanup",
+		Interval: cfg.Jobs.AuthTokenCleanupInterval,
+		Run: func(ctx context.Context) (int64, error) {
+			var total int64
+			for _, cleanup := range []func() (int64, error){
+				refreshTokenService.CleanupExpiredTokens,
+				passwordResetService.CleanupExpiredTokens,
+				sessionService.CleanupExpiredSessions,
+			} {
+				deleted, err := cleanup()
+				total += deleted
+				if err != nil {
+					return total, err
+				}
+			}
+			return total, nil
+		},
+	})
 
 	return &ServiceContainer{
-		Auth:       authService,
-		Permission: permissionService,
-		Revocation: revocationService,
-		UserRole:   userRoleService,
-		User:       userService,
-		Department: departmentService,
-		Role:       roleService,
-		JWT:        jwtService,
+		Auth:            authService,
+		MFA:             mfaService,
+		PasswordReset:   passwordResetService,
+		Session:         sessionService,
+		Introspection:   introspectionService,
+		Permission:      permissionService,
+		Revocation:      revocationService,
+		UserRole:        userRoleService,
+		User:            userService,
+		Department:      departmentService,
+		Role:            roleService,
+		TimeRestriction: timeRestrictionService,
+		UserScope:       userScopeService,
+		Approval:        approvalService,
+		ApprovalFlow:    approvalFlowService,
+		Audit:           auditService,
+		JWT:             jwtService,
+		Jobs:            scheduler,
+	}
+}
+
+// initRevocationCache 無効化キャッシュを読み込み、定期同期を開始
+// 起動時の読み込みに失敗した場合も、同期に成功するまではデータベースで判定する
+func initRevocationCache(ctx context.Context, db *gorm.DB, appLogger *logger.Logger, pollInterval time.Duration) *services.RevocationCache {
+	cache := services.NewRevocationCache(db, appLogger, pollInterval)
+	if err := cache.Sync(); err != nil {
+		log.Printf("⚠️ トークン無効化キャッシュの読み込みエラー: %v", err)
+	} else {
+		log.Printf("✅ トークン無効化キャッシュ読み込み完了（同期間隔 %s）", pollInterval)
+	}
+	go cache.Run(ctx)
+	return cache
+}
+
+// initJWTService 署名鍵の設定に応じてJWTサービスを初期化（未設定の場合はHS256）
+func initJWTService(cfg *config.Config) (*jwt.Service, error) {
+	if cfg.JWT.SigningKeyFile == "" {
+		return jwt.NewService(cfg.JWT.Secret, cfg.JWT.AccessTokenDuration), nil
+	}
+
+	signingKey, err := jwt.LoadKeyFile(cfg.JWT.SigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	var verificationKeys []*jwt.Key
+	for _, path := range cfg.JWT.VerificationKeyFiles {
+		if path == "" {
+			continue
+		}
+		key, err := jwt.LoadKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	log.Printf("✅ JWT署名鍵: alg=%s kid=%s（検証鍵 %d件）", signingKey.Algorithm, signingKey.ID, len(verificationKeys)+1)
+	return jwt.NewAsymmetricService(signingKey, verificationKeys, cfg.JWT.AccessTokenDuration)
+}
+
+// initMailer 設定に応じたメール送信を初期化
+func initMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return mailer.NewSMTPMailer(cfg.Mail.SMTP.Host, cfg.Mail.SMTP.Port, cfg.Mail.SMTP.Username, cfg.Mail.SMTP.Password, cfg.Mail.From), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.Mail.FilePath, cfg.Mail.From)
+	case "log", "":
+		return mailer.NewLogMailer(os.Stdout, cfg.Mail.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Mail.Driver)
+	}
+}
+
+// initPermissionCache 設定に応じて実効権限のキャッシュを初期化（none の場合はnil）
+func initPermissionCache(cfg *config.Config, appLogger *logger.Logger) (services.PermissionCache, error) {
+	switch cfg.Security.PermissionCache.Driver {
+	case "memory", "":
+		return services.NewLRUPermissionCache(cfg.Security.PermissionCache.Size), nil
+	case "redis":
+		client := redis.NewClient(cfg.Redis.Address(), cfg.Redis.Password, cfg.Redis.DB, cfg.Redis.Timeout)
+		if err := client.Ping(); err != nil {
+			// 接続できない間はキャッシュミスとしてデータベースで解決する
+			log.Printf("⚠️ Redisへの接続エラー（権限キャッシュを使用せずに継続）: %v", err)
+		} else {
+			log.Printf("✅ 権限キャッシュ: Redis %s", cfg.Redis.Address())
+		}
+		return services.NewRedisPermissionCache(client, appLogger, "erp:permissions:"), nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown permission cache driver: %s", cfg.Security.PermissionCache.Driver)
 	}
 }
 
@@ -130,11 +365,15 @@ func initMiddlewares(services *ServiceContainer, appLogger *logger.Logger) *Midd
 	authMiddleware := middleware.NewAuthMiddleware(
 		services.JWT,
 		services.Revocation,
+		services.Permission,
 		appLogger,
 	)
 
+	auditMiddleware := middleware.NewAuditMiddleware(services.Audit, appLogger)
+
 	return &MiddlewareContainer{
-		Auth: authMiddleware,
+		Auth:  authMiddleware,
+		Audit: auditMiddleware,
 	}
 }
 
@@ -165,22 +404,32 @@ func setupRoutes(services *ServiceContainer, middlewares *MiddlewareContainer, a
 	// 基本エンドポイント
 	setupBasicRoutes(router)
 
+	// 下流サービス向けのトークン検証用公開鍵
+	setupWellKnownRoutes(router, services.JWT)
+
 	// API v1 ルート
 	v1 := router.Group("/api/v1")
+	v1.Use(middlewares.Audit.Audit())
 	{
 		// 認証エンドポイント
-		setupAuthRoutes(v1, services.Auth, middlewares, appLogger)
+		setupAuthRoutes(v1, services, middlewares, appLogger)
 
 		// 認証が必要なエンドポイント
 		protected := v1.Group("")
 		protected.Use(middlewares.Auth.Authentication())
 		{
 			// ユーザー管理
-			setupUserRoutes(protected, services.User, appLogger)
+			setupUserRoutes(protected, services.User, services.Session, appLogger)
 
 			// ユーザーロール管理
 			setupUserRoleRoutes(protected, services.UserRole)
 
+			// 時間制限管理
+			setupTimeRestrictionRoutes(protected, services.TimeRestriction, appLogger)
+
+			// ユーザースコープ管理
+			setupUserScopeRoutes(protected, services.UserScope, appLogger)
+
 			// 部署管理
 			setupDepartmentRoutes(protected, services.Department, appLogger)
 
@@ -189,12 +438,33 @@ func setupRoutes(services *ServiceContainer, middlewares *MiddlewareContainer, a
 
 			// 権限管理
 			setupPermissionRoutes(protected, services.Permission, appLogger)
+
+			// 認可判定の調査
+			setupAuthzRoutes(protected, services.Permission, appLogger)
+
+			// 承認ワークフロー
+			setupApprovalRoutes(protected, services.Approval, appLogger)
+			setupApprovalFlowRoutes(protected, services.ApprovalFlow, appLogger)
+
+			// 監査ログ
+			setupAuditRoutes(protected, services.Audit, appLogger)
+
+			// システム管理
+			setupSystemRoutes(protected, services.Jobs, services.Permission, appLogger)
 		}
 	}
 
 	return router
 }
 
+// setupWellKnownRoutes /.well-known エンドポイントを設定
+func setupWellKnownRoutes(router *gin.Engine, jwtService *jwt.Service) {
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, jwtService.JWKS())
+	})
+}
+
 // setupBasicRoutes 基本エンドポイントを設定
 func setupBasicRoutes(router *gin.Engine) {
 	// ヘルスチェック
@@ -392,6 +662,11 @@ func setupBasicRoutes(router *gin.Engine) {
                     <span class="path">/version</span>
                     <span class="description">バージョン情報</span>
                 </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/.well-known/jwks.json</span>
+                    <span class="description">JWT検証用公開鍵（JWKS）</span>
+                </div>
             </div>
 
             <div class="endpoint-category">
@@ -411,6 +686,66 @@ func setupBasicRoutes(router *gin.Engine) {
                     <span class="path">/api/v1/auth/logout</span>
                     <span class="description">ログアウト</span>
                 </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/auth/introspect</span>
+                    <span class="description">トークンイントロスペクション（下流サービス向け）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/auth/password/forgot</span>
+                    <span class="description">パスワード再設定メール送付</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/auth/password/reset</span>
+                    <span class="description">パスワード再設定</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/auth/mfa/verify</span>
+                    <span class="description">MFAコード検証（二段階ログイン）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/auth/mfa/setup</span>
+                    <span class="description">MFA登録開始（ログイン時に登録が必要な場合）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/auth/mfa/setup/confirm</span>
+                    <span class="description">MFA登録完了・トークン発行</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/auth/mfa</span>
+                    <span class="description">MFA登録状況</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/auth/mfa/enroll</span>
+                    <span class="description">MFA登録開始</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/auth/mfa/enroll/confirm</span>
+                    <span class="description">MFA登録完了</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/auth/sessions</span>
+                    <span class="description">ログインセッション一覧</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method delete">DELETE</span>
+                    <span class="path">/api/v1/auth/sessions</span>
+                    <span class="description">他の全セッションを無効化</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method delete">DELETE</span>
+                    <span class="path">/api/v1/auth/sessions/{id}</span>
+                    <span class="description">セッション無効化</span>
+                </div>
                 <div class="endpoint">
                     <span class="method get">GET</span>
                     <span class="path">/api/v1/auth/profile</span>
@@ -450,6 +785,31 @@ func setupBasicRoutes(router *gin.Engine) {
                     <span class="path">/api/v1/users/{id}/status</span>
                     <span class="description">ステータス変更</span>
                 </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/users/{id}/unlock</span>
+                    <span class="description">アカウントロック解除</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method delete">DELETE</span>
+                    <span class="path">/api/v1/users/{id}/mfa</span>
+                    <span class="description">MFA登録リセット</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/users/{id}/sessions</span>
+                    <span class="description">ユーザーのセッション一覧</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method delete">DELETE</span>
+                    <span class="path">/api/v1/users/{id}/sessions</span>
+                    <span class="description">ユーザーの全セッション無効化</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method delete">DELETE</span>
+                    <span class="path">/api/v1/users/{id}/sessions/{session_id}</span>
+                    <span class="description">ユーザーのセッション無効化</span>
+                </div>
                 <div class="endpoint">
                     <span class="method put">PUT</span>
                     <span class="path">/api/v1/users/{id}/password</span>
@@ -462,7 +822,7 @@ func setupBasicRoutes(router *gin.Engine) {
                 <div class="endpoint">
                     <span class="method post">POST</span>
                     <span class="path">/api/v1/users/roles</span>
-                    <span class="description">ロール割り当て</span>
+                    <span class="description">ロール割り当て（承認が必要なロールは承認申請）</span>
                 </div>
                 <div class="endpoint">
                     <span class="method get">GET</span>
@@ -481,6 +841,84 @@ func setupBasicRoutes(router *gin.Engine) {
                 </div>
             </div>
 
+            <div class="endpoint-category">
+                <div class="category-title">⏰ 時間制限管理</div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/users/{id}/time-restrictions</span>
+                    <span class="description">時間制限作成</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/users/{id}/time-restrictions</span>
+                    <span class="description">時間制限一覧</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/users/{id}/time-restrictions/business-hours</span>
+                    <span class="description">営業時間プリセット適用</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/users/{id}/time-restrictions/check</span>
+                    <span class="description">アクセス可否ドライラン</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/users/{id}/time-restrictions/{restriction_id}</span>
+                    <span class="description">時間制限詳細</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method put">PUT</span>
+                    <span class="path">/api/v1/users/{id}/time-restrictions/{restriction_id}</span>
+                    <span class="description">時間制限更新</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method delete">DELETE</span>
+                    <span class="path">/api/v1/users/{id}/time-restrictions/{restriction_id}</span>
+                    <span class="description">時間制限削除</span>
+                </div>
+            </div>
+
+            <div class="endpoint-category">
+                <div class="category-title">🗺️ ユーザースコープ管理</div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/users/{id}/scopes</span>
+                    <span class="description">スコープ一覧</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/users/{id}/scopes</span>
+                    <span class="description">スコープ追加</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method put">PUT</span>
+                    <span class="path">/api/v1/users/{id}/scopes</span>
+                    <span class="description">リソース種別単位のスコープ置換</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method delete">DELETE</span>
+                    <span class="path">/api/v1/users/{id}/scopes?resource_type=</span>
+                    <span class="description">リソース種別単位のスコープ削除</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method delete">DELETE</span>
+                    <span class="path">/api/v1/users/{id}/scopes/{scope_id}</span>
+                    <span class="description">スコープ削除</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/scopes/users</span>
+                    <span class="description">スコープ到達ユーザー検索</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/scopes/schemas</span>
+                    <span class="description">スコープ値スキーマ</span>
+                </div>
+            </div>
+
             <div class="endpoint-category">
                 <div class="category-title">🏢 部署管理</div>
                 <div class="endpoint">
@@ -606,6 +1044,143 @@ func setupBasicRoutes(router *gin.Engine) {
                     <span class="path">/api/v1/permissions/{id}/roles</span>
                     <span class="description">権限を持つロール一覧</span>
                 </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/authz/explain</span>
+                    <span class="description">認可判定の根拠（ロール・継承・時間制限・スコープ）（管理者）</span>
+                </div>
+            </div>
+
+            <div class="endpoint-category">
+                <div class="category-title">✅ 承認ワークフロー</div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/approvals</span>
+                    <span class="description">承認申請</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/approvals</span>
+                    <span class="description">自分の申請一覧</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/approvals/pending</span>
+                    <span class="description">自分の承認待ち一覧</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/approvals/{id}</span>
+                    <span class="description">申請詳細・承認履歴</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/approvals/{id}/approve</span>
+                    <span class="description">承認</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/approvals/{id}/reject</span>
+                    <span class="description">却下</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/approvals/{id}/return</span>
+                    <span class="description">差し戻し</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/approvals/{id}/resubmit</span>
+                    <span class="description">再申請</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/approvals/{id}/cancel</span>
+                    <span class="description">取り下げ</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/approval-flows</span>
+                    <span class="description">承認フロー定義一覧（管理者）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/approval-flows/simulate</span>
+                    <span class="description">承認ルート・承認者シミュレーション（管理者）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/approval-flows/{resource_type}</span>
+                    <span class="description">承認フロー定義取得（管理者）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method put">PUT</span>
+                    <span class="path">/api/v1/approval-flows/{resource_type}</span>
+                    <span class="description">承認フロー定義・置き換え（管理者）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method delete">DELETE</span>
+                    <span class="path">/api/v1/approval-flows/{resource_type}</span>
+                    <span class="description">承認フロー削除（管理者）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method patch">PATCH</span>
+                    <span class="path">/api/v1/approval-flows/{resource_type}/steps/{state_id}</span>
+                    <span class="description">承認ステップ更新（管理者）</span>
+                </div>
+            </div>
+
+            <div class="endpoint-category">
+                <div class="category-title">📜 監査ログ</div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/audit-logs</span>
+                    <span class="description">監査ログ一覧（複合フィルター・カーソルページング）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/audit-logs/stats</span>
+                    <span class="description">監査統計</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/audit-logs/export</span>
+                    <span class="description">監査ログエクスポート（CSV/NDJSON）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/audit-logs/verify</span>
+                    <span class="description">ハッシュチェーン検証（管理者）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method post">POST</span>
+                    <span class="path">/api/v1/audit-logs/checkpoints</span>
+                    <span class="description">署名付きチェックポイント作成</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/audit-logs/users/{id}/activity</span>
+                    <span class="description">ユーザー別アクティビティ</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/audit-logs/{id}</span>
+                    <span class="description">監査ログ詳細</span>
+                </div>
+            </div>
+
+            <div class="endpoint-category">
+                <div class="category-title">⚙️ システム管理</div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/system/jobs</span>
+                    <span class="description">定期ジョブの実行状況（管理者）</span>
+                </div>
+                <div class="endpoint">
+                    <span class="method get">GET</span>
+                    <span class="path">/api/v1/system/permission-cache</span>
+                    <span class="description">権限キャッシュのヒット率（管理者）</span>
+                </div>
             </div>
         </div>
 
@@ -624,8 +1199,12 @@ func setupBasicRoutes(router *gin.Engine) {
 }
 
 // setupAuthRoutes 認証エンドポイントを設定
-func setupAuthRoutes(group *gin.RouterGroup, authService *services.AuthService, middlewares *MiddlewareContainer, appLogger *logger.Logger) {
-	authHandler := handlers.NewAuthHandler(authService, appLogger)
+func setupAuthRoutes(group *gin.RouterGroup, services *ServiceContainer, middlewares *MiddlewareContainer, appLogger *logger.Logger) {
+	authHandler := handlers.NewAuthHandler(services.Auth, appLogger)
+	mfaHandler := handlers.NewMFAHandler(services.Auth, services.MFA, appLogger)
+	passwordResetHandler := handlers.NewPasswordResetHandler(services.PasswordReset, appLogger)
+	introspectionHandler := handlers.NewIntrospectionHandler(services.Introspection, appLogger)
+	sessionHandler := handlers.NewSessionHandler(services.Session, appLogger)
 
 	auth := group.Group("/auth")
 	{
@@ -634,31 +1213,64 @@ func setupAuthRoutes(group *gin.RouterGroup, authService *services.AuthService,
 		auth.POST("/refresh", authHandler.RefreshToken)
 		auth.POST("/logout", authHandler.Logout)
 
+		// パスワード再設定（メールで送付したトークンで認証）
+		auth.POST("/password/forgot", passwordResetHandler.Forgot) // POST /api/v1/auth/password/forgot
+		auth.POST("/password/reset", passwordResetHandler.Reset)   // POST /api/v1/auth/password/reset
+
+		// トークンイントロスペクション（下流サービスがクライアント認証情報で呼び出す）
+		auth.POST("/introspect", introspectionHandler.Introspect) // POST /api/v1/auth/introspect
+
+		// 二段階ログイン（パスワード認証後のチャレンジトークンで認証）
+		auth.POST("/mfa/verify", mfaHandler.Verify)              // POST /api/v1/auth/mfa/verify
+		auth.POST("/mfa/setup", mfaHandler.Setup)                // POST /api/v1/auth/mfa/setup
+		auth.POST("/mfa/setup/confirm", mfaHandler.ConfirmSetup) // POST /api/v1/auth/mfa/setup/confirm
+
 		// 認証必要エンドポイント
 		protected := auth.Group("")
 		protected.Use(middlewares.Auth.Authentication())
 		{
 			protected.GET("/profile", authHandler.GetProfile)
 			protected.POST("/change-password", authHandler.ChangePassword)
+
+			// MFA登録（ログイン中のユーザー自身）
+			protected.GET("/mfa", mfaHandler.GetStatus)                     // GET /api/v1/auth/mfa
+			protected.POST("/mfa/enroll", mfaHandler.Enroll)                // POST /api/v1/auth/mfa/enroll
+			protected.POST("/mfa/enroll/confirm", mfaHandler.ConfirmEnroll) // POST /api/v1/auth/mfa/enroll/confirm
+
+			// ログインセッション（ログイン中のユーザー自身）
+			protected.GET("/sessions", sessionHandler.ListMySessions)           // GET /api/v1/auth/sessions
+			protected.DELETE("/sessions", sessionHandler.RevokeMyOtherSessions) // DELETE /api/v1/auth/sessions
+			protected.DELETE("/sessions/:id", sessionHandler.RevokeMySession)   // DELETE /api/v1/auth/sessions/:id
 		}
 	}
 }
 
 // setupUserRoutes ユーザー管理エンドポイントを設定
-func setupUserRoutes(group *gin.RouterGroup, userService *services.UserService, appLogger *logger.Logger) {
+func setupUserRoutes(group *gin.RouterGroup, userService *services.UserService, sessionService *services.SessionService, appLogger *logger.Logger) {
 	userHandler := handlers.NewUserHandler(userService, appLogger)
+	sessionHandler := handlers.NewSessionHandler(sessionService, appLogger)
+
+	// 対象ユーザーの所属部署でスコープを照合（部署管理者は自部署配下のユーザーのみ操作可能）
+	userScope := middleware.ScopeFromResource("id", userService.GetUserScopeAttributes)
 
 	users := group.Group("/users")
 	{
 		// ユーザーCRUD（権限チェック付き）
-		users.POST("", middleware.RequirePermissions("user:create"), userHandler.CreateUser)       // POST /api/v1/users
-		users.GET("", middleware.RequirePermissions("user:list"), userHandler.GetUsers)            // GET /api/v1/users
-		users.GET("/:id", middleware.RequirePermissions("user:read"), userHandler.GetUser)         // GET /api/v1/users/:id
-		users.PUT("/:id", middleware.RequirePermissions("user:update"), userHandler.UpdateUser)    // PUT /api/v1/users/:id
-		users.DELETE("/:id", middleware.RequirePermissions("user:delete"), userHandler.DeleteUser) // DELETE /api/v1/users/:id
+		users.POST("", middleware.RequirePermissions("user:create"), userHandler.CreateUser)                       // POST /api/v1/users
+		users.GET("", middleware.RequirePermissions("user:list"), userHandler.GetUsers)                            // GET /api/v1/users
+		users.GET("/:id", middleware.RequirePermissions("user:read"), userHandler.GetUser)                         // GET /api/v1/users/:id
+		users.PUT("/:id", middleware.RequireScopedPermission("user:update", userScope), userHandler.UpdateUser)    // PUT /api/v1/users/:id
+		users.DELETE("/:id", middleware.RequireScopedPermission("user:delete", userScope), userHandler.DeleteUser) // DELETE /api/v1/users/:id
 
 		// ステータス変更（管理者権限）
-		users.PUT("/:id/status", middleware.RequirePermissions("user:manage"), userHandler.ChangeUserStatus) // PUT /api/v1/users/:id/status
+		users.PUT("/:id/status", middleware.RequireScopedPermission("user:manage", userScope), userHandler.ChangeUserStatus) // PUT /api/v1/users/:id/status
+		users.POST("/:id/unlock", middleware.RequireScopedPermission("user:manage", userScope), userHandler.UnlockUser)      // POST /api/v1/users/:id/unlock
+		users.DELETE("/:id/mfa", middleware.RequireScopedPermission("user:manage", userScope), userHandler.ResetMFA)         // DELETE /api/v1/users/:id/mfa
+
+		// ログインセッション管理（管理者権限）
+		users.GET("/:id/sessions", middleware.RequireScopedPermission("user:manage", userScope), sessionHandler.ListUserSessions)                 // GET /api/v1/users/:id/sessions
+		users.DELETE("/:id/sessions", middleware.RequireScopedPermission("user:manage", userScope), sessionHandler.RevokeUserSessions)            // DELETE /api/v1/users/:id/sessions
+		users.DELETE("/:id/sessions/:session_id", middleware.RequireScopedPermission("user:manage", userScope), sessionHandler.RevokeUserSession) // DELETE /api/v1/users/:id/sessions/:session_id
 
 		// パスワード変更（自己のみ）
 		users.PUT("/:id/password", userHandler.ChangePassword) // PUT /api/v1/users/:id/password
@@ -675,6 +1287,42 @@ func setupUserRoleRoutes(group *gin.RouterGroup, userRoleService *services.UserR
 	group.DELETE("/users/:id/roles/:role_id", userRoleHandler.RevokeRole)
 }
 
+// setupTimeRestrictionRoutes 時間制限管理エンドポイントを設定
+func setupTimeRestrictionRoutes(group *gin.RouterGroup, timeRestrictionService *services.TimeRestrictionService, appLogger *logger.Logger) {
+	timeRestrictionHandler := handlers.NewTimeRestrictionHandler(timeRestrictionService, appLogger)
+
+	restrictions := group.Group("/users/:id/time-restrictions")
+	{
+		restrictions.POST("", middleware.RequirePermissions("user:manage"), timeRestrictionHandler.CreateTimeRestriction)                   // POST /api/v1/users/:id/time-restrictions
+		restrictions.GET("", middleware.RequirePermissions("user:read"), timeRestrictionHandler.GetTimeRestrictions)                        // GET /api/v1/users/:id/time-restrictions
+		restrictions.POST("/business-hours", middleware.RequirePermissions("user:manage"), timeRestrictionHandler.ApplyBusinessHoursPreset) // POST /api/v1/users/:id/time-restrictions/business-hours
+		restrictions.GET("/check", middleware.RequirePermissions("user:read"), timeRestrictionHandler.CheckTimeAccess)                      // GET /api/v1/users/:id/time-restrictions/check
+		restrictions.GET("/:restriction_id", middleware.RequirePermissions("user:read"), timeRestrictionHandler.GetTimeRestriction)         // GET /api/v1/users/:id/time-restrictions/:restriction_id
+		restrictions.PUT("/:restriction_id", middleware.RequirePermissions("user:manage"), timeRestrictionHandler.UpdateTimeRestriction)    // PUT /api/v1/users/:id/time-restrictions/:restriction_id
+		restrictions.DELETE("/:restriction_id", middleware.RequirePermissions("user:manage"), timeRestrictionHandler.DeleteTimeRestriction) // DELETE /api/v1/users/:id/time-restrictions/:restriction_id
+	}
+}
+
+// setupUserScopeRoutes ユーザースコープ管理エンドポイントを設定
+func setupUserScopeRoutes(group *gin.RouterGroup, userScopeService *services.UserScopeService, appLogger *logger.Logger) {
+	userScopeHandler := handlers.NewUserScopeHandler(userScopeService, appLogger)
+
+	userScopes := group.Group("/users/:id/scopes")
+	{
+		userScopes.GET("", middleware.RequirePermissions("user:read"), userScopeHandler.GetUserScopes)                   // GET /api/v1/users/:id/scopes
+		userScopes.POST("", middleware.RequirePermissions("user:manage"), userScopeHandler.AddUserScope)                 // POST /api/v1/users/:id/scopes
+		userScopes.PUT("", middleware.RequirePermissions("user:manage"), userScopeHandler.ReplaceUserScopes)             // PUT /api/v1/users/:id/scopes
+		userScopes.DELETE("", middleware.RequirePermissions("user:manage"), userScopeHandler.DeleteUserScopesByResource) // DELETE /api/v1/users/:id/scopes?resource_type=
+		userScopes.DELETE("/:scope_id", middleware.RequirePermissions("user:manage"), userScopeHandler.DeleteUserScope)  // DELETE /api/v1/users/:id/scopes/:scope_id
+	}
+
+	scopes := group.Group("/scopes")
+	{
+		scopes.GET("/users", middleware.RequirePermissions("user:list"), userScopeHandler.FindUsersByScope)  // GET /api/v1/scopes/users
+		scopes.GET("/schemas", middleware.RequirePermissions("user:read"), userScopeHandler.GetScopeSchemas) // GET /api/v1/scopes/schemas
+	}
+}
+
 // setupDepartmentRoutes 部署管理エンドポイントを設定
 func setupDepartmentRoutes(group *gin.RouterGroup, departmentService *services.DepartmentService, appLogger *logger.Logger) {
 	departmentHandler := handlers.NewDepartmentHandler(departmentService, appLogger)
@@ -726,8 +1374,81 @@ func setupPermissionRoutes(group *gin.RouterGroup, permissionService *services.P
 	}
 }
 
-// startServer サーバーを起動
-func startServer(router *gin.Engine, port string) {
+// setupAuthzRoutes 認可判定の調査エンドポイントを設定
+// 任意のユーザーのロール・スコープ・時間制限を参照できるため、システム管理者のみに限定する
+func setupAuthzRoutes(group *gin.RouterGroup, permissionService *services.PermissionService, appLogger *logger.Logger) {
+	authzHandler := handlers.NewAuthzHandler(permissionService, appLogger)
+
+	authz := group.Group("/authz")
+	{
+		authz.GET("/explain", middleware.RequirePermissions("system:admin"), authzHandler.Explain) // GET /api/v1/authz/explain
+	}
+}
+
+// setupApprovalRoutes 承認ワークフローエンドポイントを設定
+// 承認操作の可否は承認フロー（approval_states）の承認ロールで判定
+func setupApprovalRoutes(group *gin.RouterGroup, approvalService *services.ApprovalService, appLogger *logger.Logger) {
+	approvalHandler := handlers.NewApprovalHandler(approvalService, appLogger)
+
+	approvals := group.Group("/approvals")
+	{
+		approvals.POST("", approvalHandler.SubmitApprovalRequest)        // POST /api/v1/approvals
+		approvals.GET("", approvalHandler.GetMyApprovalRequests)         // GET /api/v1/approvals
+		approvals.GET("/pending", approvalHandler.GetPendingApprovals)   // GET /api/v1/approvals/pending
+		approvals.GET("/:id", approvalHandler.GetApprovalRequest)        // GET /api/v1/approvals/:id
+		approvals.POST("/:id/approve", approvalHandler.ApproveRequest)   // POST /api/v1/approvals/:id/approve
+		approvals.POST("/:id/reject", approvalHandler.RejectRequest)     // POST /api/v1/approvals/:id/reject
+		approvals.POST("/:id/return", approvalHandler.ReturnRequest)     // POST /api/v1/approvals/:id/return
+		approvals.POST("/:id/resubmit", approvalHandler.ResubmitRequest) // POST /api/v1/approvals/:id/resubmit
+		approvals.POST("/:id/cancel", approvalHandler.CancelRequest)     // POST /api/v1/approvals/:id/cancel
+	}
+}
+
+// setupApprovalFlowRoutes 承認フロー定義エンドポイントを設定
+func setupApprovalFlowRoutes(group *gin.RouterGroup, approvalFlowService *services.ApprovalFlowService, appLogger *logger.Logger) {
+	approvalFlowHandler := handlers.NewApprovalFlowHandler(approvalFlowService, appLogger)
+
+	approvalFlows := group.Group("/approval-flows")
+	{
+		approvalFlows.GET("", middleware.RequirePermissions("system:admin"), approvalFlowHandler.GetApprovalFlows)                                        // GET /api/v1/approval-flows
+		approvalFlows.POST("/simulate", middleware.RequirePermissions("system:admin"), approvalFlowHandler.SimulateApprovalFlow)                          // POST /api/v1/approval-flows/simulate
+		approvalFlows.GET("/:resource_type", middleware.RequirePermissions("system:admin"), approvalFlowHandler.GetApprovalFlow)                          // GET /api/v1/approval-flows/:resource_type
+		approvalFlows.PUT("/:resource_type", middleware.RequirePermissions("system:admin"), approvalFlowHandler.DefineApprovalFlow)                       // PUT /api/v1/approval-flows/:resource_type
+		approvalFlows.DELETE("/:resource_type", middleware.RequirePermissions("system:admin"), approvalFlowHandler.DeleteApprovalFlow)                    // DELETE /api/v1/approval-flows/:resource_type
+		approvalFlows.PATCH("/:resource_type/steps/:state_id", middleware.RequirePermissions("system:admin"), approvalFlowHandler.UpdateApprovalFlowStep) // PATCH /api/v1/approval-flows/:resource_type/steps/:state_id
+	}
+}
+
+// setupAuditRoutes 監査ログエンドポイントを設定
+func setupAuditRoutes(group *gin.RouterGroup, auditService *services.AuditService, appLogger *logger.Logger) {
+	auditHandler := handlers.NewAuditHandler(auditService, appLogger)
+
+	auditLogs := group.Group("/audit-logs")
+	{
+		auditLogs.GET("", middleware.RequireAnyPermission("audit:read", "audit:view"), auditHandler.GetAuditLogs)                       // GET /api/v1/audit-logs
+		auditLogs.GET("/stats", middleware.RequireAnyPermission("audit:read", "audit:view"), auditHandler.GetAuditStats)                // GET /api/v1/audit-logs/stats
+		auditLogs.GET("/export", middleware.RequirePermissions("audit:export"), auditHandler.ExportAuditLogs)                           // GET /api/v1/audit-logs/export
+		auditLogs.GET("/verify", middleware.RequirePermissions("system:admin"), auditHandler.VerifyChain)                               // GET /api/v1/audit-logs/verify
+		auditLogs.POST("/checkpoints", middleware.RequirePermissions("audit:export"), auditHandler.CreateCheckpoint)                    // POST /api/v1/audit-logs/checkpoints
+		auditLogs.GET("/users/:id/activity", middleware.RequireAnyPermission("audit:read", "audit:view"), auditHandler.GetUserActivity) // GET /api/v1/audit-logs/users/:id/activity
+		auditLogs.GET("/:id", middleware.RequireAnyPermission("audit:read", "audit:view"), auditHandler.GetAuditLog)                    // GET /api/v1/audit-logs/:id
+	}
+}
+
+// setupSystemRoutes システム管理エンドポイントを設定
+func setupSystemRoutes(group *gin.RouterGroup, scheduler *jobs.Scheduler, permissionService *services.PermissionService, appLogger *logger.Logger) {
+	jobHandler := handlers.NewJobHandler(scheduler, appLogger)
+	permissionCacheHandler := handlers.NewPermissionCacheHandler(permissionService)
+
+	system := group.Group("/system")
+	{
+		system.GET("/jobs", middleware.RequirePermissions("system:admin"), jobHandler.GetJobs)                          // GET /api/v1/system/jobs
+		system.GET("/permission-cache", middleware.RequirePermissions("system:admin"), permissionCacheHandler.GetStats) // GET /api/v1/system/permission-cache
+	}
+}
+
+// startServer サーバーを起動し、ctxの終了で処理中のリクエストを待ってから停止
+func startServer(ctx context.Context, router *gin.Engine, port string) {
 	if port == "" {
 		port = "8080"
 	}
@@ -738,24 +1459,50 @@ func startServer(router *gin.Engine, port string) {
 	log.Printf("🏥 ヘルスチェック: http://localhost:%s/health", port)
 	log.Printf("📚 API仕様: http://localhost:%s/", port)
 
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("❌ サーバー起動エラー: %v", err)
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("❌ サーバー起動エラー: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Printf("🛑 シャットダウン中...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️ サーバー停止エラー: %v", err)
 	}
 }
 
 // ServiceContainer サービスコンテナ
 type ServiceContainer struct {
-	Auth       *services.AuthService
-	Permission *services.PermissionService
-	Revocation *services.TokenRevocationService
-	UserRole   *services.UserRoleService
-	User       *services.UserService
-	Department *services.DepartmentService
-	Role       *services.RoleService
-	JWT        *jwt.Service
+	Auth            *services.AuthService
+	MFA             *services.MFAService
+	PasswordReset   *services.PasswordResetService
+	Session         *services.SessionService
+	Introspection   *services.TokenIntrospectionService
+	Permission      *services.PermissionService
+	Revocation      *services.TokenRevocationService
+	UserRole        *services.UserRoleService
+	User            *services.UserService
+	Department      *services.DepartmentService
+	Role            *services.RoleService
+	TimeRestriction *services.TimeRestrictionService
+	UserScope       *services.UserScopeService
+	Approval        *services.ApprovalService
+	ApprovalFlow    *services.ApprovalFlowService
+	Audit           *services.AuditService
+	JWT             *jwt.Service
+	Jobs            *jobs.Scheduler
 }
 
 // MiddlewareContainer ミドルウェアコンテナ
 type MiddlewareContainer struct {
-	Auth *middleware.AuthMiddleware
+	Auth  *middleware.AuthMiddleware
+	Audit *middleware.AuditMiddleware
 }
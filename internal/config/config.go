@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -14,6 +15,11 @@ type Config struct {
 	Database    DatabaseConfig `mapstructure:"database"`
 	JWT         JWTConfig      `mapstructure:"jwt"`
 	Logger      LoggerConfig   `mapstructure:"logger"`
+	Audit       AuditConfig    `mapstructure:"audit"`
+	Security    SecurityConfig `mapstructure:"security"`
+	Mail        MailConfig     `mapstructure:"mail"`
+	Jobs        JobsConfig     `mapstructure:"jobs"`
+	Redis       RedisConfig    `mapstructure:"redis"`
 }
 
 // ServerConfig サーバー設定
@@ -35,10 +41,15 @@ type DatabaseConfig struct {
 
 // JWTConfig JWT認証設定
 type JWTConfig struct {
-	Secret              string        `mapstructure:"secret"`
-	ExpiresIn           time.Duration `mapstructure:"expires_in"`
-	AccessTokenDuration time.Duration `mapstructure:"access_token_duration"`
-	Issuer              string        `mapstructure:"issuer"`
+	Secret               string        `mapstructure:"secret"`
+	ExpiresIn            time.Duration `mapstructure:"expires_in"`
+	AccessTokenDuration  time.Duration `mapstructure:"access_token_duration"`
+	RefreshTokenDuration time.Duration `mapstructure:"refresh_token_duration"`
+	Issuer               string        `mapstructure:"issuer"`
+
+	// 非対称鍵署名（設定時はSecretの代わりに使用し、/.well-known/jwks.json で公開鍵を配布）
+	SigningKeyFile       string   `mapstructure:"signing_key_file"`       // RSA/Ed25519秘密鍵（PKCS#8・PKCS#1 PEM）
+	VerificationKeyFiles []string `mapstructure:"verification_key_files"` // ローテーション前の公開鍵（PKIX PEM）
 }
 
 // LoggerConfig ログ設定
@@ -47,6 +58,128 @@ type LoggerConfig struct {
 	Format string `mapstructure:"format"`
 }
 
+// AuditConfig 監査ログ設定
+type AuditConfig struct {
+	CheckpointKeyFile string `mapstructure:"checkpoint_key_file"` // チェックポイント署名用Ed25519秘密鍵（PKCS#8 PEM）
+}
+
+// SecurityConfig 認証セキュリティ設定
+type SecurityConfig struct {
+	Lockout         LockoutConfig         `mapstructure:"lockout"`
+	MFA             MFAConfig             `mapstructure:"mfa"`
+	Password        PasswordPolicyConfig  `mapstructure:"password"`
+	PasswordReset   PasswordResetConfig   `mapstructure:"password_reset"`
+	Introspection   IntrospectionConfig   `mapstructure:"introspection"`
+	Revocation      RevocationConfig      `mapstructure:"revocation"`
+	PermissionCache PermissionCacheConfig `mapstructure:"permission_cache"`
+}
+
+// LockoutConfig ログイン失敗によるアカウントロックアウト設定
+type LockoutConfig struct {
+	Threshold   int           `mapstructure:"threshold"`    // ロックするまでの連続失敗回数
+	Duration    time.Duration `mapstructure:"duration"`     // 初回のロック期間（以降の失敗ごとに倍増）
+	MaxDuration time.Duration `mapstructure:"max_duration"` // ロック期間の上限
+}
+
+// MFAConfig TOTP多要素認証設定
+type MFAConfig struct {
+	Issuer            string        `mapstructure:"issuer"`             // 認証アプリに表示する発行者名
+	ChallengeDuration time.Duration `mapstructure:"challenge_duration"` // パスワード認証後のMFAチャレンジ有効期間
+}
+
+// PasswordPolicyConfig パスワードポリシー設定
+type PasswordPolicyConfig struct {
+	MinLength        int           `mapstructure:"min_length"`
+	MaxLength        int           `mapstructure:"max_length"` // bcryptは72バイトまで
+	RequireUppercase bool          `mapstructure:"require_uppercase"`
+	RequireLowercase bool          `mapstructure:"require_lowercase"`
+	RequireDigit     bool          `mapstructure:"require_digit"`
+	RequireSymbol    bool          `mapstructure:"require_symbol"`
+	BannedListFile   string        `mapstructure:"banned_list_file"` // 使用禁止パスワード一覧（1行1件）
+	HistoryDepth     int           `mapstructure:"history_depth"`    // 再利用を禁止する直近のパスワード数（0で無効）
+	MaxAge           time.Duration `mapstructure:"max_age"`          // パスワードの有効期間（0で無期限）
+	BcryptCost       int           `mapstructure:"bcrypt_cost"`
+}
+
+// PasswordResetConfig パスワード再設定設定
+type PasswordResetConfig struct {
+	TokenDuration time.Duration `mapstructure:"token_duration"` // 再設定トークンの有効期間
+	URL           string        `mapstructure:"url"`            // メールに記載する再設定画面のURL（?token= を付与）
+}
+
+// IntrospectionConfig トークンイントロスペクション設定
+type IntrospectionConfig struct {
+	Clients []string `mapstructure:"clients"` // 下流サービスの認証情報（client_id:client_secret）
+}
+
+// ClientSecrets client_id → client_secret のマップを取得
+func (c IntrospectionConfig) ClientSecrets() (map[string]string, error) {
+	secrets := make(map[string]string, len(c.Clients))
+	for _, entry := range c.Clients {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		clientID, secret, found := strings.Cut(entry, ":")
+		if !found || clientID == "" || secret == "" {
+			return nil, fmt.Errorf("invalid introspection client entry (expected client_id:client_secret)")
+		}
+		secrets[clientID] = secret
+	}
+	return secrets, nil
+}
+
+// RevocationConfig トークン無効化状態のキャッシュ設定
+type RevocationConfig struct {
+	CacheEnabled bool          `mapstructure:"cache_enabled"` // 無効化状態をプロセス内にキャッシュ（無効の場合はリクエストごとにDBを参照）
+	PollInterval time.Duration `mapstructure:"poll_interval"` // 他のレプリカの無効化を取り込む間隔
+}
+
+// PermissionCacheConfig 実効権限のキャッシュ設定
+type PermissionCacheConfig struct {
+	Driver string        `mapstructure:"driver"` // memory / redis / none
+	Size   int           `mapstructure:"size"`   // driver=memory で保持するユーザー数の上限
+	TTL    time.Duration `mapstructure:"ttl"`    // エントリの最大保持期間（変更を検知できない直接のDB更新の反映までの上限）
+}
+
+// JobsConfig 定期ジョブ設定（間隔に0を指定したジョブは実行しない）
+type JobsConfig struct {
+	Enabled                     bool          `mapstructure:"enabled"`
+	RevokedTokenCleanupInterval time.Duration `mapstructure:"revoked_token_cleanup_interval"` // 期限切れの無効化トークンの削除
+	ExpiredRoleCleanupInterval  time.Duration `mapstructure:"expired_role_cleanup_interval"`  // 有効期限を過ぎたロール割り当ての無効化
+	AuthTokenCleanupInterval    time.Duration `mapstructure:"auth_token_cleanup_interval"`    // 期限切れのリフレッシュトークン・再設定トークン・セッションの削除
+}
+
+// RedisConfig Redis互換サーバーの接続設定
+type RedisConfig struct {
+	Host     string        `mapstructure:"host"`
+	Port     int           `mapstructure:"port"`
+	Password string        `mapstructure:"password"`
+	DB       int           `mapstructure:"db"`
+	Timeout  time.Duration `mapstructure:"timeout"` // 接続・コマンドのタイムアウト
+}
+
+// Address 接続先アドレス（host:port）を取得
+func (c RedisConfig) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// MailConfig メール送信設定
+type MailConfig struct {
+	Driver   string         `mapstructure:"driver"` // smtp / file / log
+	From     string         `mapstructure:"from"`
+	FilePath string         `mapstructure:"file_path"` // driver=file の出力先
+	SMTP     SMTPMailConfig `mapstructure:"smtp"`
+}
+
+// SMTPMailConfig SMTPサーバー設定
+type SMTPMailConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
 // Load 環境変数と設定ファイルから設定を読み込む
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
@@ -101,11 +234,64 @@ func setDefaults() {
 	viper.SetDefault("jwt.secret", "your-super-secret-jwt-key-256-bits-long")
 	viper.SetDefault("jwt.expires_in", "24h")
 	viper.SetDefault("jwt.access_token_duration", "15m")
+	viper.SetDefault("jwt.refresh_token_duration", "168h")
 	viper.SetDefault("jwt.issuer", "erp-access-control-api")
+	viper.SetDefault("jwt.signing_key_file", "")
+	viper.SetDefault("jwt.verification_key_files", []string{})
 
 	// Logger defaults
 	viper.SetDefault("logger.level", "debug")
 	viper.SetDefault("logger.format", "json")
+
+	// Audit defaults
+	viper.SetDefault("audit.checkpoint_key_file", "")
+
+	// Security defaults
+	viper.SetDefault("security.lockout.threshold", 5)
+	viper.SetDefault("security.lockout.duration", "1m")
+	viper.SetDefault("security.lockout.max_duration", "1h")
+	viper.SetDefault("security.mfa.issuer", "ERP Access Control")
+	viper.SetDefault("security.mfa.challenge_duration", "5m")
+	viper.SetDefault("security.password.min_length", 8)
+	viper.SetDefault("security.password.max_length", 72)
+	viper.SetDefault("security.password.require_uppercase", true)
+	viper.SetDefault("security.password.require_lowercase", true)
+	viper.SetDefault("security.password.require_digit", true)
+	viper.SetDefault("security.password.require_symbol", false)
+	viper.SetDefault("security.password.banned_list_file", "")
+	viper.SetDefault("security.password.history_depth", 5)
+	viper.SetDefault("security.password.max_age", "0s")
+	viper.SetDefault("security.password.bcrypt_cost", 10)
+	viper.SetDefault("security.password_reset.token_duration", "30m")
+	viper.SetDefault("security.password_reset.url", "http://localhost:3000/reset-password")
+	viper.SetDefault("security.introspection.clients", []string{})
+	viper.SetDefault("security.revocation.cache_enabled", true)
+	viper.SetDefault("security.revocation.poll_interval", "2s")
+	viper.SetDefault("security.permission_cache.driver", "memory")
+	viper.SetDefault("security.permission_cache.size", 10000)
+	viper.SetDefault("security.permission_cache.ttl", "10m")
+
+	// Jobs defaults
+	viper.SetDefault("jobs.enabled", true)
+	viper.SetDefault("jobs.revoked_token_cleanup_interval", "1h")
+	viper.SetDefault("jobs.expired_role_cleanup_interval", "5m")
+	viper.SetDefault("jobs.auth_token_cleanup_interval", "1h")
+
+	// Redis defaults - Dockerコンテナに合わせた設定
+	viper.SetDefault("redis.host", "localhost")
+	viper.SetDefault("redis.port", 6379)
+	viper.SetDefault("redis.password", "")
+	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("redis.timeout", "500ms")
+
+	// Mail defaults
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("mail.from", "noreply@erp-access-control.local")
+	viper.SetDefault("mail.file_path", "mail.log")
+	viper.SetDefault("mail.smtp.host", "localhost")
+	viper.SetDefault("mail.smtp.port", 25)
+	viper.SetDefault("mail.smtp.username", "")
+	viper.SetDefault("mail.smtp.password", "")
 }
 
 // bindEnvVariables 環境変数を設定キーにバインド
@@ -130,11 +316,64 @@ func bindEnvVariables() {
 	viper.BindEnv("jwt.secret", "JWT_SECRET")
 	viper.BindEnv("jwt.expires_in", "JWT_EXPIRES_IN")
 	viper.BindEnv("jwt.access_token_duration", "JWT_ACCESS_TOKEN_DURATION")
+	viper.BindEnv("jwt.refresh_token_duration", "JWT_REFRESH_TOKEN_DURATION")
 	viper.BindEnv("jwt.issuer", "JWT_ISSUER")
+	viper.BindEnv("jwt.signing_key_file", "JWT_SIGNING_KEY_FILE")
+	viper.BindEnv("jwt.verification_key_files", "JWT_VERIFICATION_KEY_FILES") // カンマ区切り
 
 	// Logger
 	viper.BindEnv("logger.level", "LOG_LEVEL")
 	viper.BindEnv("logger.format", "LOG_FORMAT")
+
+	// Audit
+	viper.BindEnv("audit.checkpoint_key_file", "AUDIT_CHECKPOINT_KEY_FILE")
+
+	// Security
+	viper.BindEnv("security.lockout.threshold", "LOCKOUT_THRESHOLD")
+	viper.BindEnv("security.lockout.duration", "LOCKOUT_DURATION")
+	viper.BindEnv("security.lockout.max_duration", "LOCKOUT_MAX_DURATION")
+	viper.BindEnv("security.mfa.issuer", "MFA_ISSUER")
+	viper.BindEnv("security.mfa.challenge_duration", "MFA_CHALLENGE_DURATION")
+	viper.BindEnv("security.password.min_length", "PASSWORD_MIN_LENGTH")
+	viper.BindEnv("security.password.max_length", "PASSWORD_MAX_LENGTH")
+	viper.BindEnv("security.password.require_uppercase", "PASSWORD_REQUIRE_UPPERCASE")
+	viper.BindEnv("security.password.require_lowercase", "PASSWORD_REQUIRE_LOWERCASE")
+	viper.BindEnv("security.password.require_digit", "PASSWORD_REQUIRE_DIGIT")
+	viper.BindEnv("security.password.require_symbol", "PASSWORD_REQUIRE_SYMBOL")
+	viper.BindEnv("security.password.banned_list_file", "PASSWORD_BANNED_LIST_FILE")
+	viper.BindEnv("security.password.history_depth", "PASSWORD_HISTORY_DEPTH")
+	viper.BindEnv("security.password.max_age", "PASSWORD_MAX_AGE")
+	viper.BindEnv("security.password.bcrypt_cost", "BCRYPT_COST")
+	viper.BindEnv("security.password_reset.token_duration", "PASSWORD_RESET_TOKEN_DURATION")
+	viper.BindEnv("security.password_reset.url", "PASSWORD_RESET_URL")
+	viper.BindEnv("security.introspection.clients", "INTROSPECTION_CLIENTS") // カンマ区切り
+	viper.BindEnv("security.revocation.cache_enabled", "REVOCATION_CACHE_ENABLED")
+	viper.BindEnv("security.revocation.poll_interval", "REVOCATION_POLL_INTERVAL")
+	viper.BindEnv("security.permission_cache.driver", "PERMISSION_CACHE_DRIVER")
+	viper.BindEnv("security.permission_cache.size", "PERMISSION_CACHE_SIZE")
+	viper.BindEnv("security.permission_cache.ttl", "PERMISSION_CACHE_TTL")
+
+	// Jobs
+	viper.BindEnv("jobs.enabled", "JOBS_ENABLED")
+	viper.BindEnv("jobs.revoked_token_cleanup_interval", "JOB_REVOKED_TOKEN_CLEANUP_INTERVAL")
+	viper.BindEnv("jobs.expired_role_cleanup_interval", "JOB_EXPIRED_ROLE_CLEANUP_INTERVAL")
+	viper.BindEnv("jobs.auth_token_cleanup_interval", "JOB_AUTH_TOKEN_CLEANUP_INTERVAL")
+
+	// Redis
+	viper.BindEnv("redis.host", "REDIS_HOST")
+	viper.BindEnv("redis.port", "REDIS_PORT")
+	viper.BindEnv("redis.password", "REDIS_PASSWORD")
+	viper.BindEnv("redis.db", "REDIS_DB")
+	viper.BindEnv("redis.timeout", "REDIS_TIMEOUT")
+
+	// Mail
+	viper.BindEnv("mail.driver", "MAIL_DRIVER")
+	viper.BindEnv("mail.from", "MAIL_FROM")
+	viper.BindEnv("mail.file_path", "MAIL_FILE_PATH")
+	viper.BindEnv("mail.smtp.host", "SMTP_HOST")
+	viper.BindEnv("mail.smtp.port", "SMTP_PORT")
+	viper.BindEnv("mail.smtp.username", "SMTP_USERNAME")
+	viper.BindEnv("mail.smtp.password", "SMTP_PASSWORD")
 }
 
 // GetDatabaseURL データベース接続URLを取得
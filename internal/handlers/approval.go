@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"erp-access-control-go/internal/middleware"
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// ApprovalHandler 承認ワークフローハンドラー
+type ApprovalHandler struct {
+	approvalService *services.ApprovalService
+	logger          *logger.Logger
+}
+
+// NewApprovalHandler 新しい承認ワークフローハンドラーを作成
+func NewApprovalHandler(approvalService *services.ApprovalService, logger *logger.Logger) *ApprovalHandler {
+	return &ApprovalHandler{
+		approvalService: approvalService,
+		logger:          logger,
+	}
+}
+
+// SubmitApprovalRequest 承認申請を作成
+func (h *ApprovalHandler) SubmitApprovalRequest(c *gin.Context) {
+	var req services.SubmitApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid approval request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("Submit approval request", map[string]interface{}{
+		"resource_type": req.ResourceType,
+		"requested_by":  userID,
+		"ip":            c.ClientIP(),
+	})
+
+	request, err := h.approvalService.SubmitApprovalRequest(userID, req)
+	if err != nil {
+		h.logger.Error("Failed to submit approval request", err, map[string]interface{}{
+			"resource_type": req.ResourceType,
+			"requested_by":  userID,
+			"ip":            c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
+
+// GetMyApprovalRequests 自分の承認申請一覧を取得
+func (h *ApprovalHandler) GetMyApprovalRequests(c *gin.Context) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	requests, err := h.approvalService.GetMyApprovalRequests(userID, c.Query("status"))
+	if err != nil {
+		h.logger.Error("Failed to get approval requests", err, map[string]interface{}{
+			"user_id": userID,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetPendingApprovals 自分が承認可能な承認待ち申請を取得
+func (h *ApprovalHandler) GetPendingApprovals(c *gin.Context) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	requests, err := h.approvalService.GetPendingApprovals(userID)
+	if err != nil {
+		h.logger.Error("Failed to get pending approvals", err, map[string]interface{}{
+			"user_id": userID,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetApprovalRequest 承認申請の詳細を取得
+func (h *ApprovalHandler) GetApprovalRequest(c *gin.Context) {
+	requestID, ok := h.parseRequestID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	request, err := h.approvalService.GetApprovalRequest(requestID, userID)
+	if err != nil {
+		h.logger.Error("Failed to get approval request", err, map[string]interface{}{
+			"request_id": requestID,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// ApproveRequest 現在のステップを承認
+func (h *ApprovalHandler) ApproveRequest(c *gin.Context) {
+	h.handleAction(c, "approve", h.approvalService.ApproveRequest)
+}
+
+// RejectRequest 申請を却下
+func (h *ApprovalHandler) RejectRequest(c *gin.Context) {
+	h.handleAction(c, "reject", h.approvalService.RejectRequest)
+}
+
+// ReturnRequest 申請を差し戻し
+func (h *ApprovalHandler) ReturnRequest(c *gin.Context) {
+	h.handleAction(c, "return", h.approvalService.ReturnRequest)
+}
+
+// ResubmitRequest 差し戻された申請を再申請
+func (h *ApprovalHandler) ResubmitRequest(c *gin.Context) {
+	h.handleAction(c, "resubmit", h.approvalService.ResubmitApprovalRequest)
+}
+
+// CancelRequest 申請を取り下げ
+func (h *ApprovalHandler) CancelRequest(c *gin.Context) {
+	h.handleAction(c, "cancel", h.approvalService.CancelApprovalRequest)
+}
+
+// handleAction 申請に対する操作の共通処理
+func (h *ApprovalHandler) handleAction(c *gin.Context, action string, operate func(requestID, userID uuid.UUID, comment string) (*services.ApprovalRequestResponse, error)) {
+	requestID, ok := h.parseRequestID(c)
+	if !ok {
+		return
+	}
+
+	var req services.ApprovalActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.Warn("Invalid approval action request format", map[string]interface{}{
+				"action": action,
+				"error":  err.Error(),
+				"ip":     c.ClientIP(),
+			})
+			c.Error(errors.NewValidationError("request", "Invalid request format"))
+			return
+		}
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("Approval action request", map[string]interface{}{
+		"request_id": requestID,
+		"action":     action,
+		"user_id":    userID,
+		"ip":         c.ClientIP(),
+	})
+
+	request, err := operate(requestID, userID, req.Comment)
+	if err != nil {
+		h.logger.Error("Failed to process approval action", err, map[string]interface{}{
+			"request_id": requestID,
+			"action":     action,
+			"user_id":    userID,
+			"ip":         c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// parseRequestID パスパラメータの承認申請IDを解析
+func (h *ApprovalHandler) parseRequestID(c *gin.Context) (uuid.UUID, bool) {
+	requestIDStr := c.Param("id")
+	requestID, err := uuid.Parse(requestIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid approval request ID format", map[string]interface{}{
+			"request_id": requestIDStr,
+			"error":      err.Error(),
+			"ip":         c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("id", "Invalid UUID format"))
+		return uuid.Nil, false
+	}
+	return requestID, true
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"erp-access-control-go/internal/middleware"
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// ApprovalFlowHandler 承認フロー定義ハンドラー
+type ApprovalFlowHandler struct {
+	approvalFlowService *services.ApprovalFlowService
+	logger              *logger.Logger
+}
+
+// NewApprovalFlowHandler 新しい承認フロー定義ハンドラーを作成
+func NewApprovalFlowHandler(approvalFlowService *services.ApprovalFlowService, logger *logger.Logger) *ApprovalFlowHandler {
+	return &ApprovalFlowHandler{
+		approvalFlowService: approvalFlowService,
+		logger:              logger,
+	}
+}
+
+// GetApprovalFlows 承認フロー定義の統計を取得
+func (h *ApprovalFlowHandler) GetApprovalFlows(c *gin.Context) {
+	flows, err := h.approvalFlowService.GetApprovalFlows()
+	if err != nil {
+		h.logger.Error("Failed to get approval flows", err, map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flows)
+}
+
+// GetApprovalFlow リソース種別の承認フロー定義を取得
+func (h *ApprovalFlowHandler) GetApprovalFlow(c *gin.Context) {
+	resourceType := c.Param("resource_type")
+
+	flow, err := h.approvalFlowService.GetApprovalFlow(resourceType)
+	if err != nil {
+		h.logger.Error("Failed to get approval flow", err, map[string]interface{}{
+			"resource_type": resourceType,
+			"ip":            c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flow)
+}
+
+// DefineApprovalFlow リソース種別の承認フローを定義
+func (h *ApprovalFlowHandler) DefineApprovalFlow(c *gin.Context) {
+	resourceType := c.Param("resource_type")
+
+	var req services.DefineApprovalFlowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid approval flow request format", map[string]interface{}{
+			"resource_type": resourceType,
+			"error":         err.Error(),
+			"ip":            c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	currentUserID, _ := middleware.GetCurrentUserID(c)
+	h.logger.Info("Define approval flow request", map[string]interface{}{
+		"resource_type": resourceType,
+		"step_count":    len(req.Steps),
+		"defined_by":    currentUserID,
+		"ip":            c.ClientIP(),
+	})
+
+	flow, err := h.approvalFlowService.DefineApprovalFlow(resourceType, req)
+	if err != nil {
+		h.logger.Error("Failed to define approval flow", err, map[string]interface{}{
+			"resource_type": resourceType,
+			"ip":            c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flow)
+}
+
+// UpdateApprovalFlowStep 承認ステップを更新
+func (h *ApprovalFlowHandler) UpdateApprovalFlowStep(c *gin.Context) {
+	resourceType := c.Param("resource_type")
+	stateIDStr := c.Param("state_id")
+	stateID, err := strconv.Atoi(stateIDStr)
+	if err != nil || stateID <= 0 {
+		h.logger.Warn("Invalid approval state ID format", map[string]interface{}{
+			"state_id": stateIDStr,
+			"ip":       c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("state_id", "Invalid approval state ID"))
+		return
+	}
+
+	var req services.UpdateApprovalFlowStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid approval step update request format", map[string]interface{}{
+			"state_id": stateID,
+			"error":    err.Error(),
+			"ip":       c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	step, err := h.approvalFlowService.UpdateApprovalFlowStep(resourceType, stateID, req)
+	if err != nil {
+		h.logger.Error("Failed to update approval step", err, map[string]interface{}{
+			"resource_type": resourceType,
+			"state_id":      stateID,
+			"ip":            c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, step)
+}
+
+// DeleteApprovalFlow リソース種別の承認フローを削除
+func (h *ApprovalFlowHandler) DeleteApprovalFlow(c *gin.Context) {
+	resourceType := c.Param("resource_type")
+
+	deleted, err := h.approvalFlowService.DeleteApprovalFlow(resourceType)
+	if err != nil {
+		h.logger.Error("Failed to delete approval flow", err, map[string]interface{}{
+			"resource_type": resourceType,
+			"ip":            c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Approval flow deleted successfully",
+		"resource_type": resourceType,
+		"deleted_steps": deleted,
+	})
+}
+
+// SimulateApprovalFlow 承認ルートと承認可能なユーザーをシミュレーション
+func (h *ApprovalFlowHandler) SimulateApprovalFlow(c *gin.Context) {
+	var req services.ApprovalFlowSimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid approval simulation request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	result, err := h.approvalFlowService.SimulateApprovalFlow(req)
+	if err != nil {
+		h.logger.Error("Failed to simulate approval flow", err, map[string]interface{}{
+			"resource_type": req.ResourceType,
+			"ip":            c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// AuditHandler 監査ログハンドラー
+type AuditHandler struct {
+	auditService *services.AuditService
+	logger       *logger.Logger
+}
+
+// NewAuditHandler 新しい監査ログハンドラーを作成
+func NewAuditHandler(auditService *services.AuditService, logger *logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// GetAuditLogs 監査ログ一覧を取得
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	var filters services.AuditLogFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn("Invalid get audit logs query parameters", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("query", "Invalid query parameters"))
+		return
+	}
+
+	auditLogs, err := h.auditService.GetAuditLogs(filters)
+	if err != nil {
+		h.logger.Error("Failed to get audit logs", err, map[string]interface{}{
+			"filters": filters,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, auditLogs)
+}
+
+// GetAuditLog 監査ログ詳細を取得
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		h.logger.Warn("Invalid audit log ID format", map[string]interface{}{
+			"audit_log_id": idStr,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("id", "Invalid audit log ID"))
+		return
+	}
+
+	auditLog, err := h.auditService.GetAuditLog(id)
+	if err != nil {
+		h.logger.Error("Failed to get audit log", err, map[string]interface{}{
+			"audit_log_id": id,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, auditLog)
+}
+
+// GetAuditStats 監査統計を取得
+func (h *AuditHandler) GetAuditStats(c *gin.Context) {
+	var filters services.AuditStatsFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn("Invalid audit stats query parameters", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("query", "Invalid query parameters"))
+		return
+	}
+
+	stats, err := h.auditService.GetStats(filters)
+	if err != nil {
+		h.logger.Error("Failed to get audit stats", err, map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetUserActivity ユーザー別アクティビティサマリーを取得
+func (h *AuditHandler) GetUserActivity(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid user ID format", map[string]interface{}{
+			"user_id": userIDStr,
+			"error":   err.Error(),
+			"ip":      c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("id", "Invalid UUID format"))
+		return
+	}
+
+	var filters services.AuditStatsFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn("Invalid user activity query parameters", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("query", "Invalid query parameters"))
+		return
+	}
+
+	summary, err := h.auditService.GetUserActivity(userID, filters)
+	if err != nil {
+		h.logger.Error("Failed to get user activity", err, map[string]interface{}{
+			"user_id": userID,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// ExportAuditLogs 監査ログをCSV/NDJSONでエクスポート（ストリーミング）
+func (h *AuditHandler) ExportAuditLogs(c *gin.Context) {
+	var filters services.AuditLogFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn("Invalid export audit logs query parameters", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("query", "Invalid query parameters"))
+		return
+	}
+
+	format := c.DefaultQuery("format", services.AuditExportFormatCSV)
+	contentType := "text/csv; charset=utf-8"
+	switch format {
+	case services.AuditExportFormatCSV:
+	case services.AuditExportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		c.Error(errors.NewValidationError("format", "Format must be csv or ndjson"))
+		return
+	}
+
+	filename := fmt.Sprintf("audit_logs_%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	count, err := h.auditService.ExportAuditLogs(c.Writer, format, filters)
+	if err != nil {
+		// ストリーミング開始後はステータスを変更できないためログのみ記録
+		h.logger.Error("Failed to export audit logs", err, map[string]interface{}{
+			"format":   format,
+			"exported": count,
+			"ip":       c.ClientIP(),
+		})
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			c.Error(err)
+		}
+		return
+	}
+
+	h.logger.Info("Audit logs export completed", map[string]interface{}{
+		"format":   format,
+		"exported": count,
+		"ip":       c.ClientIP(),
+	})
+}
+
+// VerifyChain 監査ログのハッシュチェーンを検証
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	var filters services.AuditStatsFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.logger.Warn("Invalid verify chain query parameters", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("query", "Invalid query parameters"))
+		return
+	}
+
+	result, err := h.auditService.VerifyChain(filters)
+	if err != nil {
+		h.logger.Error("Failed to verify audit log chain", err, map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("Audit log chain verified", map[string]interface{}{
+		"valid":         result.Valid,
+		"checked_count": result.CheckedCount,
+		"ip":            c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateCheckpoint 署名付きチェックポイントを作成してエクスポート
+func (h *AuditHandler) CreateCheckpoint(c *gin.Context) {
+	checkpoint, err := h.auditService.CreateCheckpoint()
+	if err != nil {
+		h.logger.Error("Failed to create audit checkpoint", err, map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, checkpoint)
+}
@@ -34,31 +34,44 @@ type LoginRequest struct {
 
 // LoginResponse ログインレスポンス
 type LoginResponse struct {
-	AccessToken string              `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	TokenType   string              `json:"token_type" example:"Bearer"`
-	ExpiresIn   int64               `json:"expires_in" example:"900"`
-	User        *services.UserInfo  `json:"user"`
-	Permissions []string            `json:"permissions" example:"['user:read','user:write']"`
-	ActiveRoles []services.RoleInfo `json:"active_roles"`
-	PrimaryRole *services.RoleInfo  `json:"primary_role"`
-	HighestRole *services.RoleInfo  `json:"highest_role"`
+	AccessToken  string              `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string              `json:"refresh_token" example:"3q2-7wQk9Zr1xJmN0bXyV6hT4sLpC8uE5aDfGiHoKjY"`
+	TokenType    string              `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64               `json:"expires_in" example:"900"`
+	User         *services.UserInfo  `json:"user"`
+	Permissions  []string            `json:"permissions" example:"['user:read','user:write']"`
+	ActiveRoles  []services.RoleInfo `json:"active_roles"`
+	PrimaryRole  *services.RoleInfo  `json:"primary_role"`
+	HighestRole  *services.RoleInfo  `json:"highest_role"`
+
+	RecoveryCodes   []string `json:"recovery_codes,omitempty"`   // MFA登録完了時のみ（再表示不可）
+	PasswordExpired bool     `json:"password_expired,omitempty"` // trueの場合はパスワード変更を促す
+}
+
+// MFAChallengeResponse MFAが必要な場合のログインレスポンス
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required" example:"true"`
+	ChallengeToken     string `json:"challenge_token" example:"kP3xQ9mZ2vLw8Nc5Rt1YbH6sJd4Fa7GuXo0EiCnVqTe"`
+	EnrollmentRequired bool   `json:"enrollment_required" example:"false"` // trueの場合は /auth/mfa/setup で登録
+	ExpiresIn          int64  `json:"expires_in" example:"300"`
 }
 
 // RefreshRequest リフレッシュリクエスト
 type RefreshRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" binding:"required" example:"3q2-7wQk9Zr1xJmN0bXyV6hT4sLpC8uE5aDfGiHoKjY"`
 }
 
-// RefreshResponse リフレッシュレスポンス
+// RefreshResponse リフレッシュレスポンス（リフレッシュトークンは使用のたびに再発行）
 type RefreshResponse struct {
-	AccessToken string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	TokenType   string `json:"token_type" example:"Bearer"`
-	ExpiresIn   int64  `json:"expires_in" example:"900"`
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" example:"Vb7nQ2xLw9Kc4Rz0TgHy1MsPd6aJfE3uXoCiNkY8lZq"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
 }
 
 // LogoutRequest ログアウトリクエスト
 type LogoutRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" binding:"required" example:"3q2-7wQk9Zr1xJmN0bXyV6hT4sLpC8uE5aDfGiHoKjY"`
 }
 
 // Login ユーザーログイン処理
@@ -78,8 +91,16 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		"ip":    c.ClientIP(),
 	})
 
+	// ログイン試行（成功・失敗とも）はサービス側で監査ログに記録する
+	middleware.SkipAudit(c)
+
 	// ログイン処理
-	userInfo, accessToken, err := h.authService.LoginWithCredentials(req.Email, req.Password)
+	loginResp, err := h.authService.Login(services.LoginRequest{
+		Email:     req.Email,
+		Password:  req.Password,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
 	if err != nil {
 		h.logger.Warn("Login failed", map[string]interface{}{
 			"email": req.Email,
@@ -90,36 +111,47 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// 権限情報を取得
-	permissions, err := h.authService.GetUserPermissions(userInfo.ID)
-	if err != nil {
-		h.logger.Error("Failed to get user permissions", err, map[string]interface{}{
-			"user_id": userInfo.ID,
-			"ip":      c.ClientIP(),
+	// MFAが必要な場合はチャレンジトークンのみ返す
+	if loginResp.MFARequired {
+		h.logger.Info("Login requires MFA", map[string]interface{}{
+			"email":               req.Email,
+			"enrollment_required": loginResp.MFAEnrollmentRequired,
+			"ip":                  c.ClientIP(),
+		})
+		c.JSON(http.StatusOK, &MFAChallengeResponse{
+			MFARequired:        true,
+			ChallengeToken:     loginResp.MFAChallengeToken,
+			EnrollmentRequired: loginResp.MFAEnrollmentRequired,
+			ExpiresIn:          int64(loginResp.ExpiresIn.Seconds()),
 		})
-		c.Error(errors.NewInternalError("Failed to get user permissions"))
 		return
 	}
 
 	h.logger.Info("Login successful", map[string]interface{}{
-		"user_id": userInfo.ID,
-		"email":   userInfo.Email,
+		"user_id": loginResp.User.ID,
+		"email":   loginResp.User.Email,
 		"ip":      c.ClientIP(),
 	})
 
-	// レスポンス作成
-	response := &LoginResponse{
-		AccessToken: accessToken,
-		TokenType:   "Bearer",
-		ExpiresIn:   900, // 15分
-		User:        userInfo,
-		Permissions: permissions,
-		ActiveRoles: userInfo.ActiveRoles,
-		PrimaryRole: userInfo.PrimaryRole,
-		HighestRole: userInfo.HighestRole,
-	}
+	c.JSON(http.StatusOK, newLoginResponse(loginResp))
+}
 
-	c.JSON(http.StatusOK, response)
+// newLoginResponse サービスのログイン結果からトークンレスポンスを作成
+func newLoginResponse(loginResp *services.LoginResponse) *LoginResponse {
+	userInfo := &loginResp.User
+	return &LoginResponse{
+		AccessToken:     loginResp.Token,
+		RefreshToken:    loginResp.RefreshToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(loginResp.ExpiresIn.Seconds()),
+		User:            userInfo,
+		Permissions:     loginResp.Permissions,
+		ActiveRoles:     userInfo.ActiveRoles,
+		PrimaryRole:     userInfo.PrimaryRole,
+		HighestRole:     userInfo.HighestRole,
+		RecoveryCodes:   loginResp.RecoveryCodes,
+		PasswordExpired: loginResp.PasswordExpired,
+	}
 }
 
 // RefreshToken トークンリフレッシュ処理
@@ -150,14 +182,16 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	}
 
 	h.logger.Info("Token refresh successful", map[string]interface{}{
-		"ip": c.ClientIP(),
+		"user_id": loginResp.User.ID,
+		"ip":      c.ClientIP(),
 	})
 
 	// レスポンス作成
 	response := &RefreshResponse{
-		AccessToken: loginResp.Token,
-		TokenType:   "Bearer",
-		ExpiresIn:   900, // 15分
+		AccessToken:  loginResp.Token,
+		RefreshToken: loginResp.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(loginResp.ExpiresIn.Seconds()),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -235,7 +269,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 // ChangePasswordRequest パスワード変更リクエスト
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" binding:"required" example:"oldpassword123"`
-	NewPassword     string `json:"new_password" binding:"required,min=6" example:"newpassword123"`
+	NewPassword     string `json:"new_password" binding:"required,max=255" example:"NewPassword123"`
 }
 
 // ChangePassword パスワード変更処理
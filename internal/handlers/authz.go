@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"erp-access-control-go/internal/middleware"
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// AuthzHandler 認可判定の調査ハンドラー
+type AuthzHandler struct {
+	permissionService *services.PermissionService
+	logger            *logger.Logger
+}
+
+// NewAuthzHandler 新しい認可判定の調査ハンドラーを作成
+func NewAuthzHandler(permissionService *services.PermissionService, logger *logger.Logger) *AuthzHandler {
+	return &AuthzHandler{
+		permissionService: permissionService,
+		logger:            logger,
+	}
+}
+
+// Explain ユーザーの認可判定とその根拠（ユーザーロール・ロール階層・一致した権限・時間制限・スコープ）を取得
+// scopeはリソーススコープのJSONオブジェクト（省略時はスコープを照合しない）
+func (h *AuthzHandler) Explain(c *gin.Context) {
+	userIDStr := c.Query("user_id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid user_id parameter", map[string]interface{}{
+			"user_id": userIDStr,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("user_id", "Invalid UUID format"))
+		return
+	}
+
+	permission := c.Query("permission")
+	if !h.permissionService.ValidatePermission(permission) {
+		h.logger.Warn("Invalid permission parameter", map[string]interface{}{
+			"permission": permission,
+			"ip":         c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("permission", "Permission must be in module:action format"))
+		return
+	}
+
+	var resourceScope map[string]interface{}
+	if scopeStr := c.Query("scope"); scopeStr != "" {
+		if err := json.Unmarshal([]byte(scopeStr), &resourceScope); err != nil || resourceScope == nil {
+			h.logger.Warn("Invalid scope parameter", map[string]interface{}{
+				"scope": scopeStr,
+				"ip":    c.ClientIP(),
+			})
+			c.Error(errors.NewValidationError("scope", "Scope must be a JSON object"))
+			return
+		}
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	explanation, err := h.permissionService.ExplainPermission(userID, permission, resourceScope)
+	if err != nil {
+		h.logger.Error("Failed to explain authorization", err, map[string]interface{}{
+			"user_id":      userID,
+			"permission":   permission,
+			"requested_by": requestUserID,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("Authorization explained", map[string]interface{}{
+		"user_id":      userID,
+		"permission":   permission,
+		"allowed":      explanation.Allowed,
+		"reason":       explanation.Reason,
+		"requested_by": requestUserID,
+		"ip":           c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, explanation)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// IntrospectionHandler トークンイントロスペクションハンドラー
+type IntrospectionHandler struct {
+	introspectionService *services.TokenIntrospectionService
+	logger               *logger.Logger
+}
+
+// NewIntrospectionHandler トークンイントロスペクションハンドラーを新規作成
+func NewIntrospectionHandler(introspectionService *services.TokenIntrospectionService, logger *logger.Logger) *IntrospectionHandler {
+	return &IntrospectionHandler{
+		introspectionService: introspectionService,
+		logger:               logger,
+	}
+}
+
+// IntrospectRequest イントロスペクションリクエスト（RFC 7662 のフォーム形式・JSONに対応）
+type IntrospectRequest struct {
+	Token         string `form:"token" json:"token" binding:"required"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"` // 参考情報（アクセストークンのみ対応、リフレッシュトークンは常に非アクティブ）
+}
+
+// Introspect アクセストークンが有効か（署名・有効期限・無効化状態）を下流サービスに返す
+// クライアントはHTTP Basic認証（client_id:client_secret）で認証する
+func (h *IntrospectionHandler) Introspect(c *gin.Context) {
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok || !h.introspectionService.AuthenticateClient(clientID, clientSecret) {
+		h.logger.Warn("Introspection client authentication failed", map[string]interface{}{
+			"client_id": clientID,
+			"ip":        c.ClientIP(),
+		})
+		c.Header("WWW-Authenticate", `Basic realm="introspection"`)
+		c.Error(errors.NewAuthenticationError("invalid client credentials"))
+		return
+	}
+
+	var req IntrospectRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.logger.Warn("Invalid introspection request format", map[string]interface{}{
+			"client_id": clientID,
+			"error":     err.Error(),
+			"ip":        c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("token", "token is required"))
+		return
+	}
+
+	response, err := h.introspectionService.Introspect(req.Token)
+	if err != nil {
+		h.logger.Error("Token introspection failed", err, map[string]interface{}{
+			"client_id": clientID,
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Debug("Token introspected", map[string]interface{}{
+		"client_id": clientID,
+		"active":    response.Active,
+	})
+
+	// レスポンスはキャッシュさせない（無効化を即時に反映するため）
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"erp-access-control-go/internal/jobs"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// JobHandler 定期ジョブ管理ハンドラー
+type JobHandler struct {
+	scheduler *jobs.Scheduler
+	logger    *logger.Logger
+}
+
+// NewJobHandler 定期ジョブ管理ハンドラーを新規作成
+func NewJobHandler(scheduler *jobs.Scheduler, logger *logger.Logger) *JobHandler {
+	return &JobHandler{
+		scheduler: scheduler,
+		logger:    logger,
+	}
+}
+
+// JobListResponse 定期ジョブ一覧レスポンス
+type JobListResponse struct {
+	Jobs  []jobs.Status `json:"jobs"`
+	Total int           `json:"total"`
+}
+
+// GetJobs 定期ジョブの設定と最終実行結果を取得
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	statuses, err := h.scheduler.Statuses()
+	if err != nil {
+		h.logger.Error("Failed to get job statuses", err, map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		c.Error(errors.NewDatabaseError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, JobListResponse{
+		Jobs:  statuses,
+		Total: len(statuses),
+	})
+}
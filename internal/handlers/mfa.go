@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"erp-access-control-go/internal/middleware"
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// MFAHandler 多要素認証ハンドラー
+type MFAHandler struct {
+	authService *services.AuthService
+	mfaService  *services.MFAService
+	logger      *logger.Logger
+}
+
+// NewMFAHandler 多要素認証ハンドラーを新規作成
+func NewMFAHandler(authService *services.AuthService, mfaService *services.MFAService, logger *logger.Logger) *MFAHandler {
+	return &MFAHandler{
+		authService: authService,
+		mfaService:  mfaService,
+		logger:      logger,
+	}
+}
+
+// VerifyMFARequest MFA検証リクエスト
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required" example:"kP3xQ9mZ2vLw8Nc5Rt1YbH6sJd4Fa7GuXo0EiCnVqTe"`
+	Code           string `json:"code" binding:"omitempty,len=6,numeric" example:"123456"`
+	RecoveryCode   string `json:"recovery_code" example:"a1b2c-3d4e5"`
+}
+
+// MFAChallengeRequest MFAチャレンジトークンのみのリクエスト
+type MFAChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required" example:"kP3xQ9mZ2vLw8Nc5Rt1YbH6sJd4Fa7GuXo0EiCnVqTe"`
+}
+
+// ConfirmMFARequest MFA登録確認リクエスト
+type ConfirmMFARequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" example:"123456"`
+}
+
+// Verify 二段階ログインのMFAコードを検証してトークンを発行
+func (h *MFAHandler) Verify(c *gin.Context) {
+	var req VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid MFA verify request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	// 検証結果（成功・失敗）はサービス側で監査ログに記録する
+	middleware.SkipAudit(c)
+
+	loginResp, err := h.authService.VerifyMFA(services.VerifyMFARequest{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+		RecoveryCode:   req.RecoveryCode,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+	})
+	if err != nil {
+		h.logger.Warn("MFA verification failed", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("MFA verification successful", map[string]interface{}{
+		"user_id":            loginResp.User.ID,
+		"used_recovery_code": req.RecoveryCode != "",
+		"ip":                 c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, newLoginResponse(loginResp))
+}
+
+// Setup チャレンジトークンでMFA登録を開始（MFA必須で未登録のユーザー用）
+func (h *MFAHandler) Setup(c *gin.Context) {
+	var req MFAChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid MFA setup request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	enrollment, err := h.authService.BeginMFAEnrollment(req.ChallengeToken)
+	if err != nil {
+		h.logger.Warn("MFA setup failed", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmSetup チャレンジトークンでMFA登録を完了しトークンとリカバリーコードを発行
+func (h *MFAHandler) ConfirmSetup(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+		Code           string `json:"code" binding:"required,len=6,numeric"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid MFA setup confirm request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	// ログイン完了はサービス側で監査ログに記録する
+	middleware.SkipAudit(c)
+
+	loginResp, err := h.authService.ConfirmMFAEnrollment(services.VerifyMFARequest{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+	})
+	if err != nil {
+		h.logger.Warn("MFA setup confirmation failed", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("MFA setup completed", map[string]interface{}{
+		"user_id": loginResp.User.ID,
+		"ip":      c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, newLoginResponse(loginResp))
+}
+
+// GetStatus 自分のMFA登録状況を取得
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(errors.NewAuthenticationError("Authentication required"))
+		return
+	}
+
+	status, err := h.mfaService.GetStatus(userID)
+	if err != nil {
+		h.logger.Error("Failed to get MFA status", err, map[string]interface{}{
+			"user_id": userID,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Enroll ログイン中のユーザーのMFA登録を開始
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(errors.NewAuthenticationError("Authentication required"))
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginEnrollment(userID)
+	if err != nil {
+		h.logger.Warn("MFA enrollment failed", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmEnroll 確認コードでMFA登録を完了しリカバリーコードを発行
+func (h *MFAHandler) ConfirmEnroll(c *gin.Context) {
+	var req ConfirmMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid MFA confirm request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(errors.NewAuthenticationError("Authentication required"))
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.ConfirmEnrollment(userID, req.Code)
+	if err != nil {
+		h.logger.Warn("MFA enrollment confirmation failed", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("MFA enrollment confirmed", map[string]interface{}{
+		"user_id": userID,
+		"ip":      c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, recoveryCodes)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// PasswordResetHandler パスワード再設定ハンドラー
+type PasswordResetHandler struct {
+	passwordResetService *services.PasswordResetService
+	logger               *logger.Logger
+}
+
+// NewPasswordResetHandler パスワード再設定ハンドラーを新規作成
+func NewPasswordResetHandler(passwordResetService *services.PasswordResetService, logger *logger.Logger) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		passwordResetService: passwordResetService,
+		logger:               logger,
+	}
+}
+
+// ForgotPasswordRequest パスワード再設定要求リクエスト
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email,max=255" example:"user@example.com"`
+}
+
+// ResetPasswordRequest パスワード再設定リクエスト
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required" example:"kP3xQ9mZ2vLw8Nc5Rt1YbH6sJd4Fa7GuXo0EiCnVqTe"`
+	NewPassword string `json:"new_password" binding:"required,max=255" example:"NewPassword123"`
+}
+
+// Forgot パスワード再設定メールを送付
+// 登録の有無を推測されないよう、常に同じレスポンスを返す
+func (h *PasswordResetHandler) Forgot(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid forgot password request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	if err := h.passwordResetService.RequestPasswordReset(req.Email, c.ClientIP()); err != nil {
+		h.logger.Error("Failed to request password reset", err, map[string]interface{}{
+			"ip": c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":   "If the email address is registered, a password reset link has been sent",
+		"status":    "accepted",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// Reset 再設定トークンで新しいパスワードを設定
+func (h *PasswordResetHandler) Reset(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid reset password request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		h.logger.Warn("Password reset failed", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("Password reset successful", map[string]interface{}{
+		"ip": c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Password successfully reset. Please log in with the new password",
+		"status":    "success",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"erp-access-control-go/internal/services"
+)
+
+// PermissionCacheHandler 実効権限キャッシュの管理ハンドラー
+type PermissionCacheHandler struct {
+	permissionService *services.PermissionService
+}
+
+// NewPermissionCacheHandler 実効権限キャッシュの管理ハンドラーを新規作成
+func NewPermissionCacheHandler(permissionService *services.PermissionService) *PermissionCacheHandler {
+	return &PermissionCacheHandler{
+		permissionService: permissionService,
+	}
+}
+
+// PermissionCacheStatsResponse 実効権限キャッシュの統計レスポンス
+type PermissionCacheStatsResponse struct {
+	Enabled bool `json:"enabled"`
+	services.PermissionCacheStats
+	HitRate float64 `json:"hit_rate"` // ヒット数 / 取得回数（取得がない場合は0）
+}
+
+// GetStats 実効権限キャッシュのヒット・ミス・無効化の件数を取得（このプロセスでの累計）
+func (h *PermissionCacheHandler) GetStats(c *gin.Context) {
+	cache := h.permissionService.PermissionCache()
+	if cache == nil {
+		c.JSON(http.StatusOK, PermissionCacheStatsResponse{Enabled: false})
+		return
+	}
+
+	stats := cache.Stats()
+	response := PermissionCacheStatsResponse{
+		Enabled:              true,
+		PermissionCacheStats: stats,
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		response.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	c.JSON(http.StatusOK, response)
+}
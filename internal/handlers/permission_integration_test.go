@@ -46,6 +46,8 @@ func setupPermissionIntegrationTest(t *testing.T) (*gin.Engine, *services.Permis
 			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
 			name TEXT NOT NULL,
 			parent_id TEXT,
+			requires_approval BOOLEAN NOT NULL DEFAULT false,
+			requires_mfa BOOLEAN NOT NULL DEFAULT false,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (parent_id) REFERENCES roles(id)
 		)
@@ -57,6 +59,7 @@ func setupPermissionIntegrationTest(t *testing.T) (*gin.Engine, *services.Permis
 		CREATE TABLE IF NOT EXISTS role_permissions (
 			role_id TEXT NOT NULL,
 			permission_id TEXT NOT NULL,
+			effect TEXT NOT NULL DEFAULT 'allow',
 			PRIMARY KEY (role_id, permission_id),
 			FOREIGN KEY (role_id) REFERENCES roles(id),
 			FOREIGN KEY (permission_id) REFERENCES permissions(id)
@@ -44,7 +44,9 @@ func setupSimplePermissionTest(t *testing.T) (*gin.Engine, *services.PermissionS
 			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			name TEXT NOT NULL,
-			parent_id TEXT
+			parent_id TEXT,
+			requires_approval BOOLEAN NOT NULL DEFAULT false,
+			requires_mfa BOOLEAN NOT NULL DEFAULT false
 		)
 	`).Error
 	require.NoError(t, err)
@@ -54,6 +56,7 @@ func setupSimplePermissionTest(t *testing.T) (*gin.Engine, *services.PermissionS
 		CREATE TABLE IF NOT EXISTS role_permissions (
 			role_id TEXT NOT NULL,
 			permission_id TEXT NOT NULL,
+			effect TEXT NOT NULL DEFAULT 'allow',
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			PRIMARY KEY (role_id, permission_id)
 		)
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"erp-access-control-go/internal/middleware"
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// SessionHandler ログインセッション管理ハンドラー
+type SessionHandler struct {
+	sessionService *services.SessionService
+	logger         *logger.Logger
+}
+
+// NewSessionHandler ログインセッション管理ハンドラーを新規作成
+func NewSessionHandler(sessionService *services.SessionService, logger *logger.Logger) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		logger:         logger,
+	}
+}
+
+// =============================================================================
+// 自分のセッション
+// =============================================================================
+
+// ListMySessions 自分の有効なセッション一覧を取得
+func (h *SessionHandler) ListMySessions(c *gin.Context) {
+	userID, jti, ok := h.currentSession(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(userID, jti)
+	if err != nil {
+		h.logger.Error("Failed to list sessions", err, map[string]interface{}{
+			"user_id": userID,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeMySession 自分のセッションを1件無効化
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	userID, _, ok := h.currentSession(c)
+	if !ok {
+		return
+	}
+
+	sessionID, ok := h.parseSessionID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(userID, sessionID, "user_revoked_session"); err != nil {
+		h.logger.Warn("Failed to revoke session", map[string]interface{}{
+			"user_id":    userID,
+			"session_id": sessionID,
+			"error":      err.Error(),
+			"ip":         c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RevokeMyOtherSessions 現在のセッション以外をすべて無効化
+func (h *SessionHandler) RevokeMyOtherSessions(c *gin.Context) {
+	userID, jti, ok := h.currentSession(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.sessionService.RevokeOtherSessions(userID, jti, "user_revoked_other_sessions")
+	if err != nil {
+		h.logger.Error("Failed to revoke other sessions", err, map[string]interface{}{
+			"user_id": userID,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// =============================================================================
+// 管理者による操作
+// =============================================================================
+
+// ListUserSessions 指定ユーザーの有効なセッション一覧を取得
+func (h *SessionHandler) ListUserSessions(c *gin.Context) {
+	userID, ok := h.parseSessionID(c, "id")
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(userID, "")
+	if err != nil {
+		h.logger.Error("Failed to list user sessions", err, map[string]interface{}{
+			"user_id": userID,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeUserSession 指定ユーザーのセッションを1件無効化
+func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
+	userID, ok := h.parseSessionID(c, "id")
+	if !ok {
+		return
+	}
+	sessionID, ok := h.parseSessionID(c, "session_id")
+	if !ok {
+		return
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	if err := h.sessionService.RevokeSession(userID, sessionID, "admin_revoked_session"); err != nil {
+		h.logger.Warn("Failed to revoke user session", map[string]interface{}{
+			"user_id":      userID,
+			"session_id":   sessionID,
+			"requested_by": requestUserID,
+			"error":        err.Error(),
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("User session revoked by admin", map[string]interface{}{
+		"user_id":      userID,
+		"session_id":   sessionID,
+		"requested_by": requestUserID,
+		"ip":           c.ClientIP(),
+	})
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RevokeUserSessions 指定ユーザーの全セッションを無効化（操作者自身の場合は現在のセッションを除く）
+func (h *SessionHandler) RevokeUserSessions(c *gin.Context) {
+	userID, ok := h.parseSessionID(c, "id")
+	if !ok {
+		return
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	keepJTI := ""
+	if requestUserID == userID {
+		keepJTI, _ = middleware.GetCurrentTokenID(c)
+	}
+
+	result, err := h.sessionService.RevokeOtherSessions(userID, keepJTI, "admin_revoked_sessions")
+	if err != nil {
+		h.logger.Error("Failed to revoke user sessions", err, map[string]interface{}{
+			"user_id":      userID,
+			"requested_by": requestUserID,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("User sessions revoked by admin", map[string]interface{}{
+		"user_id":       userID,
+		"revoked_count": result.RevokedCount,
+		"requested_by":  requestUserID,
+		"ip":            c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// currentSession 認証済みユーザーのIDと現在のトークンのJTIを取得
+func (h *SessionHandler) currentSession(c *gin.Context) (uuid.UUID, string, bool) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.Error(errors.NewAuthenticationError("Authentication required"))
+		return uuid.Nil, "", false
+	}
+	jti, err := middleware.GetCurrentTokenID(c)
+	if err != nil {
+		c.Error(errors.NewAuthenticationError("Authentication required"))
+		return uuid.Nil, "", false
+	}
+	return userID, jti, true
+}
+
+// parseSessionID パスパラメータのUUIDを解析
+func (h *SessionHandler) parseSessionID(c *gin.Context, param string) (uuid.UUID, bool) {
+	value := c.Param(param)
+	id, err := uuid.Parse(value)
+	if err != nil {
+		h.logger.Warn("Invalid UUID format", map[string]interface{}{
+			param:   value,
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError(param, "Invalid UUID format"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
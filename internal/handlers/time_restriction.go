@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"erp-access-control-go/internal/middleware"
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// TimeRestrictionHandler 時間制限管理ハンドラー
+type TimeRestrictionHandler struct {
+	timeRestrictionService *services.TimeRestrictionService
+	logger                 *logger.Logger
+}
+
+// NewTimeRestrictionHandler 新しい時間制限ハンドラーを作成
+func NewTimeRestrictionHandler(timeRestrictionService *services.TimeRestrictionService, logger *logger.Logger) *TimeRestrictionHandler {
+	return &TimeRestrictionHandler{
+		timeRestrictionService: timeRestrictionService,
+		logger:                 logger,
+	}
+}
+
+// CreateTimeRestriction 時間制限を作成
+func (h *TimeRestrictionHandler) CreateTimeRestriction(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.CreateTimeRestrictionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid create time restriction request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	h.logger.Info("Create time restriction request", map[string]interface{}{
+		"user_id":       userID,
+		"resource_type": req.ResourceType,
+		"requested_by":  requestUserID,
+		"ip":            c.ClientIP(),
+	})
+
+	restriction, err := h.timeRestrictionService.CreateTimeRestriction(userID, req)
+	if err != nil {
+		h.logger.Error("Failed to create time restriction", err, map[string]interface{}{
+			"user_id":      userID,
+			"requested_by": requestUserID,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, restriction)
+}
+
+// GetTimeRestrictions 時間制限一覧を取得
+func (h *TimeRestrictionHandler) GetTimeRestrictions(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	restrictions, err := h.timeRestrictionService.GetTimeRestrictions(userID)
+	if err != nil {
+		h.logger.Error("Failed to get time restrictions", err, map[string]interface{}{
+			"user_id": userID,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restrictions)
+}
+
+// GetTimeRestriction 時間制限詳細を取得
+func (h *TimeRestrictionHandler) GetTimeRestriction(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+	restrictionID, ok := h.parseRestrictionID(c)
+	if !ok {
+		return
+	}
+
+	restriction, err := h.timeRestrictionService.GetTimeRestriction(userID, restrictionID)
+	if err != nil {
+		h.logger.Error("Failed to get time restriction", err, map[string]interface{}{
+			"user_id":             userID,
+			"time_restriction_id": restrictionID,
+			"ip":                  c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restriction)
+}
+
+// UpdateTimeRestriction 時間制限を更新
+func (h *TimeRestrictionHandler) UpdateTimeRestriction(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+	restrictionID, ok := h.parseRestrictionID(c)
+	if !ok {
+		return
+	}
+
+	var req services.UpdateTimeRestrictionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid update time restriction request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	h.logger.Info("Update time restriction request", map[string]interface{}{
+		"user_id":             userID,
+		"time_restriction_id": restrictionID,
+		"requested_by":        requestUserID,
+		"ip":                  c.ClientIP(),
+	})
+
+	restriction, err := h.timeRestrictionService.UpdateTimeRestriction(userID, restrictionID, req)
+	if err != nil {
+		h.logger.Error("Failed to update time restriction", err, map[string]interface{}{
+			"user_id":             userID,
+			"time_restriction_id": restrictionID,
+			"requested_by":        requestUserID,
+			"ip":                  c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restriction)
+}
+
+// DeleteTimeRestriction 時間制限を削除
+func (h *TimeRestrictionHandler) DeleteTimeRestriction(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+	restrictionID, ok := h.parseRestrictionID(c)
+	if !ok {
+		return
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	h.logger.Info("Delete time restriction request", map[string]interface{}{
+		"user_id":             userID,
+		"time_restriction_id": restrictionID,
+		"requested_by":        requestUserID,
+		"ip":                  c.ClientIP(),
+	})
+
+	if err := h.timeRestrictionService.DeleteTimeRestriction(userID, restrictionID); err != nil {
+		h.logger.Error("Failed to delete time restriction", err, map[string]interface{}{
+			"user_id":             userID,
+			"time_restriction_id": restrictionID,
+			"requested_by":        requestUserID,
+			"ip":                  c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ApplyBusinessHoursPreset 営業時間プリセットを適用
+func (h *TimeRestrictionHandler) ApplyBusinessHoursPreset(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.BusinessHoursPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid business hours preset request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	h.logger.Info("Apply business hours preset request", map[string]interface{}{
+		"user_id":        userID,
+		"resource_types": req.ResourceTypes,
+		"requested_by":   requestUserID,
+		"ip":             c.ClientIP(),
+	})
+
+	restrictions, err := h.timeRestrictionService.ApplyBusinessHoursPreset(userID, req)
+	if err != nil {
+		h.logger.Error("Failed to apply business hours preset", err, map[string]interface{}{
+			"user_id":      userID,
+			"requested_by": requestUserID,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, restrictions)
+}
+
+// CheckTimeAccess 指定時刻のアクセス可否を判定（ドライラン）
+func (h *TimeRestrictionHandler) CheckTimeAccess(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.TimeAccessCheckRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Warn("Invalid time access check query parameters", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("query", "Invalid query parameters"))
+		return
+	}
+
+	result, err := h.timeRestrictionService.CheckTimeAccess(userID, req)
+	if err != nil {
+		h.logger.Error("Failed to check time access", err, map[string]interface{}{
+			"user_id": userID,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseUserID パスパラメータのユーザーIDを解析
+func (h *TimeRestrictionHandler) parseUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid user ID format", map[string]interface{}{
+			"user_id": userIDStr,
+			"error":   err.Error(),
+			"ip":      c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("id", "Invalid UUID format"))
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// parseRestrictionID パスパラメータの時間制限IDを解析
+func (h *TimeRestrictionHandler) parseRestrictionID(c *gin.Context) (int, bool) {
+	idStr := c.Param("restriction_id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		h.logger.Warn("Invalid time restriction ID format", map[string]interface{}{
+			"time_restriction_id": idStr,
+			"ip":                  c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("restriction_id", "Invalid time restriction ID"))
+		return 0, false
+	}
+	return id, true
+}
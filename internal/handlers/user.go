@@ -322,6 +322,95 @@ func (h *UserHandler) ChangeUserStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+// UnlockUser ログイン失敗によるアカウントロックを解除（管理者用）
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid user ID format", map[string]interface{}{
+			"user_id": userIDStr,
+			"error":   err.Error(),
+			"ip":      c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("id", "Invalid UUID format"))
+		return
+	}
+
+	// リクエストユーザーID取得（監査ログ用）
+	requestUserID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		h.logger.Warn("Failed to get current user ID", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewAuthenticationError("Authentication required"))
+		return
+	}
+
+	user, err := h.userService.UnlockUser(userID)
+	if err != nil {
+		h.logger.Error("Failed to unlock user", err, map[string]interface{}{
+			"user_id":      userID,
+			"requested_by": requestUserID,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("User unlocked successfully", map[string]interface{}{
+		"user_id":      userID,
+		"requested_by": requestUserID,
+		"ip":           c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ResetMFA ユーザーのMFA登録を解除（管理者用）
+func (h *UserHandler) ResetMFA(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid user ID format", map[string]interface{}{
+			"user_id": userIDStr,
+			"error":   err.Error(),
+			"ip":      c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("id", "Invalid UUID format"))
+		return
+	}
+
+	// リクエストユーザーID取得（監査ログ用）
+	requestUserID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		h.logger.Warn("Failed to get current user ID", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewAuthenticationError("Authentication required"))
+		return
+	}
+
+	if err := h.userService.ResetMFA(userID); err != nil {
+		h.logger.Error("Failed to reset user MFA", err, map[string]interface{}{
+			"user_id":      userID,
+			"requested_by": requestUserID,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	h.logger.Info("User MFA reset successfully", map[string]interface{}{
+		"user_id":      userID,
+		"requested_by": requestUserID,
+		"ip":           c.ClientIP(),
+	})
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
 // ChangePassword ユーザーのパスワードを変更（自分自身のみ）
 func (h *UserHandler) ChangePassword(c *gin.Context) {
 	userIDStr := c.Param("id")
@@ -82,7 +82,7 @@ func (h *UserRoleHandler) AssignRole(c *gin.Context) {
 		req.Priority = 1
 	}
 
-	userRole, err := h.userRoleService.AssignRole(
+	result, err := h.userRoleService.AssignRole(
 		req.UserID,
 		req.RoleID,
 		validFrom,
@@ -96,7 +96,16 @@ func (h *UserRoleHandler) AssignRole(c *gin.Context) {
 		return
 	}
 
-	response := h.convertToResponse(userRole)
+	// 承認が必要なロールは承認申請を返す（最終承認時にロールが付与される）
+	if result.ApprovalRequest != nil {
+		c.JSON(http.StatusAccepted, gin.H{
+			"approval_required": true,
+			"approval_request":  result.ApprovalRequest,
+		})
+		return
+	}
+
+	response := h.convertToResponse(result.UserRole)
 	c.JSON(http.StatusCreated, response)
 }
 
@@ -178,7 +187,7 @@ func (h *UserRoleHandler) UpdateRole(c *gin.Context) {
 		return
 	}
 
-	userRole, err := h.userRoleService.UpdateRole(
+	result, err := h.userRoleService.UpdateRole(
 		userID,
 		roleID,
 		req.Priority,
@@ -191,7 +200,16 @@ func (h *UserRoleHandler) UpdateRole(c *gin.Context) {
 		return
 	}
 
-	response := h.convertToResponse(userRole)
+	// 承認が必要なロールの期限延長は承認申請を返す（最終承認時に延長される）
+	if result.ApprovalRequest != nil {
+		c.JSON(http.StatusAccepted, gin.H{
+			"approval_required": true,
+			"approval_request":  result.ApprovalRequest,
+		})
+		return
+	}
+
+	response := h.convertToResponse(result.UserRole)
 	c.JSON(http.StatusOK, response)
 }
 
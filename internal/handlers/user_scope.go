@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"erp-access-control-go/internal/middleware"
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// UserScopeHandler ユーザースコープ管理ハンドラー
+type UserScopeHandler struct {
+	userScopeService *services.UserScopeService
+	logger           *logger.Logger
+}
+
+// NewUserScopeHandler 新しいユーザースコープハンドラーを作成
+func NewUserScopeHandler(userScopeService *services.UserScopeService, logger *logger.Logger) *UserScopeHandler {
+	return &UserScopeHandler{
+		userScopeService: userScopeService,
+		logger:           logger,
+	}
+}
+
+// GetUserScopes ユーザーのスコープ一覧を取得
+func (h *UserScopeHandler) GetUserScopes(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	scopes, err := h.userScopeService.GetUserScopes(userID, c.Query("resource_type"))
+	if err != nil {
+		h.logger.Error("Failed to get user scopes", err, map[string]interface{}{
+			"user_id": userID,
+			"ip":      c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scopes)
+}
+
+// AddUserScope ユーザーにスコープを追加
+func (h *UserScopeHandler) AddUserScope(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.AddUserScopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid add user scope request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	h.logger.Info("Add user scope request", map[string]interface{}{
+		"user_id":       userID,
+		"resource_type": req.ResourceType,
+		"scope_type":    req.ScopeType,
+		"requested_by":  requestUserID,
+		"ip":            c.ClientIP(),
+	})
+
+	scope, err := h.userScopeService.AddUserScope(userID, req)
+	if err != nil {
+		h.logger.Error("Failed to add user scope", err, map[string]interface{}{
+			"user_id":      userID,
+			"requested_by": requestUserID,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, scope)
+}
+
+// ReplaceUserScopes リソース種別単位でスコープを置き換え
+func (h *UserScopeHandler) ReplaceUserScopes(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.ReplaceUserScopesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid replace user scopes request format", map[string]interface{}{
+			"error": err.Error(),
+			"ip":    c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("request", "Invalid request format"))
+		return
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	h.logger.Info("Replace user scopes request", map[string]interface{}{
+		"user_id":       userID,
+		"resource_type": req.ResourceType,
+		"scope_count":   len(req.Scopes),
+		"requested_by":  requestUserID,
+		"ip":            c.ClientIP(),
+	})
+
+	scopes, err := h.userScopeService.ReplaceUserScopes(userID, req)
+	if err != nil {
+		h.logger.Error("Failed to replace user scopes", err, map[string]interface{}{
+			"user_id":      userID,
+			"requested_by": requestUserID,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scopes)
+}
+
+// DeleteUserScopesByResource リソース種別のスコープをすべて削除
+func (h *UserScopeHandler) DeleteUserScopesByResource(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	resourceType := c.Query("resource_type")
+	if resourceType == "" {
+		c.Error(errors.NewValidationError("resource_type", "resource_type is required"))
+		return
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	deleted, err := h.userScopeService.DeleteUserScopesByResource(userID, resourceType)
+	if err != nil {
+		h.logger.Error("Failed to delete user scopes", err, map[string]interface{}{
+			"user_id":       userID,
+			"resource_type": resourceType,
+			"requested_by":  requestUserID,
+			"ip":            c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"resource_type": resourceType,
+		"deleted":       deleted,
+	})
+}
+
+// DeleteUserScope スコープを1件削除
+func (h *UserScopeHandler) DeleteUserScope(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	scopeIDStr := c.Param("scope_id")
+	scopeID, err := strconv.Atoi(scopeIDStr)
+	if err != nil || scopeID < 1 {
+		h.logger.Warn("Invalid user scope ID format", map[string]interface{}{
+			"scope_id": scopeIDStr,
+			"ip":       c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("scope_id", "Invalid scope ID"))
+		return
+	}
+
+	requestUserID, _ := middleware.GetCurrentUserID(c)
+	if err := h.userScopeService.DeleteUserScope(userID, scopeID); err != nil {
+		h.logger.Error("Failed to delete user scope", err, map[string]interface{}{
+			"user_id":      userID,
+			"scope_id":     scopeID,
+			"requested_by": requestUserID,
+			"ip":           c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// FindUsersByScope スコープに到達可能なユーザーを検索
+// GET /scopes/users?resource_type=orders&scope={"region":"kanto"}
+func (h *UserScopeHandler) FindUsersByScope(c *gin.Context) {
+	req := services.ScopeUsersRequest{
+		ResourceType: c.Query("resource_type"),
+		ScopeType:    models.ScopeType(c.Query("scope_type")),
+	}
+	if raw := c.Query("scope"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &req.Scope); err != nil {
+			h.logger.Warn("Invalid scope query parameter", map[string]interface{}{
+				"error": err.Error(),
+				"ip":    c.ClientIP(),
+			})
+			c.Error(errors.NewValidationError("scope", "scope must be a JSON object"))
+			return
+		}
+	}
+
+	result, err := h.userScopeService.FindUsersByScope(req)
+	if err != nil {
+		h.logger.Error("Failed to find users by scope", err, map[string]interface{}{
+			"resource_type": req.ResourceType,
+			"ip":            c.ClientIP(),
+		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetScopeSchemas スコープタイプ別のスキーマ定義を取得
+func (h *UserScopeHandler) GetScopeSchemas(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"schemas": services.GetScopeValueSchemas(),
+	})
+}
+
+// parseUserID パスパラメータのユーザーIDを解析
+func (h *UserScopeHandler) parseUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid user ID format", map[string]interface{}{
+			"user_id": userIDStr,
+			"error":   err.Error(),
+			"ip":      c.ClientIP(),
+		})
+		c.Error(errors.NewValidationError("id", "Invalid UUID format"))
+		return uuid.Nil, false
+	}
+	return userID, true
+}
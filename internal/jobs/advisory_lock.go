@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"hash/fnv"
+
+	"gorm.io/gorm"
+)
+
+// advisoryLockNamespace ロックキーの衝突を避けるための接頭辞
+const advisoryLockNamespace = "erp-access-control-go/jobs/"
+
+// AdvisoryLocker PostgreSQLのアドバイザリロックによるジョブの排他制御
+// トランザクション単位のロックを使用するため、プロセスが異常終了しても接続の切断で解放される
+type AdvisoryLocker struct {
+	db *gorm.DB
+}
+
+// NewAdvisoryLocker 新しいアドバイザリロックを作成
+func NewAdvisoryLocker(db *gorm.DB) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db}
+}
+
+// TryLock ジョブ名に対応するアドバイザリロックの取得を試みる
+// 取得したロックはreleaseを呼び出すまでトランザクションを保持する
+func (l *AdvisoryLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	tx := l.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, false, tx.Error
+	}
+
+	var acquired bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", advisoryLockKey(name)).Scan(&acquired).Error; err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	if !acquired {
+		tx.Rollback()
+		return nil, false, nil
+	}
+
+	return func() { tx.Rollback() }, true, nil
+}
+
+// advisoryLockKey ジョブ名からロックキー（bigint）を算出
+func advisoryLockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(advisoryLockNamespace + name))
+	return int64(hash.Sum64())
+}
@@ -0,0 +1,260 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/logger"
+)
+
+// jobIntervalJitterDivisor 実行済み判定で許容するタイマーの揺らぎ（間隔の1/N）
+const jobIntervalJitterDivisor = 10
+
+// Func ジョブの処理（削除・更新した件数を返す）
+type Func func(ctx context.Context) (int64, error)
+
+// Job 定期実行するジョブ
+type Job struct {
+	Name     string
+	Interval time.Duration // 0以下の場合は実行しない
+	Run      Func
+}
+
+// Locker 複数レプリカ間でジョブの同時実行を防ぐロック
+type Locker interface {
+	// TryLock ロックの取得を試みる（取得できなかった場合は acquired=false）
+	TryLock(ctx context.Context, name string) (release func(), acquired bool, err error)
+}
+
+// Status ジョブの設定と最終実行結果
+type Status struct {
+	Name      string         `json:"name"`
+	Enabled   bool           `json:"enabled"`
+	Interval  string         `json:"interval"`
+	NextRunAt *time.Time     `json:"next_run_at,omitempty"` // このレプリカでの次回実行予定
+	LastRun   *models.JobRun `json:"last_run,omitempty"`    // いずれかのレプリカでの最終実行結果
+}
+
+// Scheduler 定期ジョブのスケジューラー
+// 各ジョブは起動直後と以降の間隔ごとに実行し、ロックを取得できたレプリカのみが処理する
+// ロックを設定した場合、間隔内に他のレプリカが実行済みのジョブはスキップする（間隔あたり1レプリカのみが実行）
+type Scheduler struct {
+	db       *gorm.DB
+	logger   *logger.Logger
+	locker   Locker // 未設定の場合は排他制御しない（単一インスタンス）
+	instance string
+
+	mu      sync.RWMutex
+	jobs    []Job
+	nextRun map[string]time.Time
+	wg      sync.WaitGroup
+}
+
+// NewScheduler 新しいスケジューラーを作成
+func NewScheduler(db *gorm.DB, logger *logger.Logger) *Scheduler {
+	instance, err := os.Hostname()
+	if err != nil || instance == "" {
+		instance = "unknown"
+	}
+
+	return &Scheduler{
+		db:       db,
+		logger:   logger,
+		instance: instance,
+		nextRun:  make(map[string]time.Time),
+	}
+}
+
+// SetLocker レプリカ間の排他制御に使用するロックを設定
+func (s *Scheduler) SetLocker(locker Locker) {
+	s.locker = locker
+}
+
+// Register ジョブを登録（Startより前に呼び出すこと）
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job)
+}
+
+// Start 有効なジョブの定期実行を開始（ctxの終了で停止）
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			continue
+		}
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Wait 実行中のジョブの終了を待機
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Statuses 登録済みジョブの設定と最終実行結果を取得
+func (s *Scheduler) Statuses() ([]Status, error) {
+	runs, err := models.FindJobRuns(s.db)
+	if err != nil {
+		return nil, err
+	}
+	lastRuns := make(map[string]models.JobRun, len(runs))
+	for _, run := range runs {
+		lastRuns[run.Name] = run
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]Status, len(s.jobs))
+	for i, job := range s.jobs {
+		status := Status{
+			Name:     job.Name,
+			Enabled:  job.Interval > 0,
+			Interval: job.Interval.String(),
+		}
+		if next, ok := s.nextRun[job.Name]; ok {
+			status.NextRunAt = &next
+		}
+		if run, ok := lastRuns[job.Name]; ok {
+			status.LastRun = &run
+		}
+		statuses[i] = status
+	}
+	return statuses, nil
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// loop 起動直後と以降の間隔ごとにジョブを実行
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+		s.setNextRun(job.Name, time.Now().Add(job.Interval))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce ロックを取得できた場合のみジョブを実行して結果を記録
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	if s.locker != nil {
+		release, acquired, err := s.locker.TryLock(ctx, job.Name)
+		if err != nil {
+			s.logger.Error("Failed to acquire job lock", err, map[string]interface{}{
+				"job": job.Name,
+			})
+			return
+		}
+		if !acquired {
+			// 他のレプリカが実行中
+			s.logger.Debug("Job skipped: lock held by another instance", map[string]interface{}{
+				"job": job.Name,
+			})
+			return
+		}
+		defer release()
+
+		// ロック取得後に最終実行時刻を確認し、間隔内に実行済みであればスキップ
+		recent, err := s.ranRecently(job)
+		if err != nil {
+			s.logger.Error("Failed to read last job run", err, map[string]interface{}{
+				"job": job.Name,
+			})
+			return
+		}
+		if recent {
+			s.logger.Debug("Job skipped: already run within interval", map[string]interface{}{
+				"job": job.Name,
+			})
+			return
+		}
+	}
+
+	run := models.JobRun{
+		Name:          job.Name,
+		LastStartedAt: time.Now(),
+		LastStatus:    models.JobRunStatusSuccess,
+		LastInstance:  s.instance,
+	}
+	affected, err := s.execute(ctx, job)
+	run.LastFinishedAt = time.Now()
+	run.LastAffected = affected
+
+	if err != nil {
+		message := err.Error()
+		run.LastStatus = models.JobRunStatusFailed
+		run.LastError = &message
+		s.logger.Error("Job failed", err, map[string]interface{}{
+			"job":         job.Name,
+			"duration_ms": run.LastFinishedAt.Sub(run.LastStartedAt).Milliseconds(),
+		})
+	} else {
+		s.logger.Info("Job completed", map[string]interface{}{
+			"job":         job.Name,
+			"affected":    affected,
+			"duration_ms": run.LastFinishedAt.Sub(run.LastStartedAt).Milliseconds(),
+		})
+	}
+
+	if err := models.SaveJobRun(s.db, &run); err != nil {
+		s.logger.Error("Failed to record job run", err, map[string]interface{}{
+			"job": job.Name,
+		})
+	}
+}
+
+// ranRecently 間隔内にいずれかのレプリカがジョブを開始済みかチェック
+// 自身の前回実行をスキップしないよう、タイマーの揺らぎ分（間隔の1/10）を差し引いて判定する
+func (s *Scheduler) ranRecently(job Job) (bool, error) {
+	last, err := models.FindJobRun(s.db, job.Name)
+	if err != nil {
+		return false, err
+	}
+	if last == nil {
+		return false, nil
+	}
+	return time.Since(last.LastStartedAt) < job.Interval-job.Interval/jobIntervalJitterDivisor, nil
+}
+
+// execute ジョブを実行（panicは失敗として扱い、スケジューラーを停止させない）
+func (s *Scheduler) execute(ctx context.Context, job Job) (affected int64, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// setNextRun 次回実行予定を記録
+func (s *Scheduler) setNextRun(name string, next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextRun[name] = next
+}
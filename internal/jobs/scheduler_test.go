@@ -0,0 +1,241 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/logger"
+)
+
+// setupTestScheduler テスト用のインメモリSQLiteでスケジューラーを作成
+func setupTestScheduler(t *testing.T) (*Scheduler, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec(`CREATE TABLE IF NOT EXISTS job_runs (
+		name TEXT PRIMARY KEY,
+		last_started_at DATETIME NOT NULL,
+		last_finished_at DATETIME NOT NULL,
+		last_status TEXT NOT NULL,
+		last_error TEXT,
+		last_affected INTEGER NOT NULL DEFAULT 0,
+		last_instance TEXT NOT NULL
+	)`).Error)
+	require.NoError(t, db.Exec("DELETE FROM job_runs").Error)
+
+	return NewScheduler(db, logger.NewLogger()), db
+}
+
+// stubLocker 取得可否を固定したロック
+type stubLocker struct {
+	acquired bool
+	released int32
+}
+
+func (l *stubLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	if !l.acquired {
+		return nil, false, nil
+	}
+	return func() { atomic.AddInt32(&l.released, 1) }, true, nil
+}
+
+// mutexLocker レプリカ間で共有するロック（アドバイザリロックの代わり）
+type mutexLocker struct {
+	mu sync.Mutex
+}
+
+func (l *mutexLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	if !l.mu.TryLock() {
+		return nil, false, nil
+	}
+	return l.mu.Unlock, true, nil
+}
+
+func findJobRun(t *testing.T, db *gorm.DB, name string) *models.JobRun {
+	var run models.JobRun
+	err := db.First(&run, "name = ?", name).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	require.NoError(t, err)
+	return &run
+}
+
+func TestScheduler_RunOnce(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+	ctx := context.Background()
+
+	t.Run("成功した実行結果を記録", func(t *testing.T) {
+		scheduler.runOnce(ctx, Job{Name: "cleanup", Interval: time.Minute, Run: func(ctx context.Context) (int64, error) {
+			return 3, nil
+		}})
+
+		run := findJobRun(t, db, "cleanup")
+		require.NotNil(t, run)
+		assert.Equal(t, models.JobRunStatusSuccess, run.LastStatus)
+		assert.Equal(t, int64(3), run.LastAffected)
+		assert.Nil(t, run.LastError)
+		assert.NotEmpty(t, run.LastInstance)
+	})
+
+	t.Run("失敗は前回の結果を上書きして記録", func(t *testing.T) {
+		scheduler.runOnce(ctx, Job{Name: "cleanup", Interval: time.Minute, Run: func(ctx context.Context) (int64, error) {
+			return 0, errors.New("database unavailable")
+		}})
+
+		run := findJobRun(t, db, "cleanup")
+		require.NotNil(t, run)
+		assert.Equal(t, models.JobRunStatusFailed, run.LastStatus)
+		require.NotNil(t, run.LastError)
+		assert.Equal(t, "database unavailable", *run.LastError)
+		assert.Equal(t, int64(0), run.LastAffected)
+	})
+
+	t.Run("panicは失敗として記録", func(t *testing.T) {
+		scheduler.runOnce(ctx, Job{Name: "panicking", Interval: time.Minute, Run: func(ctx context.Context) (int64, error) {
+			panic("unexpected")
+		}})
+
+		run := findJobRun(t, db, "panicking")
+		require.NotNil(t, run)
+		assert.Equal(t, models.JobRunStatusFailed, run.LastStatus)
+		assert.Contains(t, *run.LastError, "unexpected")
+	})
+}
+
+func TestScheduler_Locker(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+	ctx := context.Background()
+
+	var runs int32
+	job := Job{Name: "exclusive", Interval: time.Minute, Run: func(ctx context.Context) (int64, error) {
+		atomic.AddInt32(&runs, 1)
+		return 0, nil
+	}}
+
+	t.Run("他のレプリカがロックを保持している場合は実行しない", func(t *testing.T) {
+		scheduler.SetLocker(&stubLocker{acquired: false})
+		scheduler.runOnce(ctx, job)
+
+		assert.Equal(t, int32(0), atomic.LoadInt32(&runs))
+		assert.Nil(t, findJobRun(t, db, "exclusive"))
+	})
+
+	t.Run("ロックを取得した場合は実行後に解放", func(t *testing.T) {
+		locker := &stubLocker{acquired: true}
+		scheduler.SetLocker(locker)
+		scheduler.runOnce(ctx, job)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&locker.released))
+		assert.NotNil(t, findJobRun(t, db, "exclusive"))
+	})
+}
+
+func TestScheduler_StartAndStatuses(t *testing.T) {
+	scheduler, _ := setupTestScheduler(t)
+
+	var runs int32
+	scheduler.Register(Job{Name: "frequent", Interval: 10 * time.Millisecond, Run: func(ctx context.Context) (int64, error) {
+		atomic.AddInt32(&runs, 1)
+		return 1, nil
+	}})
+	scheduler.Register(Job{Name: "disabled", Interval: 0, Run: func(ctx context.Context) (int64, error) {
+		t.Error("間隔が0のジョブは実行しない")
+		return 0, nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler.Start(ctx)
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 2 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	scheduler.Wait()
+	stopped := atomic.LoadInt32(&runs)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&runs), "停止後は実行しない")
+
+	statuses, err := scheduler.Statuses()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, "frequent", statuses[0].Name)
+	assert.True(t, statuses[0].Enabled)
+	assert.NotNil(t, statuses[0].NextRunAt)
+	require.NotNil(t, statuses[0].LastRun)
+	assert.Equal(t, models.JobRunStatusSuccess, statuses[0].LastRun.LastStatus)
+
+	assert.Equal(t, "disabled", statuses[1].Name)
+	assert.False(t, statuses[1].Enabled)
+	assert.Nil(t, statuses[1].LastRun)
+}
+
+func TestScheduler_MultipleReplicas(t *testing.T) {
+	replicaA, db := setupTestScheduler(t)
+	replicaB := NewScheduler(db, logger.NewLogger())
+	replicaA.instance, replicaB.instance = "replica-a", "replica-b"
+
+	locker := &mutexLocker{}
+	replicaA.SetLocker(locker)
+	replicaB.SetLocker(locker)
+
+	var runs int32
+	job := Job{Name: "replicated", Interval: time.Hour, Run: func(ctx context.Context) (int64, error) {
+		atomic.AddInt32(&runs, 1)
+		return 0, nil
+	}}
+	ctx := context.Background()
+
+	t.Run("間隔内は1つのレプリカのみが実行", func(t *testing.T) {
+		replicaA.runOnce(ctx, job)
+		replicaB.runOnce(ctx, job)
+		replicaA.runOnce(ctx, job)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+		assert.Equal(t, "replica-a", findJobRun(t, db, "replicated").LastInstance)
+	})
+
+	t.Run("間隔を過ぎた後は他のレプリカが実行", func(t *testing.T) {
+		require.NoError(t, db.Exec("UPDATE job_runs SET last_started_at = ? WHERE name = ?",
+			time.Now().Add(-time.Hour), "replicated").Error)
+
+		replicaB.runOnce(ctx, job)
+		replicaA.runOnce(ctx, job)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+		assert.Equal(t, "replica-b", findJobRun(t, db, "replicated").LastInstance)
+	})
+
+	t.Run("定期実行でも間隔あたり1回", func(t *testing.T) {
+		var periodic int32
+		frequent := Job{Name: "replicated-frequent", Interval: 100 * time.Millisecond, Run: func(ctx context.Context) (int64, error) {
+			atomic.AddInt32(&periodic, 1)
+			return 0, nil
+		}}
+		replicaA.Register(frequent)
+		replicaB.Register(frequent)
+
+		runCtx, cancel := context.WithCancel(context.Background())
+		replicaA.Start(runCtx)
+		replicaB.Start(runCtx)
+		time.Sleep(450 * time.Millisecond)
+		cancel()
+		replicaA.Wait()
+		replicaB.Wait()
+
+		// 起動直後と4回の間隔で最大5回（レプリカごとに実行すると10回）
+		count := atomic.LoadInt32(&periodic)
+		assert.GreaterOrEqual(t, count, int32(3))
+		assert.LessOrEqual(t, count, int32(6))
+	})
+}
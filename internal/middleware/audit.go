@@ -0,0 +1,264 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// 監査ログ用コンテキストキー
+const (
+	requiredPermissionsKey = "required_permissions" // RequirePermissions等で要求された権限
+	auditReasonCodeKey     = "audit_reason_code"    // 拒否・エラー時の理由コード
+	auditUserIDKey         = "audit_user_id"        // 認証前・認証失敗時に判明したユーザーID
+	auditSkipKey           = "audit_skip"           // ハンドラー側（サービス）で記録済みのリクエスト
+)
+
+// auditTarget 監査ログのアクション・リソース種別
+type auditTarget struct {
+	Action       string
+	ResourceType string
+}
+
+// auditRouteTargets CRUD以外のルートの監査対象定義（"METHOD FullPath"）
+var auditRouteTargets = map[string]auditTarget{
+	"POST /api/v1/auth/login":                       {Action: "login", ResourceType: "auth"},
+	"POST /api/v1/auth/logout":                      {Action: "logout", ResourceType: "auth"},
+	"POST /api/v1/auth/refresh":                     {Action: "update", ResourceType: "session"},
+	"GET /api/v1/auth/profile":                      {Action: "view", ResourceType: "auth"},
+	"POST /api/v1/auth/change-password":             {Action: "password_reset", ResourceType: "auth"},
+	"POST /api/v1/auth/introspect":                  {Action: "view", ResourceType: "session"},
+	"POST /api/v1/auth/password/forgot":             {Action: "password_reset", ResourceType: "auth"},
+	"POST /api/v1/auth/password/reset":              {Action: "password_reset", ResourceType: "auth"},
+	"POST /api/v1/auth/mfa/verify":                  {Action: "login", ResourceType: "auth"},
+	"POST /api/v1/auth/mfa/setup":                   {Action: "update", ResourceType: "auth"},
+	"POST /api/v1/auth/mfa/setup/confirm":           {Action: "login", ResourceType: "auth"},
+	"GET /api/v1/auth/mfa":                          {Action: "view", ResourceType: "auth"},
+	"POST /api/v1/auth/mfa/enroll":                  {Action: "update", ResourceType: "auth"},
+	"POST /api/v1/auth/mfa/enroll/confirm":          {Action: "update", ResourceType: "auth"},
+	"GET /api/v1/auth/sessions":                     {Action: "view", ResourceType: "session"},
+	"DELETE /api/v1/auth/sessions":                  {Action: "logout", ResourceType: "session"},
+	"DELETE /api/v1/auth/sessions/:id":              {Action: "logout", ResourceType: "session"},
+	"PUT /api/v1/users/:id/status":                  {Action: "status_change", ResourceType: "users"},
+	"PUT /api/v1/users/:id/password":                {Action: "password_reset", ResourceType: "users"},
+	"POST /api/v1/users/:id/unlock":                 {Action: "status_change", ResourceType: "users"},
+	"DELETE /api/v1/users/:id/mfa":                  {Action: "update", ResourceType: "users"},
+	"GET /api/v1/users/:id/sessions":                {Action: "view", ResourceType: "session"},
+	"DELETE /api/v1/users/:id/sessions":             {Action: "logout", ResourceType: "session"},
+	"DELETE /api/v1/users/:id/sessions/:session_id": {Action: "logout", ResourceType: "session"},
+	"POST /api/v1/users/roles":                      {Action: "role_change", ResourceType: "users"},
+	"PATCH /api/v1/users/:id/roles/:role_id":        {Action: "role_change", ResourceType: "users"},
+	"DELETE /api/v1/users/:id/roles/:role_id":       {Action: "role_change", ResourceType: "users"},
+	"PUT /api/v1/roles/:id/permissions":             {Action: "role_change", ResourceType: "roles"},
+	"GET /api/v1/audit-logs/verify":                 {Action: "view", ResourceType: "audit"},
+	"GET /api/v1/system/jobs":                       {Action: "view", ResourceType: "system"},
+	"GET /api/v1/system/permission-cache":           {Action: "view", ResourceType: "system"},
+}
+
+// auditPermissionActions 権限アクション → 監査アクション
+var auditPermissionActions = map[string]string{
+	"create":  "create",
+	"read":    "view",
+	"list":    "view",
+	"view":    "view",
+	"update":  "update",
+	"delete":  "delete",
+	"manage":  "update",
+	"approve": "approve",
+	"export":  "export",
+	"admin":   "update",
+}
+
+// auditRecorder 監査ログの記録先（services.AuditService）
+type auditRecorder interface {
+	Record(entry services.AuditEntry) (*models.AuditLog, error)
+}
+
+// AuditMiddleware 監査ログ記録ミドルウェア
+type AuditMiddleware struct {
+	auditService auditRecorder
+	logger       *logger.Logger
+}
+
+// NewAuditMiddleware 新しい監査ログミドルウェアを作成
+func NewAuditMiddleware(auditService *services.AuditService, logger *logger.Logger) *AuditMiddleware {
+	return &AuditMiddleware{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// Audit 全APIリクエストの結果をaudit_logsに記録
+// Authentication・RequirePermissionsより外側に登録すること（拒否も記録するため）
+func (m *AuditMiddleware) Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.GetBool(auditSkipKey) {
+			return
+		}
+
+		entry := buildAuditEntry(c)
+		if _, err := m.auditService.Record(entry); err != nil {
+			m.logger.Error("Failed to write audit log", err, map[string]interface{}{
+				"action":        entry.Action,
+				"resource_type": entry.ResourceType,
+				"resource_id":   entry.ResourceID,
+				"result":        entry.Result,
+				"path":          c.Request.URL.Path,
+			})
+		}
+	}
+}
+
+// SetAuditUserID 認証ミドルウェアを経由しないリクエスト（ログイン等）の監査ユーザーを設定
+func SetAuditUserID(c *gin.Context, userID uuid.UUID) {
+	c.Set(auditUserIDKey, userID)
+}
+
+// SkipAudit サービス側で監査ログを記録するリクエスト（ログイン試行等）の二重記録を防止
+func SkipAudit(c *gin.Context) {
+	c.Set(auditSkipKey, true)
+}
+
+// setAuditReasonCode 監査ログの理由コードを設定
+func setAuditReasonCode(c *gin.Context, reasonCode string) {
+	c.Set(auditReasonCodeKey, reasonCode)
+}
+
+// buildAuditEntry リクエストコンテキストから監査エントリを構築
+func buildAuditEntry(c *gin.Context) services.AuditEntry {
+	target := resolveAuditTarget(c)
+
+	entry := services.AuditEntry{
+		UserID:       resolveAuditUserID(c),
+		Action:       target.Action,
+		ResourceType: target.ResourceType,
+		ResourceID:   resolveAuditResourceID(c),
+		IPAddress:    c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+	}
+
+	// 結果の判定（ErrorHandlerがレスポンスを書き込む前に評価されるためc.Errorsを優先）
+	status := c.Writer.Status()
+	if len(c.Errors) > 0 {
+		err := c.Errors.Last().Err
+		if apiErr, ok := err.(*errors.APIError); ok {
+			status = apiErr.Status
+			entry.Reason = apiErr.Details.Reason
+			entry.ReasonCode = reasonCodeFromStatus(status, apiErr.Code)
+		} else {
+			status = http.StatusInternalServerError
+			entry.Reason = err.Error()
+			entry.ReasonCode = reasonCodeFromStatus(status, errors.ErrCodeInternal)
+		}
+	}
+
+	if code, exists := c.Get(auditReasonCodeKey); exists {
+		if reasonCode, ok := code.(string); ok {
+			entry.ReasonCode = reasonCode
+		}
+	}
+
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		entry.Result = models.AuditResultDenied
+	case status >= http.StatusBadRequest:
+		entry.Result = models.AuditResultError
+	default:
+		entry.Result = models.AuditResultSuccess
+	}
+
+	return entry
+}
+
+// resolveAuditTarget ルート定義・要求権限・HTTPメソッドからアクションとリソース種別を決定
+func resolveAuditTarget(c *gin.Context) auditTarget {
+	// 1. CRUD以外の個別ルート定義
+	if target, exists := auditRouteTargets[c.Request.Method+" "+c.FullPath()]; exists {
+		return target
+	}
+
+	// 2. RequirePermissionsのメタデータ（"module:action"）
+	if perms, exists := c.Get(requiredPermissionsKey); exists {
+		if required, ok := perms.([]string); ok && len(required) > 0 {
+			parts := strings.SplitN(required[0], ":", 2)
+			if len(parts) == 2 {
+				resourceType := services.ResourceTypeForPermission(required[0])
+				action, actionOK := auditPermissionActions[parts[1]]
+				if resourceType != "" && actionOK {
+					return auditTarget{Action: action, ResourceType: resourceType}
+				}
+			}
+		}
+	}
+
+	// 3. パスの先頭セグメントとHTTPメソッドから推定
+	target := auditTarget{Action: auditActionFromMethod(c.Request.Method), ResourceType: "system"}
+	segments := strings.Split(strings.TrimPrefix(c.FullPath(), "/api/v1/"), "/")
+	if len(segments) > 0 {
+		probe := models.AuditLog{ResourceType: segments[0]}
+		if probe.IsValidResourceType() {
+			target.ResourceType = segments[0]
+		}
+	}
+	return target
+}
+
+// resolveAuditUserID 認証済みユーザー、または認証失敗時に判明したユーザーを取得
+func resolveAuditUserID(c *gin.Context) *uuid.UUID {
+	for _, key := range []string{"user_id", auditUserIDKey} {
+		if value, exists := c.Get(key); exists {
+			if userID, ok := value.(uuid.UUID); ok && userID != uuid.Nil {
+				return &userID
+			}
+		}
+	}
+	return nil
+}
+
+// resolveAuditResourceID パスパラメータのIDを優先し、なければルートパスを使用
+func resolveAuditResourceID(c *gin.Context) string {
+	if id := c.Param("id"); id != "" {
+		return id
+	}
+	if c.FullPath() != "" {
+		return c.FullPath()
+	}
+	return c.Request.URL.Path
+}
+
+// auditActionFromMethod HTTPメソッドから監査アクションを推定
+func auditActionFromMethod(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "view"
+	}
+}
+
+// reasonCodeFromStatus HTTPステータスとエラーコードから理由コードを生成
+func reasonCodeFromStatus(status int, code string) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return "AUTH_" + code
+	case status == http.StatusForbidden:
+		return "PERM_" + code
+	case status >= http.StatusInternalServerError:
+		return "SYSR_" + code
+	default:
+		return "VALR_" + code
+	}
+}
@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"erp-access-control-go/internal/services"
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// stubAuditRecorder 記録された監査エントリを保持するテスト用レコーダー
+type stubAuditRecorder struct {
+	entries []services.AuditEntry
+}
+
+func (r *stubAuditRecorder) Record(entry services.AuditEntry) (*models.AuditLog, error) {
+	r.entries = append(r.entries, entry)
+	return &models.AuditLog{}, nil
+}
+
+// performAuditedRequest main.goと同じ順序でErrorHandler・Auditを登録したルーターでリクエストを実行
+// 記録された監査エントリとレスポンスのステータスを返す
+func performAuditedRequest(method, route, url string, handlers ...gin.HandlerFunc) ([]services.AuditEntry, int) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := &stubAuditRecorder{}
+	audit := &AuditMiddleware{auditService: recorder, logger: logger.NewLogger()}
+
+	router := gin.New()
+	router.Use(ErrorHandler(logger.NewLogger()))
+	router.Use(audit.Audit())
+	router.Handle(method, route, handlers...)
+
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(method, url, nil)
+	request.Header.Set("User-Agent", "audit-test")
+	router.ServeHTTP(response, request)
+	return recorder.entries, response.Code
+}
+
+// respondOK 200を返すハンドラー
+func respondOK(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// respondError エラーを登録して処理を中断するハンドラー
+func respondError(err error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+func TestAuditMiddleware_Target(t *testing.T) {
+	userID := uuid.New()
+	targetID := uuid.New().String()
+
+	t.Run("個別ルート定義を優先", func(t *testing.T) {
+		authenticate := func(c *gin.Context) { c.Set("user_id", userID) }
+		requirePermission := func(c *gin.Context) { c.Set(requiredPermissionsKey, []string{"user:update"}) }
+		entries, status := performAuditedRequest(http.MethodPut, "/api/v1/users/:id/status", "/api/v1/users/"+targetID+"/status",
+			authenticate, requirePermission, respondOK)
+
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "status_change", entries[0].Action)
+		assert.Equal(t, "users", entries[0].ResourceType)
+		assert.Equal(t, targetID, entries[0].ResourceID)
+		assert.Equal(t, models.AuditResultSuccess, entries[0].Result)
+		require.NotNil(t, entries[0].UserID)
+		assert.Equal(t, userID, *entries[0].UserID)
+		assert.Equal(t, "audit-test", entries[0].UserAgent)
+	})
+
+	t.Run("要求権限からアクションとリソース種別を決定", func(t *testing.T) {
+		requirePermission := func(c *gin.Context) { c.Set(requiredPermissionsKey, []string{"role:read"}) }
+		entries, _ := performAuditedRequest(http.MethodGet, "/api/v1/roles/:id", "/api/v1/roles/"+targetID, requirePermission, respondOK)
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, "view", entries[0].Action)
+		assert.Equal(t, "roles", entries[0].ResourceType)
+		assert.Nil(t, entries[0].UserID, "未認証のリクエストはユーザーなし")
+	})
+
+	t.Run("パスとHTTPメソッドから推定", func(t *testing.T) {
+		tests := []struct {
+			method           string
+			route            string
+			url              string
+			wantAction       string
+			wantResourceType string
+			wantResourceID   string
+		}{
+			{http.MethodDelete, "/api/v1/departments/:id", "/api/v1/departments/" + targetID, "delete", "departments", targetID},
+			{http.MethodPatch, "/api/v1/departments/:id", "/api/v1/departments/" + targetID, "update", "departments", targetID},
+			{http.MethodPost, "/api/v1/unknown", "/api/v1/unknown", "create", "system", "/api/v1/unknown"},
+		}
+		for _, tt := range tests {
+			entries, _ := performAuditedRequest(tt.method, tt.route, tt.url, respondOK)
+
+			require.Len(t, entries, 1, tt.method+" "+tt.route)
+			assert.Equal(t, tt.wantAction, entries[0].Action, tt.method+" "+tt.route)
+			assert.Equal(t, tt.wantResourceType, entries[0].ResourceType, tt.method+" "+tt.route)
+			assert.Equal(t, tt.wantResourceID, entries[0].ResourceID, tt.method+" "+tt.route)
+		}
+	})
+}
+
+func TestAuditMiddleware_Result(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        gin.HandlerFunc
+		wantStatus     int
+		wantResult     models.AuditResult
+		wantReasonCode string
+		wantReason     string
+	}{
+		{
+			name:       "成功",
+			handler:    respondOK,
+			wantStatus: http.StatusOK,
+			wantResult: models.AuditResultSuccess,
+		},
+		{
+			name:           "認証エラーは拒否",
+			handler:        respondError(errors.NewAuthenticationError("token expired")),
+			wantStatus:     http.StatusUnauthorized,
+			wantResult:     models.AuditResultDenied,
+			wantReasonCode: "AUTH_" + errors.ErrCodeAuthentication,
+			wantReason:     "token expired",
+		},
+		{
+			name:           "認可エラーは拒否",
+			handler:        respondError(errors.NewAuthorizationError("missing permission")),
+			wantStatus:     http.StatusForbidden,
+			wantResult:     models.AuditResultDenied,
+			wantReasonCode: "PERM_" + errors.ErrCodeAuthorization,
+			wantReason:     "missing permission",
+		},
+		{
+			name:           "バリデーションエラー",
+			handler:        respondError(errors.NewValidationError("name", "Name is required")),
+			wantStatus:     http.StatusBadRequest,
+			wantResult:     models.AuditResultError,
+			wantReasonCode: "VALR_" + errors.ErrCodeValidation,
+			wantReason:     "Name is required",
+		},
+		{
+			name:           "APIError以外は内部エラー",
+			handler:        respondError(fmt.Errorf("connection refused")),
+			wantStatus:     http.StatusInternalServerError,
+			wantResult:     models.AuditResultError,
+			wantReasonCode: "SYSR_" + errors.ErrCodeInternal,
+			wantReason:     "connection refused",
+		},
+		{
+			name: "ミドルウェアが設定した理由コードを優先",
+			handler: func(c *gin.Context) {
+				setAuditReasonCode(c, models.ReasonCodePermMissingPermission)
+				respondError(errors.NewAuthorizationError("missing permission"))(c)
+			},
+			wantStatus:     http.StatusForbidden,
+			wantResult:     models.AuditResultDenied,
+			wantReasonCode: models.ReasonCodePermMissingPermission,
+			wantReason:     "missing permission",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, status := performAuditedRequest(http.MethodGet, "/api/v1/users", "/api/v1/users", tt.handler)
+
+			assert.Equal(t, tt.wantStatus, status)
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.wantResult, entries[0].Result)
+			assert.Equal(t, tt.wantReasonCode, entries[0].ReasonCode)
+			assert.Equal(t, tt.wantReason, entries[0].Reason)
+		})
+	}
+}
+
+func TestAuditMiddleware_SkipAudit(t *testing.T) {
+	entries, status := performAuditedRequest(http.MethodPost, "/api/v1/auth/login", "/api/v1/auth/login", func(c *gin.Context) {
+		SkipAudit(c)
+		respondOK(c)
+	})
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, entries, "サービス側で記録済みのリクエストは記録しない")
+
+	entries, _ = performAuditedRequest(http.MethodPost, "/api/v1/auth/login", "/api/v1/auth/login", respondOK)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "login", entries[0].Action)
+	assert.Equal(t, "auth", entries[0].ResourceType)
+}
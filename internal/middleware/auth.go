@@ -5,28 +5,40 @@ import (
 	"net/http"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"erp-access-control-go/internal/services"
+	"erp-access-control-go/models"
 	"erp-access-control-go/pkg/errors"
 	"erp-access-control-go/pkg/jwt"
 	"erp-access-control-go/pkg/logger"
 )
 
+// permissionCheckerKey 時間制限・スコープチェッカーのコンテキストキー
+const permissionCheckerKey = "permission_checker"
+
+// TimeAccessChecker 権限に対応するリソースの時間制限をチェック
+type TimeAccessChecker interface {
+	CheckTimeAccess(userID uuid.UUID, permission string, at time.Time) error
+}
+
 // AuthMiddleware JWT認証ミドルウェア
 type AuthMiddleware struct {
 	jwtService        *jwt.Service
 	revocationService *services.TokenRevocationService
+	permissionService *services.PermissionService
 	logger            *logger.Logger
 }
 
 // NewAuthMiddleware 新しい認証ミドルウェアを作成
-func NewAuthMiddleware(jwtService *jwt.Service, revocationService *services.TokenRevocationService, logger *logger.Logger) *AuthMiddleware {
+func NewAuthMiddleware(jwtService *jwt.Service, revocationService *services.TokenRevocationService, permissionService *services.PermissionService, logger *logger.Logger) *AuthMiddleware {
 	return &AuthMiddleware{
 		jwtService:        jwtService,
 		revocationService: revocationService,
+		permissionService: permissionService,
 		logger:            logger,
 	}
 }
@@ -82,6 +94,13 @@ func ErrorHandler(log *logger.Logger) gin.HandlerFunc {
 					"error": apiErr.Error(),
 					"path":  c.Request.URL.Path,
 				})
+			case isAPIError(err) && err.(*errors.APIError).Status < http.StatusInternalServerError:
+				// その他の構造化エラー（TIME_RESTRICTED・NOT_FOUND等）はステータスをそのまま返す
+				apiErr = err.(*errors.APIError)
+				log.Warn("Request error", map[string]interface{}{
+					"error": apiErr.Error(),
+					"path":  c.Request.URL.Path,
+				})
 			default:
 				// 未知のエラーは内部エラーとして処理
 				apiErr = errors.NewInternalError(err.Error())
@@ -105,6 +124,7 @@ func (m *AuthMiddleware) Authentication() gin.HandlerFunc {
 				"path": c.Request.URL.Path,
 				"ip":   c.ClientIP(),
 			})
+			setAuditReasonCode(c, models.ReasonCodeAuthMissingToken)
 			c.Error(errors.ErrInvalidToken)
 			c.Abort()
 			return
@@ -116,6 +136,7 @@ func (m *AuthMiddleware) Authentication() gin.HandlerFunc {
 				"path": c.Request.URL.Path,
 				"ip":   c.ClientIP(),
 			})
+			setAuditReasonCode(c, models.ReasonCodeAuthInvalidToken)
 			c.Error(errors.ErrInvalidToken)
 			c.Abort()
 			return
@@ -128,6 +149,7 @@ func (m *AuthMiddleware) Authentication() gin.HandlerFunc {
 				"path":  c.Request.URL.Path,
 				"ip":    c.ClientIP(),
 			})
+			setAuditReasonCode(c, models.ReasonCodeAuthInvalidToken)
 			c.Error(errors.ErrInvalidToken)
 			c.Abort()
 			return
@@ -140,6 +162,8 @@ func (m *AuthMiddleware) Authentication() gin.HandlerFunc {
 				"user_id":  claims.UserID,
 				"path":     c.Request.URL.Path,
 			})
+			SetAuditUserID(c, claims.UserID)
+			setAuditReasonCode(c, models.ReasonCodeAuthTokenRevoked)
 			c.Error(errors.ErrTokenRevoked)
 			c.Abort()
 			return
@@ -153,6 +177,9 @@ func (m *AuthMiddleware) Authentication() gin.HandlerFunc {
 		c.Set("primary_role_id", claims.PrimaryRoleID)
 		c.Set("active_roles", claims.ActiveRoles)
 		c.Set("highest_role", claims.HighestRole)
+		if m.permissionService != nil {
+			c.Set(permissionCheckerKey, m.permissionService)
+		}
 
 		// アクセスログ
 		m.logger.Info("Authenticated request", map[string]interface{}{
@@ -169,8 +196,11 @@ func (m *AuthMiddleware) Authentication() gin.HandlerFunc {
 // RequirePermissions ユーザーが必要な権限を持っているかチェック
 func RequirePermissions(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Set(requiredPermissionsKey, permissions)
+
 		userPerms, exists := c.Get("permissions")
 		if !exists {
+			setAuditReasonCode(c, models.ReasonCodePermNoPermissions)
 			c.Error(errors.ErrPermissionDenied)
 			c.Abort()
 			return
@@ -178,6 +208,7 @@ func RequirePermissions(permissions ...string) gin.HandlerFunc {
 
 		userPermissions, ok := userPerms.([]string)
 		if !ok {
+			setAuditReasonCode(c, models.ReasonCodePermNoPermissions)
 			c.Error(errors.ErrPermissionDenied)
 			c.Abort()
 			return
@@ -185,12 +216,21 @@ func RequirePermissions(permissions ...string) gin.HandlerFunc {
 
 		for _, requiredPerm := range permissions {
 			if !hasPermission(userPermissions, requiredPerm) {
+				setAuditReasonCode(c, models.ReasonCodePermMissingPermission)
 				c.Error(errors.NewAuthorizationError(fmt.Sprintf("Missing required permission: %s", requiredPerm)))
 				c.Abort()
 				return
 			}
 		}
 
+		// 時間制限チェック
+		for _, requiredPerm := range permissions {
+			if err := checkTimeAccess(c, requiredPerm); err != nil {
+				denyTimeRestricted(c, err)
+				return
+			}
+		}
+
 		c.Next()
 	}
 }
@@ -198,8 +238,11 @@ func RequirePermissions(permissions ...string) gin.HandlerFunc {
 // RequireAnyPermission ユーザーが必要な権限のいずれかを持っているかチェック
 func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Set(requiredPermissionsKey, permissions)
+
 		userPerms, exists := c.Get("permissions")
 		if !exists {
+			setAuditReasonCode(c, models.ReasonCodePermNoPermissions)
 			c.Error(errors.ErrPermissionDenied)
 			c.Abort()
 			return
@@ -207,18 +250,31 @@ func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
 
 		userPermissions, ok := userPerms.([]string)
 		if !ok {
+			setAuditReasonCode(c, models.ReasonCodePermNoPermissions)
 			c.Error(errors.ErrPermissionDenied)
 			c.Abort()
 			return
 		}
 
+		// 保有権限のうち時間制限を満たすものが1つでもあれば許可
+		var timeErr error
 		for _, requiredPerm := range permissions {
 			if hasPermission(userPermissions, requiredPerm) {
+				if err := checkTimeAccess(c, requiredPerm); err != nil {
+					timeErr = err
+					continue
+				}
 				c.Next()
 				return
 			}
 		}
 
+		if timeErr != nil {
+			denyTimeRestricted(c, timeErr)
+			return
+		}
+
+		setAuditReasonCode(c, models.ReasonCodePermMissingPermission)
 		c.Error(errors.NewAuthorizationError(fmt.Sprintf("Missing any of required permissions: %s", strings.Join(permissions, ", "))))
 		c.Abort()
 	}
@@ -280,6 +336,21 @@ func GetCurrentUserID(c *gin.Context) (uuid.UUID, error) {
 	return currentUserID, nil
 }
 
+// GetCurrentTokenID コンテキストから現在のアクセストークンのJTIを取得
+func GetCurrentTokenID(c *gin.Context) (string, error) {
+	jti, exists := c.Get("jti")
+	if !exists {
+		return "", errors.NewAuthenticationError("Token ID not found in context")
+	}
+
+	tokenID, ok := jti.(string)
+	if !ok {
+		return "", errors.NewAuthenticationError("Invalid token ID in context")
+	}
+
+	return tokenID, nil
+}
+
 // GetCurrentUserEmail コンテキストから現在のユーザーメールアドレスを取得
 func GetCurrentUserEmail(c *gin.Context) (string, error) {
 	email, exists := c.Get("email")
@@ -310,25 +381,41 @@ func GetCurrentUserPermissions(c *gin.Context) ([]string, error) {
 	return permissions, nil
 }
 
-// hasPermission ユーザーの権限リストに指定された権限が存在するかチェック
-func hasPermission(userPermissions []string, requiredPermission string) bool {
-	for _, perm := range userPermissions {
-		// 完全一致をチェック
-		if perm == requiredPermission {
-			return true
-		}
-		// ワイルドカード権限をチェック
-		if perm == "*" || perm == "*:*" {
-			return true
-		}
-		// モジュール別ワイルドカード（例: "user:*"）をチェック
-		if strings.Contains(requiredPermission, ":") && strings.HasSuffix(perm, ":*") {
-			requiredModule := strings.Split(requiredPermission, ":")[0]
-			permModule := strings.TrimSuffix(perm, ":*")
-			if requiredModule == permModule {
-				return true
-			}
-		}
+// checkTimeAccess 認証時に設定された時間制限チェッカーで権限の時間制限を確認
+func checkTimeAccess(c *gin.Context, permission string) error {
+	value, exists := c.Get(permissionCheckerKey)
+	if !exists {
+		return nil
+	}
+	checker, ok := value.(TimeAccessChecker)
+	if !ok {
+		return nil
 	}
-	return false
+
+	userID, err := GetCurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	return checker.CheckTimeAccess(userID, permission, time.Now())
+}
+
+// denyTimeRestricted 時間制限によるアクセス拒否
+func denyTimeRestricted(c *gin.Context, err error) {
+	if errors.IsTimeRestrictedError(err) {
+		setAuditReasonCode(c, models.ReasonCodePermTimeRestricted)
+	}
+	c.Error(err)
+	c.Abort()
+}
+
+// isAPIError 構造化されたAPIエラーかチェック
+func isAPIError(err error) bool {
+	_, ok := err.(*errors.APIError)
+	return ok
+}
+
+// hasPermission ユーザーの権限リストに指定された権限が許可されているかチェック（拒否権限が優先）
+func hasPermission(userPermissions []string, requiredPermission string) bool {
+	return services.HasPermission(userPermissions, requiredPermission)
 }
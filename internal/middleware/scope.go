@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+)
+
+// ScopeChecker スコープ条件付きで権限をチェック
+type ScopeChecker interface {
+	CheckPermissionWithScope(userID uuid.UUID, permission string, resourceScope map[string]interface{}) (bool, error)
+}
+
+// ScopeExtractor リクエストからリソースのスコープ属性を抽出
+// 例: {"department_id": "...", "region": "kanto"}
+type ScopeExtractor func(c *gin.Context) (map[string]interface{}, error)
+
+// ScopeFromParams パスパラメータをスコープ属性にマッピング（パラメータ名 → 属性名）
+func ScopeFromParams(mapping map[string]string) ScopeExtractor {
+	return func(c *gin.Context) (map[string]interface{}, error) {
+		scope := make(map[string]interface{}, len(mapping))
+		for param, key := range mapping {
+			value := c.Param(param)
+			if value == "" {
+				return nil, errors.NewValidationError(param, "missing path parameter")
+			}
+			scope[key] = value
+		}
+		return scope, nil
+	}
+}
+
+// ScopeFromQuery クエリパラメータをスコープ属性にマッピング（パラメータ名 → 属性名）
+// 指定されていないパラメータは属性に含めない
+func ScopeFromQuery(mapping map[string]string) ScopeExtractor {
+	return func(c *gin.Context) (map[string]interface{}, error) {
+		scope := make(map[string]interface{}, len(mapping))
+		for query, key := range mapping {
+			if value, exists := c.GetQuery(query); exists && value != "" {
+				scope[key] = value
+			}
+		}
+		return scope, nil
+	}
+}
+
+// ScopeFromResource パスパラメータのIDでリソースを読み込み、そのスコープ属性を使用
+func ScopeFromResource(param string, load func(id uuid.UUID) (map[string]interface{}, error)) ScopeExtractor {
+	return func(c *gin.Context) (map[string]interface{}, error) {
+		id, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return nil, errors.NewValidationError(param, "Invalid UUID format")
+		}
+		return load(id)
+	}
+}
+
+// MergeScopeExtractors 複数の抽出結果をマージ（後の抽出結果を優先）
+func MergeScopeExtractors(extractors ...ScopeExtractor) ScopeExtractor {
+	return func(c *gin.Context) (map[string]interface{}, error) {
+		scope := make(map[string]interface{})
+		for _, extractor := range extractors {
+			values, err := extractor(c)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range values {
+				scope[key] = value
+			}
+		}
+		return scope, nil
+	}
+}
+
+// RequireScopedPermission 権限に加えてリソースのスコープ属性がユーザースコープ内かチェック
+func RequireScopedPermission(permission string, extractor ScopeExtractor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(requiredPermissionsKey, []string{permission})
+
+		// JWTの権限リストで事前チェック
+		userPerms, exists := c.Get("permissions")
+		userPermissions, ok := userPerms.([]string)
+		if !exists || !ok {
+			setAuditReasonCode(c, models.ReasonCodePermNoPermissions)
+			c.Error(errors.ErrPermissionDenied)
+			c.Abort()
+			return
+		}
+		if !hasPermission(userPermissions, permission) {
+			setAuditReasonCode(c, models.ReasonCodePermMissingPermission)
+			c.Error(errors.NewAuthorizationError(fmt.Sprintf("Missing required permission: %s", permission)))
+			c.Abort()
+			return
+		}
+
+		value, _ := c.Get(permissionCheckerKey)
+		checker, ok := value.(ScopeChecker)
+		if !ok {
+			c.Error(errors.NewInternalError("scope checker is not configured"))
+			c.Abort()
+			return
+		}
+
+		userID, err := GetCurrentUserID(c)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		resourceScope, err := extractor(c)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		// DB上の権限・時間制限・スコープを照合
+		allowed, err := checker.CheckPermissionWithScope(userID, permission, resourceScope)
+		if err != nil {
+			if errors.IsTimeRestrictedError(err) {
+				denyTimeRestricted(c, err)
+				return
+			}
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			setAuditReasonCode(c, models.ReasonCodePermScopeDenied)
+			c.Error(errors.NewInsufficientScopeError(permission))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// stubScopeChecker 指定した判定結果を返し、照合したスコープを記録するテスト用チェッカー
+type stubScopeChecker struct {
+	allowed bool
+	err     error
+
+	called        bool
+	permission    string
+	resourceScope map[string]interface{}
+}
+
+func (s *stubScopeChecker) CheckPermissionWithScope(userID uuid.UUID, permission string, resourceScope map[string]interface{}) (bool, error) {
+	s.called = true
+	s.permission = permission
+	s.resourceScope = resourceScope
+	return s.allowed, s.err
+}
+
+// scopeTestResult レスポンスと監査用の理由コード
+type scopeTestResult struct {
+	status     int
+	code       string
+	reasonCode string
+}
+
+// performScopedRequest 認証済みのコンテキストでRequireScopedPermissionを通したリクエストを実行
+// permissionsがnilの場合はコンテキストに権限リストを設定しない、checkerがnilの場合はチェッカーを設定しない
+func performScopedRequest(t *testing.T, permissions []string, checker ScopeChecker, extractor ScopeExtractor, url string) scopeTestResult {
+	gin.SetMode(gin.TestMode)
+
+	var reasonCode string
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		reasonCode = c.GetString(auditReasonCodeKey)
+	})
+	router.Use(ErrorHandler(logger.NewLogger()))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		if permissions != nil {
+			c.Set("permissions", permissions)
+		}
+		if checker != nil {
+			c.Set(permissionCheckerKey, checker)
+		}
+	})
+	router.GET("/departments/:id/users/:user_id", RequireScopedPermission("user:update", extractor), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	if recorder.Code != http.StatusOK {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	}
+	return scopeTestResult{status: recorder.Code, code: body.Code, reasonCode: reasonCode}
+}
+
+func TestRequireScopedPermission(t *testing.T) {
+	departmentID := uuid.New().String()
+	userID := uuid.New().String()
+	url := "/departments/" + departmentID + "/users/" + userID + "?region=kanto"
+	extractor := MergeScopeExtractors(
+		ScopeFromParams(map[string]string{"id": "department_id"}),
+		ScopeFromQuery(map[string]string{"region": "region"}),
+	)
+
+	t.Run("スコープ内のリソースは許可", func(t *testing.T) {
+		checker := &stubScopeChecker{allowed: true}
+		result := performScopedRequest(t, []string{"user:*"}, checker, extractor, url)
+
+		assert.Equal(t, http.StatusOK, result.status)
+		assert.Equal(t, "user:update", checker.permission)
+		assert.Equal(t, map[string]interface{}{"department_id": departmentID, "region": "kanto"}, checker.resourceScope)
+	})
+
+	t.Run("スコープ外のリソースは拒否", func(t *testing.T) {
+		checker := &stubScopeChecker{allowed: false}
+		result := performScopedRequest(t, []string{"user:update"}, checker, extractor, url)
+
+		assert.Equal(t, http.StatusForbidden, result.status)
+		assert.Equal(t, errors.ErrCodeInsufficientScope, result.code)
+		assert.Equal(t, models.ReasonCodePermScopeDenied, result.reasonCode)
+	})
+
+	t.Run("JWTの権限がない場合はスコープを照合せず拒否", func(t *testing.T) {
+		for name, permissions := range map[string][]string{
+			"権限なし": {"user:read"},
+			"拒否権限": {"user:*", "!user:update"},
+		} {
+			checker := &stubScopeChecker{allowed: true}
+			result := performScopedRequest(t, permissions, checker, extractor, url)
+
+			assert.Equal(t, http.StatusForbidden, result.status, name)
+			assert.Equal(t, models.ReasonCodePermMissingPermission, result.reasonCode, name)
+			assert.False(t, checker.called, name)
+		}
+	})
+
+	t.Run("コンテキストに権限リストがない場合は拒否", func(t *testing.T) {
+		result := performScopedRequest(t, nil, &stubScopeChecker{allowed: true}, extractor, url)
+
+		assert.Equal(t, http.StatusForbidden, result.status)
+		assert.Equal(t, models.ReasonCodePermNoPermissions, result.reasonCode)
+	})
+
+	t.Run("時間制限外は拒否", func(t *testing.T) {
+		checker := &stubScopeChecker{err: errors.NewTimeRestrictedError("users")}
+		result := performScopedRequest(t, []string{"user:update"}, checker, extractor, url)
+
+		assert.Equal(t, http.StatusForbidden, result.status)
+		assert.Equal(t, errors.ErrCodeTimeRestricted, result.code)
+		assert.Equal(t, models.ReasonCodePermTimeRestricted, result.reasonCode)
+	})
+
+	t.Run("スコープの抽出エラーはチェッカーを呼ばずに返す", func(t *testing.T) {
+		checker := &stubScopeChecker{allowed: true}
+		loaded := false
+		resourceExtractor := ScopeFromResource("user_id", func(id uuid.UUID) (map[string]interface{}, error) {
+			loaded = true
+			return nil, nil
+		})
+		result := performScopedRequest(t, []string{"user:update"}, checker, resourceExtractor, "/departments/"+departmentID+"/users/not-a-uuid")
+
+		assert.Equal(t, http.StatusBadRequest, result.status)
+		assert.Equal(t, errors.ErrCodeValidation, result.code)
+		assert.False(t, loaded)
+		assert.False(t, checker.called)
+	})
+
+	t.Run("チェッカー未設定は内部エラー", func(t *testing.T) {
+		result := performScopedRequest(t, []string{"user:update"}, nil, extractor, url)
+		assert.Equal(t, http.StatusInternalServerError, result.status)
+	})
+}
+
+func TestScopeExtractors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// extract パスパラメータ・クエリを設定したコンテキストで抽出
+	extract := func(extractor ScopeExtractor, params gin.Params, query string) (map[string]interface{}, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		c.Params = params
+		return extractor(c)
+	}
+
+	t.Run("ScopeFromParams", func(t *testing.T) {
+		extractor := ScopeFromParams(map[string]string{"id": "department_id"})
+
+		scope, err := extract(extractor, gin.Params{{Key: "id", Value: "dept-1"}}, "")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"department_id": "dept-1"}, scope)
+
+		_, err = extract(extractor, nil, "")
+		assert.True(t, errors.IsValidationError(err), "パスパラメータがない場合はエラー")
+	})
+
+	t.Run("ScopeFromQuery", func(t *testing.T) {
+		extractor := ScopeFromQuery(map[string]string{"region": "region", "department": "department_id"})
+
+		scope, err := extract(extractor, nil, "region=kanto&department=")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"region": "kanto"}, scope, "未指定・空のパラメータは含めない")
+	})
+
+	t.Run("ScopeFromResource", func(t *testing.T) {
+		resourceID := uuid.New()
+		extractor := ScopeFromResource("id", func(id uuid.UUID) (map[string]interface{}, error) {
+			if id != resourceID {
+				return nil, errors.NewNotFoundError("User", "User not found")
+			}
+			return map[string]interface{}{"department_id": "dept-1"}, nil
+		})
+
+		scope, err := extract(extractor, gin.Params{{Key: "id", Value: resourceID.String()}}, "")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"department_id": "dept-1"}, scope)
+
+		_, err = extract(extractor, gin.Params{{Key: "id", Value: uuid.New().String()}}, "")
+		assert.True(t, errors.IsNotFound(err), "読み込みのエラーをそのまま返す")
+
+		_, err = extract(extractor, gin.Params{{Key: "id", Value: "invalid"}}, "")
+		assert.True(t, errors.IsValidationError(err))
+	})
+
+	t.Run("MergeScopeExtractors", func(t *testing.T) {
+		extractor := MergeScopeExtractors(
+			ScopeFromParams(map[string]string{"id": "department_id"}),
+			ScopeFromQuery(map[string]string{"department": "department_id", "region": "region"}),
+		)
+
+		scope, err := extract(extractor, gin.Params{{Key: "id", Value: "dept-1"}}, "department=dept-2&region=kansai")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"department_id": "dept-2", "region": "kansai"}, scope, "後の抽出結果を優先")
+
+		_, err = extract(extractor, nil, "region=kansai")
+		assert.True(t, errors.IsValidationError(err), "いずれかの抽出エラーを返す")
+	})
+}
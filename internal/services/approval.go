@@ -0,0 +1,742 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// ApprovalService 承認ワークフローサービス
+type ApprovalService struct {
+	db         *gorm.DB
+	logger     *logger.Logger
+	finalizers map[string]ApprovalFinalizer
+}
+
+// ApprovalFinalizer 最終承認時に申請内容を反映する処理
+// 最終承認と同一トランザクションで実行され、エラーの場合は承認自体がロールバックされる
+type ApprovalFinalizer func(tx *gorm.DB, request *models.ApprovalRequest) error
+
+// NewApprovalService 新しい承認ワークフローサービスを作成
+func NewApprovalService(db *gorm.DB, logger *logger.Logger) *ApprovalService {
+	return &ApprovalService{
+		db:         db,
+		logger:     logger,
+		finalizers: make(map[string]ApprovalFinalizer),
+	}
+}
+
+// RegisterFinalizer リソース種別の最終承認時処理を登録
+// 登録したリソース種別は各機能のAPI経由でのみ申請でき、汎用の申請APIからは申請できない
+func (s *ApprovalService) RegisterFinalizer(resourceType string, finalizer ApprovalFinalizer) {
+	s.finalizers[resourceType] = finalizer
+}
+
+// 承認ステップの進捗状態
+const (
+	ApprovalStepApproved = "approved"
+	ApprovalStepCurrent  = "current"
+	ApprovalStepRejected = "rejected"
+	ApprovalStepReturned = "returned"
+	ApprovalStepWaiting  = "waiting"
+)
+
+// SubmitApprovalRequest 承認申請リクエスト
+type SubmitApprovalRequest struct {
+	ResourceType string                 `json:"resource_type" binding:"required,max=50"`
+	ResourceID   *string                `json:"resource_id" binding:"omitempty,max=255"`
+	Title        string                 `json:"title" binding:"required,max=255"`
+	Payload      map[string]interface{} `json:"payload"`
+	Scope        map[string]interface{} `json:"scope"` // approval_states.scopeと照合する条件
+	Comment      string                 `json:"comment" binding:"omitempty,max=1000"`
+}
+
+// ApprovalActionRequest 承認・却下・差し戻し等の操作リクエスト
+type ApprovalActionRequest struct {
+	Comment string `json:"comment" binding:"omitempty,max=1000"`
+}
+
+// ApprovalStepApprover ステップの承認者定義
+type ApprovalStepApprover struct {
+	ApprovalStateID  int       `json:"approval_state_id"`
+	StateName        string    `json:"state_name"`
+	ApproverRoleID   uuid.UUID `json:"approver_role_id"`
+	ApproverRoleName string    `json:"approver_role_name"`
+}
+
+// ApprovalStepResponse 承認ステップ
+// 同じstep_orderに複数の承認状態がある場合は、いずれかのロールの承認で次へ進む
+type ApprovalStepResponse struct {
+	StepOrder int                    `json:"step_order"`
+	Status    string                 `json:"status,omitempty"`
+	Approvers []ApprovalStepApprover `json:"approvers"`
+}
+
+// ApprovalActionResponse 承認操作履歴レスポンス
+type ApprovalActionResponse struct {
+	ID              int                       `json:"id"`
+	StepOrder       int                       `json:"step_order"`
+	ApprovalStateID *int                      `json:"approval_state_id,omitempty"`
+	ActorID         uuid.UUID                 `json:"actor_id"`
+	Action          models.ApprovalActionType `json:"action"`
+	Comment         string                    `json:"comment,omitempty"`
+	CreatedAt       string                    `json:"created_at"`
+}
+
+// ApprovalRequestResponse 承認申請レスポンス
+type ApprovalRequestResponse struct {
+	ID           uuid.UUID                    `json:"id"`
+	ResourceType string                       `json:"resource_type"`
+	ResourceID   *string                      `json:"resource_id,omitempty"`
+	Title        string                       `json:"title"`
+	Payload      map[string]interface{}       `json:"payload,omitempty"`
+	Scope        map[string]interface{}       `json:"scope,omitempty"`
+	RequestedBy  uuid.UUID                    `json:"requested_by"`
+	Status       models.ApprovalRequestStatus `json:"status"`
+	CurrentStep  int                          `json:"current_step"`
+	Steps        []ApprovalStepResponse       `json:"steps,omitempty"`
+	Actions      []ApprovalActionResponse     `json:"actions,omitempty"`
+	CompletedAt  *string                      `json:"completed_at,omitempty"`
+	CreatedAt    string                       `json:"created_at"`
+	UpdatedAt    string                       `json:"updated_at"`
+}
+
+// ApprovalRequestListResponse 承認申請一覧レスポンス
+type ApprovalRequestListResponse struct {
+	Requests []ApprovalRequestResponse `json:"requests"`
+	Total    int                       `json:"total"`
+}
+
+// =============================================================================
+// 申請
+// =============================================================================
+
+// SubmitApprovalRequest 承認申請を作成し、承認フローの最初のステップへ回付
+func (s *ApprovalService) SubmitApprovalRequest(requesterID uuid.UUID, req SubmitApprovalRequest) (*ApprovalRequestResponse, error) {
+	if _, reserved := s.finalizers[req.ResourceType]; reserved {
+		return nil, errors.NewValidationError("resource_type", "Requests for this resource type must be submitted through its dedicated API")
+	}
+	return s.submit(requesterID, req)
+}
+
+// submit 承認申請を作成（リソース種別の制限なし）
+func (s *ApprovalService) submit(requesterID uuid.UUID, req SubmitApprovalRequest) (*ApprovalRequestResponse, error) {
+	s.logger.Info("Submitting approval request", map[string]interface{}{
+		"requested_by":  requesterID,
+		"resource_type": req.ResourceType,
+	})
+
+	if err := ensureUserExists(s.db, requesterID); err != nil {
+		return nil, err
+	}
+
+	flow, err := s.resolveApprovalFlow(req.ResourceType, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+	steps := approvalStepOrders(flow)
+	if len(steps) == 0 {
+		return nil, errNoApprovalFlow(req.ResourceType)
+	}
+
+	request := models.ApprovalRequest{
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		Title:        req.Title,
+		Payload:      models.JSONB(req.Payload),
+		Scope:        models.JSONB(req.Scope),
+		RequestedBy:  requesterID,
+		Status:       models.ApprovalStatusPending,
+		CurrentStep:  steps[0],
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Requester", "Actions").Create(&request).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Actor").Create(&models.ApprovalAction{
+			RequestID: request.ID,
+			ActorID:   requesterID,
+			Action:    models.ApprovalActionSubmit,
+			Comment:   req.Comment,
+		}).Error
+	})
+	if err != nil {
+		s.logger.Error("Failed to submit approval request", err, map[string]interface{}{
+			"requested_by":  requesterID,
+			"resource_type": req.ResourceType,
+		})
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("Approval request submitted successfully", map[string]interface{}{
+		"request_id":   request.ID,
+		"requested_by": requesterID,
+		"current_step": request.CurrentStep,
+	})
+
+	return s.getApprovalRequestDetail(request.ID)
+}
+
+// ResubmitApprovalRequest 差し戻された申請を再申請（最初のステップからやり直し）
+func (s *ApprovalService) ResubmitApprovalRequest(requestID, requesterID uuid.UUID, comment string) (*ApprovalRequestResponse, error) {
+	request, err := s.findApprovalRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequestedBy != requesterID {
+		return nil, errors.NewAuthorizationError("Only the requester can resubmit an approval request")
+	}
+	if request.Status != models.ApprovalStatusReturned {
+		return nil, errApprovalStatus(request, "Only returned requests can be resubmitted")
+	}
+
+	flow, err := s.resolveApprovalFlow(request.ResourceType, request.Scope)
+	if err != nil {
+		return nil, err
+	}
+	steps := approvalStepOrders(flow)
+	if len(steps) == 0 {
+		return nil, errNoApprovalFlow(request.ResourceType)
+	}
+
+	updates := map[string]interface{}{
+		"status":       models.ApprovalStatusPending,
+		"current_step": steps[0],
+	}
+	action := models.ApprovalAction{
+		ActorID: requesterID,
+		Action:  models.ApprovalActionResubmit,
+		Comment: comment,
+	}
+	if err := s.applyTransition(request, updates, &action, nil); err != nil {
+		return nil, err
+	}
+
+	return s.getApprovalRequestDetail(request.ID)
+}
+
+// CancelApprovalRequest 承認待ち・差し戻し中の申請を取り下げ
+func (s *ApprovalService) CancelApprovalRequest(requestID, requesterID uuid.UUID, comment string) (*ApprovalRequestResponse, error) {
+	request, err := s.findApprovalRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequestedBy != requesterID {
+		return nil, errors.NewAuthorizationError("Only the requester can cancel an approval request")
+	}
+	if request.IsClosed() {
+		return nil, errApprovalStatus(request, "Closed requests cannot be cancelled")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":       models.ApprovalStatusCancelled,
+		"current_step": 0,
+		"completed_at": &now,
+	}
+	action := models.ApprovalAction{
+		StepOrder: request.CurrentStep,
+		ActorID:   requesterID,
+		Action:    models.ApprovalActionCancel,
+		Comment:   comment,
+	}
+	if err := s.applyTransition(request, updates, &action, nil); err != nil {
+		return nil, err
+	}
+
+	return s.getApprovalRequestDetail(request.ID)
+}
+
+// =============================================================================
+// 承認操作
+// =============================================================================
+
+// ApproveRequest 現在のステップを承認（最終ステップの場合は申請を承認済みにする）
+func (s *ApprovalService) ApproveRequest(requestID, approverID uuid.UUID, comment string) (*ApprovalRequestResponse, error) {
+	return s.decide(requestID, approverID, models.ApprovalActionApprove, comment)
+}
+
+// RejectRequest 申請を却下（コメント必須）
+func (s *ApprovalService) RejectRequest(requestID, approverID uuid.UUID, comment string) (*ApprovalRequestResponse, error) {
+	return s.decide(requestID, approverID, models.ApprovalActionReject, comment)
+}
+
+// ReturnRequest 申請を申請者へ差し戻し（コメント必須）
+func (s *ApprovalService) ReturnRequest(requestID, approverID uuid.UUID, comment string) (*ApprovalRequestResponse, error) {
+	return s.decide(requestID, approverID, models.ApprovalActionReturn, comment)
+}
+
+// decide 現在のステップの承認者として承認・却下・差し戻しを行う
+func (s *ApprovalService) decide(requestID, approverID uuid.UUID, actionType models.ApprovalActionType, comment string) (*ApprovalRequestResponse, error) {
+	s.logger.Info("Processing approval action", map[string]interface{}{
+		"request_id":  requestID,
+		"approver_id": approverID,
+		"action":      actionType,
+	})
+
+	if actionType != models.ApprovalActionApprove && strings.TrimSpace(comment) == "" {
+		return nil, errors.NewValidationError("comment", "Comment is required to reject or return a request")
+	}
+
+	request, err := s.findApprovalRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !request.IsPending() {
+		return nil, errApprovalStatus(request, "Only pending requests can be approved, rejected or returned")
+	}
+	if request.RequestedBy == approverID {
+		return nil, errors.NewAuthorizationError("Requesters cannot act on their own approval request")
+	}
+
+	flow, err := s.resolveApprovalFlow(request.ResourceType, request.Scope)
+	if err != nil {
+		return nil, err
+	}
+	roleIDs, err := s.activeRoleIDs(approverID)
+	if err != nil {
+		return nil, err
+	}
+	state := findEligibleApprovalState(flow, request.CurrentStep, roleIDs)
+	if state == nil {
+		return nil, errors.NewAuthorizationError("You are not an approver for the current step of this request")
+	}
+	// 複数ステップの承認ロールを持つ場合も、1人で複数のステップを承認できないようにする
+	if hasApprovedOtherStep(request, approverID) {
+		return nil, errors.NewAuthorizationError("You have already approved another step of this request")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{}
+	var finalize ApprovalFinalizer
+	switch actionType {
+	case models.ApprovalActionApprove:
+		if next := nextApprovalStepOrder(flow, request.CurrentStep); next > 0 {
+			updates["current_step"] = next
+		} else {
+			updates["status"] = models.ApprovalStatusApproved
+			updates["current_step"] = 0
+			updates["completed_at"] = &now
+			finalize = s.finalizers[request.ResourceType]
+		}
+	case models.ApprovalActionReject:
+		updates["status"] = models.ApprovalStatusRejected
+		updates["current_step"] = 0
+		updates["completed_at"] = &now
+	case models.ApprovalActionReturn:
+		updates["status"] = models.ApprovalStatusReturned
+		updates["current_step"] = 0
+	}
+
+	action := models.ApprovalAction{
+		StepOrder:       request.CurrentStep,
+		ApprovalStateID: &state.ID,
+		ActorID:         approverID,
+		Action:          actionType,
+		Comment:         comment,
+	}
+	if err := s.applyTransition(request, updates, &action, finalize); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Approval action recorded successfully", map[string]interface{}{
+		"request_id":  requestID,
+		"approver_id": approverID,
+		"action":      actionType,
+		"step_order":  request.CurrentStep,
+	})
+
+	return s.getApprovalRequestDetail(request.ID)
+}
+
+// applyTransition 申請の状態を更新し操作履歴を記録（finalizeが指定された場合は同一トランザクションで実行）
+// 読み込み後に他の操作で状態が変わっていた場合は競合エラー
+func (s *ApprovalService) applyTransition(request *models.ApprovalRequest, updates map[string]interface{}, action *models.ApprovalAction, finalize ApprovalFinalizer) error {
+	action.RequestID = request.ID
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ApprovalRequest{}).
+			Where("id = ? AND status = ? AND current_step = ?", request.ID, request.Status, request.CurrentStep).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.NewBusinessError(errors.ErrCodeConflict, "Approval request was modified concurrently", "The request has already been processed by another action")
+		}
+		if err := tx.Omit("Actor").Create(action).Error; err != nil {
+			return err
+		}
+		if finalize != nil {
+			return finalize(tx, request)
+		}
+		return nil
+	})
+	if err != nil {
+		if apiErr, ok := err.(*errors.APIError); ok {
+			return apiErr
+		}
+		s.logger.Error("Failed to update approval request", err, map[string]interface{}{
+			"request_id": request.ID,
+			"action":     action.Action,
+		})
+		return errors.NewDatabaseError(err)
+	}
+	return nil
+}
+
+// =============================================================================
+// 参照
+// =============================================================================
+
+// GetApprovalRequest 承認申請の詳細を取得
+// 申請者・操作済みの承認者・フロー上の承認ロールを持つユーザーのみ参照可能
+func (s *ApprovalService) GetApprovalRequest(requestID, viewerID uuid.UUID) (*ApprovalRequestResponse, error) {
+	response, err := s.getApprovalRequestDetail(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if response.RequestedBy == viewerID {
+		return response, nil
+	}
+	for _, action := range response.Actions {
+		if action.ActorID == viewerID {
+			return response, nil
+		}
+	}
+
+	roleIDs, err := s.activeRoleIDs(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range response.Steps {
+		for _, approver := range step.Approvers {
+			if roleIDs[approver.ApproverRoleID] {
+				return response, nil
+			}
+		}
+	}
+
+	return nil, errors.NewAuthorizationError("You are not allowed to view this approval request")
+}
+
+// GetMyApprovalRequests 自分が申請した承認申請の一覧を取得
+func (s *ApprovalService) GetMyApprovalRequests(userID uuid.UUID, status string) (*ApprovalRequestListResponse, error) {
+	query := s.db.Where("requested_by = ?", userID)
+	if status != "" {
+		if !models.ApprovalRequestStatus(status).IsValid() {
+			return nil, errors.NewValidationError("status", "Invalid approval request status")
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	var requests []models.ApprovalRequest
+	if err := query.Order("created_at DESC").Find(&requests).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return convertToApprovalRequestListResponse(requests), nil
+}
+
+// GetPendingApprovals 自分のアクティブなロールで承認可能な承認待ち申請を取得
+func (s *ApprovalService) GetPendingApprovals(userID uuid.UUID) (*ApprovalRequestListResponse, error) {
+	roleIDs, err := s.activeRoleIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(roleIDs) == 0 {
+		return convertToApprovalRequestListResponse(nil), nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(roleIDs))
+	for roleID := range roleIDs {
+		ids = append(ids, roleID)
+	}
+	var stepOrders []int
+	if err := s.db.Model(&models.ApprovalState{}).Where("approver_role_id IN ?", ids).
+		Distinct().Pluck("step_order", &stepOrders).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	if len(stepOrders) == 0 {
+		return convertToApprovalRequestListResponse(nil), nil
+	}
+
+	candidates, err := models.FindPendingApprovalRequestsByStep(s.db, stepOrders)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	// 承認フローはリソース種別ごとに読み込み、スコープ条件はGo側で照合
+	statesByResource := make(map[string][]models.ApprovalState)
+	var pending []models.ApprovalRequest
+	for _, request := range candidates {
+		if request.RequestedBy == userID {
+			continue
+		}
+		states, loaded := statesByResource[request.ResourceType]
+		if !loaded {
+			if states, err = models.GetApprovalFlow(s.db, request.ResourceType, nil); err != nil {
+				return nil, errors.NewDatabaseError(err)
+			}
+			statesByResource[request.ResourceType] = states
+		}
+		flow := matchApprovalFlow(states, request.Scope)
+		if findEligibleApprovalState(flow, request.CurrentStep, roleIDs) != nil && !hasApprovedOtherStep(&request, userID) {
+			pending = append(pending, request)
+		}
+	}
+
+	return convertToApprovalRequestListResponse(pending), nil
+}
+
+// getApprovalRequestDetail 承認ステップと操作履歴を含む申請詳細を取得
+func (s *ApprovalService) getApprovalRequestDetail(requestID uuid.UUID) (*ApprovalRequestResponse, error) {
+	request, err := s.findApprovalRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	flow, err := s.resolveApprovalFlow(request.ResourceType, request.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	response := convertToApprovalRequestResponse(request)
+	response.Steps = buildApprovalSteps(flow)
+	applyApprovalStepStatus(response.Steps, request)
+	response.Actions = make([]ApprovalActionResponse, len(request.Actions))
+	for i, action := range request.Actions {
+		response.Actions[i] = ApprovalActionResponse{
+			ID:              action.ID,
+			StepOrder:       action.StepOrder,
+			ApprovalStateID: action.ApprovalStateID,
+			ActorID:         action.ActorID,
+			Action:          action.Action,
+			Comment:         action.Comment,
+			CreatedAt:       action.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		}
+	}
+	return response, nil
+}
+
+// =============================================================================
+// 承認フロー解決
+// =============================================================================
+
+// resolveApprovalFlow リソース種別とスコープ条件に該当する承認ステップを取得（step_order昇順）
+func (s *ApprovalService) resolveApprovalFlow(resourceType string, scope models.JSONB) ([]models.ApprovalState, error) {
+	return resolveApprovalFlow(s.db, resourceType, scope)
+}
+
+// resolveApprovalFlow リソース種別とスコープ条件に該当する承認ステップを取得（step_order昇順）
+// スコープ条件の照合はDBに依存しないようGo側で行う
+func resolveApprovalFlow(db *gorm.DB, resourceType string, scope models.JSONB) ([]models.ApprovalState, error) {
+	states, err := models.GetApprovalFlow(db, resourceType, nil)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	return matchApprovalFlow(states, scope), nil
+}
+
+// matchApprovalFlow スコープ条件を満たす承認状態のみに絞り込み
+// approval_states.scopeのキーがすべて申請のスコープ条件と一致するステップが対象
+func matchApprovalFlow(states []models.ApprovalState, scope models.JSONB) []models.ApprovalState {
+	matched := make([]models.ApprovalState, 0, len(states))
+	for _, state := range states {
+		if state.MatchesScope(scope) {
+			matched = append(matched, state)
+		}
+	}
+	return matched
+}
+
+// approvalStepOrders 承認フローのstep_order一覧（重複なし・昇順）
+func approvalStepOrders(flow []models.ApprovalState) []int {
+	seen := make(map[int]bool, len(flow))
+	var steps []int
+	for _, state := range flow {
+		if !seen[state.StepOrder] {
+			seen[state.StepOrder] = true
+			steps = append(steps, state.StepOrder)
+		}
+	}
+	sort.Ints(steps)
+	return steps
+}
+
+// nextApprovalStepOrder 現在のステップの次のstep_order（最終ステップの場合は0）
+func nextApprovalStepOrder(flow []models.ApprovalState, current int) int {
+	for _, step := range approvalStepOrders(flow) {
+		if step > current {
+			return step
+		}
+	}
+	return 0
+}
+
+// findEligibleApprovalState 指定ステップでユーザーのロールが承認者となる承認状態を取得
+func findEligibleApprovalState(flow []models.ApprovalState, stepOrder int, roleIDs map[uuid.UUID]bool) *models.ApprovalState {
+	for i := range flow {
+		if flow[i].StepOrder == stepOrder && roleIDs[flow[i].ApproverRoleID] {
+			return &flow[i]
+		}
+	}
+	return nil
+}
+
+// hasApprovedOtherStep 直近の申請・再申請以降に、ユーザーが現在以外のステップを承認済みか
+func hasApprovedOtherStep(request *models.ApprovalRequest, userID uuid.UUID) bool {
+	approved := false
+	for _, action := range request.Actions {
+		switch action.Action {
+		case models.ApprovalActionSubmit, models.ApprovalActionResubmit:
+			approved = false
+		case models.ApprovalActionApprove:
+			if action.ActorID == userID && action.StepOrder != request.CurrentStep {
+				approved = true
+			}
+		}
+	}
+	return approved
+}
+
+// buildApprovalSteps 承認状態をstep_order単位にまとめる
+func buildApprovalSteps(flow []models.ApprovalState) []ApprovalStepResponse {
+	steps := make([]ApprovalStepResponse, 0)
+	for _, state := range flow {
+		if len(steps) == 0 || steps[len(steps)-1].StepOrder != state.StepOrder {
+			steps = append(steps, ApprovalStepResponse{
+				StepOrder: state.StepOrder,
+				Approvers: make([]ApprovalStepApprover, 0, 1),
+			})
+		}
+		step := &steps[len(steps)-1]
+		step.Approvers = append(step.Approvers, ApprovalStepApprover{
+			ApprovalStateID:  state.ID,
+			StateName:        state.StateName,
+			ApproverRoleID:   state.ApproverRoleID,
+			ApproverRoleName: state.ApproverRole.Name,
+		})
+	}
+	return steps
+}
+
+// applyApprovalStepStatus 直近の申請・再申請以降の操作履歴から各ステップの進捗状態を設定
+func applyApprovalStepStatus(steps []ApprovalStepResponse, request *models.ApprovalRequest) {
+	latest := make(map[int]models.ApprovalActionType)
+	for _, action := range request.Actions {
+		switch action.Action {
+		case models.ApprovalActionSubmit, models.ApprovalActionResubmit:
+			latest = make(map[int]models.ApprovalActionType)
+		case models.ApprovalActionApprove, models.ApprovalActionReject, models.ApprovalActionReturn:
+			latest[action.StepOrder] = action.Action
+		}
+	}
+
+	for i := range steps {
+		switch {
+		case latest[steps[i].StepOrder] == models.ApprovalActionApprove:
+			steps[i].Status = ApprovalStepApproved
+		case latest[steps[i].StepOrder] == models.ApprovalActionReject:
+			steps[i].Status = ApprovalStepRejected
+		case latest[steps[i].StepOrder] == models.ApprovalActionReturn:
+			steps[i].Status = ApprovalStepReturned
+		case request.IsPending() && steps[i].StepOrder == request.CurrentStep:
+			steps[i].Status = ApprovalStepCurrent
+		default:
+			steps[i].Status = ApprovalStepWaiting
+		}
+	}
+}
+
+// activeRoleIDs ユーザーのアクティブなロールID（メインロールを含む）
+func (s *ApprovalService) activeRoleIDs(userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	userRoles, err := models.FindActiveUserRolesByUserID(s.db, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	roleIDs := make(map[uuid.UUID]bool, len(userRoles)+1)
+	for _, userRole := range userRoles {
+		roleIDs[userRole.RoleID] = true
+	}
+
+	var user models.User
+	err = s.db.Select("id", "primary_role_id").Where("id = ?", userID).First(&user).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewDatabaseError(err)
+	}
+	if user.PrimaryRoleID != nil {
+		roleIDs[*user.PrimaryRoleID] = true
+	}
+	return roleIDs, nil
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// findApprovalRequest 承認申請を操作履歴付きで取得
+func (s *ApprovalService) findApprovalRequest(requestID uuid.UUID) (*models.ApprovalRequest, error) {
+	request, err := models.FindApprovalRequestByID(s.db, requestID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("ApprovalRequest", "Approval request not found")
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+	return request, nil
+}
+
+// errNoApprovalFlow 承認フロー未定義エラー
+func errNoApprovalFlow(resourceType string) error {
+	return errors.NewBusinessError(errors.ErrCodeBusinessRule, "Approval flow is not defined",
+		fmt.Sprintf("No approval steps are defined for resource type %s", resourceType))
+}
+
+// errApprovalStatus 申請ステータスが操作に適さない場合のエラー
+func errApprovalStatus(request *models.ApprovalRequest, reason string) error {
+	return errors.NewBusinessError(errors.ErrCodeBusinessRule,
+		fmt.Sprintf("Approval request is %s", request.Status), reason)
+}
+
+// convertToApprovalRequestResponse 承認申請をレスポンス形式に変換
+func convertToApprovalRequestResponse(request *models.ApprovalRequest) *ApprovalRequestResponse {
+	response := &ApprovalRequestResponse{
+		ID:           request.ID,
+		ResourceType: request.ResourceType,
+		ResourceID:   request.ResourceID,
+		Title:        request.Title,
+		Payload:      request.Payload,
+		Scope:        request.Scope,
+		RequestedBy:  request.RequestedBy,
+		Status:       request.Status,
+		CurrentStep:  request.CurrentStep,
+		CreatedAt:    request.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:    request.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if request.CompletedAt != nil {
+		completedAt := request.CompletedAt.Format("2006-01-02T15:04:05Z07:00")
+		response.CompletedAt = &completedAt
+	}
+	return response
+}
+
+// convertToApprovalRequestListResponse 承認申請一覧をレスポンス形式に変換
+func convertToApprovalRequestListResponse(requests []models.ApprovalRequest) *ApprovalRequestListResponse {
+	response := &ApprovalRequestListResponse{
+		Requests: make([]ApprovalRequestResponse, len(requests)),
+		Total:    len(requests),
+	}
+	for i := range requests {
+		response.Requests[i] = *convertToApprovalRequestResponse(&requests[i])
+	}
+	return response
+}
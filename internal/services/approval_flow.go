@@ -0,0 +1,485 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// ApprovalFlowService 承認フロー定義管理サービス
+type ApprovalFlowService struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewApprovalFlowService 新しい承認フロー定義サービスを作成
+func NewApprovalFlowService(db *gorm.DB, logger *logger.Logger) *ApprovalFlowService {
+	return &ApprovalFlowService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ApprovalFlowStepRequest 承認ステップ定義
+type ApprovalFlowStepRequest struct {
+	StateName      string                 `json:"state_name" binding:"required,max=100"`
+	ApproverRoleID uuid.UUID              `json:"approver_role_id" binding:"required"`
+	StepOrder      int                    `json:"step_order" binding:"required,min=1"`
+	Scope          map[string]interface{} `json:"scope"` // 指定した場合は申請のスコープ条件が一致するときのみ適用
+}
+
+// DefineApprovalFlowRequest リソース種別の承認フロー定義リクエスト（既存ステップを置き換え）
+type DefineApprovalFlowRequest struct {
+	Steps []ApprovalFlowStepRequest `json:"steps" binding:"required,min=1,dive"`
+}
+
+// UpdateApprovalFlowStepRequest 承認ステップ更新リクエスト
+// step_orderの変更はフロー全体の置き換えで行う
+type UpdateApprovalFlowStepRequest struct {
+	StateName      *string                 `json:"state_name" binding:"omitempty,max=100"`
+	ApproverRoleID *uuid.UUID              `json:"approver_role_id"`
+	Scope          *map[string]interface{} `json:"scope"` // 空オブジェクトでスコープ条件を解除
+}
+
+// ApprovalFlowSimulationRequest 承認フローのシミュレーションリクエスト
+type ApprovalFlowSimulationRequest struct {
+	ResourceType string                 `json:"resource_type" binding:"required"`
+	Scope        map[string]interface{} `json:"scope"`
+}
+
+// ApprovalFlowStepResponse 承認ステップ定義レスポンス
+type ApprovalFlowStepResponse struct {
+	ID               int                    `json:"id"`
+	StateName        string                 `json:"state_name"`
+	ApproverRoleID   uuid.UUID              `json:"approver_role_id"`
+	ApproverRoleName string                 `json:"approver_role_name"`
+	StepOrder        int                    `json:"step_order"`
+	ResourceType     *string                `json:"resource_type,omitempty"`
+	Scope            map[string]interface{} `json:"scope,omitempty"`
+	CreatedAt        string                 `json:"created_at"`
+}
+
+// ApprovalFlowResponse リソース種別の承認フロー定義レスポンス
+type ApprovalFlowResponse struct {
+	ResourceType string                     `json:"resource_type"`
+	Steps        []ApprovalFlowStepResponse `json:"steps"`
+	Total        int                        `json:"total"`
+}
+
+// EligibleApprover 承認可能なユーザー
+type EligibleApprover struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+}
+
+// ApprovalSimulationApprover シミュレーション結果の承認者定義
+type ApprovalSimulationApprover struct {
+	ApprovalStepApprover
+	Scope         map[string]interface{} `json:"scope,omitempty"`
+	EligibleUsers []EligibleApprover     `json:"eligible_users"`
+}
+
+// ApprovalSimulationStep シミュレーション結果の承認ステップ
+type ApprovalSimulationStep struct {
+	StepOrder int                          `json:"step_order"`
+	Approvers []ApprovalSimulationApprover `json:"approvers"`
+}
+
+// ApprovalFlowSimulationResponse 承認フローのシミュレーション結果
+type ApprovalFlowSimulationResponse struct {
+	ResourceType     string                   `json:"resource_type"`
+	Scope            map[string]interface{}   `json:"scope,omitempty"`
+	ApprovalRequired bool                     `json:"approval_required"`
+	Steps            []ApprovalSimulationStep `json:"steps"`
+}
+
+// GetApprovalFlows 承認フロー定義の統計（リソース種別別・ステップ別）を取得
+func (s *ApprovalFlowService) GetApprovalFlows() (map[string]interface{}, error) {
+	stats, err := models.GetApprovalStats(s.db)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	return stats, nil
+}
+
+// GetApprovalFlow リソース種別の承認フロー定義を取得
+func (s *ApprovalFlowService) GetApprovalFlow(resourceType string) (*ApprovalFlowResponse, error) {
+	if err := validateApprovalResourceType(resourceType); err != nil {
+		return nil, err
+	}
+
+	states, err := models.FindApprovalStatesByResourceType(s.db, resourceType)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return convertToApprovalFlowResponse(resourceType, states), nil
+}
+
+// DefineApprovalFlow リソース種別の承認フローを定義（既存ステップを置き換え）
+// step_orderは1からの連番（スコープ条件が異なる場合のみ重複可）、承認ロールは存在するロールのみ指定可能
+func (s *ApprovalFlowService) DefineApprovalFlow(resourceType string, req DefineApprovalFlowRequest) (*ApprovalFlowResponse, error) {
+	s.logger.Info("Defining approval flow", map[string]interface{}{
+		"resource_type": resourceType,
+		"step_count":    len(req.Steps),
+	})
+
+	if err := validateApprovalResourceType(resourceType); err != nil {
+		return nil, err
+	}
+	if err := validateApprovalStepOrders(req.Steps); err != nil {
+		return nil, err
+	}
+	roleIDs := make([]uuid.UUID, len(req.Steps))
+	for i, step := range req.Steps {
+		roleIDs[i] = step.ApproverRoleID
+	}
+	if err := s.ensureRolesExist(roleIDs, func(i int) string { return fmt.Sprintf("steps[%d].approver_role_id", i) }); err != nil {
+		return nil, err
+	}
+	if err := s.ensureNoPendingRequests(resourceType); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("resource_type = ?", resourceType).Delete(&models.ApprovalState{}).Error; err != nil {
+			return err
+		}
+		for _, step := range req.Steps {
+			var scope models.JSONB
+			if len(step.Scope) > 0 {
+				scope = models.JSONB(step.Scope)
+			}
+			if _, err := models.CreateApprovalState(tx, step.StateName, step.ApproverRoleID, step.StepOrder, &resourceType, scope); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to define approval flow", err, map[string]interface{}{
+			"resource_type": resourceType,
+		})
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("Approval flow defined successfully", map[string]interface{}{
+		"resource_type": resourceType,
+		"step_count":    len(req.Steps),
+	})
+
+	return s.GetApprovalFlow(resourceType)
+}
+
+// UpdateApprovalFlowStep 承認ステップの名称・承認ロール・スコープ条件を更新
+func (s *ApprovalFlowService) UpdateApprovalFlowStep(resourceType string, stateID int, req UpdateApprovalFlowStepRequest) (*ApprovalFlowStepResponse, error) {
+	if err := validateApprovalResourceType(resourceType); err != nil {
+		return nil, err
+	}
+
+	state, err := models.FindApprovalStateByID(s.db, stateID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("ApprovalState", "Approval step not found")
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+	if state.ResourceType == nil || *state.ResourceType != resourceType {
+		return nil, errors.NewNotFoundError("ApprovalState", "Approval step not found")
+	}
+
+	updates := make(map[string]interface{})
+	if req.StateName != nil {
+		updates["state_name"] = *req.StateName
+	}
+	if req.ApproverRoleID != nil {
+		if err := s.ensureRolesExist([]uuid.UUID{*req.ApproverRoleID}, func(int) string { return "approver_role_id" }); err != nil {
+			return nil, err
+		}
+		updates["approver_role_id"] = *req.ApproverRoleID
+	}
+	if req.Scope != nil {
+		if len(*req.Scope) > 0 {
+			updates["scope"] = models.JSONB(*req.Scope)
+		} else {
+			updates["scope"] = nil
+		}
+	}
+
+	if len(updates) > 0 {
+		// BeforeUpdateでstep_orderを検証するため読み込んだレコードを対象に更新
+		if err := s.db.Model(state).Updates(updates).Error; err != nil {
+			s.logger.Error("Failed to update approval step", err, map[string]interface{}{
+				"approval_state_id": stateID,
+			})
+			return nil, errors.NewDatabaseError(err)
+		}
+	}
+
+	updated, err := models.FindApprovalStateByID(s.db, stateID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	response := convertToApprovalFlowStepResponse(updated)
+	return &response, nil
+}
+
+// DeleteApprovalFlow リソース種別の承認フローを削除
+func (s *ApprovalFlowService) DeleteApprovalFlow(resourceType string) (int64, error) {
+	if err := validateApprovalResourceType(resourceType); err != nil {
+		return 0, err
+	}
+	if err := s.ensureNoPendingRequests(resourceType); err != nil {
+		return 0, err
+	}
+
+	result := s.db.Where("resource_type = ?", resourceType).Delete(&models.ApprovalState{})
+	if result.Error != nil {
+		return 0, errors.NewDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, errors.NewNotFoundError("ApprovalFlow", "Approval flow is not defined")
+	}
+
+	s.logger.Info("Approval flow deleted successfully", map[string]interface{}{
+		"resource_type": resourceType,
+		"deleted":       result.RowsAffected,
+	})
+
+	return result.RowsAffected, nil
+}
+
+// SimulateApprovalFlow リソース種別とスコープ条件から承認ルートと承認可能なユーザーを算出
+func (s *ApprovalFlowService) SimulateApprovalFlow(req ApprovalFlowSimulationRequest) (*ApprovalFlowSimulationResponse, error) {
+	if err := validateApprovalResourceType(req.ResourceType); err != nil {
+		return nil, err
+	}
+
+	flow, err := resolveApprovalFlow(s.db, req.ResourceType, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]uuid.UUID, 0, len(flow))
+	for _, state := range flow {
+		roleIDs = append(roleIDs, state.ApproverRoleID)
+	}
+	approversByRole, err := s.findEligibleApprovers(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ApprovalFlowSimulationResponse{
+		ResourceType:     req.ResourceType,
+		Scope:            req.Scope,
+		ApprovalRequired: len(flow) > 0,
+		Steps:            make([]ApprovalSimulationStep, 0),
+	}
+	for _, state := range flow {
+		if len(response.Steps) == 0 || response.Steps[len(response.Steps)-1].StepOrder != state.StepOrder {
+			response.Steps = append(response.Steps, ApprovalSimulationStep{StepOrder: state.StepOrder})
+		}
+		eligible := approversByRole[state.ApproverRoleID]
+		if eligible == nil {
+			eligible = []EligibleApprover{}
+		}
+		step := &response.Steps[len(response.Steps)-1]
+		step.Approvers = append(step.Approvers, ApprovalSimulationApprover{
+			ApprovalStepApprover: ApprovalStepApprover{
+				ApprovalStateID:  state.ID,
+				StateName:        state.StateName,
+				ApproverRoleID:   state.ApproverRoleID,
+				ApproverRoleName: state.ApproverRole.Name,
+			},
+			Scope:         state.Scope,
+			EligibleUsers: eligible,
+		})
+	}
+
+	return response, nil
+}
+
+// findEligibleApprovers ロールごとの承認可能なアクティブユーザー（ユーザーロール・メインロール）を取得
+func (s *ApprovalFlowService) findEligibleApprovers(roleIDs []uuid.UUID) (map[uuid.UUID][]EligibleApprover, error) {
+	result := make(map[uuid.UUID][]EligibleApprover)
+	if len(roleIDs) == 0 {
+		return result, nil
+	}
+
+	now := time.Now()
+	var userRoles []models.UserRole
+	err := s.db.Select("user_id", "role_id").
+		Where("role_id IN ? AND is_active = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)",
+			roleIDs, true, now, now).
+		Find(&userRoles).Error
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(userRoles))
+	for _, userRole := range userRoles {
+		userIDs = append(userIDs, userRole.UserID)
+	}
+	query := s.db.Select("id", "name", "email", "primary_role_id").
+		Where("status = ?", models.UserStatusActive)
+	if len(userIDs) > 0 {
+		query = query.Where("primary_role_id IN ? OR id IN ?", roleIDs, userIDs)
+	} else {
+		query = query.Where("primary_role_id IN ?", roleIDs)
+	}
+	var users []models.User
+	if err := query.Order("name ASC").Find(&users).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	rolesByUser := make(map[uuid.UUID]map[uuid.UUID]bool, len(users))
+	for _, userRole := range userRoles {
+		if rolesByUser[userRole.UserID] == nil {
+			rolesByUser[userRole.UserID] = make(map[uuid.UUID]bool)
+		}
+		rolesByUser[userRole.UserID][userRole.RoleID] = true
+	}
+	for _, user := range users {
+		if user.PrimaryRoleID != nil {
+			if rolesByUser[user.ID] == nil {
+				rolesByUser[user.ID] = make(map[uuid.UUID]bool)
+			}
+			rolesByUser[user.ID][*user.PrimaryRoleID] = true
+		}
+		for roleID := range rolesByUser[user.ID] {
+			result[roleID] = append(result[roleID], EligibleApprover{ID: user.ID, Name: user.Name, Email: user.Email})
+		}
+	}
+	return result, nil
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// validateApprovalResourceType 承認フローのリソース種別を検証
+func validateApprovalResourceType(resourceType string) error {
+	state := models.ApprovalState{ResourceType: &resourceType}
+	if resourceType == "" || !state.IsValidResourceType() {
+		return errors.NewValidationError("resource_type", "Invalid resource type")
+	}
+	return nil
+}
+
+// validateApprovalStepOrders step_orderが1からの連番で、同じスコープ条件の重複がないか検証
+// スコープ条件が異なるステップは同じstep_orderに定義できる（申請のスコープに一致するステップを適用）
+func validateApprovalStepOrders(steps []ApprovalFlowStepRequest) error {
+	seen := make(map[string]int, len(steps))
+	orders := make([]int, 0, len(steps))
+	for i, step := range steps {
+		if step.StepOrder < 1 {
+			return errors.NewValidationError(fmt.Sprintf("steps[%d].step_order", i), "step_order must be 1 or greater")
+		}
+		key, err := approvalStepScopeKey(step)
+		if err != nil {
+			return errors.NewValidationError(fmt.Sprintf("steps[%d].scope", i), "Invalid scope")
+		}
+		if first, exists := seen[key]; exists {
+			return errors.NewValidationError(fmt.Sprintf("steps[%d].step_order", i),
+				fmt.Sprintf("Duplicate step_order %d with the same scope (also used by steps[%d])", step.StepOrder, first))
+		}
+		seen[key] = i
+		orders = append(orders, step.StepOrder)
+	}
+
+	sort.Ints(orders)
+	next := 1
+	for _, order := range orders {
+		if order == next-1 {
+			continue
+		}
+		if order != next {
+			return errors.NewValidationError("steps", fmt.Sprintf("step_order must be sequential starting at 1 (missing %d)", next))
+		}
+		next++
+	}
+	return nil
+}
+
+// approvalStepScopeKey step_orderとスコープ条件の組み合わせを表すキー（スコープ条件なしは空として扱う）
+func approvalStepScopeKey(step ApprovalFlowStepRequest) (string, error) {
+	if len(step.Scope) == 0 {
+		return fmt.Sprintf("%d:", step.StepOrder), nil
+	}
+	// mapのキーは整列されるため、同じ条件は同じ文字列になる
+	encoded, err := json.Marshal(step.Scope)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d:%s", step.StepOrder, encoded), nil
+}
+
+// ensureRolesExist 承認ロールの存在を確認
+func (s *ApprovalFlowService) ensureRolesExist(roleIDs []uuid.UUID, field func(i int) string) error {
+	var existing []uuid.UUID
+	if err := s.db.Model(&models.Role{}).Where("id IN ?", roleIDs).Pluck("id", &existing).Error; err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	found := make(map[uuid.UUID]bool, len(existing))
+	for _, id := range existing {
+		found[id] = true
+	}
+	for i, roleID := range roleIDs {
+		if !found[roleID] {
+			return errors.NewValidationError(field(i), "Role does not exist")
+		}
+	}
+	return nil
+}
+
+// ensureNoPendingRequests 承認待ちの申請がある場合はフローの変更を拒否
+func (s *ApprovalFlowService) ensureNoPendingRequests(resourceType string) error {
+	var count int64
+	err := s.db.Model(&models.ApprovalRequest{}).
+		Where("resource_type = ? AND status = ?", resourceType, models.ApprovalStatusPending).
+		Count(&count).Error
+	if err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	if count > 0 {
+		return errors.NewBusinessError(errors.ErrCodeBusinessRule, "Approval flow is in use",
+			fmt.Sprintf("%d pending approval requests exist for resource type %s", count, resourceType))
+	}
+	return nil
+}
+
+// convertToApprovalFlowStepResponse 承認ステップをレスポンス形式に変換
+func convertToApprovalFlowStepResponse(state *models.ApprovalState) ApprovalFlowStepResponse {
+	return ApprovalFlowStepResponse{
+		ID:               state.ID,
+		StateName:        state.StateName,
+		ApproverRoleID:   state.ApproverRoleID,
+		ApproverRoleName: state.ApproverRole.Name,
+		StepOrder:        state.StepOrder,
+		ResourceType:     state.ResourceType,
+		Scope:            state.Scope,
+		CreatedAt:        state.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// convertToApprovalFlowResponse 承認フロー定義をレスポンス形式に変換
+func convertToApprovalFlowResponse(resourceType string, states []models.ApprovalState) *ApprovalFlowResponse {
+	response := &ApprovalFlowResponse{
+		ResourceType: resourceType,
+		Steps:        make([]ApprovalFlowStepResponse, len(states)),
+		Total:        len(states),
+	}
+	for i := range states {
+		response.Steps[i] = convertToApprovalFlowStepResponse(&states[i])
+	}
+	return response
+}
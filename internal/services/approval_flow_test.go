@@ -0,0 +1,165 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+func TestApprovalFlowService_DefineApprovalFlow(t *testing.T) {
+	approvalService, db := setupTestApproval(t)
+	service := NewApprovalFlowService(db, logger.NewLogger())
+
+	managerRole := createApprovalTestRole(t, db, "部門管理者")
+	financeRole := createApprovalTestRole(t, db, "経理担当")
+
+	t.Run("入力検証", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			resourceType string
+			steps        []ApprovalFlowStepRequest
+			wantField    string
+		}{
+			{"未定義のリソース種別", "unknown", []ApprovalFlowStepRequest{{StateName: "承認", ApproverRoleID: managerRole, StepOrder: 1}}, "resource_type"},
+			{"同じスコープ条件でstep_orderの重複", "expenses", []ApprovalFlowStepRequest{
+				{StateName: "部門長承認", ApproverRoleID: managerRole, StepOrder: 1},
+				{StateName: "経理承認", ApproverRoleID: financeRole, StepOrder: 2, Scope: map[string]interface{}{"amount_class": "large"}},
+				{StateName: "経理部長承認", ApproverRoleID: managerRole, StepOrder: 2, Scope: map[string]interface{}{"amount_class": "large"}},
+			}, "steps[2].step_order"},
+			{"step_orderの欠番", "expenses", []ApprovalFlowStepRequest{
+				{StateName: "部門長承認", ApproverRoleID: managerRole, StepOrder: 1},
+				{StateName: "経理承認", ApproverRoleID: financeRole, StepOrder: 3},
+			}, "steps"},
+			{"存在しない承認ロール", "expenses", []ApprovalFlowStepRequest{
+				{StateName: "部門長承認", ApproverRoleID: managerRole, StepOrder: 1},
+				{StateName: "経理承認", ApproverRoleID: uuid.New(), StepOrder: 2},
+			}, "steps[1].approver_role_id"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := service.DefineApprovalFlow(tt.resourceType, DefineApprovalFlowRequest{Steps: tt.steps})
+				require.True(t, errors.IsValidationError(err))
+				require.NotNil(t, err.(*errors.APIError).Details)
+				assert.Equal(t, tt.wantField, err.(*errors.APIError).Details.Field)
+			})
+		}
+	})
+
+	t.Run("スコープ条件が異なる場合は同じstep_orderを許可", func(t *testing.T) {
+		flow, err := service.DefineApprovalFlow("orders", DefineApprovalFlowRequest{Steps: []ApprovalFlowStepRequest{
+			{StateName: "部門長承認", ApproverRoleID: managerRole, StepOrder: 1},
+			{StateName: "高額経理承認", ApproverRoleID: financeRole, StepOrder: 2, Scope: map[string]interface{}{"amount_class": "large"}},
+			{StateName: "少額経理承認", ApproverRoleID: managerRole, StepOrder: 2, Scope: map[string]interface{}{"amount_class": "small"}},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, 3, flow.Total)
+	})
+
+	createApprovalTestState(t, db, "旧ステップ", managerRole, 1, "expenses", "")
+
+	flow, err := service.DefineApprovalFlow("expenses", DefineApprovalFlowRequest{Steps: []ApprovalFlowStepRequest{
+		{StateName: "経理承認", ApproverRoleID: financeRole, StepOrder: 2},
+		{StateName: "部門長承認", ApproverRoleID: managerRole, StepOrder: 1},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, 2, flow.Total, "既存ステップは置き換え")
+	assert.Equal(t, "部門長承認", flow.Steps[0].StateName)
+	assert.Equal(t, "経理担当", flow.Steps[1].ApproverRoleName)
+
+	t.Run("ステップの更新", func(t *testing.T) {
+		name := "経理部長承認"
+		scope := map[string]interface{}{"amount_class": "large"}
+		step, err := service.UpdateApprovalFlowStep("expenses", flow.Steps[1].ID, UpdateApprovalFlowStepRequest{StateName: &name, Scope: &scope})
+		require.NoError(t, err)
+		assert.Equal(t, name, step.StateName)
+		assert.Equal(t, 2, step.StepOrder)
+		assert.Equal(t, "large", step.Scope["amount_class"])
+
+		missingRole := uuid.New()
+		_, err = service.UpdateApprovalFlowStep("expenses", flow.Steps[1].ID, UpdateApprovalFlowStepRequest{ApproverRoleID: &missingRole})
+		assert.True(t, errors.IsValidationError(err))
+
+		_, err = service.UpdateApprovalFlowStep("orders", flow.Steps[1].ID, UpdateApprovalFlowStepRequest{StateName: &name})
+		assert.True(t, errors.IsNotFound(err), "他のリソース種別のステップは対象外")
+	})
+
+	t.Run("承認待ちの申請がある場合は変更不可", func(t *testing.T) {
+		requester := createUserForScopeTest(t, db, "申請者")
+		submitted, err := approvalService.SubmitApprovalRequest(requester, SubmitApprovalRequest{ResourceType: "expenses", Title: "交通費精算"})
+		require.NoError(t, err)
+
+		_, err = service.DefineApprovalFlow("expenses", DefineApprovalFlowRequest{Steps: []ApprovalFlowStepRequest{
+			{StateName: "部門長承認", ApproverRoleID: managerRole, StepOrder: 1},
+		}})
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrCodeBusinessRule, err.(*errors.APIError).Code)
+
+		_, err = service.DeleteApprovalFlow("expenses")
+		require.Error(t, err)
+
+		_, err = approvalService.CancelApprovalRequest(submitted.ID, requester, "")
+		require.NoError(t, err)
+
+		deleted, err := service.DeleteApprovalFlow("expenses")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+
+		_, err = service.DeleteApprovalFlow("expenses")
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
+
+func TestApprovalFlowService_SimulateApprovalFlow(t *testing.T) {
+	_, db := setupTestApproval(t)
+	service := NewApprovalFlowService(db, logger.NewLogger())
+
+	managerRole := createApprovalTestRole(t, db, "部門管理者")
+	deputyRole := createApprovalTestRole(t, db, "部門管理者代理")
+	financeRole := createApprovalTestRole(t, db, "経理担当")
+	createApprovalTestState(t, db, "部門長承認", managerRole, 1, "purchases", "")
+	createApprovalTestState(t, db, "代理承認", deputyRole, 1, "purchases", "")
+	createApprovalTestState(t, db, "高額経理承認", financeRole, 2, "purchases", `{"amount_class":"large"}`)
+
+	manager := createUserForScopeTest(t, db, "部門長")
+	assignApprovalTestRole(t, db, manager, managerRole)
+	deputy := createUserForScopeTest(t, db, "代理")
+	require.NoError(t, db.Exec("UPDATE users SET primary_role_id = ? WHERE id = ?", deputyRole.String(), deputy.String()).Error)
+	inactive := createUserForScopeTest(t, db, "休職者")
+	assignApprovalTestRole(t, db, inactive, managerRole)
+	require.NoError(t, db.Exec("UPDATE users SET status = ? WHERE id = ?", models.UserStatusInactive, inactive.String()).Error)
+
+	result, err := service.SimulateApprovalFlow(ApprovalFlowSimulationRequest{ResourceType: "purchases"})
+	require.NoError(t, err)
+	assert.True(t, result.ApprovalRequired)
+	require.Len(t, result.Steps, 1, "スコープ条件付きのステップは対象外")
+	require.Len(t, result.Steps[0].Approvers, 2, "同じstep_orderのステップはいずれかが承認")
+
+	eligible := map[string][]uuid.UUID{}
+	for _, approver := range result.Steps[0].Approvers {
+		for _, user := range approver.EligibleUsers {
+			eligible[approver.ApproverRoleName] = append(eligible[approver.ApproverRoleName], user.ID)
+		}
+	}
+	assert.Equal(t, []uuid.UUID{manager}, eligible["部門管理者"], "無効なユーザーは除外")
+	assert.Equal(t, []uuid.UUID{deputy}, eligible["部門管理者代理"], "メインロールも対象")
+
+	result, err = service.SimulateApprovalFlow(ApprovalFlowSimulationRequest{
+		ResourceType: "purchases",
+		Scope:        map[string]interface{}{"amount_class": "large"},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Steps, 2)
+	assert.Equal(t, 2, result.Steps[1].StepOrder)
+	assert.Empty(t, result.Steps[1].Approvers[0].EligibleUsers)
+
+	result, err = service.SimulateApprovalFlow(ApprovalFlowSimulationRequest{ResourceType: "reports"})
+	require.NoError(t, err)
+	assert.False(t, result.ApprovalRequired)
+	assert.Empty(t, result.Steps)
+}
@@ -0,0 +1,442 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// setupTestApproval 承認ワークフローテスト用のサービスとDBを作成
+func setupTestApproval(t *testing.T) (*ApprovalService, *gorm.DB) {
+	db := setupTestDB(t)
+
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS roles (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			name TEXT NOT NULL,
+			parent_id TEXT,
+			requires_approval BOOLEAN NOT NULL DEFAULT false,
+			requires_mfa BOOLEAN NOT NULL DEFAULT false
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_roles (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			user_id TEXT NOT NULL,
+			role_id TEXT NOT NULL,
+			is_active BOOLEAN DEFAULT true,
+			priority INTEGER DEFAULT 0,
+			valid_from DATETIME DEFAULT CURRENT_TIMESTAMP,
+			valid_to DATETIME,
+			assigned_by TEXT,
+			assigned_reason TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS approval_states (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			state_name TEXT NOT NULL,
+			approver_role_id TEXT NOT NULL,
+			step_order INTEGER NOT NULL DEFAULT 1,
+			resource_type TEXT,
+			scope TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS approval_requests (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			resource_type TEXT NOT NULL,
+			resource_id TEXT,
+			title TEXT NOT NULL,
+			payload TEXT,
+			scope TEXT,
+			requested_by TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			current_step INTEGER NOT NULL DEFAULT 0,
+			completed_at DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS approval_actions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			request_id TEXT NOT NULL,
+			step_order INTEGER NOT NULL DEFAULT 0,
+			approval_state_id INTEGER,
+			actor_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			comment TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+	}
+	for _, statement := range statements {
+		require.NoError(t, db.Exec(statement).Error)
+	}
+	for _, table := range []string{"approval_actions", "approval_requests", "approval_states", "user_roles"} {
+		db.Exec("DELETE FROM " + table)
+	}
+
+	return NewApprovalService(db, logger.NewLogger()), db
+}
+
+// createApprovalTestRole 承認者ロールを作成
+func createApprovalTestRole(t *testing.T, db *gorm.DB, name string) uuid.UUID {
+	roleID := uuid.New()
+	require.NoError(t, db.Exec("INSERT INTO roles (id, name) VALUES (?, ?)", roleID.String(), name).Error)
+	return roleID
+}
+
+// assignApprovalTestRole ユーザーにロールを割り当て
+func assignApprovalTestRole(t *testing.T, db *gorm.DB, userID, roleID uuid.UUID) {
+	err := db.Exec("INSERT INTO user_roles (user_id, role_id, is_active, valid_from) VALUES (?, ?, ?, ?)",
+		userID.String(), roleID.String(), true, time.Now().Add(-time.Hour)).Error
+	require.NoError(t, err)
+}
+
+// createApprovalTestState 承認ステップを作成
+func createApprovalTestState(t *testing.T, db *gorm.DB, name string, roleID uuid.UUID, stepOrder int, resourceType string, scope string) {
+	var scopeValue interface{}
+	if scope != "" {
+		scopeValue = scope
+	}
+	err := db.Exec("INSERT INTO approval_states (state_name, approver_role_id, step_order, resource_type, scope) VALUES (?, ?, ?, ?, ?)",
+		name, roleID.String(), stepOrder, resourceType, scopeValue).Error
+	require.NoError(t, err)
+}
+
+func TestApprovalService_Workflow(t *testing.T) {
+	service, db := setupTestApproval(t)
+
+	managerRole := createApprovalTestRole(t, db, "部門管理者")
+	financeRole := createApprovalTestRole(t, db, "経理担当")
+	createApprovalTestState(t, db, "部門長承認", managerRole, 1, "orders", "")
+	createApprovalTestState(t, db, "経理承認", financeRole, 2, "orders", "")
+	createApprovalTestState(t, db, "高額経理承認", financeRole, 3, "orders", `{"amount_class":"large"}`)
+
+	requester := createUserForScopeTest(t, db, "申請者")
+	manager := createUserForScopeTest(t, db, "部門長")
+	finance := createUserForScopeTest(t, db, "経理")
+	outsider := createUserForScopeTest(t, db, "部外者")
+	assignApprovalTestRole(t, db, manager, managerRole)
+	assignApprovalTestRole(t, db, finance, financeRole)
+
+	submitted, err := service.SubmitApprovalRequest(requester, SubmitApprovalRequest{
+		ResourceType: "orders",
+		Title:        "発注申請",
+		Payload:      map[string]interface{}{"order_no": "PO-001"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.ApprovalStatusPending, submitted.Status)
+	assert.Equal(t, 1, submitted.CurrentStep)
+	require.Len(t, submitted.Steps, 2, "スコープ条件付きのステップは対象外")
+	assert.Equal(t, ApprovalStepCurrent, submitted.Steps[0].Status)
+	assert.Equal(t, "部門管理者", submitted.Steps[0].Approvers[0].ApproverRoleName)
+
+	t.Run("承認フロー未定義のリソース種別はエラー", func(t *testing.T) {
+		_, err := service.SubmitApprovalRequest(requester, SubmitApprovalRequest{ResourceType: "reports", Title: "レポート"})
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrCodeBusinessRule, err.(*errors.APIError).Code)
+	})
+
+	t.Run("申請者本人と他ステップの承認者は操作不可", func(t *testing.T) {
+		_, err := service.ApproveRequest(submitted.ID, requester, "")
+		assert.True(t, errors.IsAuthorizationError(err))
+
+		_, err = service.ApproveRequest(submitted.ID, finance, "")
+		assert.True(t, errors.IsAuthorizationError(err))
+	})
+
+	t.Run("承認待ち一覧はアクティブなロールで判定", func(t *testing.T) {
+		pending, err := service.GetPendingApprovals(manager)
+		require.NoError(t, err)
+		require.Equal(t, 1, pending.Total)
+		assert.Equal(t, submitted.ID, pending.Requests[0].ID)
+
+		pending, err = service.GetPendingApprovals(finance)
+		require.NoError(t, err)
+		assert.Equal(t, 0, pending.Total)
+	})
+
+	t.Run("ステップ順に承認され最終ステップで承認済み", func(t *testing.T) {
+		result, err := service.ApproveRequest(submitted.ID, manager, "問題ありません")
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.CurrentStep)
+		assert.Equal(t, ApprovalStepApproved, result.Steps[0].Status)
+
+		pending, err := service.GetPendingApprovals(finance)
+		require.NoError(t, err)
+		assert.Equal(t, 1, pending.Total)
+
+		_, err = service.RejectRequest(submitted.ID, finance, "")
+		assert.True(t, errors.IsValidationError(err), "却下はコメント必須")
+
+		result, err = service.ApproveRequest(submitted.ID, finance, "")
+		require.NoError(t, err)
+		assert.Equal(t, models.ApprovalStatusApproved, result.Status)
+		assert.Equal(t, 0, result.CurrentStep)
+		assert.NotNil(t, result.CompletedAt)
+		require.Len(t, result.Actions, 3)
+		assert.Equal(t, models.ApprovalActionSubmit, result.Actions[0].Action)
+
+		_, err = service.ApproveRequest(submitted.ID, finance, "")
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrCodeBusinessRule, err.(*errors.APIError).Code)
+	})
+
+	t.Run("参照は関係者のみ", func(t *testing.T) {
+		_, err := service.GetApprovalRequest(submitted.ID, finance)
+		assert.NoError(t, err)
+
+		_, err = service.GetApprovalRequest(submitted.ID, outsider)
+		assert.True(t, errors.IsAuthorizationError(err))
+
+		_, err = service.GetApprovalRequest(uuid.New(), requester)
+		assert.True(t, errors.IsNotFound(err))
+	})
+
+	t.Run("自分の申請一覧", func(t *testing.T) {
+		mine, err := service.GetMyApprovalRequests(requester, "approved")
+		require.NoError(t, err)
+		assert.Equal(t, 1, mine.Total)
+
+		_, err = service.GetMyApprovalRequests(requester, "unknown")
+		assert.True(t, errors.IsValidationError(err))
+	})
+}
+
+// TestApprovalService_SameApproverForMultipleSteps 複数ステップの承認ロールを持つユーザーが1人で全ステップを承認できないことのテスト
+func TestApprovalService_SameApproverForMultipleSteps(t *testing.T) {
+	service, db := setupTestApproval(t)
+
+	managerRole := createApprovalTestRole(t, db, "部門管理者")
+	financeRole := createApprovalTestRole(t, db, "経理担当")
+	createApprovalTestState(t, db, "部門長承認", managerRole, 1, "orders", "")
+	createApprovalTestState(t, db, "経理承認", financeRole, 2, "orders", "")
+
+	requester := createUserForScopeTest(t, db, "申請者")
+	approver := createUserForScopeTest(t, db, "兼務者")
+	finance := createUserForScopeTest(t, db, "経理")
+	assignApprovalTestRole(t, db, approver, managerRole)
+	assignApprovalTestRole(t, db, approver, financeRole)
+	assignApprovalTestRole(t, db, finance, financeRole)
+
+	submitted, err := service.SubmitApprovalRequest(requester, SubmitApprovalRequest{ResourceType: "orders", Title: "発注申請"})
+	require.NoError(t, err)
+	result, err := service.ApproveRequest(submitted.ID, approver, "")
+	require.NoError(t, err)
+	require.Equal(t, 2, result.CurrentStep)
+
+	_, err = service.ApproveRequest(submitted.ID, approver, "")
+	assert.True(t, errors.IsAuthorizationError(err), "前のステップを承認したユーザーは次のステップを承認できない")
+
+	pending, err := service.GetPendingApprovals(approver)
+	require.NoError(t, err)
+	assert.Equal(t, 0, pending.Total)
+
+	result, err = service.ApproveRequest(submitted.ID, finance, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.ApprovalStatusApproved, result.Status)
+}
+
+func TestApprovalService_ReturnAndResubmit(t *testing.T) {
+	service, db := setupTestApproval(t)
+
+	managerRole := createApprovalTestRole(t, db, "部門管理者")
+	financeRole := createApprovalTestRole(t, db, "経理担当")
+	createApprovalTestState(t, db, "部門長承認", managerRole, 1, "orders", "")
+	createApprovalTestState(t, db, "高額経理承認", financeRole, 2, "orders", `{"amount_class":"large"}`)
+
+	requester := createUserForScopeTest(t, db, "申請者")
+	manager := createUserForScopeTest(t, db, "部門長")
+	assignApprovalTestRole(t, db, manager, managerRole)
+
+	submitted, err := service.SubmitApprovalRequest(requester, SubmitApprovalRequest{
+		ResourceType: "orders",
+		Title:        "高額発注申請",
+		Scope:        map[string]interface{}{"amount_class": "large"},
+	})
+	require.NoError(t, err)
+	require.Len(t, submitted.Steps, 2, "スコープ条件に一致するステップを含む")
+
+	returned, err := service.ReturnRequest(submitted.ID, manager, "見積書を添付してください")
+	require.NoError(t, err)
+	assert.Equal(t, models.ApprovalStatusReturned, returned.Status)
+	assert.Equal(t, ApprovalStepReturned, returned.Steps[0].Status)
+
+	_, err = service.ApproveRequest(submitted.ID, manager, "")
+	require.Error(t, err, "差し戻し中は承認不可")
+
+	_, err = service.ResubmitApprovalRequest(submitted.ID, manager, "")
+	assert.True(t, errors.IsAuthorizationError(err), "再申請は申請者のみ")
+
+	resubmitted, err := service.ResubmitApprovalRequest(submitted.ID, requester, "見積書を添付しました")
+	require.NoError(t, err)
+	assert.Equal(t, models.ApprovalStatusPending, resubmitted.Status)
+	assert.Equal(t, 1, resubmitted.CurrentStep)
+	assert.Equal(t, ApprovalStepCurrent, resubmitted.Steps[0].Status, "再申請で進捗はリセット")
+
+	cancelled, err := service.CancelApprovalRequest(submitted.ID, requester, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.ApprovalStatusCancelled, cancelled.Status)
+
+	pending, err := service.GetPendingApprovals(manager)
+	require.NoError(t, err)
+	assert.Equal(t, 0, pending.Total)
+}
+
+func TestUserRoleService_AssignRoleRequiresApproval(t *testing.T) {
+	approvalService, db := setupTestApproval(t)
+	userRoleService := NewUserRoleService(db)
+	userRoleService.SetApprovalService(approvalService)
+
+	adminRole := createApprovalTestRole(t, db, "システム管理者")
+	require.NoError(t, db.Exec("UPDATE roles SET requires_approval = ? WHERE id = ?", true, adminRole.String()).Error)
+	generalRole := createApprovalTestRole(t, db, "一般ユーザー")
+	managerRole := createApprovalTestRole(t, db, "部門管理者")
+	securityRole := createApprovalTestRole(t, db, "セキュリティ管理者")
+	createApprovalTestState(t, db, "上長承認", managerRole, 1, UserRoleApprovalResourceType, "")
+	createApprovalTestState(t, db, "セキュリティ承認", securityRole, 2, UserRoleApprovalResourceType, `{"role_name":"システム管理者"}`)
+
+	target := createUserForScopeTest(t, db, "対象ユーザー")
+	requester := createUserForScopeTest(t, db, "申請者")
+	manager := createUserForScopeTest(t, db, "上長")
+	security := createUserForScopeTest(t, db, "セキュリティ担当")
+	assignApprovalTestRole(t, db, manager, managerRole)
+	assignApprovalTestRole(t, db, security, securityRole)
+
+	countAdminRoles := func() int64 {
+		var count int64
+		require.NoError(t, db.Model(&models.UserRole{}).Where("user_id = ? AND role_id = ?", target, adminRole).Count(&count).Error)
+		return count
+	}
+
+	t.Run("承認不要なロールは即時付与", func(t *testing.T) {
+		result, err := userRoleService.AssignRole(target, generalRole, time.Now().Add(-time.Minute), nil, 1, requester, "配属")
+		require.NoError(t, err)
+		require.NotNil(t, result.UserRole)
+		assert.Nil(t, result.ApprovalRequest)
+	})
+
+	result, err := userRoleService.AssignRole(target, adminRole, time.Now().Add(-time.Minute), nil, 1, requester, "運用担当のため")
+	require.NoError(t, err)
+	assert.Nil(t, result.UserRole)
+	require.NotNil(t, result.ApprovalRequest)
+	request := result.ApprovalRequest
+	assert.Equal(t, UserRoleApprovalResourceType, request.ResourceType)
+	require.Len(t, request.Steps, 2, "ロール名のスコープ条件に一致するステップを含む")
+	assert.Equal(t, int64(0), countAdminRoles(), "承認前はロールを付与しない")
+
+	t.Run("進行中の申請がある場合は重複申請不可", func(t *testing.T) {
+		_, err := userRoleService.AssignRole(target, adminRole, time.Now(), nil, 1, requester, "")
+		assert.True(t, errors.IsValidationError(err))
+	})
+
+	t.Run("汎用の申請APIからは申請不可", func(t *testing.T) {
+		_, err := approvalService.SubmitApprovalRequest(requester, SubmitApprovalRequest{
+			ResourceType: UserRoleApprovalResourceType,
+			Title:        "直接申請",
+		})
+		assert.True(t, errors.IsValidationError(err))
+	})
+
+	_, err = approvalService.ApproveRequest(request.ID, manager, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), countAdminRoles(), "途中のステップではロールを付与しない")
+
+	approved, err := approvalService.ApproveRequest(request.ID, security, "確認済み")
+	require.NoError(t, err)
+	assert.Equal(t, models.ApprovalStatusApproved, approved.Status)
+	assert.Equal(t, int64(1), countAdminRoles(), "最終承認でロールを付与")
+
+	var userRole models.UserRole
+	require.NoError(t, db.Where("user_id = ? AND role_id = ?", target, adminRole).First(&userRole).Error)
+	require.NotNil(t, userRole.AssignedBy)
+	assert.Equal(t, requester, *userRole.AssignedBy)
+	assert.Equal(t, "運用担当のため", userRole.AssignedReason)
+}
+
+func TestUserRoleService_ExtendRoleRequiresApproval(t *testing.T) {
+	approvalService, db := setupTestApproval(t)
+	userRoleService := NewUserRoleService(db)
+	userRoleService.SetApprovalService(approvalService)
+
+	adminRole := createApprovalTestRole(t, db, "期限付き管理者")
+	require.NoError(t, db.Exec("UPDATE roles SET requires_approval = ? WHERE id = ?", true, adminRole.String()).Error)
+	managerRole := createApprovalTestRole(t, db, "延長承認者")
+	createApprovalTestState(t, db, "上長承認", managerRole, 1, UserRoleApprovalResourceType, "")
+
+	target := createUserForScopeTest(t, db, "期限付きユーザー")
+	requester := createUserForScopeTest(t, db, "延長申請者")
+	manager := createUserForScopeTest(t, db, "延長承認上長")
+	assignApprovalTestRole(t, db, manager, managerRole)
+	assignApprovalTestRole(t, db, target, adminRole)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	require.NoError(t, db.Exec("UPDATE user_roles SET valid_to = ? WHERE user_id = ? AND role_id = ?", expiresAt, target.String(), adminRole.String()).Error)
+	currentValidTo := func() time.Time {
+		var userRole models.UserRole
+		require.NoError(t, db.Where("user_id = ? AND role_id = ?", target, adminRole).First(&userRole).Error)
+		require.NotNil(t, userRole.ValidTo)
+		return *userRole.ValidTo
+	}
+
+	t.Run("短縮は承認なしで反映", func(t *testing.T) {
+		shortened := expiresAt.Add(-30 * time.Minute)
+		result, err := userRoleService.UpdateRole(target, adminRole, nil, &shortened, requester, "短縮")
+		require.NoError(t, err)
+		require.NotNil(t, result.UserRole)
+		assert.Nil(t, result.ApprovalRequest)
+		assert.WithinDuration(t, shortened, currentValidTo(), time.Second)
+		expiresAt = shortened
+	})
+
+	extended := expiresAt.Add(30 * 24 * time.Hour)
+	result, err := userRoleService.UpdateRole(target, adminRole, nil, &extended, requester, "プロジェクト延長のため")
+	require.NoError(t, err)
+	assert.Nil(t, result.UserRole)
+	require.NotNil(t, result.ApprovalRequest)
+	assert.Contains(t, result.ApprovalRequest.Title, "ロール期限延長")
+	assert.WithinDuration(t, expiresAt, currentValidTo(), time.Second, "承認前は延長しない")
+
+	t.Run("延長の申請が進行中の場合は重複申請不可", func(t *testing.T) {
+		_, err := userRoleService.ExtendRole(target, adminRole, nil, requester, "無期限化")
+		assert.True(t, errors.IsValidationError(err))
+	})
+
+	approved, err := approvalService.ApproveRequest(result.ApprovalRequest.ID, manager, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.ApprovalStatusApproved, approved.Status)
+	assert.WithinDuration(t, extended, currentValidTo(), time.Second, "最終承認で延長")
+
+	var count int64
+	require.NoError(t, db.Model(&models.UserRole{}).Where("user_id = ? AND role_id = ?", target, adminRole).Count(&count).Error)
+	assert.Equal(t, int64(1), count, "延長で新しいUserRoleを作成しない")
+}
+
+func TestUserService_CreateUserRejectsApprovalRequiredPrimaryRole(t *testing.T) {
+	_, db := setupTestApproval(t)
+	userService := NewUserService(db, logger.NewLogger())
+
+	department := &models.Department{Name: "承認ロール検証部"}
+	require.NoError(t, db.Create(department).Error)
+	adminRole := createApprovalTestRole(t, db, "承認必須管理者")
+	require.NoError(t, db.Exec("UPDATE roles SET requires_approval = ? WHERE id = ?", true, adminRole.String()).Error)
+
+	_, err := userService.CreateUser(CreateUserRequest{
+		Name:          "承認回避ユーザー",
+		Email:         "approval-bypass@example.com",
+		Password:      "Str0ng!Passw0rd#2024",
+		DepartmentID:  department.ID,
+		PrimaryRoleID: adminRole,
+	})
+	assert.True(t, errors.IsValidationError(err))
+
+	var count int64
+	require.NoError(t, db.Model(&models.User{}).Where("email = ?", "approval-bypass@example.com").Count(&count).Error)
+	assert.Equal(t, int64(0), count, "承認が必要なロールをプライマリロールに持つユーザーは作成しない")
+}
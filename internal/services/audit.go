@@ -0,0 +1,450 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// AuditService 監査ログサービス
+type AuditService struct {
+	db            *gorm.DB
+	logger        *logger.Logger
+	checkpointKey ed25519.PrivateKey // チェックポイント署名鍵（未設定の場合はチェックポイント作成不可）
+}
+
+// NewAuditService 新しい監査ログサービスを作成
+func NewAuditService(db *gorm.DB, logger *logger.Logger) *AuditService {
+	return &AuditService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// 監査ログエクスポート形式
+const (
+	AuditExportFormatCSV    = "csv"
+	AuditExportFormatNDJSON = "ndjson"
+)
+
+// AuditEntry 監査ログ記録用エントリ
+type AuditEntry struct {
+	UserID       *uuid.UUID // 未認証リクエストの場合はnil
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Result       models.AuditResult
+	Reason       string
+	ReasonCode   string
+	IPAddress    string
+	UserAgent    string
+}
+
+// Record 監査ログを1件記録
+func (s *AuditService) Record(entry AuditEntry) (*models.AuditLog, error) {
+	var userID uuid.UUID
+	if entry.UserID != nil {
+		userID = *entry.UserID
+	}
+
+	auditLog, err := models.CreateAuditLog(
+		s.db,
+		userID,
+		entry.Action,
+		entry.ResourceType,
+		entry.ResourceID,
+		entry.Result,
+		optionalString(entry.Reason),
+		optionalString(entry.ReasonCode),
+		optionalString(entry.IPAddress),
+		optionalString(entry.UserAgent),
+	)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return auditLog, nil
+}
+
+// =============================================================================
+// 検索・統計・エクスポート
+// =============================================================================
+
+// AuditLogFilters 監査ログ検索フィルター（action・resource_type・resultはカンマ区切りで複数指定可）
+type AuditLogFilters struct {
+	UserID       string     `form:"user_id" binding:"omitempty,uuid"`
+	Action       string     `form:"action"`
+	ResourceType string     `form:"resource_type"`
+	ResourceID   string     `form:"resource_id"`
+	Result       string     `form:"result"`
+	ReasonCode   string     `form:"reason_code"`
+	IPAddress    string     `form:"ip_address" binding:"omitempty,ip"`
+	StartTime    *time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime      *time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor       string     `form:"cursor"`
+	Limit        int        `form:"limit,default=50"`
+}
+
+// AuditLogListResponse 監査ログ一覧レスポンス（カーソルページング）
+type AuditLogListResponse struct {
+	AuditLogs  []models.AuditLog `json:"audit_logs"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	HasMore    bool              `json:"has_more"`
+	Limit      int               `json:"limit"`
+}
+
+// AuditStatsFilters 監査統計の期間指定
+type AuditStatsFilters struct {
+	StartTime *time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime   *time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// UserActivitySummary ユーザーアクティビティサマリー
+type UserActivitySummary struct {
+	UserID       uuid.UUID              `json:"user_id"`
+	LastActivity *time.Time             `json:"last_activity,omitempty"`
+	LastDenied   *time.Time             `json:"last_denied,omitempty"`
+	Stats        map[string]interface{} `json:"stats"`
+}
+
+// auditExportBatchSize エクスポート時の1回あたりの読み込み件数
+const auditExportBatchSize = 500
+
+// auditCSVHeader CSVエクスポートのヘッダー行
+var auditCSVHeader = []string{
+	"id", "timestamp", "user_id", "action", "resource_type", "resource_id",
+	"result", "reason", "reason_code", "ip_address", "user_agent",
+	"prev_hash", "hash",
+}
+
+// GetAuditLogs 監査ログ一覧を取得（複合フィルター・カーソルページング）
+// カーソルは直前ページ最終行のIDを符号化したもので、IDの降順に取得する
+func (s *AuditService) GetAuditLogs(filters AuditLogFilters) (*AuditLogListResponse, error) {
+	if filters.Limit < 1 || filters.Limit > 200 {
+		filters.Limit = 50
+	}
+
+	query, err := s.applyFilters(s.db.Model(&models.AuditLog{}), filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if filters.Cursor != "" {
+		lastID, err := decodeAuditCursor(filters.Cursor)
+		if err != nil {
+			return nil, errors.NewValidationError("cursor", "Invalid cursor")
+		}
+		query = query.Where("id < ?", lastID)
+	}
+
+	// 次ページ有無の判定のため1件多く取得
+	var auditLogs []models.AuditLog
+	if err := query.Order("id DESC").Limit(filters.Limit + 1).Find(&auditLogs).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	response := &AuditLogListResponse{
+		AuditLogs: auditLogs,
+		Limit:     filters.Limit,
+	}
+	if len(auditLogs) > filters.Limit {
+		response.AuditLogs = auditLogs[:filters.Limit]
+		response.HasMore = true
+		response.NextCursor = encodeAuditCursor(response.AuditLogs[filters.Limit-1].ID)
+	}
+
+	return response, nil
+}
+
+// GetAuditLog 監査ログ詳細を取得
+func (s *AuditService) GetAuditLog(id int) (*models.AuditLog, error) {
+	auditLog, err := models.FindAuditLogByID(s.db, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("audit_log", "Audit log not found")
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+	return auditLog, nil
+}
+
+// GetStats 監査統計を取得
+func (s *AuditService) GetStats(filters AuditStatsFilters) (map[string]interface{}, error) {
+	startTime, endTime := normalizeAuditPeriod(filters.StartTime, filters.EndTime)
+
+	stats, err := models.GetAuditStats(s.db, startTime, endTime)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	return stats, nil
+}
+
+// GetUserActivity ユーザー別アクティビティサマリーを取得
+func (s *AuditService) GetUserActivity(userID uuid.UUID, filters AuditStatsFilters) (*UserActivitySummary, error) {
+	startTime, endTime := normalizeAuditPeriod(filters.StartTime, filters.EndTime)
+
+	stats, err := models.GetUserActivityStats(s.db, userID, startTime, endTime)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	summary := &UserActivitySummary{
+		UserID: userID,
+		Stats:  stats,
+	}
+
+	var last models.AuditLog
+	err = s.db.Where("user_id = ?", userID).Order("id DESC").First(&last).Error
+	if err == nil {
+		summary.LastActivity = &last.Timestamp
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	var lastDenied models.AuditLog
+	err = s.db.Where("user_id = ? AND result = ?", userID, models.AuditResultDenied).
+		Order("id DESC").First(&lastDenied).Error
+	if err == nil {
+		summary.LastDenied = &lastDenied.Timestamp
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return summary, nil
+}
+
+// ExportAuditLogs 監査ログをCSV/NDJSON形式でストリーミング出力
+// 全件をメモリに載せないようIDの昇順にバッチで読み込んで書き出す
+func (s *AuditService) ExportAuditLogs(w io.Writer, format string, filters AuditLogFilters) (int, error) {
+	if format != AuditExportFormatCSV && format != AuditExportFormatNDJSON {
+		return 0, errors.NewValidationError("format", "Format must be csv or ndjson")
+	}
+
+	query, err := s.applyFilters(s.db.Model(&models.AuditLog{}), filters)
+	if err != nil {
+		return 0, err
+	}
+
+	var csvWriter *csv.Writer
+	var jsonEncoder *json.Encoder
+	if format == AuditExportFormatCSV {
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write(auditCSVHeader); err != nil {
+			return 0, err
+		}
+	} else {
+		jsonEncoder = json.NewEncoder(w)
+	}
+
+	exported := 0
+	lastID := 0
+	for {
+		var batch []models.AuditLog
+		err := query.Session(&gorm.Session{}).
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(auditExportBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return exported, errors.NewDatabaseError(err)
+		}
+
+		for i := range batch {
+			if csvWriter != nil {
+				err = csvWriter.Write(auditLogCSVRecord(&batch[i]))
+			} else {
+				err = jsonEncoder.Encode(&batch[i])
+			}
+			if err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		if csvWriter != nil {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return exported, err
+			}
+		}
+		if flusher, ok := w.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
+
+		if len(batch) < auditExportBatchSize {
+			break
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+
+	s.logger.Info("Audit logs exported", map[string]interface{}{
+		"format": format,
+		"count":  exported,
+	})
+
+	return exported, nil
+}
+
+// applyFilters 監査ログ検索フィルターをクエリに適用
+func (s *AuditService) applyFilters(query *gorm.DB, filters AuditLogFilters) (*gorm.DB, error) {
+	if filters.UserID != "" {
+		userID, err := uuid.Parse(filters.UserID)
+		if err != nil {
+			return nil, errors.NewValidationError("user_id", "Invalid UUID format")
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+
+	if values := splitFilterValues(filters.Action); len(values) > 0 {
+		for _, action := range values {
+			if probe := (models.AuditLog{Action: action}); !probe.IsValidAction() {
+				return nil, errors.NewValidationError("action", fmt.Sprintf("Invalid action: %s", action))
+			}
+		}
+		query = query.Where("action IN ?", values)
+	}
+
+	if values := splitFilterValues(filters.ResourceType); len(values) > 0 {
+		for _, resourceType := range values {
+			if probe := (models.AuditLog{ResourceType: resourceType}); !probe.IsValidResourceType() {
+				return nil, errors.NewValidationError("resource_type", fmt.Sprintf("Invalid resource type: %s", resourceType))
+			}
+		}
+		query = query.Where("resource_type IN ?", values)
+	}
+
+	if filters.ResourceID != "" {
+		query = query.Where("resource_id = ?", filters.ResourceID)
+	}
+
+	if values := splitFilterValues(filters.Result); len(values) > 0 {
+		for _, result := range values {
+			if !models.ValidateAuditResult(models.AuditResult(result)) {
+				return nil, errors.NewValidationError("result", fmt.Sprintf("Invalid result: %s", result))
+			}
+		}
+		query = query.Where("result IN ?", values)
+	}
+
+	if filters.ReasonCode != "" {
+		query = query.Where("reason_code = ?", filters.ReasonCode)
+	}
+
+	if filters.IPAddress != "" {
+		query = query.Where("ip_address = ?", filters.IPAddress)
+	}
+
+	if filters.StartTime != nil && filters.EndTime != nil && filters.EndTime.Before(*filters.StartTime) {
+		return nil, errors.NewValidationError("end_time", "End time must be after start time")
+	}
+	if filters.StartTime != nil {
+		query = query.Where("timestamp >= ?", *filters.StartTime)
+	}
+	if filters.EndTime != nil {
+		query = query.Where("timestamp <= ?", *filters.EndTime)
+	}
+
+	return query, nil
+}
+
+// auditLogCSVRecord 監査ログをCSVの1行に変換
+func auditLogCSVRecord(auditLog *models.AuditLog) []string {
+	userID := ""
+	if auditLog.UserID != nil {
+		userID = auditLog.UserID.String()
+	}
+
+	return []string{
+		strconv.Itoa(auditLog.ID),
+		auditLog.Timestamp.UTC().Format(time.RFC3339Nano),
+		userID,
+		auditLog.Action,
+		auditLog.ResourceType,
+		auditLog.ResourceID,
+		string(auditLog.Result),
+		derefString(auditLog.Reason),
+		derefString(auditLog.ReasonCode),
+		auditLog.GetIPAddressString(),
+		derefString(auditLog.UserAgent),
+		derefString(auditLog.PrevHash),
+		derefString(auditLog.Hash),
+	}
+}
+
+// normalizeAuditPeriod 片側のみ指定された期間を補完（モデルの統計関数は両端指定時のみ期間で絞り込むため）
+func normalizeAuditPeriod(startTime, endTime *time.Time) (*time.Time, *time.Time) {
+	if startTime == nil && endTime == nil {
+		return nil, nil
+	}
+	if startTime == nil {
+		epoch := time.Unix(0, 0).UTC()
+		startTime = &epoch
+	}
+	if endTime == nil {
+		now := time.Now()
+		endTime = &now
+	}
+	return startTime, endTime
+}
+
+// splitFilterValues カンマ区切りのフィルター値を分割
+func splitFilterValues(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// encodeAuditCursor 監査ログIDをカーソル文字列に変換
+func encodeAuditCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+// decodeAuditCursor カーソル文字列を監査ログIDに変換
+func decodeAuditCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.Atoi(string(raw))
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid cursor: %s", cursor)
+	}
+	return id, nil
+}
+
+// derefString nilの場合は空文字列を返す
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// optionalString 空文字列をnilに変換
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
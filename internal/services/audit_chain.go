@@ -0,0 +1,358 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+)
+
+// ハッシュチェーン破損の種別
+const (
+	AuditChainBreakMissingHash        = "missing_hash"        // 連結開始後にハッシュのない行がある
+	AuditChainBreakPrevHashMismatch   = "prev_hash_mismatch"  // 直前行のハッシュと一致しない（削除・挿入・並び替え）
+	AuditChainBreakHashMismatch       = "hash_mismatch"       // 内容から再計算したハッシュと一致しない（改ざん）
+	AuditChainBreakCheckpointMismatch = "checkpoint_mismatch" // 署名済みチェックポイントと一致しない（末尾削除等）
+)
+
+// auditChainBatchSize 検証時の1回あたりの読み込み件数
+const auditChainBatchSize = 1000
+
+// AuditChainBreak ハッシュチェーンの破損箇所
+type AuditChainBreak struct {
+	LogID        int    `json:"log_id"`
+	Reason       string `json:"reason"`
+	ExpectedHash string `json:"expected_hash,omitempty"`
+	ActualHash   string `json:"actual_hash,omitempty"`
+	CheckpointID int    `json:"checkpoint_id,omitempty"`
+}
+
+// AuditChainVerification ハッシュチェーン検証結果
+type AuditChainVerification struct {
+	Valid         bool             `json:"valid"`
+	StartTime     *time.Time       `json:"start_time,omitempty"`
+	EndTime       *time.Time       `json:"end_time,omitempty"`
+	FirstLogID    int              `json:"first_log_id,omitempty"`
+	LastLogID     int              `json:"last_log_id,omitempty"`
+	CheckedCount  int              `json:"checked_count"`
+	UnsealedCount int              `json:"unsealed_count"` // ハッシュチェーン導入前の未連結ログ
+	LastHash      string           `json:"last_hash,omitempty"`
+	FirstBroken   *AuditChainBreak `json:"first_broken,omitempty"`
+	VerifiedAt    time.Time        `json:"verified_at"`
+}
+
+// AuditCheckpointPayload 署名対象のチェックポイント内容
+type AuditCheckpointPayload struct {
+	Version       int    `json:"version"`
+	LastLogID     int    `json:"last_log_id"`
+	LastHash      string `json:"last_hash"`
+	LogCount      int64  `json:"log_count"`
+	HashAlgorithm string `json:"hash_algorithm"`
+	KeyID         string `json:"key_id"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// AuditCheckpointExport オフライン検証用のチェックポイント
+// payloadはBase64URLエンコードした署名対象バイト列、signatureはそのEd25519署名
+type AuditCheckpointExport struct {
+	CheckpointID       int                    `json:"checkpoint_id"`
+	Checkpoint         AuditCheckpointPayload `json:"checkpoint"`
+	Payload            string                 `json:"payload"`
+	Signature          string                 `json:"signature"`
+	SignatureAlgorithm string                 `json:"signature_algorithm"`
+	PublicKey          string                 `json:"public_key"`
+}
+
+// SetCheckpointSigningKey チェックポイント署名鍵を設定
+func (s *AuditService) SetCheckpointSigningKey(key ed25519.PrivateKey) {
+	s.checkpointKey = key
+}
+
+// LoadCheckpointSigningKey PKCS#8 PEM形式のEd25519秘密鍵を読み込む
+func LoadCheckpointSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode checkpoint key PEM: %s", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint key: %w", err)
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("checkpoint key must be Ed25519: %s", path)
+	}
+
+	return privateKey, nil
+}
+
+// VerifyChain 期間内の監査ログのハッシュチェーンを検証し、最初の破損箇所を報告
+func (s *AuditService) VerifyChain(filters AuditStatsFilters) (*AuditChainVerification, error) {
+	if filters.StartTime != nil && filters.EndTime != nil && filters.EndTime.Before(*filters.StartTime) {
+		return nil, errors.NewValidationError("end_time", "End time must be after start time")
+	}
+
+	result := &AuditChainVerification{
+		Valid:      true,
+		StartTime:  filters.StartTime,
+		EndTime:    filters.EndTime,
+		VerifiedAt: time.Now(),
+	}
+
+	// 期間をIDの範囲に変換（時刻の前後があってもID順に連続した区間を検証する）
+	startID, endID, err := s.auditLogIDRange(filters)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	if startID == 0 {
+		// 区間に行がなくても、終端未指定ならチェックポイント済みの行が消えていないか確認する
+		if filters.EndTime == nil {
+			broken, err := s.verifyCheckpoints(1, 0, true)
+			if err != nil {
+				return nil, errors.NewDatabaseError(err)
+			}
+			if broken != nil {
+				result.Valid = false
+				result.FirstBroken = broken
+			}
+		}
+		return result, nil
+	}
+	result.FirstLogID = startID
+	result.LastLogID = endID
+
+	// 区間直前の行のハッシュを起点とする
+	var previous models.AuditLog
+	if err := s.db.Select("id", "hash").Where("id < ?", startID).Order("id DESC").Limit(1).Find(&previous).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	expectedPrev := derefString(previous.Hash)
+	chainStarted := previous.Hash != nil
+
+	lastID := startID - 1
+	for result.FirstBroken == nil {
+		var batch []models.AuditLog
+		err := s.db.Where("id > ? AND id <= ?", lastID, endID).
+			Order("id ASC").
+			Limit(auditChainBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return nil, errors.NewDatabaseError(err)
+		}
+
+		for i := range batch {
+			auditLog := &batch[i]
+
+			if !auditLog.IsSealed() {
+				if chainStarted {
+					result.FirstBroken = &AuditChainBreak{LogID: auditLog.ID, Reason: AuditChainBreakMissingHash}
+					break
+				}
+				result.UnsealedCount++
+				continue
+			}
+
+			actualPrev := derefString(auditLog.PrevHash)
+			if actualPrev != expectedPrev {
+				result.FirstBroken = &AuditChainBreak{
+					LogID:        auditLog.ID,
+					Reason:       AuditChainBreakPrevHashMismatch,
+					ExpectedHash: expectedPrev,
+					ActualHash:   actualPrev,
+				}
+				break
+			}
+
+			if !auditLog.VerifyHash() {
+				result.FirstBroken = &AuditChainBreak{
+					LogID:        auditLog.ID,
+					Reason:       AuditChainBreakHashMismatch,
+					ExpectedHash: auditLog.ComputeHash(actualPrev),
+					ActualHash:   *auditLog.Hash,
+				}
+				break
+			}
+
+			chainStarted = true
+			expectedPrev = *auditLog.Hash
+			result.CheckedCount++
+			result.LastHash = expectedPrev
+		}
+
+		if len(batch) < auditChainBatchSize {
+			break
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+
+	if result.FirstBroken == nil {
+		broken, err := s.verifyCheckpoints(startID, endID, filters.EndTime == nil)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err)
+		}
+		result.FirstBroken = broken
+	}
+
+	if result.FirstBroken != nil {
+		result.Valid = false
+		s.logger.Warn("Audit log hash chain broken", map[string]interface{}{
+			"log_id": result.FirstBroken.LogID,
+			"reason": result.FirstBroken.Reason,
+		})
+	}
+
+	return result, nil
+}
+
+// CreateCheckpoint 現在のチェーン末尾に対する署名付きチェックポイントを作成
+func (s *AuditService) CreateCheckpoint() (*AuditCheckpointExport, error) {
+	if s.checkpointKey == nil {
+		return nil, errors.NewBusinessError(errors.ErrCodeBusinessRule, "Checkpoint signing key is not configured", "audit.checkpoint_key_file is not set")
+	}
+
+	var last models.AuditLog
+	err := s.db.Where("hash IS NOT NULL").Order("id DESC").First(&last).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewBusinessError(errors.ErrCodeBusinessRule, "No sealed audit logs", "There are no audit logs to checkpoint")
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	var count int64
+	if err := s.db.Model(&models.AuditLog{}).Where("id <= ? AND hash IS NOT NULL", last.ID).Count(&count).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	publicKey := s.checkpointKey.Public().(ed25519.PublicKey)
+	payload := AuditCheckpointPayload{
+		Version:       1,
+		LastLogID:     last.ID,
+		LastHash:      *last.Hash,
+		LogCount:      count,
+		HashAlgorithm: "SHA-256",
+		KeyID:         checkpointKeyID(publicKey),
+		CreatedAt:     time.Now().UTC().Format(time.RFC3339Nano),
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.NewInternalError("Failed to encode checkpoint")
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(s.checkpointKey, payloadJSON))
+
+	checkpoint := &models.AuditCheckpoint{
+		LastLogID: payload.LastLogID,
+		LastHash:  payload.LastHash,
+		LogCount:  payload.LogCount,
+		Payload:   string(payloadJSON),
+		Signature: signature,
+		KeyID:     payload.KeyID,
+	}
+	if err := s.db.Create(checkpoint).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, errors.NewInternalError("Failed to encode checkpoint public key")
+	}
+
+	s.logger.Info("Audit checkpoint created", map[string]interface{}{
+		"checkpoint_id": checkpoint.ID,
+		"last_log_id":   checkpoint.LastLogID,
+		"log_count":     checkpoint.LogCount,
+		"key_id":        checkpoint.KeyID,
+	})
+
+	return &AuditCheckpointExport{
+		CheckpointID:       checkpoint.ID,
+		Checkpoint:         payload,
+		Payload:            base64.RawURLEncoding.EncodeToString(payloadJSON),
+		Signature:          signature,
+		SignatureAlgorithm: "Ed25519",
+		PublicKey:          string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})),
+	}, nil
+}
+
+// auditLogIDRange 期間に含まれる監査ログのID範囲を取得（該当なしの場合は0）
+func (s *AuditService) auditLogIDRange(filters AuditStatsFilters) (int, int, error) {
+	var bounds struct {
+		StartID *int
+		EndID   *int
+	}
+
+	query := s.db.Model(&models.AuditLog{})
+	if filters.StartTime != nil {
+		query = query.Where("timestamp >= ?", *filters.StartTime)
+	}
+	if filters.EndTime != nil {
+		query = query.Where("timestamp <= ?", *filters.EndTime)
+	}
+	if err := query.Select("MIN(id) AS start_id, MAX(id) AS end_id").Scan(&bounds).Error; err != nil {
+		return 0, 0, err
+	}
+
+	if bounds.StartID == nil || bounds.EndID == nil {
+		return 0, 0, nil
+	}
+	return *bounds.StartID, *bounds.EndID, nil
+}
+
+// verifyCheckpoints 区間内のチェックポイントが現在の監査ログと一致するか検証
+// includeTail がtrueの場合は区間より後のチェックポイントも対象とし、末尾の削除を検出する
+func (s *AuditService) verifyCheckpoints(startID, endID int, includeTail bool) (*AuditChainBreak, error) {
+	query := s.db.Where("last_log_id >= ?", startID)
+	if !includeTail {
+		query = query.Where("last_log_id <= ?", endID)
+	}
+
+	var checkpoints []models.AuditCheckpoint
+	if err := query.Order("last_log_id ASC").Find(&checkpoints).Error; err != nil {
+		return nil, err
+	}
+
+	for _, checkpoint := range checkpoints {
+		var auditLog models.AuditLog
+		if err := s.db.Select("id", "hash").Where("id = ?", checkpoint.LastLogID).Limit(1).Find(&auditLog).Error; err != nil {
+			return nil, err
+		}
+
+		actualHash := derefString(auditLog.Hash)
+		if auditLog.ID == 0 || actualHash != checkpoint.LastHash {
+			return &AuditChainBreak{
+				LogID:        checkpoint.LastLogID,
+				Reason:       AuditChainBreakCheckpointMismatch,
+				ExpectedHash: checkpoint.LastHash,
+				ActualHash:   actualHash,
+				CheckpointID: checkpoint.ID,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// checkpointKeyID 公開鍵のSHA-256先頭8バイトを鍵IDとする
+func checkpointKeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:8])
+}
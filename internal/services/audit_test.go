@@ -0,0 +1,391 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/logger"
+)
+
+// setupTestAudit 監査ログテスト用のサービスとDBを作成
+func setupTestAudit(t *testing.T) (*AuditService, *gorm.DB) {
+	db := setupTestDB(t)
+
+	err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_logs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT,
+			action TEXT NOT NULL,
+			resource_type TEXT NOT NULL,
+			resource_id TEXT NOT NULL,
+			result TEXT NOT NULL,
+			reason TEXT,
+			reason_code TEXT,
+			ip_address TEXT,
+			user_agent TEXT,
+			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+			prev_hash TEXT,
+			hash TEXT
+		)
+	`).Error
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_checkpoints (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			last_log_id INTEGER NOT NULL,
+			last_hash TEXT NOT NULL,
+			log_count INTEGER NOT NULL,
+			payload TEXT NOT NULL,
+			signature TEXT NOT NULL,
+			key_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error
+	require.NoError(t, err)
+
+	db.Exec("DELETE FROM audit_logs")
+	db.Exec("DELETE FROM audit_checkpoints")
+
+	return NewAuditService(db, logger.NewLogger()), db
+}
+
+func TestAuditService_Record(t *testing.T) {
+	service, db := setupTestAudit(t)
+
+	t.Run("認証済みユーザーの成功ログ", func(t *testing.T) {
+		userID := uuid.New()
+
+		auditLog, err := service.Record(AuditEntry{
+			UserID:       &userID,
+			Action:       "view",
+			ResourceType: "users",
+			ResourceID:   userID.String(),
+			Result:       models.AuditResultSuccess,
+			IPAddress:    "192.168.1.10",
+			UserAgent:    "test-agent",
+		})
+		require.NoError(t, err)
+
+		assert.NotZero(t, auditLog.ID)
+		require.NotNil(t, auditLog.UserID)
+		assert.Equal(t, userID, *auditLog.UserID)
+		assert.True(t, auditLog.IsSuccess())
+		assert.Equal(t, "192.168.1.10", auditLog.GetIPAddressString())
+		assert.Nil(t, auditLog.Reason)
+		assert.Nil(t, auditLog.ReasonCode)
+	})
+
+	t.Run("未認証リクエストの拒否ログ", func(t *testing.T) {
+		auditLog, err := service.Record(AuditEntry{
+			Action:       "view",
+			ResourceType: "users",
+			ResourceID:   "/api/v1/users",
+			Result:       models.AuditResultDenied,
+			Reason:       "Invalid token",
+			ReasonCode:   models.ReasonCodeAuthMissingToken,
+		})
+		require.NoError(t, err)
+
+		assert.Nil(t, auditLog.UserID)
+		assert.True(t, auditLog.IsDenied())
+		require.NotNil(t, auditLog.ReasonCode)
+		assert.Equal(t, models.ReasonCodeAuthMissingToken, *auditLog.ReasonCode)
+		assert.Equal(t, "AUTHENTICATION", auditLog.GetReasonCodeCategory())
+
+		var count int64
+		db.Model(&models.AuditLog{}).Where("user_id IS NULL").Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("不正なアクションは記録しない", func(t *testing.T) {
+		_, err := service.Record(AuditEntry{
+			Action:       "unknown",
+			ResourceType: "users",
+			ResourceID:   "1",
+			Result:       models.AuditResultSuccess,
+		})
+		assert.Error(t, err)
+	})
+}
+
+// seedAuditLogs テスト用の監査ログを作成
+func seedAuditLogs(t *testing.T, service *AuditService, userID uuid.UUID) {
+	entries := []AuditEntry{
+		{UserID: &userID, Action: "login", ResourceType: "auth", ResourceID: "/api/v1/auth/login", Result: models.AuditResultSuccess, IPAddress: "10.0.0.1"},
+		{UserID: &userID, Action: "view", ResourceType: "users", ResourceID: "1", Result: models.AuditResultSuccess, IPAddress: "10.0.0.1"},
+		{UserID: &userID, Action: "delete", ResourceType: "users", ResourceID: "2", Result: models.AuditResultDenied, ReasonCode: models.ReasonCodePermMissingPermission},
+		{Action: "view", ResourceType: "roles", ResourceID: "/api/v1/roles", Result: models.AuditResultDenied, ReasonCode: models.ReasonCodeAuthMissingToken},
+		{UserID: &userID, Action: "update", ResourceType: "roles", ResourceID: "3", Result: models.AuditResultError, Reason: "boom, \"quoted\""},
+	}
+	for _, entry := range entries {
+		_, err := service.Record(entry)
+		require.NoError(t, err)
+	}
+}
+
+func TestAuditService_GetAuditLogs(t *testing.T) {
+	service, _ := setupTestAudit(t)
+	userID := uuid.New()
+	seedAuditLogs(t, service, userID)
+
+	t.Run("複合フィルター", func(t *testing.T) {
+		result, err := service.GetAuditLogs(AuditLogFilters{
+			UserID:       userID.String(),
+			ResourceType: "users,roles",
+			Result:       "DENIED,ERROR",
+		})
+		require.NoError(t, err)
+
+		require.Len(t, result.AuditLogs, 2)
+		assert.Equal(t, "update", result.AuditLogs[0].Action)
+		assert.Equal(t, "delete", result.AuditLogs[1].Action)
+		assert.False(t, result.HasMore)
+		assert.Empty(t, result.NextCursor)
+	})
+
+	t.Run("カーソルページング", func(t *testing.T) {
+		first, err := service.GetAuditLogs(AuditLogFilters{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, first.AuditLogs, 2)
+		assert.True(t, first.HasMore)
+		require.NotEmpty(t, first.NextCursor)
+
+		second, err := service.GetAuditLogs(AuditLogFilters{Limit: 2, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		require.Len(t, second.AuditLogs, 2)
+		assert.Less(t, second.AuditLogs[0].ID, first.AuditLogs[1].ID)
+
+		third, err := service.GetAuditLogs(AuditLogFilters{Limit: 2, Cursor: second.NextCursor})
+		require.NoError(t, err)
+		assert.Len(t, third.AuditLogs, 1)
+		assert.False(t, third.HasMore)
+	})
+
+	t.Run("不正なフィルター", func(t *testing.T) {
+		_, err := service.GetAuditLogs(AuditLogFilters{Action: "view,unknown"})
+		assert.Error(t, err)
+
+		_, err = service.GetAuditLogs(AuditLogFilters{Cursor: "!!invalid"})
+		assert.Error(t, err)
+	})
+}
+
+func TestAuditService_Stats(t *testing.T) {
+	service, _ := setupTestAudit(t)
+	userID := uuid.New()
+	seedAuditLogs(t, service, userID)
+
+	stats, err := service.GetStats(AuditStatsFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stats["total"])
+
+	summary, err := service.GetUserActivity(userID, AuditStatsFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, userID, summary.UserID)
+	assert.Equal(t, int64(4), summary.Stats["total"])
+	assert.NotNil(t, summary.LastActivity)
+	assert.NotNil(t, summary.LastDenied)
+
+	summary, err = service.GetUserActivity(uuid.New(), AuditStatsFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), summary.Stats["total"])
+	assert.Nil(t, summary.LastActivity)
+}
+
+func TestAuditService_ExportAuditLogs(t *testing.T) {
+	service, _ := setupTestAudit(t)
+	userID := uuid.New()
+	seedAuditLogs(t, service, userID)
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		count, err := service.ExportAuditLogs(&buf, AuditExportFormatCSV, AuditLogFilters{ResourceType: "roles"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, auditCSVHeader, records[0])
+		assert.Equal(t, "", records[1][2])
+		assert.Equal(t, "boom, \"quoted\"", records[2][7])
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		count, err := service.ExportAuditLogs(&buf, AuditExportFormatNDJSON, AuditLogFilters{})
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 5)
+		var first models.AuditLog
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		assert.Equal(t, "login", first.Action)
+	})
+
+	t.Run("不正な形式", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := service.ExportAuditLogs(&buf, "xml", AuditLogFilters{})
+		assert.Error(t, err)
+		assert.Zero(t, buf.Len())
+	})
+}
+
+func TestAuditService_VerifyChain(t *testing.T) {
+	t.Run("改ざんなし", func(t *testing.T) {
+		service, _ := setupTestAudit(t)
+		seedAuditLogs(t, service, uuid.New())
+
+		result, err := service.VerifyChain(AuditStatsFilters{})
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Equal(t, 5, result.CheckedCount)
+		assert.Nil(t, result.FirstBroken)
+		assert.NotEmpty(t, result.LastHash)
+	})
+
+	t.Run("導入前の未連結ログは許容", func(t *testing.T) {
+		service, db := setupTestAudit(t)
+		require.NoError(t, db.Exec(`INSERT INTO audit_logs (action, resource_type, resource_id, result) VALUES ('view', 'users', 'legacy', 'SUCCESS')`).Error)
+		seedAuditLogs(t, service, uuid.New())
+
+		result, err := service.VerifyChain(AuditStatsFilters{})
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Equal(t, 1, result.UnsealedCount)
+		assert.Equal(t, 5, result.CheckedCount)
+	})
+
+	t.Run("内容の改ざんを検出", func(t *testing.T) {
+		service, db := setupTestAudit(t)
+		seedAuditLogs(t, service, uuid.New())
+
+		var target models.AuditLog
+		require.NoError(t, db.Where("action = ?", "delete").First(&target).Error)
+		require.NoError(t, db.Exec("UPDATE audit_logs SET result = 'SUCCESS' WHERE id = ?", target.ID).Error)
+
+		result, err := service.VerifyChain(AuditStatsFilters{})
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		require.NotNil(t, result.FirstBroken)
+		assert.Equal(t, target.ID, result.FirstBroken.LogID)
+		assert.Equal(t, AuditChainBreakHashMismatch, result.FirstBroken.Reason)
+	})
+
+	t.Run("途中の削除を検出", func(t *testing.T) {
+		service, db := setupTestAudit(t)
+		seedAuditLogs(t, service, uuid.New())
+
+		var logs []models.AuditLog
+		require.NoError(t, db.Order("id ASC").Find(&logs).Error)
+		require.NoError(t, db.Exec("DELETE FROM audit_logs WHERE id = ?", logs[2].ID).Error)
+
+		result, err := service.VerifyChain(AuditStatsFilters{})
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		require.NotNil(t, result.FirstBroken)
+		assert.Equal(t, logs[3].ID, result.FirstBroken.LogID)
+		assert.Equal(t, AuditChainBreakPrevHashMismatch, result.FirstBroken.Reason)
+	})
+
+	t.Run("IPアドレスを読み戻してもハッシュが一致", func(t *testing.T) {
+		service, db := setupTestAudit(t)
+		written, err := service.Record(AuditEntry{
+			Action:       "login",
+			ResourceType: "auth",
+			ResourceID:   "/api/v1/auth/login",
+			Result:       models.AuditResultSuccess,
+			IPAddress:    "192.0.2.1",
+		})
+		require.NoError(t, err)
+
+		// INET型と同じく文字列で保存される
+		var stored string
+		require.NoError(t, db.Raw("SELECT ip_address FROM audit_logs WHERE id = ?", written.ID).Scan(&stored).Error)
+		assert.Equal(t, "192.0.2.1", stored)
+
+		read, err := models.FindAuditLogByID(db, written.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "192.0.2.1", read.GetIPAddressString())
+		assert.True(t, read.VerifyHash())
+
+		// PostgreSQLがネットマスク付きで返す場合も同じ値として扱う
+		require.NoError(t, db.Exec("UPDATE audit_logs SET ip_address = '192.0.2.1/32' WHERE id = ?", written.ID).Error)
+		result, err := service.VerifyChain(AuditStatsFilters{})
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+	})
+
+	t.Run("期間指定は直前の行を起点に検証", func(t *testing.T) {
+		service, db := setupTestAudit(t)
+		seedAuditLogs(t, service, uuid.New())
+
+		var logs []models.AuditLog
+		require.NoError(t, db.Order("id ASC").Find(&logs).Error)
+		start := logs[2].Timestamp
+
+		result, err := service.VerifyChain(AuditStatsFilters{StartTime: &start})
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Equal(t, logs[2].ID, result.FirstLogID)
+		assert.Equal(t, 3, result.CheckedCount)
+	})
+}
+
+func TestAuditService_CreateCheckpoint(t *testing.T) {
+	service, db := setupTestAudit(t)
+
+	_, err := service.CreateCheckpoint()
+	assert.Error(t, err, "署名鍵未設定の場合はエラー")
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	service.SetCheckpointSigningKey(privateKey)
+
+	seedAuditLogs(t, service, uuid.New())
+
+	export, err := service.CreateCheckpoint()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), export.Checkpoint.LogCount)
+	assert.Equal(t, "Ed25519", export.SignatureAlgorithm)
+
+	// オフライン検証: ペイロードの署名と内容を確認
+	payload, err := base64.RawURLEncoding.DecodeString(export.Payload)
+	require.NoError(t, err)
+	signature, err := base64.StdEncoding.DecodeString(export.Signature)
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(publicKey, payload, signature))
+
+	var decoded AuditCheckpointPayload
+	require.NoError(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, export.Checkpoint, decoded)
+
+	var last models.AuditLog
+	require.NoError(t, db.Order("id DESC").First(&last).Error)
+	assert.Equal(t, last.ID, decoded.LastLogID)
+	assert.Equal(t, *last.Hash, decoded.LastHash)
+
+	// 末尾の削除はチェーン上は検出できないがチェックポイントで検出する
+	require.NoError(t, db.Exec("DELETE FROM audit_logs WHERE id = ?", last.ID).Error)
+
+	result, err := service.VerifyChain(AuditStatsFilters{})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.FirstBroken)
+	assert.Equal(t, AuditChainBreakCheckpointMismatch, result.FirstBroken.Reason)
+	assert.Equal(t, export.CheckpointID, result.FirstBroken.CheckpointID)
+}
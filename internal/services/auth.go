@@ -1,6 +1,7 @@
 package services
 
 import (
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -15,10 +16,20 @@ import (
 
 // AuthService 認証・認可サービス
 type AuthService struct {
-	db                *gorm.DB
-	jwtService        *jwt.Service
-	permissionService *PermissionService
-	revocationService *TokenRevocationService
+	db                  *gorm.DB
+	jwtService          *jwt.Service
+	permissionService   *PermissionService
+	revocationService   *TokenRevocationService
+	refreshTokenService *RefreshTokenService
+	auditService        *AuditService   // ログイン試行の監査記録（未設定の場合は記録しない）
+	mfaService          *MFAService     // 多要素認証（未設定の場合はパスワードのみで認証）
+	sessionService      *SessionService // ログインセッションの記録（未設定の場合は記録しない）
+	passwordPolicy      *PasswordPolicyService
+	lockoutPolicy       LockoutPolicy
+
+	// 存在しないユーザーのログイン時に比較するダミーハッシュ（設定されたbcryptコストで生成）
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
 }
 
 // NewAuthService 新しい認証サービスを作成
@@ -27,27 +38,70 @@ func NewAuthService(
 	jwtService *jwt.Service,
 	permissionService *PermissionService,
 	revocationService *TokenRevocationService,
+	refreshTokenService *RefreshTokenService,
 ) *AuthService {
 	return &AuthService{
-		db:                db,
-		jwtService:        jwtService,
-		permissionService: permissionService,
-		revocationService: revocationService,
+		db:                  db,
+		jwtService:          jwtService,
+		permissionService:   permissionService,
+		revocationService:   revocationService,
+		refreshTokenService: refreshTokenService,
+		passwordPolicy:      NewPasswordPolicyService(db, DefaultPasswordPolicy()),
+		lockoutPolicy:       DefaultLockoutPolicy(),
 	}
 }
 
+// SetAuditService ログイン試行を監査ログに記録する監査サービスを設定
+func (s *AuthService) SetAuditService(auditService *AuditService) {
+	s.auditService = auditService
+}
+
+// SetMFAService 二段階ログインに使用するMFAサービスを設定
+func (s *AuthService) SetMFAService(mfaService *MFAService) {
+	s.mfaService = mfaService
+}
+
+// SetSessionService ログインセッションの記録を有効化
+func (s *AuthService) SetSessionService(sessionService *SessionService) {
+	s.sessionService = sessionService
+}
+
+// SetPasswordPolicyService パスワード変更時に適用するパスワードポリシーを設定
+func (s *AuthService) SetPasswordPolicyService(passwordPolicy *PasswordPolicyService) {
+	s.passwordPolicy = passwordPolicy
+}
+
+// SetLockoutPolicy アカウントロックアウト設定を変更
+func (s *AuthService) SetLockoutPolicy(policy LockoutPolicy) {
+	s.lockoutPolicy = policy
+}
+
 // LoginRequest ログインリクエストデータ
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+
+	// 監査記録用のリクエスト元情報
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 // LoginResponse ログインレスポンスデータ
 type LoginResponse struct {
-	Token       string        `json:"token"`
-	ExpiresIn   time.Duration `json:"expires_in"`
-	User        UserInfo      `json:"user"`
-	Permissions []string      `json:"permissions"`
+	Token        string        `json:"token"`
+	RefreshToken string        `json:"refresh_token"`
+	ExpiresIn    time.Duration `json:"expires_in"`
+	User         UserInfo      `json:"user"`
+	Permissions  []string      `json:"permissions"`
+
+	// MFAが必要な場合はトークンを発行せずチャレンジトークンのみ返す（ExpiresInはチャレンジの有効期間）
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAChallengeToken     string   `json:"mfa_challenge_token,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"` // MFA登録完了時のみ
+
+	// パスワードの有効期限切れ（ログインは許可し、クライアントにパスワード変更を促す）
+	PasswordExpired bool `json:"password_expired,omitempty"`
 }
 
 // UserInfo レスポンス用ユーザー情報（複数ロール対応）
@@ -76,13 +130,11 @@ type DeptInfo struct {
 	Name string    `json:"name"`
 }
 
-// Login ユーザー認証を行いJWTトークンを返す
+// Login ユーザー認証を行いアクセストークンとリフレッシュトークンを返す
+// 連続失敗でアカウントをロックし、すべての試行を監査ログに記録する
 func (s *AuthService) Login(req LoginRequest) (*LoginResponse, error) {
 	// TODO: セキュリティ強化
-	// - レート制限 (IP/ユーザー別ログイン試行回数制限)
-	// - ブルートフォース攻撃対策 (アカウントロックアウト)
-	// - ログイン履歴記録 (IP、User-Agent、成功/失敗)
-	// - MFA (多要素認証) 対応
+	// - レート制限 (IP別ログイン試行回数制限)
 
 	// Find user by email（複数ロール対応）
 	var user models.User
@@ -90,159 +142,61 @@ func (s *AuthService) Login(req LoginRequest) (*LoginResponse, error) {
 		Preload("UserRoles.Role").
 		Where("email = ?", req.Email).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			// TODO: タイミング攻撃対策 - 常に一定時間でレスポンス
+			// 存在しないメールアドレスでも同じ時間がかかるようダミーハッシュと比較
+			s.compareDummyPassword(req.Password)
+			s.recordLoginAttempt(req.IPAddress, req.UserAgent, nil, models.AuditResultDenied, models.ReasonCodeAuthBadCredentials, "unknown email")
 			return nil, errors.NewAuthenticationError("invalid email or password")
 		}
 		return nil, errors.NewDatabaseError(err)
 	}
 
-	// Check if user is active
-	if user.Status != models.UserStatusActive {
-		return nil, errors.NewAuthenticationError("user account is not active")
+	// ロックアウト中はパスワードを検証しない（失敗回数も加算しない）
+	if user.IsLockedOut() {
+		s.recordLoginAttempt(req.IPAddress, req.UserAgent, &user.ID, models.AuditResultDenied, models.ReasonCodeAuthAccountLocked, "account is locked")
+		return nil, errors.NewAccountLockedError(*user.LockedUntil)
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, errors.NewAuthenticationError("invalid email or password")
-	}
-
-	// Get user permissions（複数ロール対応）
-	permissions, err := s.permissionService.GetUserPermissions(user.ID)
-	if err != nil {
-		return nil, errors.NewDatabaseError(err)
-	}
-
-	// アクティブロール情報を取得
-	activeRoles, err := user.GetActiveRoles(s.db)
-	if err != nil {
-		return nil, errors.NewDatabaseError(err)
-	}
-
-	// 最高優先度ロールを取得
-	highestRole, err := user.GetHighestPriorityRole(s.db)
-	if err != nil && err != gorm.ErrRecordNotFound {
-		return nil, errors.NewDatabaseError(err)
-	}
-
-	// JWT用のロール情報を構築
-	jwtActiveRoles := make([]jwt.RoleInfo, len(activeRoles))
-	for i, role := range activeRoles {
-		// 対応するUserRoleから期限と優先度を取得
-		var priority int
-		var validTo *time.Time
-		for _, ur := range user.UserRoles {
-			if ur.RoleID == role.ID && ur.IsValidNow() {
-				priority = ur.Priority
-				validTo = ur.ValidTo
-				break
-			}
+		lockedUntil, lockErr := s.registerLoginFailure(&user)
+		if lockErr != nil {
+			return nil, lockErr
 		}
-		jwtActiveRoles[i] = jwt.RoleInfo{
-			ID:       role.ID,
-			Name:     role.Name,
-			Priority: priority,
-			ValidTo:  validTo,
+		if lockedUntil != nil {
+			s.recordLoginAttempt(req.IPAddress, req.UserAgent, &user.ID, models.AuditResultDenied, models.ReasonCodeAuthAccountLocked, "invalid password; account locked")
+			return nil, errors.NewAccountLockedError(*lockedUntil)
 		}
+		s.recordLoginAttempt(req.IPAddress, req.UserAgent, &user.ID, models.AuditResultDenied, models.ReasonCodeAuthBadCredentials, "invalid password")
+		return nil, errors.NewAuthenticationError("invalid email or password")
 	}
 
-	var jwtHighestRole *jwt.RoleInfo
-	if highestRole != nil {
-		// 最高優先度ロールの詳細情報を取得
-		var priority int
-		var validTo *time.Time
-		for _, ur := range user.UserRoles {
-			if ur.RoleID == highestRole.ID && ur.IsValidNow() {
-				priority = ur.Priority
-				validTo = ur.ValidTo
-				break
-			}
-		}
-		jwtHighestRole = &jwt.RoleInfo{
-			ID:       highestRole.ID,
-			Name:     highestRole.Name,
-			Priority: priority,
-			ValidTo:  validTo,
-		}
-	}
-
-	// Generate JWT token（複数ロール対応）
-	token, err := s.jwtService.GenerateToken(
-		user.ID,
-		user.Email,
-		permissions,
-		user.PrimaryRoleID,
-		jwtActiveRoles,
-		jwtHighestRole,
-	)
-	if err != nil {
-		return nil, errors.NewInternalError("failed to generate token")
-	}
-
-	// Prepare response（複数ロール対応）
-	userInfo := UserInfo{
-		ID:     user.ID,
-		Name:   user.Name,
-		Email:  user.Email,
-		Status: string(user.Status),
-		Department: DeptInfo{
-			ID:   user.Department.ID,
-			Name: user.Department.Name,
-		},
-	}
-
-	// プライマリロール情報を設定
-	if user.PrimaryRole != nil {
-		userInfo.PrimaryRole = &RoleInfo{
-			ID:   user.PrimaryRole.ID,
-			Name: user.PrimaryRole.Name,
-		}
+	// Check if user is active（パスワード検証後に判定してアカウント状態を漏らさない）
+	if user.Status != models.UserStatusActive {
+		s.recordLoginAttempt(req.IPAddress, req.UserAgent, &user.ID, models.AuditResultDenied, models.ReasonCodeAuthAccountInactive, "user account is not active")
+		return nil, errors.NewAuthenticationError("user account is not active")
 	}
 
-	// アクティブロール情報を設定
-	userInfo.ActiveRoles = make([]RoleInfo, len(activeRoles))
-	for i, role := range activeRoles {
-		var priority int
-		var validTo *time.Time
-		for _, ur := range user.UserRoles {
-			if ur.RoleID == role.ID && ur.IsValidNow() {
-				priority = ur.Priority
-				validTo = ur.ValidTo
-				break
-			}
+	// MFAが必要な場合はチャレンジトークンを返し、/auth/mfa/verify でトークンを発行する
+	if s.mfaService != nil {
+		required, enrolled, err := s.mfaService.GetRequirement(&user)
+		if err != nil {
+			return nil, err
 		}
-		userInfo.ActiveRoles[i] = RoleInfo{
-			ID:       role.ID,
-			Name:     role.Name,
-			Priority: priority,
-			ValidTo:  validTo,
-		}
-	}
-
-	// 最高優先度ロール情報を設定
-	if highestRole != nil {
-		var priority int
-		var validTo *time.Time
-		for _, ur := range user.UserRoles {
-			if ur.RoleID == highestRole.ID && ur.IsValidNow() {
-				priority = ur.Priority
-				validTo = ur.ValidTo
-				break
+		if required {
+			challengeToken, err := s.mfaService.CreateChallenge(user.ID)
+			if err != nil {
+				return nil, err
 			}
-		}
-		userInfo.HighestRole = &RoleInfo{
-			ID:       highestRole.ID,
-			Name:     highestRole.Name,
-			Priority: priority,
-			ValidTo:  validTo,
+			return &LoginResponse{
+				ExpiresIn:             s.mfaService.ChallengeDuration(),
+				MFARequired:           true,
+				MFAChallengeToken:     challengeToken,
+				MFAEnrollmentRequired: !enrolled,
+			}, nil
 		}
 	}
 
-	return &LoginResponse{
-		Token:       token,
-		ExpiresIn:   24 * time.Hour, // Should match JWT config
-		User:        userInfo,
-		Permissions: permissions,
-	}, nil
+	return s.completeLogin(&user, req.IPAddress, req.UserAgent)
 }
 
 // Logout 現在のJWTトークンを無効化
@@ -255,85 +209,51 @@ func (s *AuthService) LogoutAllSessions(userID uuid.UUID) error {
 	return s.revocationService.RevokeAllUserTokens(userID, "logout_all_sessions")
 }
 
-// RefreshToken 既存の有効なトークンから新しいトークンを生成
-func (s *AuthService) RefreshToken(currentToken string) (*LoginResponse, error) {
-	// Validate current token
-	claims, err := s.jwtService.ValidateToken(currentToken)
+// RefreshToken リフレッシュトークンをローテーションして新しいトークンペアを発行
+// 使用済みのリフレッシュトークンが再提示された場合はファミリー全体を無効化する
+func (s *AuthService) RefreshToken(refreshToken string) (*LoginResponse, error) {
+	consumed, rotatedToken, err := s.refreshTokenService.RotateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, errors.NewAuthenticationError("invalid token")
-	}
-
-	// Check if token is revoked
-	if err := s.revocationService.ValidateTokenStatus(claims.ID, claims.UserID, claims.IssuedAt.Time); err != nil {
-		return nil, errors.NewAuthenticationError("token is revoked")
+		return nil, err
 	}
 
-	// Get updated user info and permissions
-	var user models.User
-	if err := s.db.Preload("PrimaryRole").Preload("Department").
-		First(&user, claims.UserID).Error; err != nil {
-		return nil, errors.NewDatabaseError(err)
+	// Get updated user info（複数ロール対応）
+	user, err := s.loadLoginUser(consumed.UserID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check if user is still active
 	if user.Status != models.UserStatusActive {
+		if err := s.refreshTokenService.RevokeRefreshToken(rotatedToken, "user_inactive"); err != nil {
+			return nil, err
+		}
 		return nil, errors.NewAuthenticationError("user account is no longer active")
 	}
 
-	// Get current permissions
-	permissions, err := s.permissionService.GetUserPermissions(user.ID)
+	// 最新の権限・ロールでアクセストークンを再発行
+	response, err := s.issueAccessToken(user)
 	if err != nil {
 		return nil, err
 	}
+	response.RefreshToken = rotatedToken
 
-	// Revoke old token
-	if err := s.revocationService.RevokeToken(claims.ID, claims.UserID, "token_refresh"); err != nil {
-		return nil, err
-	}
-
-	// Generate new token（複数ロール対応）
-	newToken, err := s.jwtService.GenerateTokenSimple(user.ID, user.Email, permissions)
-	if err != nil {
-		return nil, errors.NewInternalError("failed to generate new token")
-	}
-
-	// Prepare response
-	userInfo := UserInfo{
-		ID:     user.ID,
-		Name:   user.Name,
-		Email:  user.Email,
-		Status: string(user.Status),
-		Department: DeptInfo{
-			ID:   user.Department.ID,
-			Name: user.Department.Name,
-		},
-	}
-
-	// プライマリロール情報を設定
-	if user.PrimaryRole != nil {
-		userInfo.PrimaryRole = &RoleInfo{
-			ID:   user.PrimaryRole.ID,
-			Name: user.PrimaryRole.Name,
+	if s.sessionService != nil {
+		jti, err := s.jwtService.GetTokenID(response.Token)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to read token id")
+		}
+		if err := s.sessionService.TouchSession(consumed.FamilyID, jti); err != nil {
+			return nil, err
 		}
 	}
 
-	return &LoginResponse{
-		Token:       newToken,
-		ExpiresIn:   24 * time.Hour,
-		User:        userInfo,
-		Permissions: permissions,
-	}, nil
+	return response, nil
 }
 
 // ChangePassword ユーザーパスワードを変更
+// 新しいパスワードはパスワードポリシーと履歴で検証し、変更後は既存トークンをすべて無効化する
 func (s *AuthService) ChangePassword(userID uuid.UUID, currentPassword, newPassword string) error {
-	// TODO: パスワードポリシー強化
-	// - 最小長度 (8文字以上)
-	// - 複雑性要件 (大文字、小文字、数字、特殊文字)
-	// - 過去のパスワード履歴チェック (直近N回と重複禁止)
-	// - 辞書攻撃対策 (一般的なパスワードの禁止)
-	// - パスワード強度スコア計算
-
 	// Get user
 	var user models.User
 	if err := s.db.First(&user, userID).Error; err != nil {
@@ -345,20 +265,20 @@ func (s *AuthService) ChangePassword(userID uuid.UUID, currentPassword, newPassw
 		return errors.NewAuthenticationError("current password is incorrect")
 	}
 
-	// TODO: パスワード強度バリデーション実装
-	// if !isStrongPassword(newPassword) {
-	//     return errors.NewValidationError("password", "password does not meet security requirements")
-	// }
+	if err := s.passwordPolicy.ValidateNewPassword("new_password", newPassword, user.Email, &user); err != nil {
+		return err
+	}
 
 	// Hash new password
-	// TODO: bcrypt cost調整 (現在は10、本番では12-14推奨)
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwordPolicy.HashPassword(newPassword)
 	if err != nil {
-		return errors.NewInternalError("failed to hash password")
+		return err
 	}
 
-	// Update password
-	if err := s.db.Model(&user).Update("password_hash", string(hashedPassword)).Error; err != nil {
+	// Update password and history
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.passwordPolicy.SetPassword(tx, &user, hashedPassword)
+	}); err != nil {
 		return errors.NewDatabaseError(err)
 	}
 
@@ -438,20 +358,146 @@ func (s *AuthService) GetUserInfo(userID uuid.UUID) (*UserInfo, error) {
 		return nil, errors.NewDatabaseError(err)
 	}
 
-	// アクティブロール情報を取得
-	activeRoles, err := user.GetActiveRoles(s.db)
+	activeRoles, highestRole, err := s.loadActiveRoles(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo := buildUserInfo(&user, activeRoles, highestRole)
+	return &userInfo, nil
+}
+
+// LogoutWithToken ログアウト処理（リフレッシュトークンのファミリーを無効化）
+func (s *AuthService) LogoutWithToken(refreshToken string) error {
+	return s.refreshTokenService.RevokeRefreshToken(refreshToken, "logout")
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// completeLogin 認証完了時の共通処理（失敗回数リセット・トークン発行・監査記録）
+func (s *AuthService) completeLogin(user *models.User, ipAddress, userAgent string) (*LoginResponse, error) {
+	if err := s.registerLoginSuccess(user); err != nil {
+		return nil, err
+	}
+
+	response, err := s.issueAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	// ログイン単位の新しいファミリーでリフレッシュトークンを発行
+	refreshToken, familyID, err := s.refreshTokenService.IssueRefreshToken(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	response.RefreshToken = refreshToken
+
+	if s.sessionService != nil {
+		jti, err := s.jwtService.GetTokenID(response.Token)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to read token id")
+		}
+		if err := s.sessionService.StartSession(user.ID, familyID, jti, ipAddress, userAgent); err != nil {
+			return nil, err
+		}
+	}
+	response.PasswordExpired = s.passwordPolicy.Policy().IsExpired(user)
+
+	s.recordLoginAttempt(ipAddress, userAgent, &user.ID, models.AuditResultSuccess, "", "")
+	return response, nil
+}
+
+// loadLoginUser トークン発行に必要な関連データ付きでユーザーを取得
+func (s *AuthService) loadLoginUser(userID uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := s.db.Preload("PrimaryRole").Preload("Department").
+		Preload("UserRoles.Role").
+		Where("id = ?", userID).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+	return &user, nil
+}
+
+// issueAccessToken ユーザーの最新の権限・ロール情報でアクセストークンを発行
+// ユーザーはPrimaryRole・Department・UserRoles.RoleをPreload済みであること
+func (s *AuthService) issueAccessToken(user *models.User) (*LoginResponse, error) {
+	// Get user permissions（複数ロール対応）
+	permissions, err := s.permissionService.GetUserPermissions(user.ID)
 	if err != nil {
 		return nil, errors.NewDatabaseError(err)
 	}
 
-	// 最高優先度ロールを取得
+	activeRoles, highestRole, err := s.loadActiveRoles(user)
+	if err != nil {
+		return nil, err
+	}
+	userInfo := buildUserInfo(user, activeRoles, highestRole)
+
+	// JWT用のロール情報を構築
+	jwtActiveRoles := make([]jwt.RoleInfo, len(userInfo.ActiveRoles))
+	for i, role := range userInfo.ActiveRoles {
+		jwtActiveRoles[i] = jwt.RoleInfo{
+			ID:       role.ID,
+			Name:     role.Name,
+			Priority: role.Priority,
+			ValidTo:  role.ValidTo,
+		}
+	}
+
+	var jwtHighestRole *jwt.RoleInfo
+	if userInfo.HighestRole != nil {
+		jwtHighestRole = &jwt.RoleInfo{
+			ID:       userInfo.HighestRole.ID,
+			Name:     userInfo.HighestRole.Name,
+			Priority: userInfo.HighestRole.Priority,
+			ValidTo:  userInfo.HighestRole.ValidTo,
+		}
+	}
+
+	// Generate JWT token（複数ロール対応）
+	token, err := s.jwtService.GenerateToken(
+		user.ID,
+		user.Email,
+		permissions,
+		user.PrimaryRoleID,
+		jwtActiveRoles,
+		jwtHighestRole,
+	)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to generate token")
+	}
+
+	return &LoginResponse{
+		Token:       token,
+		ExpiresIn:   s.jwtService.ExpiresIn(),
+		User:        userInfo,
+		Permissions: permissions,
+	}, nil
+}
+
+// loadActiveRoles アクティブロールと最高優先度ロールを取得
+func (s *AuthService) loadActiveRoles(user *models.User) ([]models.Role, *models.Role, error) {
+	activeRoles, err := user.GetActiveRoles(s.db)
+	if err != nil {
+		return nil, nil, errors.NewDatabaseError(err)
+	}
+
 	highestRole, err := user.GetHighestPriorityRole(s.db)
 	if err != nil && err != gorm.ErrRecordNotFound {
-		return nil, errors.NewDatabaseError(err)
+		return nil, nil, errors.NewDatabaseError(err)
 	}
 
-	// UserInfoを構築
-	userInfo := &UserInfo{
+	return activeRoles, highestRole, nil
+}
+
+// buildUserInfo レスポンス用ユーザー情報を構築（優先度・期限は有効なUserRoleから取得）
+func buildUserInfo(user *models.User, activeRoles []models.Role, highestRole *models.Role) UserInfo {
+	userInfo := UserInfo{
 		ID:     user.ID,
 		Name:   user.Name,
 		Email:  user.Email,
@@ -473,54 +519,30 @@ func (s *AuthService) GetUserInfo(userID uuid.UUID) (*UserInfo, error) {
 	// アクティブロール情報を設定
 	userInfo.ActiveRoles = make([]RoleInfo, len(activeRoles))
 	for i, role := range activeRoles {
-		// 対応するUserRoleから期限と優先度を取得
-		var priority int
-		var validTo *time.Time
-		for _, ur := range user.UserRoles {
-			if ur.RoleID == role.ID && ur.IsValidNow() {
-				priority = ur.Priority
-				validTo = ur.ValidTo
-				break
-			}
-		}
-		userInfo.ActiveRoles[i] = RoleInfo{
-			ID:       role.ID,
-			Name:     role.Name,
-			Priority: priority,
-			ValidTo:  validTo,
-		}
+		userInfo.ActiveRoles[i] = buildRoleInfo(user, &role)
 	}
 
 	// 最高優先度ロール情報を設定
 	if highestRole != nil {
-		var priority int
-		var validTo *time.Time
-		for _, ur := range user.UserRoles {
-			if ur.RoleID == highestRole.ID && ur.IsValidNow() {
-				priority = ur.Priority
-				validTo = ur.ValidTo
-				break
-			}
-		}
-		userInfo.HighestRole = &RoleInfo{
-			ID:       highestRole.ID,
-			Name:     highestRole.Name,
-			Priority: priority,
-			ValidTo:  validTo,
-		}
+		roleInfo := buildRoleInfo(user, highestRole)
+		userInfo.HighestRole = &roleInfo
 	}
 
-	return userInfo, nil
+	return userInfo
 }
 
-// LogoutWithToken ログアウト処理（リフレッシュトークン無効化）
-func (s *AuthService) LogoutWithToken(refreshToken string) error {
-	// JWTトークンを検証してJTIを取得
-	claims, err := s.jwtService.ValidateToken(refreshToken)
-	if err != nil {
-		return errors.ErrInvalidToken
+// buildRoleInfo 対応するUserRoleから期限と優先度を取得してロール情報を構築
+func buildRoleInfo(user *models.User, role *models.Role) RoleInfo {
+	roleInfo := RoleInfo{
+		ID:   role.ID,
+		Name: role.Name,
+	}
+	for _, ur := range user.UserRoles {
+		if ur.RoleID == role.ID && ur.IsValidNow() {
+			roleInfo.Priority = ur.Priority
+			roleInfo.ValidTo = ur.ValidTo
+			break
+		}
 	}
-
-	// トークンを無効化
-	return s.revocationService.RevokeToken(claims.ID, claims.UserID, "logout")
+	return roleInfo
 }
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+)
+
+// loginAuditResourceID ログイン試行の監査ログに記録するリソースID
+const loginAuditResourceID = "/api/v1/auth/login"
+
+// LockoutPolicy ログイン失敗によるアカウントロックアウト設定
+type LockoutPolicy struct {
+	Threshold   int           // ロックを開始する連続失敗回数
+	Duration    time.Duration // 初回のロック時間（以降の失敗ごとに倍増）
+	MaxDuration time.Duration // ロック時間の上限
+}
+
+// DefaultLockoutPolicy デフォルトのロックアウト設定
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		Threshold:   5,
+		Duration:    time.Minute,
+		MaxDuration: time.Hour,
+	}
+}
+
+// LockDuration 連続失敗回数に応じたロック時間を計算（閾値未満は0）
+func (p LockoutPolicy) LockDuration(failedAttempts int) time.Duration {
+	if p.Threshold <= 0 || failedAttempts < p.Threshold {
+		return 0
+	}
+
+	duration := p.Duration
+	for i := p.Threshold; i < failedAttempts; i++ {
+		duration *= 2
+		if p.MaxDuration > 0 && duration >= p.MaxDuration {
+			return p.MaxDuration
+		}
+	}
+	if p.MaxDuration > 0 && duration > p.MaxDuration {
+		return p.MaxDuration
+	}
+	return duration
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// compareDummyPassword 存在しないユーザーでも実ユーザーと同等の時間をかけるためのダミー比較
+func (s *AuthService) compareDummyPassword(password string) {
+	s.dummyPasswordHashOnce.Do(func() {
+		s.dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), s.passwordPolicy.Policy().BcryptCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(s.dummyPasswordHash, []byte(password))
+}
+
+// registerLoginFailure 失敗回数を加算し、閾値に達した場合はロック期限を設定
+// ロックを開始した場合はロック期限を返す
+func (s *AuthService) registerLoginFailure(user *models.User) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// ユーザーモデルのフックを経由せずに更新
+		// 同時に失敗したリクエストの加算も含めるため、加算後の値を読み直してロック時間を計算
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			UpdateColumn("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error; err != nil {
+			return err
+		}
+		var failedAttempts int
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Select("failed_login_attempts").Scan(&failedAttempts).Error; err != nil {
+			return err
+		}
+		user.FailedLoginAttempts = failedAttempts
+
+		duration := s.lockoutPolicy.LockDuration(failedAttempts)
+		if duration <= 0 {
+			return nil
+		}
+		until := time.Now().Add(duration)
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumn("locked_until", until).Error; err != nil {
+			return err
+		}
+		lockedUntil = &until
+		return nil
+	})
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	return lockedUntil, nil
+}
+
+// registerLoginSuccess 失敗回数とロックを解除し最終ログイン日時を更新
+func (s *AuthService) registerLoginSuccess(user *models.User) error {
+	now := time.Now()
+	if err := s.db.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumns(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+		"last_login_at":         now,
+	}).Error; err != nil {
+		return errors.NewDatabaseError(err)
+	}
+
+	user.FailedLoginAttempts = 0
+	user.LockedUntil = nil
+	user.LastLoginAt = &now
+	return nil
+}
+
+// recordLoginAttempt ログイン試行を監査ログに記録
+func (s *AuthService) recordLoginAttempt(ipAddress, userAgent string, userID *uuid.UUID, result models.AuditResult, reasonCode, reason string) {
+	if s.auditService == nil {
+		return
+	}
+
+	// 監査記録の失敗でログイン結果は変えない
+	_, _ = s.auditService.Record(AuditEntry{
+		UserID:       userID,
+		Action:       "login",
+		ResourceType: "auth",
+		ResourceID:   loginAuditResourceID,
+		Result:       result,
+		Reason:       reason,
+		ReasonCode:   reasonCode,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+	})
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+)
+
+// VerifyMFARequest 二段階ログインのMFA検証リクエストデータ
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`          // 認証アプリのTOTPコード
+	RecoveryCode   string `json:"recovery_code"` // 認証アプリを使用できない場合のリカバリーコード
+
+	// 監査記録用のリクエスト元情報
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// VerifyMFA チャレンジトークンとMFAコードを検証してアクセストークンとリフレッシュトークンを返す
+func (s *AuthService) VerifyMFA(req VerifyMFARequest) (*LoginResponse, error) {
+	if s.mfaService == nil {
+		return nil, errors.NewInternalError("MFA is not configured")
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		return nil, errors.NewValidationError("code", "code or recovery_code is required")
+	}
+
+	challenge, err := s.mfaService.ResolveChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaService.VerifyCode(challenge.UserID, req.Code, req.RecoveryCode); err != nil {
+		if errors.IsAuthenticationError(err) {
+			if failErr := s.mfaService.RecordChallengeFailure(challenge); failErr != nil {
+				return nil, failErr
+			}
+			s.recordLoginAttempt(req.IPAddress, req.UserAgent, &challenge.UserID, models.AuditResultDenied, models.ReasonCodeAuthMFAInvalid, "invalid MFA code")
+		}
+		return nil, err
+	}
+
+	return s.completeMFAChallenge(challenge, req)
+}
+
+// BeginMFAEnrollment チャレンジトークンでMFA登録を開始（MFA必須ロールで未登録のユーザー用）
+func (s *AuthService) BeginMFAEnrollment(challengeToken string) (*MFAEnrollmentResponse, error) {
+	if s.mfaService == nil {
+		return nil, errors.NewInternalError("MFA is not configured")
+	}
+
+	challenge, err := s.mfaService.ResolveChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.mfaService.BeginEnrollment(challenge.UserID)
+}
+
+// ConfirmMFAEnrollment 確認コードでMFA登録を完了し、トークンとリカバリーコードを返す
+func (s *AuthService) ConfirmMFAEnrollment(req VerifyMFARequest) (*LoginResponse, error) {
+	if s.mfaService == nil {
+		return nil, errors.NewInternalError("MFA is not configured")
+	}
+
+	challenge, err := s.mfaService.ResolveChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.mfaService.ConfirmEnrollment(challenge.UserID, req.Code)
+	if err != nil {
+		if errors.IsValidationError(err) {
+			if failErr := s.mfaService.RecordChallengeFailure(challenge); failErr != nil {
+				return nil, failErr
+			}
+		}
+		return nil, err
+	}
+
+	response, err := s.completeMFAChallenge(challenge, req)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes.RecoveryCodes
+	return response, nil
+}
+
+// completeMFAChallenge チャレンジを使用済みにしてログインを完了
+func (s *AuthService) completeMFAChallenge(challenge *models.MFAChallenge, req VerifyMFARequest) (*LoginResponse, error) {
+	if err := s.mfaService.CompleteChallenge(challenge); err != nil {
+		return nil, err
+	}
+
+	user, err := s.loadLoginUser(challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// チャレンジ発行後に無効化・ロックされた場合は発行しない
+	if user.Status != models.UserStatusActive {
+		s.recordLoginAttempt(req.IPAddress, req.UserAgent, &user.ID, models.AuditResultDenied, models.ReasonCodeAuthAccountInactive, "user account is not active")
+		return nil, errors.NewAuthenticationError("user account is not active")
+	}
+	if user.IsLockedOut() {
+		s.recordLoginAttempt(req.IPAddress, req.UserAgent, &user.ID, models.AuditResultDenied, models.ReasonCodeAuthAccountLocked, "account is locked")
+		return nil, errors.NewAccountLockedError(*user.LockedUntil)
+	}
+
+	return s.completeLogin(user, req.IPAddress, req.UserAgent)
+}
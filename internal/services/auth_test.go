@@ -0,0 +1,562 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/jwt"
+	"erp-access-control-go/pkg/logger"
+	"erp-access-control-go/pkg/totp"
+)
+
+// setupTestAuth 認証テスト用のサービスとDBを作成
+func setupTestAuth(t *testing.T) (*AuthService, *gorm.DB) {
+	_, db := setupTestApproval(t)
+
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS permissions (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			module TEXT NOT NULL,
+			action TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS role_permissions (
+			role_id TEXT NOT NULL,
+			permission_id TEXT NOT NULL,
+			effect TEXT NOT NULL DEFAULT 'allow',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (role_id, permission_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS revoked_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			token_jti TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			revoked_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			expires_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS refresh_tokens (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			user_id TEXT NOT NULL,
+			family_id TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			expires_at DATETIME NOT NULL,
+			used_at DATETIME,
+			revoked_at DATETIME,
+			revoked_reason TEXT
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_mfa (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			user_id TEXT NOT NULL UNIQUE,
+			secret TEXT NOT NULL,
+			confirmed_at DATETIME,
+			last_used_step INTEGER NOT NULL DEFAULT 0
+		)`,
+		`CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			user_id TEXT NOT NULL,
+			code_hash TEXT NOT NULL UNIQUE,
+			used_at DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS mfa_challenges (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			user_id TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			expires_at DATETIME NOT NULL,
+			failed_attempts INTEGER NOT NULL DEFAULT 0,
+			used_at DATETIME
+		)`,
+		`CREATE TABLE IF NOT EXISTS password_history (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			user_id TEXT NOT NULL,
+			password_hash TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS password_reset_tokens (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			user_id TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			expires_at DATETIME NOT NULL,
+			used_at DATETIME,
+			requested_ip TEXT
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_sessions (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			user_id TEXT NOT NULL,
+			family_id TEXT NOT NULL UNIQUE,
+			current_jti TEXT NOT NULL,
+			user_agent TEXT,
+			ip_address TEXT,
+			last_seen_at DATETIME NOT NULL,
+			expires_at DATETIME NOT NULL,
+			revoked_at DATETIME,
+			revoked_reason TEXT
+		)`,
+	}
+	for _, statement := range statements {
+		require.NoError(t, db.Exec(statement).Error)
+	}
+	for _, table := range []string{"refresh_tokens", "revoked_tokens", "user_mfa", "mfa_recovery_codes", "mfa_challenges", "password_history", "password_reset_tokens", "user_sessions"} {
+		db.Exec("DELETE FROM " + table)
+	}
+
+	auditService, _ := setupTestAudit(t)
+
+	appLogger := logger.NewLogger()
+	service := NewAuthService(
+		db,
+		jwt.NewService("test-secret", 15*time.Minute),
+		NewPermissionService(db, appLogger),
+		NewTokenRevocationService(db),
+		NewRefreshTokenService(db, time.Hour),
+	)
+	service.SetAuditService(auditService)
+	service.SetMFAService(NewMFAService(db, appLogger, "ERP Test", 5*time.Minute))
+	return service, db
+}
+
+// createAuthTestUser パスワード付きのユーザーを作成
+func createAuthTestUser(t *testing.T, db *gorm.DB, email, password string) uuid.UUID {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	err = db.Exec("INSERT INTO users (id, name, email, password_hash, status) VALUES (?, ?, ?, ?, 'active')",
+		userID.String(), email, email, string(hash)).Error
+	require.NoError(t, err)
+	return userID
+}
+
+func TestAuthService_RefreshTokenRotation(t *testing.T) {
+	service, db := setupTestAuth(t)
+
+	userID := createAuthTestUser(t, db, "refresh@example.com", "password123")
+	roleID := createApprovalTestRole(t, db, "営業担当")
+	assignApprovalTestRole(t, db, userID, roleID)
+
+	login, err := service.Login(LoginRequest{Email: "refresh@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.NotEmpty(t, login.RefreshToken)
+	assert.Equal(t, 15*time.Minute, login.ExpiresIn)
+
+	var stored models.RefreshToken
+	require.NoError(t, db.Where("user_id = ?", userID).First(&stored).Error)
+	assert.Equal(t, models.HashRefreshToken(login.RefreshToken), stored.TokenHash, "トークン本体は保存しない")
+
+	refreshed, err := service.RefreshToken(login.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken, "使用のたびに再発行")
+
+	t.Run("再発行したアクセストークンにロール情報を保持", func(t *testing.T) {
+		claims, err := service.jwtService.ValidateToken(refreshed.Token)
+		require.NoError(t, err)
+		require.Len(t, claims.ActiveRoles, 1)
+		assert.Equal(t, "営業担当", claims.ActiveRoles[0].Name)
+		require.NotNil(t, claims.HighestRole)
+		assert.Equal(t, roleID, claims.HighestRole.ID)
+	})
+
+	t.Run("使用済みトークンの再提示でファミリー全体を無効化", func(t *testing.T) {
+		_, err := service.RefreshToken(login.RefreshToken)
+		assert.True(t, errors.IsAuthenticationError(err))
+
+		_, err = service.RefreshToken(refreshed.RefreshToken)
+		require.Error(t, err, "正規の最新トークンも使用不可")
+
+		var active int64
+		require.NoError(t, db.Model(&models.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", stored.FamilyID).Count(&active).Error)
+		assert.Equal(t, int64(0), active)
+	})
+
+	t.Run("ログアウトと全セッション無効化", func(t *testing.T) {
+		first, err := service.Login(LoginRequest{Email: "refresh@example.com", Password: "password123"})
+		require.NoError(t, err)
+		second, err := service.Login(LoginRequest{Email: "refresh@example.com", Password: "password123"})
+		require.NoError(t, err)
+
+		require.NoError(t, service.LogoutWithToken(first.RefreshToken))
+		_, err = service.RefreshToken(first.RefreshToken)
+		require.Error(t, err)
+
+		require.NoError(t, service.LogoutAllSessions(userID))
+		_, err = service.RefreshToken(second.RefreshToken)
+		require.Error(t, err)
+	})
+
+	t.Run("未発行のトークンは無効", func(t *testing.T) {
+		_, err := service.RefreshToken("unknown-token")
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrCodeInvalidToken, err.(*errors.APIError).Code)
+	})
+}
+
+func TestLockoutPolicy_LockDuration(t *testing.T) {
+	policy := LockoutPolicy{Threshold: 3, Duration: time.Minute, MaxDuration: 10 * time.Minute}
+
+	assert.Equal(t, time.Duration(0), policy.LockDuration(2), "閾値未満はロックしない")
+	assert.Equal(t, time.Minute, policy.LockDuration(3))
+	assert.Equal(t, 2*time.Minute, policy.LockDuration(4), "失敗ごとに倍増")
+	assert.Equal(t, 8*time.Minute, policy.LockDuration(6))
+	assert.Equal(t, 10*time.Minute, policy.LockDuration(7), "上限で打ち止め")
+	assert.Equal(t, 10*time.Minute, policy.LockDuration(100))
+}
+
+func TestAuthService_LoginLockout(t *testing.T) {
+	service, db := setupTestAuth(t)
+	service.SetLockoutPolicy(LockoutPolicy{Threshold: 3, Duration: time.Minute, MaxDuration: time.Hour})
+
+	userID := createAuthTestUser(t, db, "lockout@example.com", "password123")
+	wrong := LoginRequest{Email: "lockout@example.com", Password: "wrong-password", IPAddress: "192.0.2.1", UserAgent: "test-agent"}
+
+	countLoginAudits := func(reasonCode string) int64 {
+		var count int64
+		require.NoError(t, db.Model(&models.AuditLog{}).
+			Where("action = ? AND reason_code = ?", "login", reasonCode).Count(&count).Error)
+		return count
+	}
+
+	t.Run("閾値未満の失敗は認証エラー", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := service.Login(wrong)
+			require.Error(t, err)
+			assert.True(t, errors.IsAuthenticationError(err))
+			assert.False(t, errors.IsAccountLockedError(err))
+		}
+		assert.Equal(t, int64(2), countLoginAudits(models.ReasonCodeAuthBadCredentials))
+	})
+
+	t.Run("閾値到達でロックし正しいパスワードも拒否", func(t *testing.T) {
+		_, err := service.Login(wrong)
+		assert.True(t, errors.IsAccountLockedError(err))
+
+		_, err = service.Login(LoginRequest{Email: "lockout@example.com", Password: "password123"})
+		assert.True(t, errors.IsAccountLockedError(err))
+		assert.Equal(t, int64(2), countLoginAudits(models.ReasonCodeAuthAccountLocked))
+
+		var user models.User
+		require.NoError(t, db.First(&user, "id = ?", userID).Error)
+		assert.Equal(t, 3, user.FailedLoginAttempts, "ロック中の試行は加算しない")
+		require.NotNil(t, user.LockedUntil)
+	})
+
+	t.Run("ロック期限切れ後の失敗はロック時間を延長", func(t *testing.T) {
+		require.NoError(t, db.Model(&models.User{}).Where("id = ?", userID).
+			UpdateColumn("locked_until", time.Now().Add(-time.Second)).Error)
+
+		_, err := service.Login(wrong)
+		assert.True(t, errors.IsAccountLockedError(err))
+
+		var user models.User
+		require.NoError(t, db.First(&user, "id = ?", userID).Error)
+		require.NotNil(t, user.LockedUntil)
+		assert.WithinDuration(t, time.Now().Add(2*time.Minute), *user.LockedUntil, 5*time.Second)
+	})
+
+	t.Run("管理者によるロック解除後はログイン可能", func(t *testing.T) {
+		userService := NewUserService(db, logger.NewLogger())
+		_, err := userService.UnlockUser(userID)
+		require.NoError(t, err)
+
+		login, err := service.Login(LoginRequest{Email: "lockout@example.com", Password: "password123", IPAddress: "192.0.2.1"})
+		require.NoError(t, err)
+		require.NotEmpty(t, login.Token)
+
+		var user models.User
+		require.NoError(t, db.First(&user, "id = ?", userID).Error)
+		assert.Equal(t, 0, user.FailedLoginAttempts)
+		assert.Nil(t, user.LockedUntil)
+		assert.NotNil(t, user.LastLoginAt)
+
+		var success models.AuditLog
+		require.NoError(t, db.Where("action = ? AND result = ?", "login", models.AuditResultSuccess).First(&success).Error)
+		require.NotNil(t, success.IPAddress)
+		assert.Equal(t, "192.0.2.1", success.IPAddress.String())
+	})
+
+	t.Run("同時の失敗は加算後の回数でロック", func(t *testing.T) {
+		var stale models.User
+		require.NoError(t, db.First(&stale, "id = ?", userID).Error)
+		require.Equal(t, 0, stale.FailedLoginAttempts)
+
+		// 同じ時点で読み込んだユーザーに対する失敗を閾値まで加算
+		for i := 1; i <= 3; i++ {
+			concurrent := stale
+			lockedUntil, err := service.registerLoginFailure(&concurrent)
+			require.NoError(t, err)
+			assert.Equal(t, i, concurrent.FailedLoginAttempts)
+			if i < 3 {
+				assert.Nil(t, lockedUntil)
+			} else {
+				assert.NotNil(t, lockedUntil, "読み込み時点の回数ではなく加算後の回数で判定")
+			}
+		}
+	})
+
+	t.Run("存在しないメールアドレスも同じエラーで記録", func(t *testing.T) {
+		_, err := service.Login(LoginRequest{Email: "unknown@example.com", Password: "password123"})
+		require.Error(t, err)
+		assert.True(t, errors.IsAuthenticationError(err))
+		assert.Equal(t, "invalid email or password", err.(*errors.APIError).Details.Reason)
+
+		var denied models.AuditLog
+		require.NoError(t, db.Where("action = ? AND reason = ?", "login", "unknown email").First(&denied).Error)
+		assert.Equal(t, models.ReasonCodeAuthBadCredentials, *denied.ReasonCode)
+	})
+}
+
+// enrollAuthTestMFA ユーザーのMFA登録を完了してシークレットとリカバリーコードを返す
+func enrollAuthTestMFA(t *testing.T, service *AuthService, userID uuid.UUID) (string, []string) {
+	enrollment, err := service.mfaService.BeginEnrollment(userID)
+	require.NoError(t, err)
+
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now().Add(-totp.Period))
+	require.NoError(t, err)
+	recovery, err := service.mfaService.ConfirmEnrollment(userID, code)
+	require.NoError(t, err)
+	require.Len(t, recovery.RecoveryCodes, recoveryCodeCount)
+
+	return enrollment.Secret, recovery.RecoveryCodes
+}
+
+func TestAuthService_MFALogin(t *testing.T) {
+	service, db := setupTestAuth(t)
+
+	userID := createAuthTestUser(t, db, "mfa@example.com", "password123")
+	secret, recoveryCodes := enrollAuthTestMFA(t, service, userID)
+
+	var stored models.MFARecoveryCode
+	require.NoError(t, db.Where("user_id = ?", userID).First(&stored).Error)
+	assert.NotContains(t, recoveryCodes, stored.CodeHash, "リカバリーコードはハッシュのみ保存")
+
+	login := func(t *testing.T) *LoginResponse {
+		resp, err := service.Login(LoginRequest{Email: "mfa@example.com", Password: "password123"})
+		require.NoError(t, err)
+		require.True(t, resp.MFARequired)
+		assert.Empty(t, resp.Token, "パスワードのみではトークンを発行しない")
+		assert.False(t, resp.MFAEnrollmentRequired)
+		return resp
+	}
+
+	t.Run("TOTPコードでトークンを発行", func(t *testing.T) {
+		challenge := login(t)
+
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+		resp, err := service.VerifyMFA(VerifyMFARequest{ChallengeToken: challenge.MFAChallengeToken, Code: code})
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Token)
+		assert.NotEmpty(t, resp.RefreshToken)
+
+		_, err = service.VerifyMFA(VerifyMFARequest{ChallengeToken: challenge.MFAChallengeToken, Code: code})
+		assert.True(t, errors.IsAuthenticationError(err), "チャレンジは一度のみ使用可能")
+
+		next := login(t)
+		_, err = service.VerifyMFA(VerifyMFARequest{ChallengeToken: next.MFAChallengeToken, Code: code})
+		assert.True(t, errors.IsAuthenticationError(err), "使用済みのTOTPコードは再利用不可")
+	})
+
+	t.Run("失敗回数の上限でチャレンジを無効化", func(t *testing.T) {
+		challenge := login(t)
+		for i := 0; i < mfaChallengeMaxAttempts; i++ {
+			_, err := service.VerifyMFA(VerifyMFARequest{ChallengeToken: challenge.MFAChallengeToken, Code: "000000"})
+			require.Error(t, err)
+		}
+
+		_, err := service.VerifyMFA(VerifyMFARequest{ChallengeToken: challenge.MFAChallengeToken, RecoveryCode: recoveryCodes[0]})
+		assert.True(t, errors.IsAuthenticationError(err))
+
+		var denied int64
+		require.NoError(t, db.Model(&models.AuditLog{}).Where("reason_code = ?", models.ReasonCodeAuthMFAInvalid).Count(&denied).Error)
+		assert.GreaterOrEqual(t, denied, int64(mfaChallengeMaxAttempts))
+	})
+
+	t.Run("リカバリーコードは一度のみ使用可能", func(t *testing.T) {
+		challenge := login(t)
+		resp, err := service.VerifyMFA(VerifyMFARequest{ChallengeToken: challenge.MFAChallengeToken, RecoveryCode: strings.ToUpper(recoveryCodes[1])})
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Token)
+
+		challenge = login(t)
+		_, err = service.VerifyMFA(VerifyMFARequest{ChallengeToken: challenge.MFAChallengeToken, RecoveryCode: recoveryCodes[1]})
+		assert.True(t, errors.IsAuthenticationError(err))
+
+		status, err := service.mfaService.GetStatus(userID)
+		require.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, int64(recoveryCodeCount-1), status.RecoveryCodesRemaining)
+	})
+
+	t.Run("管理者がリセットするとパスワードのみでログイン", func(t *testing.T) {
+		require.NoError(t, NewUserService(db, logger.NewLogger()).ResetMFA(userID))
+
+		resp, err := service.Login(LoginRequest{Email: "mfa@example.com", Password: "password123"})
+		require.NoError(t, err)
+		assert.False(t, resp.MFARequired)
+		assert.NotEmpty(t, resp.Token)
+	})
+}
+
+func TestAuthService_MFARequiredByRole(t *testing.T) {
+	service, db := setupTestAuth(t)
+
+	userID := createAuthTestUser(t, db, "mfa-admin@example.com", "password123")
+	roleID := createApprovalTestRole(t, db, "MFA必須管理者")
+	require.NoError(t, db.Exec("UPDATE roles SET requires_mfa = ? WHERE id = ?", true, roleID.String()).Error)
+	assignApprovalTestRole(t, db, userID, roleID)
+
+	resp, err := service.Login(LoginRequest{Email: "mfa-admin@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.True(t, resp.MFARequired)
+	assert.True(t, resp.MFAEnrollmentRequired, "未登録の場合はログイン時に登録を要求")
+
+	_, err = service.VerifyMFA(VerifyMFARequest{ChallengeToken: resp.MFAChallengeToken, Code: "123456"})
+	assert.True(t, errors.IsAuthenticationError(err), "登録前はコード検証でトークンを発行しない")
+
+	enrollment, err := service.BeginMFAEnrollment(resp.MFAChallengeToken)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.OTPAuthURI, "otpauth://totp/")
+	assert.Contains(t, enrollment.OTPAuthURI, "secret="+enrollment.Secret)
+
+	_, err = service.ConfirmMFAEnrollment(VerifyMFARequest{ChallengeToken: resp.MFAChallengeToken, Code: "000000"})
+	assert.True(t, errors.IsValidationError(err))
+
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	completed, err := service.ConfirmMFAEnrollment(VerifyMFARequest{ChallengeToken: resp.MFAChallengeToken, Code: code})
+	require.NoError(t, err)
+	assert.NotEmpty(t, completed.Token)
+	assert.Len(t, completed.RecoveryCodes, recoveryCodeCount)
+
+	next, err := service.Login(LoginRequest{Email: "mfa-admin@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.True(t, next.MFARequired)
+	assert.False(t, next.MFAEnrollmentRequired)
+}
+
+// TestAuthService_MFARequiredBySystemAdmin requires_mfaの設定がなくても継承・ワイルドカードでsystem:adminを持つユーザーはMFA必須となることのテスト
+func TestAuthService_MFARequiredBySystemAdmin(t *testing.T) {
+	service, db := setupTestAuth(t)
+
+	wildcard := createResolverTestRole(t, db, "MFAワイルドカード管理者", nil, [2]string{"*", "*"})
+	inherited := createResolverTestRole(t, db, "MFA継承管理者", &wildcard, [2]string{"user", "read"})
+	denied := createResolverTestRole(t, db, "MFA拒否管理者", nil, [2]string{"system", "*"})
+	var systemAdmin string
+	db.Raw("SELECT id FROM permissions WHERE module = 'system' AND action = 'admin'").Scan(&systemAdmin)
+	if systemAdmin == "" {
+		systemAdmin = uuid.New().String()
+		require.NoError(t, db.Exec("INSERT INTO permissions (id, module, action) VALUES (?, 'system', 'admin')", systemAdmin).Error)
+	}
+	require.NoError(t, db.Exec("INSERT INTO role_permissions (role_id, permission_id, effect) VALUES (?, ?, 'deny')", denied.String(), systemAdmin).Error)
+
+	for email, tc := range map[string]struct {
+		roleID   uuid.UUID
+		required bool
+	}{
+		"mfa-inherited@example.com": {roleID: inherited, required: true},
+		"mfa-denied@example.com":    {roleID: denied, required: false},
+	} {
+		userID := createAuthTestUser(t, db, email, "password123")
+		assignApprovalTestRole(t, db, userID, tc.roleID)
+
+		resp, err := service.Login(LoginRequest{Email: email, Password: "password123"})
+		require.NoError(t, err)
+		assert.Equal(t, tc.required, resp.MFARequired, email)
+		assert.Equal(t, tc.required, resp.MFAEnrollmentRequired, email)
+	}
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	policy.RequireSymbol = true
+	policy.BannedPasswords = map[string]struct{}{"password1!": {}}
+
+	rules := func(violations []errors.Violation) []string {
+		var result []string
+		for _, violation := range violations {
+			result = append(result, violation.Rule)
+		}
+		return result
+	}
+
+	assert.Empty(t, policy.Check("Str0ng-Passw0rd", "alice@example.com"))
+	assert.Equal(t, []string{
+		PasswordRuleMinLength,
+		PasswordRuleRequireUppercase,
+		PasswordRuleRequireDigit,
+		PasswordRuleRequireSymbol,
+	}, rules(policy.Check("abc", "alice@example.com")), "違反したルールをすべて返す")
+	assert.Equal(t, []string{PasswordRuleBanned}, rules(policy.Check("Password1!", "bob@example.com")))
+	assert.Equal(t, []string{PasswordRuleContainsEmail}, rules(policy.Check("Alice-2024!", "alice@example.com")))
+	assert.Equal(t, []string{PasswordRuleMaxLength}, rules(policy.Check("Aa1!"+strings.Repeat("x", 70), "alice@example.com")))
+}
+
+func TestAuthService_ChangePasswordPolicy(t *testing.T) {
+	service, db := setupTestAuth(t)
+	policy := DefaultPasswordPolicy()
+	policy.HistoryDepth = 3
+	policy.BcryptCost = bcrypt.MinCost
+	service.SetPasswordPolicyService(NewPasswordPolicyService(db, policy))
+
+	userID := createAuthTestUser(t, db, "policy@example.com", "Initial-Pass1")
+
+	t.Run("ポリシー違反はすべてのルールを返す", func(t *testing.T) {
+		err := service.ChangePassword(userID, "Initial-Pass1", "short")
+		require.Error(t, err)
+		assert.True(t, errors.IsValidationError(err))
+		details := err.(*errors.APIError).Details
+		assert.Equal(t, "new_password", details.Field)
+		assert.Len(t, details.Violations, 3)
+	})
+
+	t.Run("現在および直近の履歴と同じパスワードは拒否", func(t *testing.T) {
+		err := service.ChangePassword(userID, "Initial-Pass1", "Initial-Pass1")
+		require.Error(t, err)
+		assert.Equal(t, PasswordRuleHistory, err.(*errors.APIError).Details.Violations[0].Rule)
+
+		require.NoError(t, service.ChangePassword(userID, "Initial-Pass1", "Second-Pass2"))
+		require.NoError(t, service.ChangePassword(userID, "Second-Pass2", "Third-Pass3"))
+
+		err = service.ChangePassword(userID, "Third-Pass3", "Initial-Pass1")
+		require.Error(t, err)
+		assert.True(t, errors.IsValidationError(err), "直近3件以内の再利用は禁止")
+
+		var user models.User
+		require.NoError(t, db.First(&user, "id = ?", userID).Error)
+		assert.NotNil(t, user.PasswordChangedAt)
+	})
+
+	t.Run("履歴件数を超えた古いパスワードは再利用可能", func(t *testing.T) {
+		require.NoError(t, service.ChangePassword(userID, "Third-Pass3", "Fourth-Pass4"))
+
+		var count int64
+		require.NoError(t, db.Model(&models.PasswordHistory{}).Where("user_id = ?", userID).Count(&count).Error)
+		assert.Equal(t, int64(2), count, "現在のパスワードを除き depth-1 件を保持")
+
+		require.NoError(t, service.ChangePassword(userID, "Fourth-Pass4", "Initial-Pass1"))
+	})
+
+	t.Run("有効期限切れはログイン時に通知", func(t *testing.T) {
+		policy.MaxAge = time.Hour
+		service.SetPasswordPolicyService(NewPasswordPolicyService(db, policy))
+		require.NoError(t, db.Model(&models.User{}).Where("id = ?", userID).
+			UpdateColumn("password_changed_at", time.Now().Add(-2*time.Hour)).Error)
+
+		login, err := service.Login(LoginRequest{Email: "policy@example.com", Password: "Initial-Pass1"})
+		require.NoError(t, err)
+		assert.True(t, login.PasswordExpired)
+	})
+}
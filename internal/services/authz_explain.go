@@ -0,0 +1,377 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+)
+
+// AuthorizationReason 認可判定の最終的な理由
+type AuthorizationReason string
+
+const (
+	AuthorizationReasonAllowed        AuthorizationReason = "allowed"
+	AuthorizationReasonExplicitDeny   AuthorizationReason = "explicit_deny"
+	AuthorizationReasonNoPermission   AuthorizationReason = "no_matching_permission"
+	AuthorizationReasonTimeRestricted AuthorizationReason = "time_restricted"
+	AuthorizationReasonScopeMismatch  AuthorizationReason = "scope_mismatch"
+)
+
+// UserRoleStatus 判定時点でのユーザーロールの状態
+type UserRoleStatus string
+
+const (
+	UserRoleStatusActive      UserRoleStatus = "active"
+	UserRoleStatusInactive    UserRoleStatus = "inactive"      // is_active = false
+	UserRoleStatusNotYetValid UserRoleStatus = "not_yet_valid" // valid_from が未来
+	UserRoleStatusExpired     UserRoleStatus = "expired"       // valid_to を経過
+	UserRoleStatusPrimary     UserRoleStatus = "primary"       // 後方互換性のPrimaryRole
+)
+
+// AuthorizationExplanation 認可判定とその根拠
+// CheckPermission（scope未指定時）・CheckPermissionWithScope と同じ規則で判定し、各段階の評価結果を返す
+type AuthorizationExplanation struct {
+	UserID           uuid.UUID                      `json:"user_id"`
+	Permission       string                         `json:"permission"`
+	EvaluatedAt      time.Time                      `json:"evaluated_at"`
+	Allowed          bool                           `json:"allowed"`
+	Reason           AuthorizationReason            `json:"reason"`
+	Decision         PermissionDecision             `json:"decision"` // 実効権限による判定（時間制限・スコープを除く）
+	UserRoles        []ExplainedUserRole            `json:"user_roles"`
+	Roles            []ExplainedRole                `json:"roles"`
+	Grants           []ExplainedGrant               `json:"grants"` // 指定権限に一致した許可・拒否
+	TimeRestrictions ExplainedTimeRestrictionResult `json:"time_restrictions"`
+	Scope            ExplainedScopeResult           `json:"scope"`
+}
+
+// ExplainedUserRole 判定で確認したユーザーロール
+type ExplainedUserRole struct {
+	RoleID     uuid.UUID      `json:"role_id"`
+	RoleName   string         `json:"role_name"`
+	ValidFrom  *time.Time     `json:"valid_from,omitempty"`
+	ValidTo    *time.Time     `json:"valid_to,omitempty"`
+	IsActive   bool           `json:"is_active"`
+	Priority   int            `json:"priority"`
+	Status     UserRoleStatus `json:"status"`
+	Considered bool           `json:"considered"` // 実効権限の解決に使用したか
+}
+
+// ExplainedRole 実効権限の解決に使用したロール（親ロールを含む）
+type ExplainedRole struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	Inherited bool       `json:"inherited"` // ユーザーに割り当てられたロールではなく親ロールとして辿った
+}
+
+// ExplainedGrant 指定権限に一致した権限の付与
+type ExplainedGrant struct {
+	Grant          string                  `json:"grant"` // 拒否は "!" 付き
+	Effect         models.PermissionEffect `json:"effect"`
+	Match          string                  `json:"match"` // exact または wildcard
+	SourceRoleID   uuid.UUID               `json:"source_role_id"`
+	SourceRoleName string                  `json:"source_role_name"`
+	Inherited      bool                    `json:"inherited"`
+	Decisive       bool                    `json:"decisive"` // 判定を決定した権限文字列と一致する
+}
+
+// ExplainedTimeRestrictionResult 時間制限の評価結果
+type ExplainedTimeRestrictionResult struct {
+	ResourceType string                     `json:"resource_type,omitempty"`
+	Evaluated    bool                       `json:"evaluated"` // リソース種別に対応しない権限は評価しない
+	Allowed      bool                       `json:"allowed"`   // 制限がない場合、またはいずれかの制限に合致した場合に許可
+	Rules        []ExplainedTimeRestriction `json:"rules"`
+}
+
+// ExplainedTimeRestriction 評価した時間制限
+type ExplainedTimeRestriction struct {
+	ID          int     `json:"id"`
+	StartTime   *string `json:"start_time,omitempty"` // HH:MM:SS
+	EndTime     *string `json:"end_time,omitempty"`
+	AllowedDays []int64 `json:"allowed_days,omitempty"`
+	Timezone    string  `json:"timezone"`
+	TimeAllowed bool    `json:"time_allowed"`
+	DayAllowed  bool    `json:"day_allowed"`
+	Allowed     bool    `json:"allowed"`
+}
+
+// ExplainedScopeResult ユーザースコープの評価結果
+type ExplainedScopeResult struct {
+	Evaluated     bool                   `json:"evaluated"` // リソーススコープが指定された場合のみ評価
+	ResourceScope map[string]interface{} `json:"resource_scope,omitempty"`
+	Allowed       bool                   `json:"allowed"` // スコープが未設定の場合、またはいずれかのスコープに合致した場合に許可
+	Rules         []ExplainedScope       `json:"rules"`
+}
+
+// ExplainedScope 評価したユーザースコープ
+type ExplainedScope struct {
+	ID           int                    `json:"id"`
+	ResourceType string                 `json:"resource_type"`
+	ScopeType    models.ScopeType       `json:"scope_type"`
+	ScopeValue   map[string]interface{} `json:"scope_value"` // 部署の配下を含めて展開した値
+	Matched      *bool                  `json:"matched,omitempty"`
+}
+
+// ExplainPermission ユーザーの認可判定とその根拠を取得
+// resourceScopeがnilの場合はCheckPermission、指定した場合はCheckPermissionWithScopeと同じ判定になる
+// 実効権限はキャッシュを使用せずデータベースで解決するため、キャッシュのTTL内は実際の判定と異なる場合がある
+func (s *PermissionService) ExplainPermission(userID uuid.UUID, permission string, resourceScope map[string]interface{}) (*AuthorizationExplanation, error) {
+	now := time.Now()
+
+	var user models.User
+	if err := s.db.Preload("UserRoles.Role").Preload("PrimaryRole").First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("User", "User not found")
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	roleIDs, _, err := s.resolver.userRoleIDs(userID, now)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	explanation := &AuthorizationExplanation{
+		UserID:      userID,
+		Permission:  permission,
+		EvaluatedAt: now,
+		UserRoles:   explainUserRoles(user, now),
+		Roles:       []ExplainedRole{},
+		Grants:      []ExplainedGrant{},
+	}
+
+	// 1. 実効権限（ロール・親ロールの許可と拒否）
+	hierarchy, err := s.resolver.loadRoleHierarchy(roleIDs)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	explanation.Roles = explainRoles(roleIDs, hierarchy)
+
+	permissions, err := s.resolver.ResolveRoles(roleIDs)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	explanation.Decision = EvaluatePermission(effectivePermissionKeys(permissions), permission)
+	explanation.Grants = explainGrants(permissions, permission, explanation.Decision)
+
+	// 2. 時間制限
+	explanation.TimeRestrictions, err = s.explainTimeRestrictions(userID, permission, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. ユーザースコープ
+	explanation.Scope, err = s.explainScopes(userID, permission, resourceScope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case explanation.Decision.Rule == PermissionRuleExplicitDeny:
+		explanation.Reason = AuthorizationReasonExplicitDeny
+	case !explanation.Decision.Allowed:
+		explanation.Reason = AuthorizationReasonNoPermission
+	case !explanation.TimeRestrictions.Allowed:
+		explanation.Reason = AuthorizationReasonTimeRestricted
+	case explanation.Scope.Evaluated && !explanation.Scope.Allowed:
+		explanation.Reason = AuthorizationReasonScopeMismatch
+	default:
+		explanation.Allowed = true
+		explanation.Reason = AuthorizationReasonAllowed
+	}
+
+	s.logger.Debug("Authorization explained", map[string]interface{}{
+		"user_id":    userID,
+		"permission": permission,
+		"allowed":    explanation.Allowed,
+		"reason":     explanation.Reason,
+	})
+
+	return explanation, nil
+}
+
+// explainTimeRestrictions 権限のリソース種別に設定された時間制限を個別に評価
+func (s *PermissionService) explainTimeRestrictions(userID uuid.UUID, permission string, at time.Time) (ExplainedTimeRestrictionResult, error) {
+	result := ExplainedTimeRestrictionResult{Allowed: true, Rules: []ExplainedTimeRestriction{}}
+	resourceType := ResourceTypeForPermission(permission)
+	if resourceType == "" {
+		return result, nil
+	}
+	result.ResourceType = resourceType
+	result.Evaluated = true
+
+	restrictions, err := models.FindTimeRestrictionsByUserAndResource(s.db, userID, resourceType)
+	if err != nil {
+		return result, errors.NewDatabaseError(err)
+	}
+
+	// models.CheckTimeAccess と同じく、いずれかの制限に合致すれば許可
+	result.Allowed = len(restrictions) == 0
+	for _, restriction := range restrictions {
+		rule := ExplainedTimeRestriction{
+			ID:          restriction.ID,
+			StartTime:   formatTimeOfDay(restriction.StartTime),
+			EndTime:     formatTimeOfDay(restriction.EndTime),
+			AllowedDays: restriction.AllowedDays,
+			Timezone:    restriction.Timezone,
+			TimeAllowed: restriction.IsAllowedTime(at),
+			DayAllowed:  restriction.IsAllowedDay(at),
+			Allowed:     restriction.IsAllowed(at),
+		}
+		if rule.Allowed {
+			result.Allowed = true
+		}
+		result.Rules = append(result.Rules, rule)
+	}
+	return result, nil
+}
+
+// explainScopes 権限のリソース種別に設定されたユーザースコープを個別に評価
+// resourceScopeがnilの場合はスコープの一覧のみを返す
+func (s *PermissionService) explainScopes(userID uuid.UUID, permission string, resourceScope map[string]interface{}) (ExplainedScopeResult, error) {
+	result := ExplainedScopeResult{
+		Evaluated:     resourceScope != nil,
+		ResourceScope: resourceScope,
+		Allowed:       true,
+		Rules:         []ExplainedScope{},
+	}
+
+	userScopes, err := s.findScopesForPermission(userID, permission)
+	if err != nil {
+		return result, errors.NewDatabaseError(err)
+	}
+
+	// CheckScopeAccess と同じく、スコープがない場合またはいずれかのスコープに合致すれば許可
+	result.Allowed = !result.Evaluated || len(userScopes) == 0
+	for _, scope := range userScopes {
+		scopeValue, matched, err := s.matchScope(scope, resourceScope)
+		if err != nil {
+			return result, errors.NewDatabaseError(err)
+		}
+
+		rule := ExplainedScope{
+			ID:           scope.ID,
+			ResourceType: scope.ResourceType,
+			ScopeType:    scope.ScopeType,
+			ScopeValue:   scopeValue,
+		}
+		if result.Evaluated {
+			rule.Matched = &matched
+			if matched {
+				result.Allowed = true
+			}
+		}
+		result.Rules = append(result.Rules, rule)
+	}
+	return result, nil
+}
+
+// explainUserRoles ユーザーロールとPrimaryRoleを判定時点の状態付きで取得
+func explainUserRoles(user models.User, now time.Time) []ExplainedUserRole {
+	userRoles := make([]ExplainedUserRole, 0, len(user.UserRoles)+1)
+
+	for _, userRole := range user.UserRoles {
+		validFrom := userRole.ValidFrom
+		explained := ExplainedUserRole{
+			RoleID:    userRole.RoleID,
+			RoleName:  userRole.Role.Name,
+			ValidFrom: &validFrom,
+			ValidTo:   userRole.ValidTo,
+			IsActive:  userRole.IsActive,
+			Priority:  userRole.Priority,
+		}
+
+		// PermissionResolver.userRoleIDs と同じ条件
+		switch {
+		case !userRole.IsActive:
+			explained.Status = UserRoleStatusInactive
+		case userRole.ValidFrom.After(now):
+			explained.Status = UserRoleStatusNotYetValid
+		case userRole.ValidTo != nil && !userRole.ValidTo.After(now):
+			explained.Status = UserRoleStatusExpired
+		default:
+			explained.Status = UserRoleStatusActive
+			explained.Considered = true
+		}
+		userRoles = append(userRoles, explained)
+	}
+
+	if user.PrimaryRoleID != nil {
+		explained := ExplainedUserRole{
+			RoleID:     *user.PrimaryRoleID,
+			IsActive:   true,
+			Status:     UserRoleStatusPrimary,
+			Considered: true,
+		}
+		if user.PrimaryRole != nil {
+			explained.RoleName = user.PrimaryRole.Name
+		}
+		userRoles = append(userRoles, explained)
+	}
+	return userRoles
+}
+
+// explainRoles 実効権限の解決に使用したロールを、割り当てられたロール・親ロールの順で取得
+func explainRoles(roleIDs []uuid.UUID, hierarchy map[uuid.UUID]models.Role) []ExplainedRole {
+	roles := make([]ExplainedRole, 0, len(hierarchy))
+	seen := make(map[uuid.UUID]bool, len(hierarchy))
+
+	for _, roleID := range roleIDs {
+		// 割り当てられたロールから親ロールを辿る
+		current, inherited := roleID, false
+		for depth := 0; depth <= maxRoleHierarchyDepth && !seen[current]; depth++ {
+			role, exists := hierarchy[current]
+			if !exists {
+				break
+			}
+			seen[current] = true
+			roles = append(roles, ExplainedRole{ID: role.ID, Name: role.Name, ParentID: role.ParentID, Inherited: inherited})
+			if role.ParentID == nil {
+				break
+			}
+			current, inherited = *role.ParentID, true
+		}
+	}
+	return roles
+}
+
+// explainGrants 実効権限のうち指定権限に一致する許可・拒否を取得
+func explainGrants(permissions []EffectivePermission, permission string, decision PermissionDecision) []ExplainedGrant {
+	grants := []ExplainedGrant{}
+	for _, effective := range permissions {
+		key := effective.Key()
+		match := ""
+		switch {
+		case key == permission:
+			match = "exact"
+		case effective.Effect != models.PermissionEffectDeny && matchesPermissionWildcard(key, permission):
+			match = "wildcard" // 拒否は完全一致のみ
+		default:
+			continue
+		}
+
+		grants = append(grants, ExplainedGrant{
+			Grant:          effective.Grant(),
+			Effect:         effective.Effect,
+			Match:          match,
+			SourceRoleID:   effective.SourceRoleID,
+			SourceRoleName: effective.SourceRoleName,
+			Inherited:      effective.Inherited,
+			Decisive:       effective.Grant() == decision.Grant,
+		})
+	}
+	return grants
+}
+
+// formatTimeOfDay 時刻をHH:MM:SS形式に変換（未設定はnil）
+func formatTimeOfDay(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("15:04:05")
+	return &formatted
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+)
+
+// TestPermissionService_ExplainPermission 認可判定の根拠がCheckPermission・CheckPermissionWithScopeの判定と一致することのテスト
+func TestPermissionService_ExplainPermission(t *testing.T) {
+	scopeService, _ := setupTestUserScope(t)
+	service, db := setupTestAuth(t)
+	setupTestTimeRestrictions(t, db)
+	permissionService := service.permissionService
+
+	// 説明テスト親（user:*）> 説明テスト子（department:read、user:export を拒否）、期限切れ（role:read）
+	parent := createResolverTestRole(t, db, "説明テスト親", nil, [2]string{"user", "*"})
+	child := createResolverTestRole(t, db, "説明テスト子", &parent, [2]string{"department", "read"})
+	expired := createResolverTestRole(t, db, "説明テスト期限切れ", nil, [2]string{"role", "read"})
+
+	var userExport string
+	db.Raw("SELECT id FROM permissions WHERE module = 'user' AND action = 'export'").Scan(&userExport)
+	if userExport == "" {
+		userExport = uuid.New().String()
+		require.NoError(t, db.Exec("INSERT INTO permissions (id, module, action) VALUES (?, 'user', 'export')", userExport).Error)
+	}
+	require.NoError(t, db.Exec("INSERT INTO role_permissions (role_id, permission_id, effect) VALUES (?, ?, 'deny')", child.String(), userExport).Error)
+
+	userID := createAuthTestUser(t, db, "explain@example.com", "password123")
+	assignApprovalTestRole(t, db, userID, child)
+	require.NoError(t, db.Exec("INSERT INTO user_roles (user_id, role_id, is_active, valid_from, valid_to) VALUES (?, ?, ?, ?, ?)",
+		userID.String(), expired.String(), true, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)).Error)
+
+	explain := func(permission string, resourceScope map[string]interface{}) *AuthorizationExplanation {
+		explanation, err := permissionService.ExplainPermission(userID, permission, resourceScope)
+		require.NoError(t, err)
+		return explanation
+	}
+
+	t.Run("親ロールのワイルドカードによる許可", func(t *testing.T) {
+		explanation := explain("user:update", nil)
+		assert.True(t, explanation.Allowed)
+		assert.Equal(t, AuthorizationReasonAllowed, explanation.Reason)
+		assert.Equal(t, PermissionDecision{Allowed: true, Rule: PermissionRuleWildcardAllow, Grant: "user:*"}, explanation.Decision)
+
+		require.Len(t, explanation.Grants, 1)
+		assert.Equal(t, "wildcard", explanation.Grants[0].Match)
+		assert.Equal(t, parent, explanation.Grants[0].SourceRoleID)
+		assert.True(t, explanation.Grants[0].Inherited)
+		assert.True(t, explanation.Grants[0].Decisive)
+
+		require.Len(t, explanation.Roles, 2)
+		assert.Equal(t, child, explanation.Roles[0].ID)
+		assert.False(t, explanation.Roles[0].Inherited)
+		assert.Equal(t, parent, explanation.Roles[1].ID)
+		assert.True(t, explanation.Roles[1].Inherited)
+
+		statuses := map[uuid.UUID]UserRoleStatus{}
+		for _, userRole := range explanation.UserRoles {
+			statuses[userRole.RoleID] = userRole.Status
+			assert.Equal(t, userRole.Status == UserRoleStatusActive, userRole.Considered)
+		}
+		assert.Equal(t, map[uuid.UUID]UserRoleStatus{child: UserRoleStatusActive, expired: UserRoleStatusExpired}, statuses)
+
+		allowed, err := permissionService.CheckPermission(userID, "user:update")
+		require.NoError(t, err)
+		assert.Equal(t, allowed, explanation.Allowed)
+	})
+
+	t.Run("拒否はワイルドカードの許可より優先", func(t *testing.T) {
+		explanation := explain("user:export", nil)
+		assert.False(t, explanation.Allowed)
+		assert.Equal(t, AuthorizationReasonExplicitDeny, explanation.Reason)
+		assert.Equal(t, "!user:export", explanation.Decision.Grant)
+
+		decisive := map[string]bool{}
+		for _, grant := range explanation.Grants {
+			decisive[grant.Grant] = grant.Decisive
+		}
+		assert.Equal(t, map[string]bool{"!user:export": true, "user:*": false}, decisive)
+
+		allowed, err := permissionService.CheckPermission(userID, "user:export")
+		require.NoError(t, err)
+		assert.Equal(t, allowed, explanation.Allowed)
+	})
+
+	t.Run("期限切れのユーザーロールの権限は使用しない", func(t *testing.T) {
+		explanation := explain("role:read", nil)
+		assert.False(t, explanation.Allowed)
+		assert.Equal(t, AuthorizationReasonNoPermission, explanation.Reason)
+		assert.Empty(t, explanation.Grants)
+	})
+
+	t.Run("時間制限", func(t *testing.T) {
+		// 今日以外の曜日のみ許可
+		tomorrow := int64((time.Now().UTC().Weekday()+1)%7) + 1
+		restriction := models.TimeRestriction{UserID: userID, ResourceType: "departments", AllowedDays: models.IntArray{tomorrow}, Timezone: "UTC"}
+		require.NoError(t, db.Create(&restriction).Error)
+
+		explanation := explain("department:read", nil)
+		assert.False(t, explanation.Allowed)
+		assert.Equal(t, AuthorizationReasonTimeRestricted, explanation.Reason)
+		assert.True(t, explanation.Decision.Allowed)
+		assert.Equal(t, "departments", explanation.TimeRestrictions.ResourceType)
+		require.Len(t, explanation.TimeRestrictions.Rules, 1)
+		assert.Equal(t, restriction.ID, explanation.TimeRestrictions.Rules[0].ID)
+		assert.True(t, explanation.TimeRestrictions.Rules[0].TimeAllowed)
+		assert.False(t, explanation.TimeRestrictions.Rules[0].DayAllowed)
+
+		_, err := permissionService.CheckPermission(userID, "department:read")
+		assert.True(t, errors.IsTimeRestrictedError(err))
+	})
+
+	t.Run("スコープの照合", func(t *testing.T) {
+		_, err := scopeService.AddUserScope(userID, AddUserScopeRequest{
+			ResourceType: "users",
+			UserScopeItem: UserScopeItem{
+				ScopeType:  models.ScopeTypeRegion,
+				ScopeValue: map[string]interface{}{"region": "kanto"},
+			},
+		})
+		require.NoError(t, err)
+
+		explanation := explain("user:update", nil)
+		assert.True(t, explanation.Allowed, "スコープ未指定の場合は照合しない")
+		assert.False(t, explanation.Scope.Evaluated)
+		require.Len(t, explanation.Scope.Rules, 1)
+		assert.Nil(t, explanation.Scope.Rules[0].Matched)
+
+		for region, want := range map[string]bool{"kanto": true, "kansai": false} {
+			resourceScope := map[string]interface{}{"region": region}
+			explanation := explain("user:update", resourceScope)
+			assert.Equal(t, want, explanation.Allowed, region)
+			require.Len(t, explanation.Scope.Rules, 1)
+			require.NotNil(t, explanation.Scope.Rules[0].Matched)
+			assert.Equal(t, want, *explanation.Scope.Rules[0].Matched)
+			if !want {
+				assert.Equal(t, AuthorizationReasonScopeMismatch, explanation.Reason)
+			}
+
+			allowed, err := permissionService.CheckPermissionWithScope(userID, "user:update", resourceScope)
+			require.NoError(t, err)
+			assert.Equal(t, allowed, explanation.Allowed, region)
+		}
+	})
+
+	t.Run("存在しないユーザー", func(t *testing.T) {
+		_, err := permissionService.ExplainPermission(uuid.New(), "user:read", nil)
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+)
+
+// maxRoleHierarchyDepth 親ロールを辿る上限（循環参照・不正データによる無限ループ防止）
+const maxRoleHierarchyDepth = 10
+
+// EffectivePermission 実効権限（どのロールから付与されたかを含む）
+// 同じ権限が複数のロールから付与されている場合はロールごとに1件ずつ返す
+type EffectivePermission struct {
+	ID             uuid.UUID
+	Module         string
+	Action         string
+	Effect         models.PermissionEffect
+	SourceRoleID   uuid.UUID // 権限が直接付与されているロール
+	SourceRoleName string
+	Inherited      bool // 起点のロールではなく親ロールから継承した権限
+}
+
+// Key 権限文字列（module:action、ワイルドカードを含む）
+func (p EffectivePermission) Key() string {
+	return p.Module + ":" + p.Action
+}
+
+// Grant 判定に使用する権限文字列（拒否は "!" 付き）
+func (p EffectivePermission) Grant() string {
+	if p.Effect == models.PermissionEffectDeny {
+		return DeniedPermissionPrefix + p.Key()
+	}
+	return p.Key()
+}
+
+// PermissionResolver ロール・ユーザーの実効権限を解決する
+// 直接付与（role_permissions）と親ロールからの継承を合わせて返す
+// ワイルドカード（*:*、user:* など）・拒否は権限の1行として返し、照合は EvaluatePermission で行う
+type PermissionResolver struct {
+	db *gorm.DB
+}
+
+// NewPermissionResolver 新しい実効権限リゾルバーを作成
+func NewPermissionResolver(db *gorm.DB) *PermissionResolver {
+	return &PermissionResolver{db: db}
+}
+
+// ResolveRoles 指定したロールと、その親ロールから継承した権限を取得
+func (r *PermissionResolver) ResolveRoles(roleIDs []uuid.UUID) ([]EffectivePermission, error) {
+	if len(roleIDs) == 0 {
+		return []EffectivePermission{}, nil
+	}
+
+	roles, err := r.loadRoleHierarchy(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(roles))
+	for id := range roles {
+		ids = append(ids, id)
+	}
+
+	var grants []struct {
+		RoleID       uuid.UUID
+		PermissionID uuid.UUID
+		Module       string
+		Action       string
+		Effect       models.PermissionEffect
+	}
+	if err := r.db.Table("role_permissions rp").
+		Select("rp.role_id, p.id AS permission_id, p.module, p.action, rp.effect").
+		Joins("JOIN permissions p ON p.id = rp.permission_id").
+		Where("rp.role_id IN ?", ids).
+		Scan(&grants).Error; err != nil {
+		return nil, err
+	}
+
+	direct := make(map[uuid.UUID]bool, len(roleIDs))
+	for _, id := range roleIDs {
+		direct[id] = true
+	}
+
+	permissions := make([]EffectivePermission, 0, len(grants))
+	for _, grant := range grants {
+		permissions = append(permissions, EffectivePermission{
+			ID:             grant.PermissionID,
+			Module:         grant.Module,
+			Action:         grant.Action,
+			Effect:         grant.Effect,
+			SourceRoleID:   grant.RoleID,
+			SourceRoleName: roles[grant.RoleID].Name,
+			Inherited:      !direct[grant.RoleID],
+		})
+	}
+
+	sort.Slice(permissions, func(i, j int) bool {
+		if permissions[i].Module != permissions[j].Module {
+			return permissions[i].Module < permissions[j].Module
+		}
+		if permissions[i].Action != permissions[j].Action {
+			return permissions[i].Action < permissions[j].Action
+		}
+		if permissions[i].Effect != permissions[j].Effect {
+			return permissions[i].Effect == models.PermissionEffectDeny
+		}
+		return permissions[i].SourceRoleName < permissions[j].SourceRoleName
+	})
+	return permissions, nil
+}
+
+// ResolveUser ユーザーの実効権限を取得
+// 有効期間内のアクティブなユーザーロールとPrimaryRole、およびそれらの親ロールの権限を対象とする
+func (r *PermissionResolver) ResolveUser(userID uuid.UUID) ([]EffectivePermission, error) {
+	roleIDs, _, err := r.userRoleIDs(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return r.ResolveRoles(roleIDs)
+}
+
+// ResolveUserKeys ユーザーの実効権限を権限文字列（重複なし、拒否は "!" 付き）で取得
+// validUntilはユーザーロールの有効期間の開始・終了により結果が変わる最も近い時刻（予定がない場合はゼロ値）
+func (r *PermissionResolver) ResolveUserKeys(userID uuid.UUID) (keys []string, validUntil time.Time, err error) {
+	roleIDs, validUntil, err := r.userRoleIDs(userID, time.Now())
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	permissions, err := r.ResolveRoles(roleIDs)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return effectivePermissionKeys(permissions), validUntil, nil
+}
+
+// RoleHolders 指定したロールまたはその子孫ロールを持つユーザーを取得
+// ロールの権限・親ロールを変更した際に、実効権限が変わるユーザーの特定に使用する
+func (r *PermissionResolver) RoleHolders(roleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	roles, err := r.loadRoleDescendants(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var assigned []uuid.UUID
+	if err := r.db.Model(&models.UserRole{}).
+		Where("role_id IN ? AND is_active = ?", roles, true).
+		Distinct().Pluck("user_id", &assigned).Error; err != nil {
+		return nil, err
+	}
+	var primary []uuid.UUID
+	if err := r.db.Model(&models.User{}).Where("primary_role_id IN ?", roles).Pluck("id", &primary).Error; err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool, len(assigned)+len(primary))
+	userIDs := make([]uuid.UUID, 0, len(assigned)+len(primary))
+	for _, userID := range append(assigned, primary...) {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs, nil
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// userRoleIDs ユーザーに割り当てられた有効なロールIDと、有効なロールが次に変わる時刻を取得
+func (r *PermissionResolver) userRoleIDs(userID uuid.UUID, now time.Time) ([]uuid.UUID, time.Time, error) {
+	var user models.User
+	if err := r.db.Preload("UserRoles").First(&user, userID).Error; err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var validUntil time.Time
+	nextChange := func(at time.Time) {
+		if validUntil.IsZero() || at.Before(validUntil) {
+			validUntil = at
+		}
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	roleIDs := make([]uuid.UUID, 0, len(user.UserRoles)+1)
+	for _, userRole := range user.UserRoles {
+		if !userRole.IsActive {
+			continue
+		}
+		if userRole.ValidFrom.After(now) {
+			nextChange(userRole.ValidFrom)
+			continue
+		}
+		if userRole.ValidTo != nil {
+			if !userRole.ValidTo.After(now) {
+				continue
+			}
+			nextChange(*userRole.ValidTo)
+		}
+		if !seen[userRole.RoleID] {
+			seen[userRole.RoleID] = true
+			roleIDs = append(roleIDs, userRole.RoleID)
+		}
+	}
+
+	// 後方互換性: PrimaryRoleの権限も対象
+	if user.PrimaryRoleID != nil && !seen[*user.PrimaryRoleID] {
+		roleIDs = append(roleIDs, *user.PrimaryRoleID)
+	}
+	return roleIDs, validUntil, nil
+}
+
+// loadRoleHierarchy 指定したロールと親ロールを階層の上限まで取得
+func (r *PermissionResolver) loadRoleHierarchy(roleIDs []uuid.UUID) (map[uuid.UUID]models.Role, error) {
+	roles := make(map[uuid.UUID]models.Role)
+	frontier := roleIDs
+
+	for depth := 0; len(frontier) > 0 && depth <= maxRoleHierarchyDepth; depth++ {
+		var found []models.Role
+		if err := r.db.Select("id", "name", "parent_id").Where("id IN ?", frontier).Find(&found).Error; err != nil {
+			return nil, err
+		}
+
+		frontier = nil
+		for _, role := range found {
+			roles[role.ID] = role
+		}
+		for _, role := range found {
+			if role.ParentID == nil {
+				continue
+			}
+			if _, visited := roles[*role.ParentID]; !visited {
+				frontier = append(frontier, *role.ParentID)
+			}
+		}
+	}
+	return roles, nil
+}
+
+// loadRoleDescendants 指定したロールと子孫ロールのIDを階層の上限まで取得
+func (r *PermissionResolver) loadRoleDescendants(roleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool, len(roleIDs))
+	result := make([]uuid.UUID, 0, len(roleIDs))
+	frontier := make([]uuid.UUID, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+			frontier = append(frontier, id)
+		}
+	}
+
+	for depth := 0; len(frontier) > 0 && depth < maxRoleHierarchyDepth; depth++ {
+		var children []uuid.UUID
+		if err := r.db.Model(&models.Role{}).Where("parent_id IN ?", frontier).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+
+		frontier = nil
+		for _, id := range children {
+			if !seen[id] {
+				seen[id] = true
+				result = append(result, id)
+				frontier = append(frontier, id)
+			}
+		}
+	}
+	return result, nil
+}
+
+// effectivePermissionKeys 実効権限を権限文字列に変換（重複除去）
+func effectivePermissionKeys(permissions []EffectivePermission) []string {
+	seen := make(map[string]bool, len(permissions))
+	keys := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		key := permission.Grant()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
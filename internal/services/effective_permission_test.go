@@ -0,0 +1,208 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/logger"
+)
+
+// createResolverTestRole 親ロールと権限を指定してロールを作成
+func createResolverTestRole(t *testing.T, db *gorm.DB, name string, parentID *uuid.UUID, permissions ...[2]string) uuid.UUID {
+	roleID := uuid.New()
+	var parent interface{}
+	if parentID != nil {
+		parent = parentID.String()
+	}
+	require.NoError(t, db.Exec("INSERT INTO roles (id, name, parent_id) VALUES (?, ?, ?)", roleID.String(), name, parent).Error)
+
+	for _, permission := range permissions {
+		var permissionID string
+		db.Raw("SELECT id FROM permissions WHERE module = ? AND action = ?", permission[0], permission[1]).Scan(&permissionID)
+		if permissionID == "" {
+			permissionID = uuid.New().String()
+			require.NoError(t, db.Exec("INSERT INTO permissions (id, module, action) VALUES (?, ?, ?)", permissionID, permission[0], permission[1]).Error)
+		}
+		require.NoError(t, db.Exec("INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?)", roleID.String(), permissionID).Error)
+	}
+	return roleID
+}
+
+// TestPermissionResolver_Consistency ログイン・リフレッシュ・CheckPermission・ロール詳細が同じ実効権限を返すことのテスト
+func TestPermissionResolver_Consistency(t *testing.T) {
+	service, db := setupTestAuth(t)
+	setupTestTimeRestrictions(t, db)
+	roleService := NewRoleService(db, logger.NewLogger())
+
+	// 部長（user:*）> 課長（department:read）> 担当（role:read）
+	director := createResolverTestRole(t, db, "継承テスト部長", nil, [2]string{"user", "*"})
+	manager := createResolverTestRole(t, db, "継承テスト課長", &director, [2]string{"department", "read"})
+	staff := createResolverTestRole(t, db, "継承テスト担当", &manager, [2]string{"role", "read"})
+
+	userID := createAuthTestUser(t, db, "resolver@example.com", "password123")
+	assignApprovalTestRole(t, db, userID, staff)
+
+	expected := []string{"user:*", "department:read", "role:read"}
+
+	t.Run("ログインのJWTは親ロールの権限を含む", func(t *testing.T) {
+		login, err := service.Login(LoginRequest{Email: "resolver@example.com", Password: "password123"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, expected, login.Permissions)
+
+		claims, err := service.jwtService.ValidateToken(login.Token)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, expected, claims.Permissions)
+
+		refreshed, err := service.RefreshToken(login.RefreshToken)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, expected, refreshed.Permissions)
+	})
+
+	t.Run("CheckPermissionは継承・ワイルドカードを判定", func(t *testing.T) {
+		for _, permission := range []string{"role:read", "department:read", "user:delete"} {
+			allowed, err := service.permissionService.CheckPermission(userID, permission)
+			require.NoError(t, err)
+			assert.True(t, allowed, permission)
+		}
+
+		allowed, err := service.permissionService.CheckPermission(userID, "department:update")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("ロール詳細の全権限と一致", func(t *testing.T) {
+		resp, err := roleService.GetRolePermissions(staff)
+		require.NoError(t, err)
+
+		keys := make([]string, len(resp.AllPermissions))
+		for i, permission := range resp.AllPermissions {
+			keys[i] = permission.Module + ":" + permission.Action
+		}
+		assert.ElementsMatch(t, expected, keys)
+
+		require.Len(t, resp.InheritedPermissions, 2)
+		sources := map[string]string{}
+		for _, permission := range resp.InheritedPermissions {
+			sources[permission.Module+":"+permission.Action] = permission.FromRoleName
+		}
+		assert.Equal(t, "継承テスト部長", sources["user:*"])
+		assert.Equal(t, "継承テスト課長", sources["department:read"])
+	})
+
+	t.Run("子ロールの権限は親ロールに継承しない", func(t *testing.T) {
+		permissions, err := service.permissionService.resolver.ResolveRoles([]uuid.UUID{manager})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"user:*", "department:read"}, effectivePermissionKeys(permissions))
+	})
+}
+
+// TestPermissionResolver_CircularHierarchy 親ロールが循環していても解決が終了することのテスト
+func TestPermissionResolver_CircularHierarchy(t *testing.T) {
+	_, db := setupTestAuth(t)
+	resolver := NewPermissionResolver(db)
+
+	first := createResolverTestRole(t, db, "循環ロールA", nil, [2]string{"orders", "read"})
+	second := createResolverTestRole(t, db, "循環ロールB", &first, [2]string{"reports", "read"})
+	require.NoError(t, db.Exec("UPDATE roles SET parent_id = ? WHERE id = ?", second.String(), first.String()).Error)
+
+	permissions, err := resolver.ResolveRoles([]uuid.UUID{first})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"orders:read", "reports:read"}, effectivePermissionKeys(permissions))
+}
+
+// TestPermissionResolver_Deny 拒否権限が継承・他ロール・ワイルドカードの許可より優先されることのテスト
+func TestPermissionResolver_Deny(t *testing.T) {
+	service, db := setupTestAuth(t)
+	setupTestTimeRestrictions(t, db)
+	roleService := NewRoleService(db, logger.NewLogger())
+
+	// 正社員（user:*）> 契約社員（user:delete を拒否）、削除担当（user:delete を許可）
+	employee := createResolverTestRole(t, db, "拒否テスト正社員", nil, [2]string{"user", "*"}, [2]string{"user", "delete"})
+	contractor := createResolverTestRole(t, db, "拒否テスト契約社員", &employee, [2]string{"department", "read"})
+	deleter := createResolverTestRole(t, db, "拒否テスト削除担当", nil, [2]string{"user", "delete"})
+
+	var userDelete, userAll string
+	db.Raw("SELECT id FROM permissions WHERE module = 'user' AND action = 'delete'").Scan(&userDelete)
+	db.Raw("SELECT id FROM permissions WHERE module = 'user' AND action = '*'").Scan(&userAll)
+
+	resp, err := roleService.AssignPermissions(contractor, AssignPermissionsRequest{
+		PermissionIDs: []uuid.UUID{uuid.MustParse(userDelete)},
+		Effect:        models.PermissionEffectDeny,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.DirectPermissions, 2)
+	assert.Equal(t, models.PermissionEffectDeny, resp.DirectPermissions[1].Effect)
+
+	userID := createAuthTestUser(t, db, "deny@example.com", "password123")
+	assignApprovalTestRole(t, db, userID, contractor)
+	assignApprovalTestRole(t, db, userID, deleter)
+
+	t.Run("JWTクレームに拒否権限を含む", func(t *testing.T) {
+		login, err := service.Login(LoginRequest{Email: "deny@example.com", Password: "password123"})
+		require.NoError(t, err)
+
+		claims, err := service.jwtService.ValidateToken(login.Token)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"!user:delete", "user:*", "user:delete", "department:read"}, claims.Permissions)
+		assert.False(t, HasPermission(claims.Permissions, "user:delete"))
+		assert.True(t, HasPermission(claims.Permissions, "user:update"))
+	})
+
+	t.Run("拒否は継承・他ロールの許可より優先", func(t *testing.T) {
+		allowed, err := service.permissionService.CheckPermission(userID, "user:delete")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		allowed, err = service.permissionService.CheckPermission(userID, "department:read")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("ワイルドカード権限は拒否できない", func(t *testing.T) {
+		_, err := roleService.AssignPermissions(contractor, AssignPermissionsRequest{
+			PermissionIDs: []uuid.UUID{uuid.MustParse(userAll)},
+			Effect:        models.PermissionEffectDeny,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("許可の置き換えは拒否を残す", func(t *testing.T) {
+		var departmentRead string
+		db.Raw("SELECT id FROM permissions WHERE module = 'department' AND action = 'read'").Scan(&departmentRead)
+
+		resp, err := roleService.AssignPermissions(contractor, AssignPermissionsRequest{
+			PermissionIDs: []uuid.UUID{uuid.MustParse(departmentRead)},
+			Replace:       true,
+		})
+		require.NoError(t, err)
+
+		effects := map[string]models.PermissionEffect{}
+		for _, permission := range resp.AllPermissions {
+			effects[permission.Module+":"+permission.Action] = permission.Effect
+		}
+		assert.Equal(t, models.PermissionEffectDeny, effects["user:delete"], "継承した許可より直接の拒否が優先")
+		assert.Equal(t, models.PermissionEffectAllow, effects["department:read"])
+	})
+
+	t.Run("権限マトリックスに拒否しているロールを含む", func(t *testing.T) {
+		matrix, err := service.permissionService.GetPermissionMatrix()
+		require.NoError(t, err)
+
+		var action *ActionInfo
+		for _, module := range matrix.Modules {
+			for i := range module.Actions {
+				if module.Name == "user" && module.Actions[i].Name == "delete" {
+					action = &module.Actions[i]
+				}
+			}
+		}
+		require.NotNil(t, action)
+		assert.Contains(t, action.Roles, "拒否テスト削除担当")
+		assert.Equal(t, []string{"拒否テスト契約社員"}, action.DeniedRoles)
+	})
+}
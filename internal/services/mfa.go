@@ -0,0 +1,422 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+	"erp-access-control-go/pkg/totp"
+)
+
+const (
+	recoveryCodeCount       = 10
+	recoveryCodeBytes       = 5 // 10桁の16進数（xxxxx-xxxxx）
+	mfaChallengeMaxAttempts = 5
+	totpAllowedSkew         = 1 // 前後1ステップ（±30秒）の時刻ずれを許容
+)
+
+// mfaRequiredPermission 実効権限として保持するユーザーはロールの設定に関わらずMFA必須
+const mfaRequiredPermission = "system:admin"
+
+// MFAService TOTP多要素認証サービス
+type MFAService struct {
+	db                *gorm.DB
+	logger            *logger.Logger
+	issuer            string
+	challengeDuration time.Duration
+}
+
+// NewMFAService 新しいMFAサービスを作成
+func NewMFAService(db *gorm.DB, logger *logger.Logger, issuer string, challengeDuration time.Duration) *MFAService {
+	return &MFAService{
+		db:                db,
+		logger:            logger,
+		issuer:            issuer,
+		challengeDuration: challengeDuration,
+	}
+}
+
+// MFAEnrollmentResponse MFA登録開始レスポンス
+type MFAEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFARecoveryCodesResponse MFA登録完了レスポンス（リカバリーコードはこの時のみ表示）
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAStatusResponse MFA登録状況レスポンス
+type MFAStatusResponse struct {
+	Enabled                bool    `json:"enabled"`
+	Required               bool    `json:"required"`
+	ConfirmedAt            *string `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int64   `json:"recovery_codes_remaining"`
+}
+
+// ChallengeDuration MFAチャレンジの有効期間を取得
+func (s *MFAService) ChallengeDuration() time.Duration {
+	return s.challengeDuration
+}
+
+// GetRequirement ユーザーにMFAが必要か・登録済みかを判定
+// 登録済みのユーザー、またはMFA必須ロールを保持するユーザーはMFAが必要
+func (s *MFAService) GetRequirement(user *models.User) (required bool, enrolled bool, err error) {
+	enrolled, err = s.isEnrolled(user.ID)
+	if err != nil {
+		return false, false, err
+	}
+
+	roleRequired, err := s.isRequiredByRole(user)
+	if err != nil {
+		return false, false, err
+	}
+
+	return enrolled || roleRequired, enrolled, nil
+}
+
+// GetStatus ユーザーのMFA登録状況を取得
+func (s *MFAService) GetStatus(userID uuid.UUID) (*MFAStatusResponse, error) {
+	var user models.User
+	if err := s.db.Preload("PrimaryRole").First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	required, enrolled, err := s.GetRequirement(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MFAStatusResponse{
+		Enabled:  enrolled,
+		Required: required,
+	}
+	if enrolled {
+		mfa, err := models.FindUserMFA(s.db, userID)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err)
+		}
+		confirmedAt := mfa.ConfirmedAt.Format("2006-01-02T15:04:05Z07:00")
+		status.ConfirmedAt = &confirmedAt
+
+		remaining, err := models.CountUnusedRecoveryCodes(s.db, userID)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err)
+		}
+		status.RecoveryCodesRemaining = remaining
+	}
+
+	return status, nil
+}
+
+// BeginEnrollment TOTPシークレットを生成して登録を開始（確認コード検証までは無効）
+func (s *MFAService) BeginEnrollment(userID uuid.UUID) (*MFAEnrollmentResponse, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.NewInternalError("failed to generate MFA secret")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := models.FindUserMFA(tx, userID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		if existing != nil {
+			if existing.IsConfirmed() {
+				return errors.NewBusinessError(errors.ErrCodeBusinessRule, "MFA is already enabled", "reset MFA before enrolling a new authenticator")
+			}
+			// 登録途中のシークレットは新しいものに置き換える
+			return tx.Model(existing).Update("secret", secret).Error
+		}
+
+		return tx.Create(&models.UserMFA{UserID: userID, Secret: secret}).Error
+	})
+	if err != nil {
+		if apiErr, ok := err.(*errors.APIError); ok {
+			return nil, apiErr
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("MFA enrollment started", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	return &MFAEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment 確認コードを検証して登録を完了し、リカバリーコードを発行
+func (s *MFAService) ConfirmEnrollment(userID uuid.UUID, code string) (*MFARecoveryCodesResponse, error) {
+	mfa, err := models.FindUserMFA(s.db, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewBusinessError(errors.ErrCodeBusinessRule, "MFA enrollment not started", "start enrollment before confirming")
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+	if mfa.IsConfirmed() {
+		return nil, errors.NewBusinessError(errors.ErrCodeBusinessRule, "MFA is already enabled", "reset MFA before enrolling a new authenticator")
+	}
+
+	step, ok := totp.Validate(mfa.Secret, code, time.Now(), totpAllowedSkew)
+	if !ok {
+		return nil, errors.NewValidationError("code", "Invalid verification code")
+	}
+
+	var recoveryCodes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(mfa).Updates(map[string]interface{}{
+			"confirmed_at":   now,
+			"last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		codes, err := s.replaceRecoveryCodes(tx, userID)
+		if err != nil {
+			return err
+		}
+		recoveryCodes = codes
+		return nil
+	})
+	if err != nil {
+		if apiErr, ok := err.(*errors.APIError); ok {
+			return nil, apiErr
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("MFA enrollment confirmed", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	return &MFARecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// VerifyCode TOTPコードまたはリカバリーコードを検証
+// TOTPコードは使用済みステップ以前のものを拒否し、リカバリーコードは一度のみ使用可能
+func (s *MFAService) VerifyCode(userID uuid.UUID, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		return s.useRecoveryCode(userID, recoveryCode)
+	}
+
+	mfa, err := models.FindUserMFA(s.db, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewAuthenticationError("MFA is not enabled")
+		}
+		return errors.NewDatabaseError(err)
+	}
+	if !mfa.IsConfirmed() {
+		return errors.NewAuthenticationError("MFA is not enabled")
+	}
+
+	step, ok := totp.Validate(mfa.Secret, code, time.Now(), totpAllowedSkew)
+	if !ok || step <= mfa.LastUsedStep {
+		return errors.NewAuthenticationError("invalid MFA code")
+	}
+
+	// 同時に同じコードが使用された場合は片方のみ成功させる
+	result := s.db.Model(&models.UserMFA{}).
+		Where("id = ? AND last_used_step < ?", mfa.ID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return errors.NewDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewAuthenticationError("invalid MFA code")
+	}
+
+	return nil
+}
+
+// =============================================================================
+// MFAチャレンジ
+// =============================================================================
+
+// CreateChallenge パスワード認証済みユーザーのMFAチャレンジトークンを発行
+func (s *MFAService) CreateChallenge(userID uuid.UUID) (string, error) {
+	token, err := generateRefreshToken()
+	if err != nil {
+		return "", errors.NewInternalError("failed to generate MFA challenge")
+	}
+
+	challenge := models.MFAChallenge{
+		UserID:    userID,
+		TokenHash: models.HashMFASecretValue(token),
+		ExpiresAt: time.Now().Add(s.challengeDuration),
+	}
+	if err := s.db.Create(&challenge).Error; err != nil {
+		return "", errors.NewDatabaseError(err)
+	}
+	return token, nil
+}
+
+// ResolveChallenge 使用可能なMFAチャレンジを取得
+func (s *MFAService) ResolveChallenge(token string) (*models.MFAChallenge, error) {
+	challenge, err := models.FindMFAChallengeByToken(s.db, token)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrInvalidToken
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+	if !challenge.IsUsable(mfaChallengeMaxAttempts) {
+		return nil, errors.NewAuthenticationError("MFA challenge is expired or no longer valid")
+	}
+	return challenge, nil
+}
+
+// RecordChallengeFailure コード検証の失敗回数を加算（上限到達でチャレンジ無効）
+func (s *MFAService) RecordChallengeFailure(challenge *models.MFAChallenge) error {
+	if err := s.db.Model(&models.MFAChallenge{}).Where("id = ?", challenge.ID).
+		UpdateColumn("failed_attempts", gorm.Expr("failed_attempts + 1")).Error; err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	return nil
+}
+
+// CompleteChallenge チャレンジを使用済みにする（同時使用は片方のみ成功）
+func (s *MFAService) CompleteChallenge(challenge *models.MFAChallenge) error {
+	result := s.db.Model(&models.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL", challenge.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return errors.NewDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewAuthenticationError("MFA challenge is expired or no longer valid")
+	}
+	return nil
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// isEnrolled 登録完了済みのMFA設定があるかチェック
+func (s *MFAService) isEnrolled(userID uuid.UUID) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.UserMFA{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL", userID).
+		Count(&count).Error; err != nil {
+		return false, errors.NewDatabaseError(err)
+	}
+	return count > 0, nil
+}
+
+// isRequiredByRole MFA必須ロール（プライマリロール・有効なユーザーロール）、またはsystem:adminの実効権限を保持しているかチェック
+// system:adminは親ロールからの継承・ワイルドカードを含めてログイン時に判定する（ロールのrequires_mfaの設定漏れに依存しない）
+func (s *MFAService) isRequiredByRole(user *models.User) (bool, error) {
+	if user.PrimaryRoleID != nil {
+		var count int64
+		if err := s.db.Model(&models.Role{}).
+			Where("id = ? AND requires_mfa = ?", *user.PrimaryRoleID, true).
+			Count(&count).Error; err != nil {
+			return false, errors.NewDatabaseError(err)
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	activeRoles, err := user.GetActiveRoles(s.db)
+	if err != nil {
+		return false, errors.NewDatabaseError(err)
+	}
+	for _, role := range activeRoles {
+		if role.RequiresMFA {
+			return true, nil
+		}
+	}
+
+	permissions, _, err := NewPermissionResolver(s.db).ResolveUserKeys(user.ID)
+	if err != nil {
+		return false, errors.NewDatabaseError(err)
+	}
+	return HasPermission(permissions, mfaRequiredPermission), nil
+}
+
+// useRecoveryCode 未使用のリカバリーコードを使用済みにする
+func (s *MFAService) useRecoveryCode(userID uuid.UUID, recoveryCode string) error {
+	codeHash := models.HashMFASecretValue(normalizeRecoveryCode(recoveryCode))
+	result := s.db.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return errors.NewDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewAuthenticationError("invalid recovery code")
+	}
+
+	s.logger.Info("MFA recovery code used", map[string]interface{}{
+		"user_id": userID,
+	})
+	return nil
+}
+
+// replaceRecoveryCodes 既存のリカバリーコードを破棄して新しいコードを発行
+func (s *MFAService) replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	records := make([]models.MFARecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, errors.NewInternalError("failed to generate recovery codes")
+		}
+		codes[i] = code
+		records[i] = models.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: models.HashMFASecretValue(normalizeRecoveryCode(code)),
+		}
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode 入力しやすい形式（xxxxx-xxxxx）のリカバリーコードを生成
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	encoded := hex.EncodeToString(buf)
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// normalizeRecoveryCode 区切り文字・大文字小文字の違いを吸収
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+)
+
+// パスワードポリシーのルール識別子（違反一覧のruleに使用）
+const (
+	PasswordRuleMinLength        = "min_length"
+	PasswordRuleMaxLength        = "max_length"
+	PasswordRuleRequireUppercase = "require_uppercase"
+	PasswordRuleRequireLowercase = "require_lowercase"
+	PasswordRuleRequireDigit     = "require_digit"
+	PasswordRuleRequireSymbol    = "require_symbol"
+	PasswordRuleBanned           = "banned"
+	PasswordRuleContainsEmail    = "contains_email"
+	PasswordRuleHistory          = "history"
+)
+
+// PasswordPolicy パスワードの強度・履歴・有効期限ポリシー
+type PasswordPolicy struct {
+	MinLength        int
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	BannedPasswords  map[string]struct{} // 小文字で保持
+	HistoryDepth     int                 // 再利用を禁止する直近のパスワード数（0で無効）
+	MaxAge           time.Duration       // パスワードの有効期間（0で無期限）
+	BcryptCost       int
+}
+
+// DefaultPasswordPolicy デフォルトのパスワードポリシー
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        8,
+		MaxLength:        72,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		HistoryDepth:     5,
+		BcryptCost:       bcrypt.DefaultCost,
+	}
+}
+
+// LoadBannedPasswords 使用禁止パスワード一覧ファイルを読み込む（1行1件、#以降はコメント）
+func LoadBannedPasswords(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open banned password list: %w", err)
+	}
+	defer file.Close()
+
+	banned := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line != "" {
+			banned[line] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read banned password list: %w", err)
+	}
+
+	return banned, nil
+}
+
+// Check パスワードが満たしていないルールをすべて返す（履歴は含まない）
+func (p PasswordPolicy) Check(password, email string) []errors.Violation {
+	var violations []errors.Violation
+	add := func(rule, message string) {
+		violations = append(violations, errors.Violation{Rule: rule, Message: message})
+	}
+
+	length := len([]rune(password))
+	if p.MinLength > 0 && length < p.MinLength {
+		add(PasswordRuleMinLength, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		add(PasswordRuleMaxLength, fmt.Sprintf("must be at most %d bytes", p.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUppercase && !hasUpper {
+		add(PasswordRuleRequireUppercase, "must contain an uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		add(PasswordRuleRequireLowercase, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		add(PasswordRuleRequireDigit, "must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		add(PasswordRuleRequireSymbol, "must contain a symbol")
+	}
+
+	lower := strings.ToLower(password)
+	if _, banned := p.BannedPasswords[lower]; banned {
+		add(PasswordRuleBanned, "is too common")
+	}
+	if local, _, found := strings.Cut(strings.ToLower(email), "@"); found && len(local) >= 3 && strings.Contains(lower, local) {
+		add(PasswordRuleContainsEmail, "must not contain the email address")
+	}
+
+	return violations
+}
+
+// IsExpired 最終変更日時からパスワードの有効期限切れを判定
+func (p PasswordPolicy) IsExpired(user *models.User) bool {
+	if p.MaxAge <= 0 {
+		return false
+	}
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	return time.Since(changedAt) > p.MaxAge
+}
+
+// PasswordPolicyService パスワードポリシーの検証・ハッシュ化・履歴管理サービス
+type PasswordPolicyService struct {
+	db     *gorm.DB
+	policy PasswordPolicy
+}
+
+// NewPasswordPolicyService 新しいパスワードポリシーサービスを作成
+func NewPasswordPolicyService(db *gorm.DB, policy PasswordPolicy) *PasswordPolicyService {
+	if policy.BcryptCost == 0 {
+		policy.BcryptCost = bcrypt.DefaultCost
+	}
+	return &PasswordPolicyService{
+		db:     db,
+		policy: policy,
+	}
+}
+
+// Policy 適用中のパスワードポリシーを取得
+func (s *PasswordPolicyService) Policy() PasswordPolicy {
+	return s.policy
+}
+
+// ValidateNewPassword 新しいパスワードを検証し、違反したルールをすべて含むエラーを返す
+// userがnilでない場合（変更時）は現在のパスワードと履歴との一致も禁止する
+func (s *PasswordPolicyService) ValidateNewPassword(field, password, email string, user *models.User) error {
+	violations := s.policy.Check(password, email)
+
+	if user != nil {
+		reused, err := s.isReused(user, password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			violations = append(violations, errors.Violation{
+				Rule:    PasswordRuleHistory,
+				Message: fmt.Sprintf("must not match any of the last %d passwords", s.historyDepth()),
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return errors.NewViolationsError(field, violations)
+	}
+	return nil
+}
+
+// HashPassword 設定されたコストでパスワードをハッシュ化
+func (s *PasswordPolicyService) HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.policy.BcryptCost)
+	if err != nil {
+		return "", errors.NewInternalError("failed to hash password")
+	}
+	return string(hash), nil
+}
+
+// SetPassword パスワードハッシュと変更日時を更新し、旧パスワードを履歴に追加
+func (s *PasswordPolicyService) SetPassword(tx *gorm.DB, user *models.User, passwordHash string) error {
+	now := time.Now()
+	// ユーザーモデルのフックを経由せずに更新
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumns(map[string]interface{}{
+		"password_hash":       passwordHash,
+		"password_changed_at": now,
+	}).Error; err != nil {
+		return err
+	}
+
+	if err := s.RecordHistory(tx, user.ID, user.PasswordHash); err != nil {
+		return err
+	}
+
+	user.PasswordHash = passwordHash
+	user.PasswordChangedAt = &now
+	return nil
+}
+
+// RecordHistory パスワードハッシュを履歴に追加し、ポリシーの件数を超えた古い履歴を削除
+func (s *PasswordPolicyService) RecordHistory(tx *gorm.DB, userID uuid.UUID, passwordHash string) error {
+	if s.policy.HistoryDepth <= 0 || passwordHash == "" {
+		return nil
+	}
+
+	if err := tx.Create(&models.PasswordHistory{UserID: userID, PasswordHash: passwordHash}).Error; err != nil {
+		return err
+	}
+	// 現在のパスワードはusersテーブルで照合するため、履歴は depth-1 件まで保持
+	return models.TrimPasswordHistory(tx, userID, s.historyDepth()-1)
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// historyDepth 照合対象のパスワード数（現在のパスワードを含む）
+func (s *PasswordPolicyService) historyDepth() int {
+	if s.policy.HistoryDepth <= 0 {
+		return 1
+	}
+	return s.policy.HistoryDepth
+}
+
+// isReused 現在のパスワードまたは直近の履歴と一致するかチェック
+func (s *PasswordPolicyService) isReused(user *models.User, password string) (bool, error) {
+	if user.PasswordHash != "" && bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil {
+		return true, nil
+	}
+	if s.policy.HistoryDepth <= 1 {
+		return false, nil
+	}
+
+	history, err := models.FindRecentPasswordHistory(s.db, user.ID, s.policy.HistoryDepth-1)
+	if err != nil {
+		return false, errors.NewDatabaseError(err)
+	}
+	for _, entry := range history {
+		if bcrypt.CompareHashAndPassword([]byte(entry.PasswordHash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
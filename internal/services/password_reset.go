@@ -0,0 +1,240 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+	"erp-access-control-go/pkg/mailer"
+)
+
+// PasswordResetService パスワード再設定（メールでのトークン送付・再設定）サービス
+type PasswordResetService struct {
+	db                *gorm.DB
+	logger            *logger.Logger
+	mailer            mailer.Mailer
+	revocationService *TokenRevocationService
+	passwordPolicy    *PasswordPolicyService
+	tokenDuration     time.Duration
+	resetURL          string // 再設定画面のURL（?token= を付与してメールに記載）
+	wg                sync.WaitGroup
+}
+
+// NewPasswordResetService 新しいパスワード再設定サービスを作成
+func NewPasswordResetService(
+	db *gorm.DB,
+	logger *logger.Logger,
+	mailer mailer.Mailer,
+	revocationService *TokenRevocationService,
+	tokenDuration time.Duration,
+	resetURL string,
+) *PasswordResetService {
+	return &PasswordResetService{
+		db:                db,
+		logger:            logger,
+		mailer:            mailer,
+		revocationService: revocationService,
+		passwordPolicy:    NewPasswordPolicyService(db, DefaultPasswordPolicy()),
+		tokenDuration:     tokenDuration,
+		resetURL:          resetURL,
+	}
+}
+
+// SetPasswordPolicyService 再設定時に適用するパスワードポリシーを設定
+func (s *PasswordResetService) SetPasswordPolicyService(passwordPolicy *PasswordPolicyService) {
+	s.passwordPolicy = passwordPolicy
+}
+
+// RequestPasswordReset 再設定トークンを発行してメールで送付
+// 登録の有無を推測されないよう、存在しない・無効なユーザーでもエラーを返さない
+// 応答時間の差からも推測されないよう、トークンの発行とメール送信はバックグラウンドで行う
+func (s *PasswordResetService) RequestPasswordReset(email, ipAddress string) error {
+	var user models.User
+	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			s.logger.Info("Password reset requested for unknown email", map[string]interface{}{
+				"ip": ipAddress,
+			})
+			return nil
+		}
+		return errors.NewDatabaseError(err)
+	}
+	if !user.IsActive() {
+		s.logger.Info("Password reset requested for inactive user", map[string]interface{}{
+			"user_id": user.ID,
+			"ip":      ipAddress,
+		})
+		return nil
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.issueResetToken(user, ipAddress)
+	}()
+	return nil
+}
+
+// Wait バックグラウンドで処理中の再設定メール送信の終了を待機
+func (s *PasswordResetService) Wait() {
+	s.wg.Wait()
+}
+
+// ResetPassword 再設定トークンを検証して新しいパスワードを設定
+// 成功時はトークンを使用済みにし、ユーザーの全セッションを無効化する
+func (s *PasswordResetService) ResetPassword(token, newPassword string) error {
+	resetToken, err := models.FindPasswordResetTokenByToken(s.db, token)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrInvalidToken
+		}
+		return errors.NewDatabaseError(err)
+	}
+	if !resetToken.IsUsable() {
+		return errors.NewAuthenticationError("password reset token is expired or no longer valid")
+	}
+
+	var user models.User
+	if err := s.db.First(&user, "id = ?", resetToken.UserID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrInvalidToken
+		}
+		return errors.NewDatabaseError(err)
+	}
+	if !user.IsActive() {
+		return errors.NewAuthenticationError("password reset token is expired or no longer valid")
+	}
+
+	if err := s.passwordPolicy.ValidateNewPassword("new_password", newPassword, user.Email, &user); err != nil {
+		return err
+	}
+	hashedPassword, err := s.passwordPolicy.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	consumed := true
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 同時に使用された場合は片方のみ成功させる
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			consumed = false
+			return nil
+		}
+
+		if err := s.passwordPolicy.SetPassword(tx, &user, hashedPassword); err != nil {
+			return err
+		}
+		// メールの受信で本人確認できたため、ログイン失敗によるロックも解除
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumns(map[string]interface{}{
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		}).Error
+	}); err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	if !consumed {
+		return errors.NewAuthenticationError("password reset token is expired or no longer valid")
+	}
+
+	if err := s.revocationService.RevokeAllUserTokens(user.ID, "password_reset"); err != nil {
+		s.logger.Error("Failed to revoke sessions after password reset", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+		return err
+	}
+
+	s.logger.Info("Password reset completed", map[string]interface{}{
+		"user_id": user.ID,
+	})
+	return nil
+}
+
+// CleanupExpiredTokens 期限切れの再設定トークンを削除（定期ジョブ用）
+func (s *PasswordResetService) CleanupExpiredTokens() (int64, error) {
+	deleted, err := models.CleanupExpiredPasswordResetTokens(s.db)
+	if err != nil {
+		return 0, errors.NewDatabaseError(err)
+	}
+	return deleted, nil
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// issueResetToken 再設定トークンを発行してメールで送付
+// 失敗はリクエスト元に返さずログのみ記録する（登録の有無の推測防止）
+func (s *PasswordResetService) issueResetToken(user models.User, ipAddress string) {
+	token, err := generateRefreshToken()
+	if err != nil {
+		s.logger.Error("Failed to generate password reset token", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+		return
+	}
+
+	resetToken := models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: models.HashPasswordResetToken(token),
+		ExpiresAt: time.Now().Add(s.tokenDuration),
+	}
+	if ipAddress != "" {
+		resetToken.RequestedIP = &ipAddress
+	}
+
+	// 以前に発行した未使用トークンは無効化し、最新のトークンのみ使用可能にする
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := models.InvalidateUserPasswordResetTokens(tx, user.ID); err != nil {
+			return err
+		}
+		return tx.Create(&resetToken).Error
+	}); err != nil {
+		s.logger.Error("Failed to store password reset token", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+		return
+	}
+
+	if err := s.mailer.Send(s.buildResetMessage(user.Email, token)); err != nil {
+		s.logger.Error("Failed to send password reset mail", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+		return
+	}
+
+	s.logger.Info("Password reset token issued", map[string]interface{}{
+		"user_id":    user.ID,
+		"expires_at": resetToken.ExpiresAt,
+		"ip":         ipAddress,
+	})
+}
+
+// buildResetMessage 再設定リンクを記載したメールを作成
+func (s *PasswordResetService) buildResetMessage(email, token string) mailer.Message {
+	link := s.resetURL + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf(`パスワード再設定のリクエストを受け付けました。
+
+以下のリンクから %d 分以内に新しいパスワードを設定してください。
+%s
+
+このメールに心当たりがない場合は破棄してください。パスワードは変更されません。
+`, int(s.tokenDuration.Minutes()), link)
+
+	return mailer.Message{
+		To:      []string{email},
+		Subject: "【ERP Access Control】パスワード再設定のご案内",
+		Body:    body,
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+	"erp-access-control-go/pkg/mailer"
+)
+
+// recordingMailer 送信したメールを保持するテスト用Mailer
+type recordingMailer struct {
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// blockingMailer releaseが閉じられるまで送信を完了しないテスト用Mailer
+type blockingMailer struct {
+	release chan struct{}
+	sent    chan mailer.Message
+}
+
+func (m *blockingMailer) Send(msg mailer.Message) error {
+	<-m.release
+	m.sent <- msg
+	return nil
+}
+
+var resetTokenPattern = regexp.MustCompile(`\?token=(\S+)`)
+
+// lastResetToken 最後に送信したメールから再設定トークンを取り出す
+func (m *recordingMailer) lastResetToken(t *testing.T) string {
+	require.NotEmpty(t, m.messages)
+	match := resetTokenPattern.FindStringSubmatch(m.messages[len(m.messages)-1].Body)
+	require.Len(t, match, 2)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+// setupTestPasswordReset パスワード再設定テスト用のサービスを作成
+func setupTestPasswordReset(t *testing.T) (*PasswordResetService, *AuthService, *recordingMailer, *gorm.DB) {
+	authService, db := setupTestAuth(t)
+
+	policy := DefaultPasswordPolicy()
+	policy.BcryptCost = bcrypt.MinCost
+	passwordPolicy := NewPasswordPolicyService(db, policy)
+	authService.SetPasswordPolicyService(passwordPolicy)
+
+	sent := &recordingMailer{}
+	service := NewPasswordResetService(db, logger.NewLogger(), sent, NewTokenRevocationService(db), 30*time.Minute, "https://erp.example.com/reset-password")
+	service.SetPasswordPolicyService(passwordPolicy)
+	return service, authService, sent, db
+}
+
+func TestPasswordResetService_ResetFlow(t *testing.T) {
+	service, authService, sent, db := setupTestPasswordReset(t)
+	userID := createAuthTestUser(t, db, "forgot@example.com", "Initial-Pass1")
+
+	login, err := authService.Login(LoginRequest{Email: "forgot@example.com", Password: "Initial-Pass1"})
+	require.NoError(t, err)
+
+	require.NoError(t, service.RequestPasswordReset("forgot@example.com", "192.0.2.10"))
+	service.Wait()
+	require.Len(t, sent.messages, 1)
+	assert.Equal(t, []string{"forgot@example.com"}, sent.messages[0].To)
+	assert.Contains(t, sent.messages[0].Body, "https://erp.example.com/reset-password?token=")
+	token := sent.lastResetToken(t)
+
+	var stored models.PasswordResetToken
+	require.NoError(t, db.First(&stored, "user_id = ?", userID).Error)
+	assert.NotEqual(t, token, stored.TokenHash, "トークン本体は保存しない")
+	assert.Equal(t, models.HashPasswordResetToken(token), stored.TokenHash)
+
+	t.Run("ポリシー違反はトークンを消費しない", func(t *testing.T) {
+		err := service.ResetPassword(token, "weak")
+		require.Error(t, err)
+		assert.True(t, errors.IsValidationError(err))
+	})
+
+	t.Run("再設定成功で全セッションを無効化", func(t *testing.T) {
+		require.NoError(t, service.ResetPassword(token, "Reset-Pass2"))
+
+		_, err := authService.RefreshToken(login.RefreshToken)
+		assert.Error(t, err, "既存のリフレッシュトークンは使用不可")
+
+		_, err = authService.Login(LoginRequest{Email: "forgot@example.com", Password: "Initial-Pass1"})
+		assert.True(t, errors.IsAuthenticationError(err))
+		_, err = authService.Login(LoginRequest{Email: "forgot@example.com", Password: "Reset-Pass2"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("トークンは1回限り", func(t *testing.T) {
+		err := service.ResetPassword(token, "Another-Pass3")
+		assert.True(t, errors.IsAuthenticationError(err))
+	})
+}
+
+func TestPasswordResetService_TokenLifecycle(t *testing.T) {
+	service, _, sent, db := setupTestPasswordReset(t)
+	userID := createAuthTestUser(t, db, "lifecycle@example.com", "Initial-Pass1")
+
+	t.Run("存在しないメールアドレスでもエラーを返さない", func(t *testing.T) {
+		require.NoError(t, service.RequestPasswordReset("unknown@example.com", ""))
+		service.Wait()
+		assert.Empty(t, sent.messages)
+	})
+
+	t.Run("新しい要求で以前のトークンを無効化", func(t *testing.T) {
+		require.NoError(t, service.RequestPasswordReset("lifecycle@example.com", ""))
+		service.Wait()
+		first := sent.lastResetToken(t)
+		require.NoError(t, service.RequestPasswordReset("lifecycle@example.com", ""))
+		service.Wait()
+		second := sent.lastResetToken(t)
+
+		assert.True(t, errors.IsAuthenticationError(service.ResetPassword(first, "Reset-Pass2")))
+		assert.NoError(t, service.ResetPassword(second, "Reset-Pass2"))
+	})
+
+	t.Run("期限切れトークンは拒否", func(t *testing.T) {
+		require.NoError(t, service.RequestPasswordReset("lifecycle@example.com", ""))
+		service.Wait()
+		token := sent.lastResetToken(t)
+		require.NoError(t, db.Model(&models.PasswordResetToken{}).
+			Where("token_hash = ?", models.HashPasswordResetToken(token)).
+			UpdateColumn("expires_at", time.Now().Add(-time.Minute)).Error)
+
+		assert.True(t, errors.IsAuthenticationError(service.ResetPassword(token, "Expired-Pass3")))
+	})
+
+	t.Run("不明なトークンは拒否", func(t *testing.T) {
+		assert.Equal(t, errors.ErrInvalidToken, service.ResetPassword("unknown-token", "Unknown-Pass4"))
+	})
+
+	var user models.User
+	require.NoError(t, db.First(&user, "id = ?", userID).Error)
+	assert.True(t, user.CheckPassword("Reset-Pass2"))
+}
+
+// TestPasswordResetService_RequestDoesNotWaitForMail 登録済みのメールアドレスでもメール送信の完了を待たずに応答することのテスト
+func TestPasswordResetService_RequestDoesNotWaitForMail(t *testing.T) {
+	_, _, _, db := setupTestPasswordReset(t)
+	createAuthTestUser(t, db, "slow-mail@example.com", "Initial-Pass1")
+
+	blocking := &blockingMailer{release: make(chan struct{}), sent: make(chan mailer.Message, 1)}
+	service := NewPasswordResetService(db, logger.NewLogger(), blocking, NewTokenRevocationService(db), 30*time.Minute, "https://erp.example.com/reset-password")
+
+	done := make(chan error, 1)
+	go func() { done <- service.RequestPasswordReset("slow-mail@example.com", "") }()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("メール送信の完了を待って応答している")
+	}
+
+	close(blocking.release)
+	service.Wait()
+	msg := <-blocking.sent
+	assert.Equal(t, []string{"slow-mail@example.com"}, msg.To)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -15,18 +16,34 @@ import (
 
 // PermissionService 権限評価・管理サービス
 type PermissionService struct {
-	db     *gorm.DB
-	logger *logger.Logger
+	db       *gorm.DB
+	logger   *logger.Logger
+	resolver *PermissionResolver
+	cache    PermissionCache // 未設定の場合は毎回データベースで解決
+	cacheTTL time.Duration
 }
 
 // NewPermissionService 新しい権限サービスを作成
 func NewPermissionService(db *gorm.DB, logger *logger.Logger) *PermissionService {
 	return &PermissionService{
-		db:     db,
-		logger: logger,
+		db:       db,
+		logger:   logger,
+		resolver: NewPermissionResolver(db),
 	}
 }
 
+// SetPermissionCache 実効権限のキャッシュを設定（ttlはエントリの最大保持期間）
+// 権限に影響する変更を行う各サービスにも同じキャッシュを設定すること
+func (s *PermissionService) SetPermissionCache(cache PermissionCache, ttl time.Duration) {
+	s.cache = cache
+	s.cacheTTL = ttl
+}
+
+// PermissionCache 設定済みの実効権限のキャッシュを取得（未設定の場合はnil）
+func (s *PermissionService) PermissionCache() PermissionCache {
+	return s.cache
+}
+
 // =============================================================================
 // CRUD操作用の新しい構造体
 // =============================================================================
@@ -107,7 +124,8 @@ type ActionInfo struct {
 	Name         string   `json:"name"`
 	DisplayName  string   `json:"display_name"`
 	PermissionID string   `json:"permission_id"`
-	Roles        []string `json:"roles"`
+	Roles        []string `json:"roles"`        // 許可しているロール
+	DeniedRoles  []string `json:"denied_roles"` // 拒否しているロール（継承・他ロールの許可より優先）
 }
 
 // MatrixSummaryInfo マトリックス概要情報
@@ -403,15 +421,16 @@ func (s *PermissionService) GetPermissionMatrix() (*PermissionMatrixResponse, er
 
 	// ロール-権限関係取得
 	var rolePerms []struct {
-		RoleID       uuid.UUID `json:"role_id"`
-		RoleName     string    `json:"role_name"`
-		PermissionID uuid.UUID `json:"permission_id"`
-		Module       string    `json:"module"`
-		Action       string    `json:"action"`
+		RoleID       uuid.UUID               `json:"role_id"`
+		RoleName     string                  `json:"role_name"`
+		PermissionID uuid.UUID               `json:"permission_id"`
+		Module       string                  `json:"module"`
+		Action       string                  `json:"action"`
+		Effect       models.PermissionEffect `json:"effect"`
 	}
 
 	query := `
-		SELECT rp.role_id, r.name as role_name, rp.permission_id, p.module, p.action
+		SELECT rp.role_id, r.name as role_name, rp.permission_id, p.module, p.action, rp.effect
 		FROM role_permissions rp
 		JOIN roles r ON rp.role_id = r.id
 		JOIN permissions p ON rp.permission_id = p.id
@@ -424,8 +443,13 @@ func (s *PermissionService) GetPermissionMatrix() (*PermissionMatrixResponse, er
 
 	// ロール別権限マップ作成
 	permissionRoles := make(map[string][]string)
+	deniedRoles := make(map[string][]string)
 	for _, rp := range rolePerms {
 		permKey := rp.Module + ":" + rp.Action
+		if rp.Effect == models.PermissionEffectDeny {
+			deniedRoles[permKey] = append(deniedRoles[permKey], rp.RoleName)
+			continue
+		}
 		permissionRoles[permKey] = append(permissionRoles[permKey], rp.RoleName)
 	}
 
@@ -440,6 +464,7 @@ func (s *PermissionService) GetPermissionMatrix() (*PermissionMatrixResponse, er
 			DisplayName:  s.getActionDisplayName(perm.Action),
 			PermissionID: perm.ID.String(),
 			Roles:        permissionRoles[permKey],
+			DeniedRoles:  deniedRoles[permKey],
 		}
 
 		moduleMap[perm.Module] = append(moduleMap[perm.Module], actionInfo)
@@ -460,7 +485,7 @@ func (s *PermissionService) GetPermissionMatrix() (*PermissionMatrixResponse, er
 	unusedCount := 0
 	for _, perm := range permissions {
 		permKey := perm.Module + ":" + perm.Action
-		if len(permissionRoles[permKey]) == 0 {
+		if len(permissionRoles[permKey]) == 0 && len(deniedRoles[permKey]) == 0 {
 			unusedCount++
 		}
 	}
@@ -571,7 +596,7 @@ func (s *PermissionService) isSystemPermission(module, action string) bool {
 	systemPermissions := []string{
 		"user:read", "user:list", "department:read", "department:list",
 		"role:read", "role:list", "permission:read", "permission:list",
-		"system:admin", "audit:read",
+		"system:admin", "audit:read", "*:*",
 	}
 
 	permKey := module + ":" + action
@@ -865,6 +890,28 @@ const (
 	ModuleReports    Module = "reports"
 )
 
+// moduleResourceTypes 権限モジュール → リソース種別（time_restrictions・audit_logsのresource_type）
+var moduleResourceTypes = map[Module]string{
+	ModuleUser:       "users",
+	ModuleDepartment: "departments",
+	ModuleRole:       "roles",
+	ModulePermission: "permissions",
+	ModuleAudit:      "audit",
+	ModuleSystem:     "system",
+	ModuleInventory:  "inventory",
+	ModuleOrders:     "orders",
+	ModuleReports:    "reports",
+}
+
+// ResourceTypeForPermission 権限文字列（module:action）からリソース種別を取得（該当なしは空文字列）
+func ResourceTypeForPermission(permission string) string {
+	module := permission
+	if idx := strings.Index(permission, ":"); idx >= 0 {
+		module = permission[:idx]
+	}
+	return moduleResourceTypes[Module(module)]
+}
+
 // Action 実行可能なアクションを表す
 type Action string
 
@@ -889,241 +936,37 @@ func NewPermission(module Module, action Action) Permission {
 	return Permission(fmt.Sprintf("%s:%s", module, action))
 }
 
-// PermissionMatrix システムの権限マトリックスを定義
-// TODO: アーキテクチャ改善
-// - データベースベース権限管理への移行検討
-// - 動的権限追加・削除機能
-// - 時間ベース権限 (営業時間のみ有効など)
-// - 地理的制限 (特定IPレンジ、地域からのみアクセス)
-// - 継承ベース階層権限 (部門長→課長→係長の自動継承)
-var PermissionMatrix = map[string][]Permission{
-	// システム管理者 - Full access
-	"システム管理者": {
-		"*:*", // Wildcard permission for everything
-	},
-
-	// 部門管理者 - Most permissions except system management
-	"部門管理者": {
-		NewPermission(ModuleUser, ActionCreate),
-		NewPermission(ModuleUser, ActionRead),
-		NewPermission(ModuleUser, ActionUpdate),
-		NewPermission(ModuleUser, ActionDelete),
-		NewPermission(ModuleUser, ActionList),
-		NewPermission(ModuleDepartment, ActionCreate),
-		NewPermission(ModuleDepartment, ActionRead),
-		NewPermission(ModuleDepartment, ActionUpdate),
-		NewPermission(ModuleDepartment, ActionDelete),
-		NewPermission(ModuleDepartment, ActionList),
-		NewPermission(ModuleRole, ActionCreate),
-		NewPermission(ModuleRole, ActionRead),
-		NewPermission(ModuleRole, ActionUpdate),
-		NewPermission(ModuleRole, ActionDelete),
-		NewPermission(ModuleRole, ActionList),
-		NewPermission(ModulePermission, ActionRead),
-		NewPermission(ModulePermission, ActionList),
-		NewPermission(ModuleAudit, ActionRead),
-		NewPermission(ModuleAudit, ActionList),
-	},
-
-	// 開発者 - Development related permissions
-	"開発者": {
-		NewPermission(ModuleUser, ActionRead),
-		NewPermission(ModuleUser, ActionUpdate),
-		NewPermission(ModuleUser, ActionList),
-		NewPermission(ModuleDepartment, ActionRead),
-		NewPermission(ModuleDepartment, ActionList),
-		NewPermission(ModuleRole, ActionRead),
-		NewPermission(ModuleRole, ActionList),
-		NewPermission(ModuleAudit, ActionRead),
-	},
-
-	// 一般ユーザー - Basic read access
-	"一般ユーザー": {
-		NewPermission(ModuleUser, ActionRead),
-		NewPermission(ModuleDepartment, ActionRead),
-		NewPermission(ModuleRole, ActionRead),
-	},
-
-	// ゲストユーザー - Read-only access
-	"ゲストユーザー": {
-		NewPermission(ModuleUser, ActionRead),
-		NewPermission(ModuleUser, ActionList),
-		NewPermission(ModuleDepartment, ActionRead),
-		NewPermission(ModuleDepartment, ActionList),
-		NewPermission(ModuleRole, ActionRead),
-		NewPermission(ModuleRole, ActionList),
-	},
-
-	// プロジェクトマネージャー - Project management permissions
-	"プロジェクトマネージャー": {
-		NewPermission(ModuleUser, ActionRead),
-		NewPermission(ModuleUser, ActionUpdate),
-		NewPermission(ModuleUser, ActionList),
-		NewPermission(ModuleDepartment, ActionRead),
-		NewPermission(ModuleDepartment, ActionUpdate),
-		NewPermission(ModuleDepartment, ActionList),
-		NewPermission(ModuleRole, ActionRead),
-		NewPermission(ModuleRole, ActionUpdate),
-		NewPermission(ModuleRole, ActionList),
-		NewPermission(ModuleAudit, ActionRead),
-	},
-
-	// テスター - Testing related permissions
-	"テスター": {
-		NewPermission(ModuleUser, ActionRead),
-		NewPermission(ModuleUser, ActionList),
-		NewPermission(ModuleDepartment, ActionRead),
-		NewPermission(ModuleDepartment, ActionList),
-		NewPermission(ModuleRole, ActionRead),
-		NewPermission(ModuleRole, ActionList),
-		NewPermission(ModuleAudit, ActionRead),
-	},
-}
-
-// GetUserPermissions ユーザーの全権限を取得（複数ロール対応）
+// GetUserPermissions ユーザーの実効権限を取得（複数ロール・親ロールからの継承に対応）
+// ログイン・トークンリフレッシュ時のJWTとCheckPermissionはこの結果で判定する
 func (s *PermissionService) GetUserPermissions(userID uuid.UUID) ([]string, error) {
-	// TODO: パフォーマンス最適化
-	// - Redis/Memcachedによる権限キャッシュ (TTL: 5-15分)
-	// - 階層的権限の事前計算とキャッシュ
-	// - バッチ権限取得機能 (複数ユーザー一括処理)
-	// - 権限変更時のキャッシュ無効化戦略
-
-	var user models.User
-	if err := s.db.Preload("UserRoles.Role.Permissions").Preload("PrimaryRole.Permissions").First(&user, userID).Error; err != nil {
-		return nil, err
-	}
-
-	permissionSet := make(map[string]bool)
-
-	// デバッグログ：ユーザーロール情報
-	s.logger.Info("GetUserPermissions debug", map[string]interface{}{
-		"user_id":          userID,
-		"primary_role":     user.PrimaryRole,
-		"user_roles_count": len(user.UserRoles),
-	})
-
-	// 複数ロールから権限を集約
-	for _, userRole := range user.UserRoles {
-		// アクティブで有効期間内のロールのみ処理
-		if !userRole.IsValidNow() {
-			continue
-		}
-
-		s.logger.Info("Processing user role", map[string]interface{}{
-			"role_name": userRole.Role.Name,
-			"role_id":   userRole.Role.ID,
-		})
-
-		// Get base permissions from matrix for each role
-		if basePerms, exists := PermissionMatrix[userRole.Role.Name]; exists {
-			s.logger.Info("Found role in PermissionMatrix", map[string]interface{}{
-				"role_name":        userRole.Role.Name,
-				"permissions":      basePerms,
-				"permission_count": len(basePerms),
-			})
-			for _, perm := range basePerms {
-				permString := string(perm)
-				permissionSet[permString] = true
-
-				// ワイルドカード権限の場合はそのまま追加
-				if permString == "*:*" || permString == "*" {
-					permissionSet[permString] = true
-				}
-			}
-		} else {
-			s.logger.Warn("Role not found in PermissionMatrix", map[string]interface{}{
-				"role_name": userRole.Role.Name,
-			})
-		}
-
-		// Add explicit permissions from database for each role
-		for _, perm := range userRole.Role.Permissions {
-			permissionSet[perm.GetUniqueKey()] = true
+	if s.cache != nil {
+		if permissions, ok := s.cache.Get(userID); ok {
+			return permissions, nil
 		}
 	}
 
-	// 後方互換性: PrimaryRoleが設定されている場合は優先
-	if user.PrimaryRole != nil {
-		s.logger.Info("Processing primary role", map[string]interface{}{
-			"primary_role_name": user.PrimaryRole.Name,
-			"primary_role_id":   user.PrimaryRole.ID,
-		})
-
-		if basePerms, exists := PermissionMatrix[user.PrimaryRole.Name]; exists {
-			s.logger.Info("Found primary role in PermissionMatrix", map[string]interface{}{
-				"role_name":        user.PrimaryRole.Name,
-				"permissions":      basePerms,
-				"permission_count": len(basePerms),
-			})
-			for _, perm := range basePerms {
-				permString := string(perm)
-				permissionSet[permString] = true
-
-				// ワイルドカード権限の場合はそのまま追加
-				if permString == "*:*" || permString == "*" {
-					permissionSet[permString] = true
-				}
-			}
-		} else {
-			s.logger.Warn("Primary role not found in PermissionMatrix", map[string]interface{}{
-				"role_name": user.PrimaryRole.Name,
-			})
-		}
-
-		// PrimaryRoleの明示的権限も追加
-		for _, perm := range user.PrimaryRole.Permissions {
-			permissionSet[perm.GetUniqueKey()] = true
-		}
+	permissions, validUntil, err := s.resolver.ResolveUserKeys(userID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Convert to slice
-	permissions := make([]string, 0, len(permissionSet))
-	for perm := range permissionSet {
-		permissions = append(permissions, perm)
+	if s.cache != nil {
+		// ユーザーロールの有効期間の開始・終了時刻を過ぎたエントリは使用しない
+		ttl := s.cacheTTL
+		if !validUntil.IsZero() && time.Until(validUntil) < ttl {
+			ttl = time.Until(validUntil)
+		}
+		s.cache.Set(userID, permissions, ttl)
 	}
 
-	s.logger.Info("Final permissions result", map[string]interface{}{
+	s.logger.Debug("User permissions resolved", map[string]interface{}{
 		"user_id":           userID,
 		"total_permissions": len(permissions),
-		"permissions":       permissions,
 	})
 
 	return permissions, nil
 }
 
-// GetUserRoleHierarchyPermissions 階層ロール権限を含めて取得
-func (s *PermissionService) GetUserRoleHierarchyPermissions(userID uuid.UUID) ([]string, error) {
-	var permissions []string
-
-	query := `
-		WITH RECURSIVE role_hierarchy AS (
-			-- アクティブなユーザーロール
-			SELECT ur.role_id, ur.priority
-			FROM user_roles ur
-			WHERE ur.user_id = ? 
-				AND ur.is_active = true
-				AND ur.valid_from <= NOW()
-				AND (ur.valid_to IS NULL OR ur.valid_to > NOW())
-			
-			UNION
-			
-			-- 親ロールを辿る
-			SELECT r.parent_id, rh.priority
-			FROM roles r
-			JOIN role_hierarchy rh ON r.id = rh.role_id
-			WHERE r.parent_id IS NOT NULL
-		)
-		SELECT DISTINCT CONCAT(p.module, ':', p.action) as permission
-		FROM permissions p
-		JOIN role_permissions rp ON p.id = rp.permission_id
-		JOIN role_hierarchy rh ON rp.role_id = rh.role_id
-		ORDER BY permission
-	`
-
-	err := s.db.Raw(query, userID).Pluck("permission", &permissions).Error
-	return permissions, err
-}
-
 // CheckPermission ユーザーが特定の権限を持っているかチェック（複数ロール対応）
 func (s *PermissionService) CheckPermission(userID uuid.UUID, requiredPermission string) (bool, error) {
 	// TODO: 監査ログ強化
@@ -1137,12 +980,41 @@ func (s *PermissionService) CheckPermission(userID uuid.UUID, requiredPermission
 	}
 
 	hasPermission := s.hasPermission(permissions, requiredPermission)
+	if !hasPermission {
+		return false, nil
+	}
+
+	// 時間制限チェック（権限があっても許可時間外は拒否）
+	if err := s.CheckTimeAccess(userID, requiredPermission, time.Now()); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CheckTimeAccess 権限のモジュールに対応するリソースの時間制限をチェック
+// 許可時間外の場合はTIME_RESTRICTEDエラーを返す
+func (s *PermissionService) CheckTimeAccess(userID uuid.UUID, permission string, at time.Time) error {
+	resourceType := ResourceTypeForPermission(permission)
+	if resourceType == "" {
+		return nil
+	}
 
-	// TODO: ログ出力実装
-	// log.Info("Permission check", zap.String("user_id", userID.String()),
-	//     zap.String("permission", requiredPermission), zap.Bool("granted", hasPermission))
+	allowed, err := models.CheckTimeAccess(s.db, userID, resourceType, at)
+	if err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	if !allowed {
+		s.logger.Warn("Access denied by time restriction", map[string]interface{}{
+			"user_id":       userID,
+			"permission":    permission,
+			"resource_type": resourceType,
+			"checked_at":    at.UTC().Format(time.RFC3339),
+		})
+		return errors.NewTimeRestrictedError(resourceType)
+	}
 
-	return hasPermission, nil
+	return nil
 }
 
 // CheckPermissionWithScope スコープ条件付きで権限をチェック
@@ -1156,9 +1028,14 @@ func (s *PermissionService) CheckPermissionWithScope(userID uuid.UUID, requiredP
 		return false, nil
 	}
 
-	// Check scope restrictions
-	var userScopes []models.UserScope
-	if err := s.db.Where("user_id = ?", userID).Find(&userScopes).Error; err != nil {
+	return s.CheckScopeAccess(userID, requiredPermission, resourceScope)
+}
+
+// CheckScopeAccess 権限のリソース種別に設定されたユーザースコープでリソーススコープを照合
+// スコープが未設定の場合は制限なしとして許可する
+func (s *PermissionService) CheckScopeAccess(userID uuid.UUID, permission string, resourceScope map[string]interface{}) (bool, error) {
+	userScopes, err := s.findScopesForPermission(userID, permission)
+	if err != nil {
 		return false, err
 	}
 
@@ -1169,12 +1046,11 @@ func (s *PermissionService) CheckPermissionWithScope(userID uuid.UUID, requiredP
 
 	// Check if any scope matches
 	for _, scope := range userScopes {
-		// Convert JSONB to json.RawMessage
-		scopeJSON, err := json.Marshal(scope.ScopeValue)
+		_, matched, err := s.matchScope(scope, resourceScope)
 		if err != nil {
-			continue
+			return false, err
 		}
-		if s.evaluateScope(json.RawMessage(scopeJSON), resourceScope) {
+		if matched {
 			return true, nil
 		}
 	}
@@ -1182,8 +1058,90 @@ func (s *PermissionService) CheckPermissionWithScope(userID uuid.UUID, requiredP
 	return false, nil
 }
 
+// findScopesForPermission 権限のリソース種別に設定されたユーザースコープを取得
+func (s *PermissionService) findScopesForPermission(userID uuid.UUID, permission string) ([]models.UserScope, error) {
+	query := s.db.Where("user_id = ?", userID)
+	if resourceType := ResourceTypeForPermission(permission); resourceType != "" {
+		query = query.Where("resource_type = ?", resourceType)
+	}
+
+	var userScopes []models.UserScope
+	if err := query.Order("id").Find(&userScopes).Error; err != nil {
+		return nil, err
+	}
+	return userScopes, nil
+}
+
+// matchScope ユーザースコープを展開してリソーススコープと照合
+func (s *PermissionService) matchScope(scope models.UserScope, resourceScope map[string]interface{}) (map[string]interface{}, bool, error) {
+	scopeValue, err := s.expandScopeValue(scope)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Convert JSONB to json.RawMessage
+	scopeJSON, err := json.Marshal(scopeValue)
+	if err != nil {
+		return scopeValue, false, nil
+	}
+	return scopeValue, evaluateScope(json.RawMessage(scopeJSON), resourceScope), nil
+}
+
+// expandScopeValue 照合用にスコープ値を展開
+// 部署スコープのinclude_childrenは配下の部署IDを含む配列に展開する
+func (s *PermissionService) expandScopeValue(scope models.UserScope) (map[string]interface{}, error) {
+	expanded := make(map[string]interface{}, len(scope.ScopeValue))
+	for key, value := range scope.ScopeValue {
+		expanded[key] = value
+	}
+
+	if scope.ScopeType != models.ScopeTypeDepartment {
+		return expanded, nil
+	}
+	includeChildren, _ := expanded[ScopeKeyIncludeChildren].(bool)
+	delete(expanded, ScopeKeyIncludeChildren)
+	if !includeChildren {
+		return expanded, nil
+	}
+
+	var rootIDs []string
+	switch v := expanded[ScopeKeyDepartmentID].(type) {
+	case string:
+		rootIDs = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if id, ok := item.(string); ok {
+				rootIDs = append(rootIDs, id)
+			}
+		}
+	}
+
+	departmentIDs := make([]interface{}, 0, len(rootIDs))
+	for _, rootID := range rootIDs {
+		departmentIDs = append(departmentIDs, rootID)
+		if rootID == "*" {
+			continue
+		}
+		parsedID, err := uuid.Parse(rootID)
+		if err != nil {
+			continue
+		}
+		root := models.Department{BaseModel: models.BaseModel{ID: parsedID}}
+		descendants, err := root.GetDescendants(s.db)
+		if err != nil {
+			return nil, err
+		}
+		for _, descendant := range descendants {
+			departmentIDs = append(departmentIDs, descendant.ID.String())
+		}
+	}
+	expanded[ScopeKeyDepartmentID] = departmentIDs
+
+	return expanded, nil
+}
+
 // evaluateScope JSONBスコープ条件をリソーススコープと照合評価
-func (s *PermissionService) evaluateScope(scopeValue json.RawMessage, resourceScope map[string]interface{}) bool {
+func evaluateScope(scopeValue json.RawMessage, resourceScope map[string]interface{}) bool {
 	var conditions map[string]interface{}
 	if err := json.Unmarshal(scopeValue, &conditions); err != nil {
 		return false
@@ -1191,7 +1149,7 @@ func (s *PermissionService) evaluateScope(scopeValue json.RawMessage, resourceSc
 
 	for key, expectedValue := range conditions {
 		if actualValue, exists := resourceScope[key]; exists {
-			if !s.compareValues(expectedValue, actualValue) {
+			if !compareScopeValues(expectedValue, actualValue) {
 				return false
 			}
 		} else {
@@ -1203,8 +1161,8 @@ func (s *PermissionService) evaluateScope(scopeValue json.RawMessage, resourceSc
 	return true
 }
 
-// compareValues 配列とワイルドカードをサポートしてスコープ値を比較
-func (s *PermissionService) compareValues(expected, actual interface{}) bool {
+// compareScopeValues 配列とワイルドカードをサポートしてスコープ値を比較
+func compareScopeValues(expected, actual interface{}) bool {
 	switch exp := expected.(type) {
 	case string:
 		if exp == "*" {
@@ -1216,7 +1174,7 @@ func (s *PermissionService) compareValues(expected, actual interface{}) bool {
 	case []interface{}:
 		// Check if actual value is in the expected array
 		for _, val := range exp {
-			if s.compareValues(val, actual) {
+			if compareScopeValues(val, actual) {
 				return true
 			}
 		}
@@ -1225,7 +1183,7 @@ func (s *PermissionService) compareValues(expected, actual interface{}) bool {
 		if actMap, ok := actual.(map[string]interface{}); ok {
 			for k, v := range exp {
 				if actVal, exists := actMap[k]; exists {
-					if !s.compareValues(v, actVal) {
+					if !compareScopeValues(v, actVal) {
 						return false
 					}
 				} else {
@@ -1240,45 +1198,9 @@ func (s *PermissionService) compareValues(expected, actual interface{}) bool {
 	return false
 }
 
-// hasPermission ワイルドカードサポート付きでユーザー権限に指定権限が存在するかチェック
+// hasPermission ワイルドカード・拒否権限を考慮してユーザー権限に指定権限が存在するかチェック
 func (s *PermissionService) hasPermission(userPermissions []string, requiredPermission string) bool {
-	for _, perm := range userPermissions {
-		if perm == "*" || perm == "*:*" {
-			return true // Super admin wildcard
-		}
-		if perm == requiredPermission {
-			return true // Exact match
-		}
-		if s.matchesWildcard(perm, requiredPermission) {
-			return true // Wildcard pattern match
-		}
-	}
-	return false
-}
-
-// matchesWildcard 権限がワイルドカードパターンにマッチするかチェック
-func (s *PermissionService) matchesWildcard(pattern, permission string) bool {
-	// Handle module:* patterns (e.g., "user:*" matches "user:read")
-	if strings.HasSuffix(pattern, ":*") {
-		module := strings.TrimSuffix(pattern, ":*")
-		return strings.HasPrefix(permission, module+":")
-	}
-
-	// Handle *:action patterns (e.g., "*:read" matches "user:read")
-	if strings.HasPrefix(pattern, "*:") {
-		action := strings.TrimPrefix(pattern, "*:")
-		return strings.HasSuffix(permission, ":"+action)
-	}
-
-	return false
-}
-
-// GetRolePermissions 特定のロールの権限を取得
-func (s *PermissionService) GetRolePermissions(roleName string) []Permission {
-	if perms, exists := PermissionMatrix[roleName]; exists {
-		return perms
-	}
-	return []Permission{}
+	return HasPermission(userPermissions, requiredPermission)
 }
 
 // ValidatePermission 権限文字列が有効かバリデーション
@@ -0,0 +1,379 @@
+package services
+
+import (
+	"container/list"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/pkg/logger"
+)
+
+// PermissionCache ユーザーの実効権限のキャッシュ
+// role_permissions・user_roles・roles.parent_id・users.primary_role_id の変更時に、影響するユーザーのみを無効化する
+type PermissionCache interface {
+	// Get キャッシュ済みの実効権限を取得（見つからない場合は ok=false）
+	Get(userID uuid.UUID) (permissions []string, ok bool)
+	// Set 実効権限を保存（ttl経過後は再計算する）
+	Set(userID uuid.UUID, permissions []string, ttl time.Duration)
+	// Invalidate 指定したユーザーのキャッシュを削除
+	Invalidate(userIDs ...uuid.UUID)
+	// InvalidateAll 全ユーザーのキャッシュを削除
+	InvalidateAll()
+	// Stats ヒット・ミスの件数（このプロセスでの累計）
+	Stats() PermissionCacheStats
+}
+
+// PermissionCacheStats 権限キャッシュの統計
+type PermissionCacheStats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Invalidations uint64 `json:"invalidations"` // 無効化したユーザー数（全件無効化は1件）
+}
+
+// permissionCacheCounters ヒット・ミス・無効化の件数
+type permissionCacheCounters struct {
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// record 取得結果を記録
+func (c *permissionCacheCounters) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// stats 統計を取得
+func (c *permissionCacheCounters) stats() PermissionCacheStats {
+	return PermissionCacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+	}
+}
+
+// =============================================================================
+// プロセス内LRU
+// =============================================================================
+
+// lruPermissionEntry LRUキャッシュのエントリ
+type lruPermissionEntry struct {
+	userID      uuid.UUID
+	permissions []string
+	expiresAt   time.Time
+}
+
+// LRUPermissionCache プロセス内のLRUキャッシュ（単一インスタンス向け）
+// 無効化は他のレプリカに伝わらないため、複数レプリカではRedisPermissionCacheを使用する
+type LRUPermissionCache struct {
+	capacity int
+	counters permissionCacheCounters
+
+	mu      sync.Mutex
+	order   *list.List // 先頭が最近使用したエントリ
+	entries map[uuid.UUID]*list.Element
+}
+
+// NewLRUPermissionCache 新しいLRUキャッシュを作成（capacityを超えた場合は最も古いエントリを削除）
+func NewLRUPermissionCache(capacity int) *LRUPermissionCache {
+	return &LRUPermissionCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[uuid.UUID]*list.Element),
+	}
+}
+
+// Get キャッシュ済みの実効権限を取得
+func (c *LRUPermissionCache) Get(userID uuid.UUID) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.entries[userID]
+	if found && time.Now().After(element.Value.(*lruPermissionEntry).expiresAt) {
+		c.remove(element)
+		found = false
+	}
+	c.counters.record(found)
+	if !found {
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(*lruPermissionEntry).permissions, true
+}
+
+// Set 実効権限を保存
+func (c *LRUPermissionCache) Set(userID uuid.UUID, permissions []string, ttl time.Duration) {
+	if c.capacity <= 0 || ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &lruPermissionEntry{userID: userID, permissions: permissions, expiresAt: time.Now().Add(ttl)}
+	if element, found := c.entries[userID]; found {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[userID] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// Invalidate 指定したユーザーのキャッシュを削除
+func (c *LRUPermissionCache) Invalidate(userIDs ...uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, userID := range userIDs {
+		if element, found := c.entries[userID]; found {
+			c.remove(element)
+		}
+	}
+	c.counters.invalidations.Add(uint64(len(userIDs)))
+}
+
+// InvalidateAll 全ユーザーのキャッシュを削除
+func (c *LRUPermissionCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[uuid.UUID]*list.Element)
+	c.counters.invalidations.Add(1)
+}
+
+// Stats ヒット・ミスの件数
+func (c *LRUPermissionCache) Stats() PermissionCacheStats {
+	return c.counters.stats()
+}
+
+// Len キャッシュ済みのユーザー数
+func (c *LRUPermissionCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove エントリを削除（呼び出し元でロックを取得すること）
+func (c *LRUPermissionCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruPermissionEntry).userID)
+}
+
+// =============================================================================
+// Redis互換サーバー
+// =============================================================================
+
+// RedisCommander Redis互換サーバーへのコマンド送信（pkg/redis.Client が実装）
+type RedisCommander interface {
+	MGet(keys ...string) ([]*string, error)
+	Set(key, value string, ttl time.Duration) error
+	Del(keys ...string) (int64, error)
+	Incr(key string) (int64, error)
+}
+
+// RedisPermissionCache Redis互換サーバーを使用したキャッシュ（複数レプリカで無効化を共有）
+// 全件無効化は世代番号の更新で行い、異なる世代で保存されたエントリはミスとして扱う
+// サーバーに接続できない場合はミスとして扱い、データベースで権限を解決する
+// 反映できなかった全件無効化は記録し、再送に成功するまでキャッシュを使用しない
+type RedisPermissionCache struct {
+	client   RedisCommander
+	logger   *logger.Logger
+	prefix   string
+	counters permissionCacheCounters
+
+	pendingInvalidations atomic.Uint64 // サーバーに反映できていない全件無効化の件数
+}
+
+// NewRedisPermissionCache 新しいRedisキャッシュを作成（prefixはキーの接頭辞）
+func NewRedisPermissionCache(client RedisCommander, logger *logger.Logger, prefix string) *RedisPermissionCache {
+	return &RedisPermissionCache{
+		client: client,
+		logger: logger,
+		prefix: prefix,
+	}
+}
+
+// Get キャッシュ済みの実効権限を取得
+func (c *RedisPermissionCache) Get(userID uuid.UUID) ([]string, bool) {
+	if !c.flushPendingInvalidations() {
+		c.counters.record(false)
+		return nil, false
+	}
+
+	values, err := c.client.MGet(c.generationKey(), c.userKey(userID))
+	if err != nil {
+		c.logger.Warn("Failed to read permission cache", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		c.counters.record(false)
+		return nil, false
+	}
+
+	permissions, ok := decodeRedisPermissions(values[0], values[1])
+	c.counters.record(ok)
+	return permissions, ok
+}
+
+// Set 実効権限を保存
+func (c *RedisPermissionCache) Set(userID uuid.UUID, permissions []string, ttl time.Duration) {
+	if ttl <= 0 || !c.flushPendingInvalidations() {
+		return
+	}
+
+	values, err := c.client.MGet(c.generationKey())
+	if err != nil {
+		c.logger.Warn("Failed to read permission cache generation", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	encoded, err := json.Marshal(permissions)
+	if err != nil {
+		return
+	}
+
+	value := redisGeneration(values[0]) + "\n" + string(encoded)
+	if err := c.client.Set(c.userKey(userID), value, ttl); err != nil {
+		c.logger.Warn("Failed to write permission cache", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// Invalidate 指定したユーザーのキャッシュを削除
+func (c *RedisPermissionCache) Invalidate(userIDs ...uuid.UUID) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = c.userKey(userID)
+	}
+	if _, err := c.client.Del(keys...); err != nil {
+		// 削除できない場合は世代を進めて全件を無効化する
+		c.logger.Warn("Failed to invalidate permission cache, invalidating all", map[string]interface{}{
+			"user_count": len(userIDs),
+			"error":      err.Error(),
+		})
+		c.InvalidateAll()
+		return
+	}
+	c.counters.invalidations.Add(uint64(len(userIDs)))
+}
+
+// InvalidateAll 世代番号を更新して全ユーザーのキャッシュを無効化
+// 更新に失敗した場合は、次回のGet・Setで再送するまでキャッシュを使用しない
+func (c *RedisPermissionCache) InvalidateAll() {
+	c.pendingInvalidations.Add(1)
+	c.flushPendingInvalidations()
+}
+
+// flushPendingInvalidations 反映できていない全件無効化を再送（未反映のものがない場合はtrue）
+func (c *RedisPermissionCache) flushPendingInvalidations() bool {
+	for {
+		pending := c.pendingInvalidations.Load()
+		if pending == 0 {
+			return true
+		}
+		if _, err := c.client.Incr(c.generationKey()); err != nil {
+			c.logger.Error("Failed to invalidate permission cache, will retry before next use", err, map[string]interface{}{
+				"pending": pending,
+			})
+			return false
+		}
+		// 再送中に追加された無効化は改めて反映する
+		if c.pendingInvalidations.CompareAndSwap(pending, 0) {
+			c.counters.invalidations.Add(pending)
+			return true
+		}
+	}
+}
+
+// Stats ヒット・ミスの件数（このプロセスでの累計）
+func (c *RedisPermissionCache) Stats() PermissionCacheStats {
+	return c.counters.stats()
+}
+
+// generationKey 世代番号のキー
+func (c *RedisPermissionCache) generationKey() string {
+	return c.prefix + "generation"
+}
+
+// userKey ユーザーの実効権限のキー
+func (c *RedisPermissionCache) userKey(userID uuid.UUID) string {
+	return c.prefix + "user:" + userID.String()
+}
+
+// redisGeneration 世代番号の値（未設定の場合は0）
+func redisGeneration(value *string) string {
+	if value == nil {
+		return "0"
+	}
+	return *value
+}
+
+// decodeRedisPermissions 「世代番号\nJSON配列」形式の値を復元（世代が異なる場合は ok=false）
+func decodeRedisPermissions(generation, value *string) ([]string, bool) {
+	if value == nil {
+		return nil, false
+	}
+	storedGeneration, encoded, found := strings.Cut(*value, "\n")
+	if !found || storedGeneration != redisGeneration(generation) {
+		return nil, false
+	}
+	if _, err := strconv.ParseInt(storedGeneration, 10, 64); err != nil {
+		return nil, false
+	}
+
+	var permissions []string
+	if err := json.Unmarshal([]byte(encoded), &permissions); err != nil {
+		return nil, false
+	}
+	return permissions, true
+}
+
+// =============================================================================
+// 無効化
+// =============================================================================
+
+// invalidateUserPermissions ユーザーのロール割り当て・PrimaryRoleの変更後にキャッシュを無効化
+func invalidateUserPermissions(cache PermissionCache, userIDs ...uuid.UUID) {
+	if cache == nil || len(userIDs) == 0 {
+		return
+	}
+	cache.Invalidate(userIDs...)
+}
+
+// invalidateRolePermissions ロールの権限・親ロールの変更後に、そのロールと子孫ロールを持つユーザーのキャッシュを無効化
+// 対象ユーザーを特定できない場合は全件を無効化する
+func invalidateRolePermissions(db *gorm.DB, cache PermissionCache, roleIDs ...uuid.UUID) {
+	if cache == nil || len(roleIDs) == 0 {
+		return
+	}
+
+	userIDs, err := NewPermissionResolver(db).RoleHolders(roleIDs)
+	if err != nil {
+		cache.InvalidateAll()
+		return
+	}
+	cache.Invalidate(userIDs...)
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"erp-access-control-go/pkg/logger"
+)
+
+// fakeRedis テスト用のRedisCommander（有効期限は扱わない）
+type fakeRedis struct {
+	mu      sync.Mutex
+	values  map[string]string
+	err     error // 設定時は全コマンドが失敗
+	delErr  error // 設定時はDELのみ失敗
+	incrErr error // 設定時はINCRのみ失敗
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{values: make(map[string]string)}
+}
+
+func (f *fakeRedis) MGet(keys ...string) ([]*string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	values := make([]*string, len(keys))
+	for i, key := range keys {
+		if value, ok := f.values[key]; ok {
+			values[i] = &value
+		}
+	}
+	return values, nil
+}
+
+func (f *fakeRedis) Set(key, value string, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.values[key] = value
+	return nil
+}
+
+func (f *fakeRedis) Del(keys ...string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return 0, f.err
+	}
+	if f.delErr != nil {
+		return 0, f.delErr
+	}
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := f.values[key]; ok {
+			delete(f.values, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (f *fakeRedis) Incr(key string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return 0, f.err
+	}
+	if f.incrErr != nil {
+		return 0, f.incrErr
+	}
+	value, _ := strconv.ParseInt(f.values[key], 10, 64)
+	value++
+	f.values[key] = strconv.FormatInt(value, 10)
+	return value, nil
+}
+
+func TestLRUPermissionCache(t *testing.T) {
+	t.Run("容量を超えると最も古いエントリを削除", func(t *testing.T) {
+		cache := NewLRUPermissionCache(2)
+		first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+		cache.Set(first, []string{"user:read"}, time.Minute)
+		cache.Set(second, []string{"role:read"}, time.Minute)
+		_, ok := cache.Get(first) // firstを最近使用したエントリにする
+		require.True(t, ok)
+		cache.Set(third, []string{"audit:read"}, time.Minute)
+
+		assert.Equal(t, 2, cache.Len())
+		_, ok = cache.Get(second)
+		assert.False(t, ok, "最も古いエントリが削除される")
+		permissions, ok := cache.Get(first)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"user:read"}, permissions)
+	})
+
+	t.Run("有効期限を過ぎたエントリはミス", func(t *testing.T) {
+		cache := NewLRUPermissionCache(10)
+		userID := uuid.New()
+
+		cache.Set(userID, []string{"user:read"}, 10*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+
+		_, ok := cache.Get(userID)
+		assert.False(t, ok)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("無効化とヒット・ミスの件数", func(t *testing.T) {
+		cache := NewLRUPermissionCache(10)
+		target, other := uuid.New(), uuid.New()
+		cache.Set(target, []string{"user:read"}, time.Minute)
+		cache.Set(other, []string{"user:read"}, time.Minute)
+
+		cache.Get(target)
+		cache.Invalidate(target)
+		_, ok := cache.Get(target)
+		assert.False(t, ok)
+		_, ok = cache.Get(other)
+		assert.True(t, ok, "他のユーザーのエントリは残る")
+
+		cache.InvalidateAll()
+		assert.Equal(t, 0, cache.Len())
+		assert.Equal(t, PermissionCacheStats{Hits: 2, Misses: 1, Invalidations: 2}, cache.Stats())
+	})
+}
+
+func TestRedisPermissionCache(t *testing.T) {
+	appLogger := logger.NewLogger()
+
+	t.Run("保存・取得・ユーザー単位の無効化", func(t *testing.T) {
+		cache := NewRedisPermissionCache(newFakeRedis(), appLogger, "test:")
+		target, other := uuid.New(), uuid.New()
+		cache.Set(target, []string{"user:*", "role:read"}, time.Minute)
+		cache.Set(other, []string{"user:read"}, time.Minute)
+
+		permissions, ok := cache.Get(target)
+		require.True(t, ok)
+		assert.Equal(t, []string{"user:*", "role:read"}, permissions)
+
+		cache.Invalidate(target)
+		_, ok = cache.Get(target)
+		assert.False(t, ok)
+		_, ok = cache.Get(other)
+		assert.True(t, ok)
+		assert.Equal(t, PermissionCacheStats{Hits: 2, Misses: 1, Invalidations: 1}, cache.Stats())
+	})
+
+	t.Run("全件無効化は他のレプリカにも反映", func(t *testing.T) {
+		server := newFakeRedis()
+		replicaA := NewRedisPermissionCache(server, appLogger, "test:")
+		replicaB := NewRedisPermissionCache(server, appLogger, "test:")
+		userID := uuid.New()
+
+		replicaA.Set(userID, []string{"user:read"}, time.Minute)
+		_, ok := replicaB.Get(userID)
+		require.True(t, ok)
+
+		replicaB.InvalidateAll()
+		_, ok = replicaA.Get(userID)
+		assert.False(t, ok, "世代が異なるエントリは使用しない")
+
+		replicaA.Set(userID, []string{"role:read"}, time.Minute)
+		permissions, ok := replicaB.Get(userID)
+		require.True(t, ok)
+		assert.Equal(t, []string{"role:read"}, permissions)
+	})
+
+	t.Run("削除に失敗した場合は全件を無効化", func(t *testing.T) {
+		server := newFakeRedis()
+		cache := NewRedisPermissionCache(server, appLogger, "test:")
+		target, other := uuid.New(), uuid.New()
+		cache.Set(target, []string{"user:read"}, time.Minute)
+		cache.Set(other, []string{"user:read"}, time.Minute)
+
+		server.delErr = fmt.Errorf("connection reset")
+		cache.Invalidate(target)
+
+		_, ok := cache.Get(target)
+		assert.False(t, ok)
+		_, ok = cache.Get(other)
+		assert.False(t, ok)
+	})
+
+	t.Run("無効化を反映できない場合は再送するまでキャッシュを使用しない", func(t *testing.T) {
+		server := newFakeRedis()
+		replicaA := NewRedisPermissionCache(server, appLogger, "test:")
+		replicaB := NewRedisPermissionCache(server, appLogger, "test:")
+		userID := uuid.New()
+		replicaA.Set(userID, []string{"user:*"}, time.Minute)
+
+		server.delErr = fmt.Errorf("connection reset")
+		server.incrErr = fmt.Errorf("connection reset")
+		replicaA.Invalidate(userID)
+
+		_, ok := replicaA.Get(userID)
+		assert.False(t, ok, "未反映の無効化がある間は読み取れてもミス")
+		replicaA.Set(userID, []string{"user:*"}, time.Minute)
+		assert.Equal(t, uint64(0), replicaA.Stats().Invalidations)
+
+		server.delErr = nil
+		server.incrErr = nil
+		_, ok = replicaA.Get(userID)
+		assert.False(t, ok)
+		assert.Equal(t, uint64(0), replicaA.pendingInvalidations.Load(), "再接続後に全件無効化を再送")
+		assert.Equal(t, uint64(1), replicaA.Stats().Invalidations)
+
+		_, ok = replicaB.Get(userID)
+		assert.False(t, ok, "再送した無効化は他のレプリカにも反映")
+	})
+
+	t.Run("サーバーに接続できない場合はミス", func(t *testing.T) {
+		server := newFakeRedis()
+		cache := NewRedisPermissionCache(server, appLogger, "test:")
+		userID := uuid.New()
+		cache.Set(userID, []string{"user:read"}, time.Minute)
+
+		server.err = fmt.Errorf("connection refused")
+		_, ok := cache.Get(userID)
+		assert.False(t, ok)
+		assert.Equal(t, uint64(1), cache.Stats().Misses)
+	})
+}
+
+// TestPermissionCache_Invalidation ロール・権限の割り当て変更で影響するユーザーのみが無効化されることのテスト
+func TestPermissionCache_Invalidation(t *testing.T) {
+	service, db := setupTestAuth(t)
+	setupTestTimeRestrictions(t, db)
+	appLogger := logger.NewLogger()
+
+	cache := NewLRUPermissionCache(100)
+	permissionService := service.permissionService
+	permissionService.SetPermissionCache(cache, time.Minute)
+	roleService := NewRoleService(db, appLogger)
+	roleService.SetPermissionCache(cache)
+	userRoleService := NewUserRoleService(db)
+	userRoleService.SetPermissionCache(cache)
+
+	// 親（user:read）> 子（role:read）、無関係（department:read）
+	parent := createResolverTestRole(t, db, "キャッシュテスト親", nil, [2]string{"user", "read"})
+	child := createResolverTestRole(t, db, "キャッシュテスト子", &parent, [2]string{"role", "read"})
+	unrelated := createResolverTestRole(t, db, "キャッシュテスト無関係", nil, [2]string{"department", "read"})
+
+	childHolder := createAuthTestUser(t, db, "cache-child@example.com", "password123")
+	unrelatedHolder := createAuthTestUser(t, db, "cache-unrelated@example.com", "password123")
+	assignApprovalTestRole(t, db, childHolder, child)
+	assignApprovalTestRole(t, db, unrelatedHolder, unrelated)
+
+	warm := func() {
+		for _, userID := range []uuid.UUID{childHolder, unrelatedHolder} {
+			_, err := permissionService.GetUserPermissions(userID)
+			require.NoError(t, err)
+		}
+	}
+	cached := func(userID uuid.UUID) bool {
+		_, ok := cache.Get(userID)
+		return ok
+	}
+
+	t.Run("2回目以降はキャッシュから取得", func(t *testing.T) {
+		warm()
+		before := cache.Stats()
+
+		permissions, err := permissionService.GetUserPermissions(childHolder)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"user:read", "role:read"}, permissions)
+		assert.Equal(t, before.Hits+1, cache.Stats().Hits)
+	})
+
+	t.Run("親ロールへの権限追加は子ロールの保持者のみ無効化", func(t *testing.T) {
+		warm()
+		auditRead := createPermissionForPermissionTest(t, db, "audit", "read")
+
+		_, err := roleService.AssignPermissions(parent, AssignPermissionsRequest{PermissionIDs: []uuid.UUID{auditRead.ID}})
+		require.NoError(t, err)
+
+		assert.False(t, cached(childHolder))
+		assert.True(t, cached(unrelatedHolder))
+
+		permissions, err := permissionService.GetUserPermissions(childHolder)
+		require.NoError(t, err)
+		assert.Contains(t, permissions, "audit:read")
+	})
+
+	t.Run("親ロールの変更は保持者を無効化", func(t *testing.T) {
+		warm()
+
+		_, err := roleService.UpdateRole(child, UpdateRoleRequest{ParentID: &unrelated})
+		require.NoError(t, err)
+
+		assert.False(t, cached(childHolder))
+		assert.True(t, cached(unrelatedHolder))
+
+		permissions, err := permissionService.GetUserPermissions(childHolder)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"department:read", "role:read"}, permissions)
+	})
+
+	t.Run("ロールの付与・取り消しは対象ユーザーのみ無効化", func(t *testing.T) {
+		warm()
+
+		_, err := userRoleService.AssignRole(childHolder, parent, time.Now().Add(-time.Minute), nil, 0, childHolder, "テスト")
+		require.NoError(t, err)
+		assert.False(t, cached(childHolder))
+		assert.True(t, cached(unrelatedHolder))
+
+		permissions, err := permissionService.GetUserPermissions(childHolder)
+		require.NoError(t, err)
+		assert.Contains(t, permissions, "user:read")
+
+		_, err = userRoleService.RevokeRole(childHolder, parent, childHolder, "テスト")
+		require.NoError(t, err)
+		assert.False(t, cached(childHolder))
+
+		permissions, err = permissionService.GetUserPermissions(childHolder)
+		require.NoError(t, err)
+		assert.NotContains(t, permissions, "user:read")
+	})
+
+	t.Run("有効期間の開始時刻でエントリが失効", func(t *testing.T) {
+		startsAt := time.Now().Add(50 * time.Millisecond)
+		_, err := userRoleService.AssignRole(unrelatedHolder, parent, startsAt, nil, 0, unrelatedHolder, "テスト")
+		require.NoError(t, err)
+
+		permissions, err := permissionService.GetUserPermissions(unrelatedHolder)
+		require.NoError(t, err)
+		assert.NotContains(t, permissions, "user:read")
+
+		time.Sleep(time.Until(startsAt) + 10*time.Millisecond)
+		permissions, err = permissionService.GetUserPermissions(unrelatedHolder)
+		require.NoError(t, err)
+		assert.Contains(t, permissions, "user:read")
+	})
+}
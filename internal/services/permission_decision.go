@@ -0,0 +1,85 @@
+package services
+
+import "strings"
+
+// DeniedPermissionPrefix 権限文字列の一覧（実効権限・JWTクレーム）で拒否権限を表す接頭辞（例: "!finance:export"）
+const DeniedPermissionPrefix = "!"
+
+// PermissionRule 権限判定を決定した規則
+// 優先順位: 明示的な拒否 > 明示的な許可 > ワイルドカードによる許可
+type PermissionRule string
+
+const (
+	PermissionRuleExplicitDeny  PermissionRule = "explicit_deny"
+	PermissionRuleExplicitAllow PermissionRule = "explicit_allow"
+	PermissionRuleWildcardAllow PermissionRule = "wildcard_allow"
+	PermissionRuleNoMatch       PermissionRule = "no_match"
+)
+
+// PermissionDecision 権限判定の結果
+type PermissionDecision struct {
+	Allowed bool           `json:"allowed"`
+	Rule    PermissionRule `json:"rule"`
+	Grant   string         `json:"grant,omitempty"` // 判定を決定した権限文字列（拒否は "!" 付き）
+}
+
+// EvaluatePermission 権限文字列の一覧で指定権限を判定
+// 拒否は module:action の完全一致のみで、同じ権限の許可（ワイルドカードを含む）より常に優先する
+func EvaluatePermission(grants []string, requiredPermission string) PermissionDecision {
+	var wildcard string
+	explicitAllow := false
+
+	for _, grant := range grants {
+		if denied, ok := strings.CutPrefix(grant, DeniedPermissionPrefix); ok {
+			if denied == requiredPermission {
+				return PermissionDecision{Allowed: false, Rule: PermissionRuleExplicitDeny, Grant: grant}
+			}
+			continue
+		}
+		if grant == requiredPermission {
+			explicitAllow = true
+		} else if wildcard == "" && matchesPermissionWildcard(grant, requiredPermission) {
+			wildcard = grant
+		}
+	}
+
+	switch {
+	case explicitAllow:
+		return PermissionDecision{Allowed: true, Rule: PermissionRuleExplicitAllow, Grant: requiredPermission}
+	case wildcard != "":
+		return PermissionDecision{Allowed: true, Rule: PermissionRuleWildcardAllow, Grant: wildcard}
+	default:
+		return PermissionDecision{Allowed: false, Rule: PermissionRuleNoMatch}
+	}
+}
+
+// HasPermission 権限文字列の一覧で指定権限が許可されているかチェック
+func HasPermission(grants []string, requiredPermission string) bool {
+	return EvaluatePermission(grants, requiredPermission).Allowed
+}
+
+// IsWildcardPermission ワイルドカードを含む権限か（*、*:*、module:*、*:action）
+func IsWildcardPermission(module, action string) bool {
+	return module == "*" || action == "*"
+}
+
+// matchesPermissionWildcard 権限がワイルドカードパターンにマッチするかチェック
+func matchesPermissionWildcard(pattern, permission string) bool {
+	if pattern == "*" || pattern == "*:*" {
+		return true // Super admin wildcard
+	}
+
+	// Handle module:* patterns (e.g., "user:*" matches "user:read")
+	if strings.HasSuffix(pattern, ":*") {
+		module := strings.TrimSuffix(pattern, ":*")
+		return strings.HasPrefix(permission, module+":")
+	}
+
+	// Handle *:action patterns (e.g., "*:read" matches "user:read")
+	if strings.HasPrefix(pattern, "*:") {
+		action := strings.TrimPrefix(pattern, "*:")
+		return strings.HasSuffix(permission, ":"+action)
+	}
+
+	return false
+}
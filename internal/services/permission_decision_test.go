@@ -0,0 +1,85 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEvaluatePermission 明示的な拒否 > 明示的な許可 > ワイルドカードによる許可 の優先順位のテスト
+func TestEvaluatePermission(t *testing.T) {
+	tests := []struct {
+		name     string
+		grants   []string
+		required string
+		expected PermissionDecision
+	}{
+		{
+			name:     "明示的な許可",
+			grants:   []string{"user:read"},
+			required: "user:read",
+			expected: PermissionDecision{Allowed: true, Rule: PermissionRuleExplicitAllow, Grant: "user:read"},
+		},
+		{
+			name:     "明示的な許可はワイルドカードより優先",
+			grants:   []string{"*:*", "user:read"},
+			required: "user:read",
+			expected: PermissionDecision{Allowed: true, Rule: PermissionRuleExplicitAllow, Grant: "user:read"},
+		},
+		{
+			name:     "モジュール単位のワイルドカード",
+			grants:   []string{"user:*"},
+			required: "user:delete",
+			expected: PermissionDecision{Allowed: true, Rule: PermissionRuleWildcardAllow, Grant: "user:*"},
+		},
+		{
+			name:     "アクション単位のワイルドカード",
+			grants:   []string{"*:read"},
+			required: "audit:read",
+			expected: PermissionDecision{Allowed: true, Rule: PermissionRuleWildcardAllow, Grant: "*:read"},
+		},
+		{
+			name:     "拒否は全権限のワイルドカードより優先",
+			grants:   []string{"*:*", "!finance:export"},
+			required: "finance:export",
+			expected: PermissionDecision{Allowed: false, Rule: PermissionRuleExplicitDeny, Grant: "!finance:export"},
+		},
+		{
+			name:     "拒否は明示的な許可より優先（順序に依存しない）",
+			grants:   []string{"!finance:export", "finance:export"},
+			required: "finance:export",
+			expected: PermissionDecision{Allowed: false, Rule: PermissionRuleExplicitDeny, Grant: "!finance:export"},
+		},
+		{
+			name:     "拒否は他の権限に影響しない",
+			grants:   []string{"finance:*", "!finance:export"},
+			required: "finance:read",
+			expected: PermissionDecision{Allowed: true, Rule: PermissionRuleWildcardAllow, Grant: "finance:*"},
+		},
+		{
+			name:     "拒否のワイルドカードは照合しない",
+			grants:   []string{"finance:export", "!finance:*"},
+			required: "finance:export",
+			expected: PermissionDecision{Allowed: true, Rule: PermissionRuleExplicitAllow, Grant: "finance:export"},
+		},
+		{
+			name:     "拒否のみ",
+			grants:   []string{"user:read", "!role:read"},
+			required: "role:read",
+			expected: PermissionDecision{Allowed: false, Rule: PermissionRuleExplicitDeny, Grant: "!role:read"},
+		},
+		{
+			name:     "該当なし",
+			grants:   nil,
+			required: "role:read",
+			expected: PermissionDecision{Allowed: false, Rule: PermissionRuleNoMatch},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EvaluatePermission(tt.grants, tt.required))
+			assert.Equal(t, tt.expected.Allowed, HasPermission(tt.grants, tt.required))
+		})
+	}
+}
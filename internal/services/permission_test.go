@@ -3,6 +3,7 @@ package services
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -43,6 +44,8 @@ func setupTestPermission(t *testing.T) (*PermissionService, *gorm.DB) {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			name TEXT NOT NULL,
 			parent_id TEXT,
+			requires_approval BOOLEAN NOT NULL DEFAULT false,
+			requires_mfa BOOLEAN NOT NULL DEFAULT false,
 			FOREIGN KEY (parent_id) REFERENCES roles(id)
 		)
 	`).Error
@@ -53,6 +56,7 @@ func setupTestPermission(t *testing.T) (*PermissionService, *gorm.DB) {
 		CREATE TABLE IF NOT EXISTS role_permissions (
 			role_id TEXT NOT NULL,
 			permission_id TEXT NOT NULL,
+			effect TEXT NOT NULL DEFAULT 'allow',
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			PRIMARY KEY (role_id, permission_id),
 			FOREIGN KEY (role_id) REFERENCES roles(id),
@@ -562,6 +566,7 @@ func TestPermissionService_SystemPermissionProtection(t *testing.T) {
 		{"permission", "read"},
 		{"system", "admin"},
 		{"audit", "read"},
+		{"*", "*"},
 	}
 
 	for _, sp := range systemPermissions {
@@ -783,3 +788,201 @@ func TestPermissionService_Step43_ValidationEnhancements(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+// setupTestTimeRestrictions 時間制限テーブルを作成
+func setupTestTimeRestrictions(t *testing.T, db *gorm.DB) {
+	err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS time_restrictions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			resource_type TEXT NOT NULL,
+			start_time TEXT,
+			end_time TEXT,
+			allowed_days TEXT,
+			timezone TEXT DEFAULT 'UTC',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error
+	require.NoError(t, err)
+	db.Exec("DELETE FROM time_restrictions")
+}
+
+// TestPermissionService_CheckTimeAccess 時間制限チェックのテスト
+func TestPermissionService_CheckTimeAccess(t *testing.T) {
+	svc, db := setupTestPermission(t)
+	setupTestTimeRestrictions(t, db)
+
+	userID := uuid.New()
+	restriction, err := models.GetBusinessHoursRestriction(9, 18, []int{2, 3, 4, 5, 6}, "Asia/Tokyo")
+	require.NoError(t, err)
+	restriction.UserID = userID
+	restriction.ResourceType = "users"
+	require.NoError(t, db.Create(&restriction).Error)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	t.Run("営業時間内は許可", func(t *testing.T) {
+		at := time.Date(2025, 1, 6, 10, 0, 0, 0, tokyo) // 月曜日
+		assert.NoError(t, svc.CheckTimeAccess(userID, "user:read", at))
+	})
+
+	t.Run("営業時間外はTIME_RESTRICTED", func(t *testing.T) {
+		at := time.Date(2025, 1, 6, 20, 0, 0, 0, tokyo)
+		err := svc.CheckTimeAccess(userID, "user:read", at)
+		require.Error(t, err)
+		assert.True(t, errors.IsTimeRestrictedError(err))
+	})
+
+	t.Run("許可曜日以外はTIME_RESTRICTED", func(t *testing.T) {
+		at := time.Date(2025, 1, 5, 10, 0, 0, 0, tokyo) // 日曜日
+		err := svc.CheckTimeAccess(userID, "user:update", at)
+		assert.True(t, errors.IsTimeRestrictedError(err))
+	})
+
+	t.Run("制限のないリソースは許可", func(t *testing.T) {
+		at := time.Date(2025, 1, 6, 20, 0, 0, 0, tokyo)
+		assert.NoError(t, svc.CheckTimeAccess(userID, "role:read", at))
+	})
+
+	t.Run("他ユーザーには影響しない", func(t *testing.T) {
+		at := time.Date(2025, 1, 6, 20, 0, 0, 0, tokyo)
+		assert.NoError(t, svc.CheckTimeAccess(uuid.New(), "user:read", at))
+	})
+
+	t.Run("保存した時刻を読み戻せる", func(t *testing.T) {
+		var stored models.TimeRestriction
+		require.NoError(t, db.First(&stored, restriction.ID).Error)
+		require.NotNil(t, stored.StartTime)
+		require.NotNil(t, stored.EndTime)
+		assert.Equal(t, "09:00:00", stored.StartTime.Format("15:04:05"))
+		assert.Equal(t, "18:00:00", stored.EndTime.Format("15:04:05"))
+	})
+}
+
+// TestResourceTypeForPermission 権限文字列からリソース種別への変換テスト
+func TestResourceTypeForPermission(t *testing.T) {
+	assert.Equal(t, "users", ResourceTypeForPermission("user:read"))
+	assert.Equal(t, "departments", ResourceTypeForPermission("department:update"))
+	assert.Equal(t, "audit", ResourceTypeForPermission("audit"))
+	assert.Equal(t, "", ResourceTypeForPermission("unknown:read"))
+}
+
+// TestPermissionService_CheckScopeAccess スコープ照合のテスト（部署配下の展開を含む）
+func TestPermissionService_CheckScopeAccess(t *testing.T) {
+	svc, db := setupTestPermission(t)
+	scopeService, _ := setupTestUserScope(t)
+
+	// 部署階層: 本社 > 営業部 > 東京営業課 / 開発部
+	createDepartment := func(name string, parentID *uuid.UUID) uuid.UUID {
+		id := uuid.New()
+		var parent interface{}
+		if parentID != nil {
+			parent = parentID.String()
+		}
+		require.NoError(t, db.Exec("INSERT INTO departments (id, name, parent_id) VALUES (?, ?, ?)", id.String(), name, parent).Error)
+		return id
+	}
+	headOffice := createDepartment("本社", nil)
+	sales := createDepartment("営業部", &headOffice)
+	tokyoSales := createDepartment("東京営業課", &sales)
+	development := createDepartment("開発部", &headOffice)
+
+	manager := createUserForScopeTest(t, db, "営業部長")
+	_, err := scopeService.AddUserScope(manager, AddUserScopeRequest{
+		ResourceType: "users",
+		UserScopeItem: UserScopeItem{
+			ScopeType:  models.ScopeTypeDepartment,
+			ScopeValue: map[string]interface{}{"department_id": sales.String(), "include_children": true},
+		},
+	})
+	require.NoError(t, err)
+
+	lead := createUserForScopeTest(t, db, "東京営業課長")
+	_, err = scopeService.AddUserScope(lead, AddUserScopeRequest{
+		ResourceType: "users",
+		UserScopeItem: UserScopeItem{
+			ScopeType:  models.ScopeTypeDepartment,
+			ScopeValue: map[string]interface{}{"department_id": tokyoSales.String()},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		permission string
+		department uuid.UUID
+		expected   bool
+	}{
+		{"配下を含む: 自部署", manager, "user:update", sales, true},
+		{"配下を含む: 子部署", manager, "user:update", tokyoSales, true},
+		{"配下を含む: 親部署は対象外", manager, "user:update", headOffice, false},
+		{"配下を含む: 兄弟部署は対象外", manager, "user:update", development, false},
+		{"自部署のみ: 一致", lead, "user:update", tokyoSales, true},
+		{"自部署のみ: 親部署は対象外", lead, "user:update", sales, false},
+		{"別リソース種別のスコープは適用しない", manager, "orders:update", development, true},
+		{"スコープ未設定は制限なし", uuid.New(), "user:update", development, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := svc.CheckScopeAccess(tt.userID, tt.permission, map[string]interface{}{
+				"department_id": tt.department.String(),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, allowed)
+		})
+	}
+}
+
+// TestPermissionService_GetUserPermissions ロールの権限（ワイルドカードを含む）取得のテスト
+func TestPermissionService_GetUserPermissions(t *testing.T) {
+	svc, db := setupTestPermission(t)
+
+	createUserWithPrimaryRole := func(name string, roleID uuid.UUID) uuid.UUID {
+		userID := uuid.New()
+		require.NoError(t, db.Exec("INSERT INTO users (id, name, email, status, primary_role_id) VALUES (?, ?, ?, 'active', ?)",
+			userID.String(), name, name+"@example.com", roleID.String()).Error)
+		return userID
+	}
+
+	t.Run("ロール名では権限を付与しない", func(t *testing.T) {
+		role := createRoleForPermissionTest(t, db, "システム管理者", nil)
+		userID := createUserWithPrimaryRole("admin-without-rows", role.ID)
+
+		permissions, err := svc.GetUserPermissions(userID)
+		require.NoError(t, err)
+		assert.Empty(t, permissions)
+	})
+
+	t.Run("全権限のワイルドカード", func(t *testing.T) {
+		role := createRoleForPermissionTest(t, db, "全権限ロール", nil)
+		all := createPermissionForPermissionTest(t, db, "*", "*")
+		assignPermissionToRole(t, db, role.ID, all.ID)
+		userID := createUserWithPrimaryRole("super-admin", role.ID)
+
+		permissions, err := svc.GetUserPermissions(userID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"*:*"}, permissions)
+		assert.True(t, svc.hasPermission(permissions, "system:admin"))
+
+		// ロール名を変更しても権限は変わらない
+		require.NoError(t, db.Exec("UPDATE roles SET name = ? WHERE id = ?", "名称変更後ロール", role.ID.String()).Error)
+		permissions, err = svc.GetUserPermissions(userID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"*:*"}, permissions)
+	})
+
+	t.Run("モジュール単位のワイルドカード", func(t *testing.T) {
+		role := createRoleForPermissionTest(t, db, "ユーザー管理ロール", nil)
+		userAll := createPermissionForPermissionTest(t, db, "user", "*")
+		assignPermissionToRole(t, db, role.ID, userAll.ID)
+		userID := createUserWithPrimaryRole("user-manager", role.ID)
+
+		permissions, err := svc.GetUserPermissions(userID)
+		require.NoError(t, err)
+		assert.True(t, svc.hasPermission(permissions, "user:delete"))
+		assert.False(t, svc.hasPermission(permissions, "role:read"))
+	})
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+)
+
+const refreshTokenBytes = 32
+
+// RefreshTokenService リフレッシュトークン（ローテーション・再利用検知）サービス
+type RefreshTokenService struct {
+	db                *gorm.DB
+	expiresIn         time.Duration
+	revocationService *TokenRevocationService // ファミリー無効化時にセッションのアクセストークンを失効（未設定の場合は失効しない）
+}
+
+// NewRefreshTokenService 新しいリフレッシュトークンサービスを作成
+func NewRefreshTokenService(db *gorm.DB, expiresIn time.Duration) *RefreshTokenService {
+	return &RefreshTokenService{
+		db:        db,
+		expiresIn: expiresIn,
+	}
+}
+
+// SetRevocationService ファミリー無効化時に、ログインセッションの最新のアクセストークンも失効させる
+func (s *RefreshTokenService) SetRevocationService(revocationService *TokenRevocationService) {
+	s.revocationService = revocationService
+}
+
+// ExpiresIn リフレッシュトークンの有効期間を取得
+func (s *RefreshTokenService) ExpiresIn() time.Duration {
+	return s.expiresIn
+}
+
+// IssueRefreshToken 新しいファミリーでリフレッシュトークンを発行（ログイン時）
+// 戻り値のファミリーIDはログインセッションの識別に使用する
+func (s *RefreshTokenService) IssueRefreshToken(userID uuid.UUID) (string, uuid.UUID, error) {
+	familyID := uuid.New()
+	token, err := s.issue(s.db, userID, familyID)
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	return token, familyID, nil
+}
+
+// RotateRefreshToken リフレッシュトークンを使用済みにして同じファミリーで再発行
+// 使用したトークン（ユーザー・ファミリー）と新しいトークンを返す
+// 使用済みトークンが再提示された場合はファミリー全体を無効化する
+func (s *RefreshTokenService) RotateRefreshToken(token string) (*models.RefreshToken, string, error) {
+	current, err := models.FindRefreshTokenByToken(s.db, token)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, "", errors.ErrInvalidToken
+		}
+		return nil, "", errors.NewDatabaseError(err)
+	}
+
+	if current.IsRevoked() {
+		return nil, "", errors.ErrTokenRevoked
+	}
+	if current.IsUsed() {
+		return nil, "", s.handleReuse(current)
+	}
+	if current.IsExpired() {
+		return nil, "", errors.ErrTokenExpired
+	}
+
+	var newToken string
+	reused := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 同時に使用された場合は片方のみ成功させる
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", current.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return nil
+		}
+
+		issued, err := s.issue(tx, current.UserID, current.FamilyID)
+		if err != nil {
+			return err
+		}
+		newToken = issued
+		return nil
+	})
+	if err != nil {
+		if apiErr, ok := err.(*errors.APIError); ok {
+			return nil, "", apiErr
+		}
+		return nil, "", errors.NewDatabaseError(err)
+	}
+	if reused {
+		return nil, "", s.handleReuse(current)
+	}
+
+	return current, newToken, nil
+}
+
+// RevokeRefreshToken リフレッシュトークンのファミリーとログインセッションを無効化（ログアウト）
+func (s *RefreshTokenService) RevokeRefreshToken(token, reason string) error {
+	current, err := models.FindRefreshTokenByToken(s.db, token)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrInvalidToken
+		}
+		return errors.NewDatabaseError(err)
+	}
+
+	return s.revokeSessionFamily(current.FamilyID, reason)
+}
+
+// RevokeFamily 指定ファミリーのリフレッシュトークンを無効化
+func (s *RefreshTokenService) RevokeFamily(familyID uuid.UUID, reason string) error {
+	if _, err := models.RevokeRefreshTokenFamily(s.db, familyID, reason); err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	return nil
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// CleanupExpiredTokens 期限切れのリフレッシュトークンを削除（定期ジョブ用）
+func (s *RefreshTokenService) CleanupExpiredTokens() (int64, error) {
+	deleted, err := models.CleanupExpiredRefreshTokens(s.db)
+	if err != nil {
+		return 0, errors.NewDatabaseError(err)
+	}
+	return deleted, nil
+}
+
+// issue リフレッシュトークンを生成してハッシュを保存
+func (s *RefreshTokenService) issue(db *gorm.DB, userID, familyID uuid.UUID) (string, error) {
+	token, err := generateRefreshToken()
+	if err != nil {
+		return "", errors.NewInternalError("failed to generate refresh token")
+	}
+
+	refreshToken := models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: models.HashRefreshToken(token),
+		ExpiresAt: time.Now().Add(s.expiresIn),
+	}
+	if err := db.Create(&refreshToken).Error; err != nil {
+		return "", errors.NewDatabaseError(err)
+	}
+	return token, nil
+}
+
+// handleReuse 使用済みトークンの再提示（漏洩の可能性）時にファミリー全体を無効化
+func (s *RefreshTokenService) handleReuse(token *models.RefreshToken) error {
+	if err := s.revokeSessionFamily(token.FamilyID, "reuse_detected"); err != nil {
+		return err
+	}
+	return errors.NewAuthenticationError("refresh token reuse detected")
+}
+
+// revokeSessionFamily ファミリーを無効化し、ログインセッションの最新のアクセストークンも失効させる
+func (s *RefreshTokenService) revokeSessionFamily(familyID uuid.UUID, reason string) error {
+	if _, err := models.RevokeRefreshTokenFamily(s.db, familyID, reason); err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	if s.revocationService == nil {
+		return nil
+	}
+
+	var session models.UserSession
+	if err := s.db.Where("family_id = ? AND revoked_at IS NULL", familyID).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil // セッション記録の導入前に発行されたファミリー、または無効化済み
+		}
+		return errors.NewDatabaseError(err)
+	}
+	if err := s.revocationService.RevokeToken(session.CurrentJTI, session.UserID, reason); err != nil {
+		return err
+	}
+	if err := models.RevokeUserSession(s.db, session.ID, reason); err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	return nil
+}
+
+// generateRefreshToken 推測困難な不透明トークンを生成
+func generateRefreshToken() (string, error) {
+	buf := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/logger"
+)
+
+// revocationSyncOverlap 差分同期で前回の取得範囲と重ねる時間
+// レプリカ間の時計のずれやコミット順の前後で無効化を取りこぼさないようにする
+const revocationSyncOverlap = time.Minute
+
+// defaultRevocationPollInterval 同期間隔に0以下が指定された場合の同期間隔
+const defaultRevocationPollInterval = 2 * time.Second
+
+// userRevocation ユーザー単位の一括無効化（この時刻以前に発行されたトークンは無効）
+type userRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+// RevocationCache 無効化済みトークンのプロセス内キャッシュ
+// 起動時に有効な無効化レコードを読み込み、以降はrevoked_atによる差分ポーリングで他のレプリカの無効化を反映する
+// 同期が途絶えて古くなった場合は判定せず、呼び出し元にデータベースでの確認を促す
+type RevocationCache struct {
+	db           *gorm.DB
+	logger       *logger.Logger
+	pollInterval time.Duration
+	staleAfter   time.Duration // 最終同期からこの時間を過ぎたキャッシュは使用しない
+
+	mu        sync.RWMutex
+	tokens    map[string]time.Time // JTI → 無効化レコードの有効期限
+	users     map[uuid.UUID]userRevocation
+	syncedAt  time.Time // 最後に同期に成功した時刻（ゼロ値は未同期）
+	watermark time.Time // 次回の同期で取得するrevoked_atの下限
+}
+
+// NewRevocationCache 新しい無効化キャッシュを作成（pollIntervalが0以下の場合は既定の同期間隔を使用）
+func NewRevocationCache(db *gorm.DB, logger *logger.Logger, pollInterval time.Duration) *RevocationCache {
+	if pollInterval <= 0 {
+		logger.Warn("Invalid revocation poll interval, using default", map[string]interface{}{
+			"poll_interval": pollInterval.String(),
+			"default":       defaultRevocationPollInterval.String(),
+		})
+		pollInterval = defaultRevocationPollInterval
+	}
+	return &RevocationCache{
+		db:           db,
+		logger:       logger,
+		pollInterval: pollInterval,
+		staleAfter:   5 * pollInterval,
+		tokens:       make(map[string]time.Time),
+		users:        make(map[uuid.UUID]userRevocation),
+	}
+}
+
+// Sync データベースから無効化レコードを取得してキャッシュに反映
+// 初回は有効期限内の全件、以降は前回の同期以降に無効化されたレコードのみを取得する
+func (c *RevocationCache) Sync() error {
+	startedAt := time.Now()
+
+	c.mu.RLock()
+	watermark := c.watermark
+	c.mu.RUnlock()
+
+	query := c.db.Model(&models.RevokedToken{}).Where("expires_at > ?", startedAt)
+	if !watermark.IsZero() {
+		query = query.Where("revoked_at >= ?", watermark)
+	}
+	var records []models.RevokedToken
+	if err := query.Select("token_jti", "user_id", "revoked_at", "expires_at").Find(&records).Error; err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, record := range records {
+		c.add(record.TokenJTI, record.UserID, record.RevokedAt, record.ExpiresAt)
+	}
+	c.prune(startedAt)
+	c.syncedAt = startedAt
+	c.watermark = startedAt.Add(-revocationSyncOverlap)
+	return nil
+}
+
+// Run ctxが終了するまで定期的に同期（ブロックするため goroutine で実行する）
+func (c *RevocationCache) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Sync(); err != nil {
+				c.logger.Error("Failed to sync revocation cache", err, map[string]interface{}{
+					"last_synced_at": c.lastSyncedAt(),
+				})
+			}
+		}
+	}
+}
+
+// Add 無効化をキャッシュに即時反映（このプロセスで無効化した場合）
+func (c *RevocationCache) Add(jti string, userID uuid.UUID, revokedAt, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(jti, userID, revokedAt, expiresAt)
+}
+
+// Check トークンが無効化されているかキャッシュで判定
+// キャッシュが未同期または古い場合は ok=false を返す
+func (c *RevocationCache) Check(jti string, userID uuid.UUID, issuedAt time.Time) (revoked bool, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.syncedAt.IsZero() || time.Since(c.syncedAt) > c.staleAfter {
+		return false, false
+	}
+
+	now := time.Now()
+	if expiresAt, found := c.tokens[jti]; found && expiresAt.After(now) {
+		return true, true
+	}
+	if revocation, found := c.users[userID]; found && revocation.expiresAt.After(now) && revocation.revokedAt.After(issuedAt) {
+		return true, true
+	}
+	return false, true
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// add 無効化レコードを反映（呼び出し元でロックを取得すること）
+func (c *RevocationCache) add(jti string, userID uuid.UUID, revokedAt, expiresAt time.Time) {
+	if jti != revokeAllTokensJTI {
+		c.tokens[jti] = expiresAt
+		return
+	}
+
+	// 一括無効化は最新の時刻のみ保持すれば判定できる
+	current, found := c.users[userID]
+	if !found || revokedAt.After(current.revokedAt) {
+		current.revokedAt = revokedAt
+	}
+	if expiresAt.After(current.expiresAt) {
+		current.expiresAt = expiresAt
+	}
+	c.users[userID] = current
+}
+
+// prune 有効期限を過ぎた無効化レコードを削除（呼び出し元でロックを取得すること）
+func (c *RevocationCache) prune(now time.Time) {
+	for jti, expiresAt := range c.tokens {
+		if !expiresAt.After(now) {
+			delete(c.tokens, jti)
+		}
+	}
+	for userID, revocation := range c.users {
+		if !revocation.expiresAt.After(now) {
+			delete(c.users, userID)
+		}
+	}
+}
+
+// lastSyncedAt 最後に同期に成功した時刻を取得
+func (c *RevocationCache) lastSyncedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.syncedAt
+}
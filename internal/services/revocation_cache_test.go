@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+func TestRevocationCache_ValidateTokenStatus(t *testing.T) {
+	service, db := setupTestAuth(t)
+	revocation := service.revocationService
+	userID := createAuthTestUser(t, db, "revocation-cache@example.com", "password123")
+
+	// 起動前に記録済みの無効化
+	require.NoError(t, revocation.RevokeToken("revoked-before-start", userID, "logout"))
+
+	cache := NewRevocationCache(db, logger.NewLogger(), time.Second)
+	revocation.SetCache(cache)
+	defer revocation.SetCache(nil)
+
+	issuedAt := time.Now().Add(-time.Minute)
+
+	t.Run("未同期のキャッシュはデータベースで判定", func(t *testing.T) {
+		_, ok := cache.Check("revoked-before-start", userID, issuedAt)
+		assert.False(t, ok)
+		assert.Equal(t, errors.ErrInvalidToken, revocation.ValidateTokenStatus("revoked-before-start", userID, issuedAt))
+	})
+
+	require.NoError(t, cache.Sync())
+
+	t.Run("起動時に読み込んだ無効化を判定", func(t *testing.T) {
+		revoked, ok := cache.Check("revoked-before-start", userID, issuedAt)
+		require.True(t, ok)
+		assert.True(t, revoked)
+		assert.NoError(t, revocation.ValidateTokenStatus("active-token", userID, issuedAt))
+	})
+
+	t.Run("このプロセスでの無効化は即時反映", func(t *testing.T) {
+		require.NoError(t, revocation.RevokeToken("revoked-locally", userID, "logout"))
+		assert.Equal(t, errors.ErrInvalidToken, revocation.ValidateTokenStatus("revoked-locally", userID, issuedAt))
+	})
+
+	t.Run("他のレプリカでの無効化は同期で反映", func(t *testing.T) {
+		require.NoError(t, db.Create(&models.RevokedToken{
+			TokenJTI:  "revoked-by-replica",
+			UserID:    userID,
+			RevokedAt: time.Now(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}).Error)
+		assert.NoError(t, revocation.ValidateTokenStatus("revoked-by-replica", userID, issuedAt), "同期前はキャッシュの内容で判定")
+
+		require.NoError(t, cache.Sync())
+		assert.Equal(t, errors.ErrInvalidToken, revocation.ValidateTokenStatus("revoked-by-replica", userID, issuedAt))
+	})
+
+	t.Run("一括無効化は無効化以前に発行したトークンのみ対象", func(t *testing.T) {
+		require.NoError(t, revocation.RevokeAllUserTokens(userID, "password_change"))
+		assert.Equal(t, errors.ErrInvalidToken, revocation.ValidateTokenStatus("issued-before", userID, issuedAt))
+		assert.NoError(t, revocation.ValidateTokenStatus("issued-after", userID, time.Now().Add(time.Minute)))
+
+		require.NoError(t, revocation.RevokeAllUserTokens(userID, "admin"), "同じユーザーを繰り返し一括無効化できる")
+	})
+
+	t.Run("古くなったキャッシュはデータベースで判定", func(t *testing.T) {
+		cache.mu.Lock()
+		cache.syncedAt = time.Now().Add(-time.Hour)
+		cache.mu.Unlock()
+
+		_, ok := cache.Check("revoked-locally", userID, issuedAt)
+		assert.False(t, ok)
+		assert.Equal(t, errors.ErrInvalidToken, revocation.ValidateTokenStatus("revoked-locally", userID, issuedAt))
+	})
+}
+
+func TestRevocationCache_Prune(t *testing.T) {
+	cache := NewRevocationCache(nil, logger.NewLogger(), time.Second)
+	userID := uuid.New()
+	now := time.Now()
+
+	cache.Add("expired", userID, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	cache.Add("active", userID, now, now.Add(time.Hour))
+	cache.Add(revokeAllTokensJTI, userID, now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	cache.mu.Lock()
+	cache.prune(now)
+	cache.mu.Unlock()
+
+	assert.Len(t, cache.tokens, 1, "トークンの有効期限を過ぎた無効化は保持しない")
+	assert.Contains(t, cache.tokens, "active")
+	assert.Empty(t, cache.users)
+}
+
+func TestRevocationCache_InvalidPollInterval(t *testing.T) {
+	for _, pollInterval := range []time.Duration{0, -time.Second} {
+		cache := NewRevocationCache(nil, logger.NewLogger(), pollInterval)
+		assert.Equal(t, defaultRevocationPollInterval, cache.pollInterval, "0以下の場合は既定の同期間隔")
+		assert.Equal(t, 5*defaultRevocationPollInterval, cache.staleAfter)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.NotPanics(t, func() { cache.Run(ctx) })
+	}
+}
+
+// setupRevocationBenchmark 無効化済みトークンを登録したベンチマーク用のデータベースを作成
+func setupRevocationBenchmark(b *testing.B, revokedCount int) (*gorm.DB, uuid.UUID) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:revocation_bench_%d?mode=memory&cache=shared", revokedCount)), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	require.NoError(b, err)
+	require.NoError(b, db.Exec(`CREATE TABLE IF NOT EXISTS revoked_tokens (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		token_jti TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		revoked_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		expires_at DATETIME NOT NULL
+	)`).Error)
+	require.NoError(b, db.Exec("CREATE INDEX IF NOT EXISTS idx_revoked_tokens_jti ON revoked_tokens(token_jti)").Error)
+	require.NoError(b, db.Exec("CREATE INDEX IF NOT EXISTS idx_revoked_tokens_user ON revoked_tokens(user_id)").Error)
+	require.NoError(b, db.Exec("DELETE FROM revoked_tokens").Error)
+
+	userID := uuid.New()
+	revocation := NewTokenRevocationService(db)
+	for i := 0; i < revokedCount; i++ {
+		require.NoError(b, revocation.RevokeToken(fmt.Sprintf("revoked-%d", i), uuid.New(), "logout"))
+	}
+	return db, userID
+}
+
+// BenchmarkTokenRevocation_ValidateTokenStatus 有効なトークンの検証（リクエストごとの判定）
+func BenchmarkTokenRevocation_ValidateTokenStatus(b *testing.B) {
+	db, userID := setupRevocationBenchmark(b, 1000)
+	issuedAt := time.Now()
+
+	b.Run("Database", func(b *testing.B) {
+		revocation := NewTokenRevocationService(db)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := revocation.ValidateTokenStatus("active-token", userID, issuedAt); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Cache", func(b *testing.B) {
+		revocation := NewTokenRevocationService(db)
+		cache := NewRevocationCache(db, logger.NewLogger(), time.Hour)
+		require.NoError(b, cache.Sync())
+		revocation.SetCache(cache)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := revocation.ValidateTokenStatus("active-token", userID, issuedAt); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("CacheParallel", func(b *testing.B) {
+		revocation := NewTokenRevocationService(db)
+		cache := NewRevocationCache(db, logger.NewLogger(), time.Hour)
+		require.NoError(b, cache.Sync())
+		revocation.SetCache(cache)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if err := revocation.ValidateTokenStatus("active-token", userID, issuedAt); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+}
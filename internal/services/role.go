@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"erp-access-control-go/models"
 	"erp-access-control-go/pkg/errors"
@@ -13,35 +14,48 @@ import (
 
 // RoleService ロール管理サービス
 type RoleService struct {
-	db     *gorm.DB
-	logger *logger.Logger
+	db              *gorm.DB
+	logger          *logger.Logger
+	resolver        *PermissionResolver
+	permissionCache PermissionCache
 }
 
 // NewRoleService 新しいロールサービスを作成
 func NewRoleService(db *gorm.DB, logger *logger.Logger) *RoleService {
 	return &RoleService{
-		db:     db,
-		logger: logger,
+		db:       db,
+		logger:   logger,
+		resolver: NewPermissionResolver(db),
 	}
 }
 
+// SetPermissionCache ロールの権限・親ロールの変更時に無効化する実効権限のキャッシュを設定
+func (s *RoleService) SetPermissionCache(cache PermissionCache) {
+	s.permissionCache = cache
+}
+
 // CreateRoleRequest ロール作成リクエスト
 type CreateRoleRequest struct {
-	Name          string      `json:"name" binding:"required,min=2,max=100"`
-	ParentID      *uuid.UUID  `json:"parent_id" binding:"omitempty"`
-	PermissionIDs []uuid.UUID `json:"permission_ids" binding:"omitempty,dive,uuid"`
+	Name             string      `json:"name" binding:"required,min=2,max=100"`
+	ParentID         *uuid.UUID  `json:"parent_id" binding:"omitempty"`
+	PermissionIDs    []uuid.UUID `json:"permission_ids" binding:"omitempty,dive,uuid"`
+	RequiresApproval bool        `json:"requires_approval"` // ロール付与に承認を必要とする
+	RequiresMFA      bool        `json:"requires_mfa"`      // 保持ユーザーのログインにMFAを必要とする
 }
 
 // UpdateRoleRequest ロール更新リクエスト
 type UpdateRoleRequest struct {
-	Name     *string    `json:"name" binding:"omitempty,min=2,max=100"`
-	ParentID *uuid.UUID `json:"parent_id"`
+	Name             *string    `json:"name" binding:"omitempty,min=2,max=100"`
+	ParentID         *uuid.UUID `json:"parent_id"`
+	RequiresApproval *bool      `json:"requires_approval"`
+	RequiresMFA      *bool      `json:"requires_mfa"`
 }
 
 // AssignPermissionsRequest 権限割り当てリクエスト
 type AssignPermissionsRequest struct {
-	PermissionIDs []uuid.UUID `json:"permission_ids" binding:"required,dive,uuid"`
-	Replace       bool        `json:"replace"` // trueの場合、既存権限を置き換え
+	PermissionIDs []uuid.UUID             `json:"permission_ids" binding:"required,dive,uuid"`
+	Replace       bool                    `json:"replace"`                                     // trueの場合、同じ効果（許可・拒否）の既存権限を置き換え
+	Effect        models.PermissionEffect `json:"effect" binding:"omitempty,oneof=allow deny"` // 省略時は allow
 }
 
 // RoleResponse ロールレスポンス
@@ -50,6 +64,8 @@ type RoleResponse struct {
 	Name                 string                    `json:"name"`
 	ParentID             *uuid.UUID                `json:"parent_id,omitempty"`
 	Level                int                       `json:"level"`
+	RequiresApproval     bool                      `json:"requires_approval"`
+	RequiresMFA          bool                      `json:"requires_mfa"`
 	CreatedAt            string                    `json:"created_at"`
 	Parent               *RoleBasicInfo            `json:"parent,omitempty"`
 	Children             []RoleBasicInfo           `json:"children,omitempty"`
@@ -67,19 +83,21 @@ type RoleBasicInfo struct {
 
 // PermissionInfo 権限情報
 type PermissionInfo struct {
-	ID        uuid.UUID `json:"id"`
-	Module    string    `json:"module"`
-	Action    string    `json:"action"`
-	Inherited bool      `json:"inherited"`
+	ID        uuid.UUID               `json:"id"`
+	Module    string                  `json:"module"`
+	Action    string                  `json:"action"`
+	Effect    models.PermissionEffect `json:"effect"`
+	Inherited bool                    `json:"inherited"`
 }
 
 // InheritedPermissionInfo 継承権限情報
 type InheritedPermissionInfo struct {
-	ID            uuid.UUID `json:"id"`
-	Module        string    `json:"module"`
-	Action        string    `json:"action"`
-	InheritedFrom uuid.UUID `json:"inherited_from"`
-	FromRoleName  string    `json:"from_role_name"`
+	ID            uuid.UUID               `json:"id"`
+	Module        string                  `json:"module"`
+	Action        string                  `json:"action"`
+	Effect        models.PermissionEffect `json:"effect"`
+	InheritedFrom uuid.UUID               `json:"inherited_from"`
+	FromRoleName  string                  `json:"from_role_name"`
 }
 
 // RoleListResponse ロール一覧レスポンス
@@ -166,8 +184,10 @@ func (s *RoleService) CreateRole(req CreateRoleRequest) (*RoleResponse, error) {
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// ロール作成
 		role = models.Role{
-			Name:     req.Name,
-			ParentID: req.ParentID,
+			Name:             req.Name,
+			ParentID:         req.ParentID,
+			RequiresApproval: req.RequiresApproval,
+			RequiresMFA:      req.RequiresMFA,
 		}
 
 		if err := tx.Create(&role).Error; err != nil {
@@ -286,8 +306,15 @@ func (s *RoleService) UpdateRole(roleID uuid.UUID, req UpdateRoleRequest) (*Role
 	if req.ParentID != nil {
 		updates["parent_id"] = *req.ParentID
 	}
+	if req.RequiresApproval != nil {
+		updates["requires_approval"] = *req.RequiresApproval
+	}
+	if req.RequiresMFA != nil {
+		updates["requires_mfa"] = *req.RequiresMFA
+	}
 
 	// 更新実行
+	parentChanged := req.ParentID != nil && (role.ParentID == nil || *role.ParentID != *req.ParentID)
 	if len(updates) > 0 {
 		if err := s.db.Model(&role).Updates(updates).Error; err != nil {
 			s.logger.Error("Failed to update role", err, map[string]interface{}{
@@ -297,6 +324,11 @@ func (s *RoleService) UpdateRole(roleID uuid.UUID, req UpdateRoleRequest) (*Role
 		}
 	}
 
+	// 親ロールの変更はこのロールと子孫ロールを持つユーザーの継承権限に影響する
+	if parentChanged {
+		invalidateRolePermissions(s.db, s.permissionCache, roleID)
+	}
+
 	s.logger.Info("Role updated successfully", map[string]interface{}{
 		"role_id": roleID,
 	})
@@ -448,21 +480,43 @@ func (s *RoleService) AssignPermissions(roleID uuid.UUID, req AssignPermissionsR
 		return nil, errors.NewValidationError("permission_ids", "One or more permissions do not exist")
 	}
 
+	// 拒否は特定の権限のみ（ワイルドカードの拒否は優先順位が曖昧になるため不可）
+	effect := req.Effect
+	if effect == "" {
+		effect = models.PermissionEffectAllow
+	}
+	if effect == models.PermissionEffectDeny {
+		for _, permission := range permissions {
+			if IsWildcardPermission(permission.Module, permission.Action) {
+				return nil, errors.NewValidationError("permission_ids", "Wildcard permissions cannot be denied")
+			}
+		}
+	}
+
 	// 権限割り当て
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		if req.Replace {
-			// 既存権限をクリア
-			if err := tx.Model(&role).Association("Permissions").Clear(); err != nil {
+			// 同じ効果の既存権限をクリア
+			if err := tx.Where("role_id = ? AND effect = ?", role.ID, effect).Delete(&models.RolePermission{}).Error; err != nil {
 				return err
 			}
 		}
 
 		// 新しい権限を追加
-		if err := tx.Model(&role).Association("Permissions").Append(permissions); err != nil {
-			return err
-		}
-
-		return nil
+		// 関連付けのみを登録する（Association.Appendは権限レコードを保存し直すため、
+		// モデルの検証でワイルドカード権限などが拒否される）
+		if len(permissions) == 0 {
+			return nil
+		}
+		// 既に付与されている権限は効果（許可・拒否）を更新する
+		rolePermissions := make([]models.RolePermission, len(permissions))
+		for i, permission := range permissions {
+			rolePermissions[i] = models.RolePermission{RoleID: role.ID, PermissionID: permission.ID, Effect: effect}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "role_id"}, {Name: "permission_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"effect"}),
+		}).Create(&rolePermissions).Error
 	})
 
 	if err != nil {
@@ -472,6 +526,8 @@ func (s *RoleService) AssignPermissions(roleID uuid.UUID, req AssignPermissionsR
 		return nil, errors.NewDatabaseError(err)
 	}
 
+	invalidateRolePermissions(s.db, s.permissionCache, roleID)
+
 	s.logger.Info("Permissions assigned successfully", map[string]interface{}{
 		"role_id": roleID,
 	})
@@ -484,26 +540,15 @@ func (s *RoleService) AssignPermissions(roleID uuid.UUID, req AssignPermissionsR
 func (s *RoleService) GetRolePermissions(roleID uuid.UUID) (*RolePermissionsResponse, error) {
 	// ロール存在確認
 	var role models.Role
-	if err := s.db.Preload("Permissions").First(&role, roleID).Error; err != nil {
+	if err := s.db.First(&role, roleID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.NewNotFoundError("Role", "Role not found")
 		}
 		return nil, errors.NewDatabaseError(err)
 	}
 
-	// 直接権限
-	directPermissions := make([]PermissionInfo, len(role.Permissions))
-	for i, perm := range role.Permissions {
-		directPermissions[i] = PermissionInfo{
-			ID:        perm.ID,
-			Module:    perm.Module,
-			Action:    perm.Action,
-			Inherited: false,
-		}
-	}
-
-	// 継承権限
-	inheritedPermissions, err := s.getInheritedPermissions(roleID)
+	// 直接権限・継承権限
+	directPermissions, inheritedPermissions, err := s.getRolePermissionInfo(roleID)
 	if err != nil {
 		return nil, err
 	}
@@ -695,19 +740,8 @@ func (s *RoleService) convertToRoleResponse(role *models.Role) (*RoleResponse, e
 		}
 	}
 
-	// 直接権限
-	permissions := make([]PermissionInfo, len(role.Permissions))
-	for i, perm := range role.Permissions {
-		permissions[i] = PermissionInfo{
-			ID:        perm.ID,
-			Module:    perm.Module,
-			Action:    perm.Action,
-			Inherited: false,
-		}
-	}
-
-	// 継承権限
-	inheritedPermissions, err := s.getInheritedPermissions(role.ID)
+	// 直接権限・継承権限
+	permissions, inheritedPermissions, err := s.getRolePermissionInfo(role.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -717,6 +751,8 @@ func (s *RoleService) convertToRoleResponse(role *models.Role) (*RoleResponse, e
 		Name:                 role.Name,
 		ParentID:             role.ParentID,
 		Level:                level,
+		RequiresApproval:     role.RequiresApproval,
+		RequiresMFA:          role.RequiresMFA,
 		CreatedAt:            role.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		Parent:               parent,
 		Children:             children,
@@ -768,54 +804,37 @@ func (s *RoleService) getUserCount(roleID uuid.UUID) (int64, error) {
 	return primaryCount + additionalCount, nil
 }
 
-// getInheritedPermissions 継承権限を取得
-func (s *RoleService) getInheritedPermissions(roleID uuid.UUID) ([]InheritedPermissionInfo, error) {
-	var inheritedPermissions []InheritedPermissionInfo
-
-	// 親ロールからの権限継承を再帰的に取得
-	query := `
-		WITH RECURSIVE role_ancestors AS (
-			SELECT id, name, parent_id, 1 as level
-			FROM roles WHERE id = ?
-			UNION ALL
-			SELECT r.id, r.name, r.parent_id, ra.level + 1
-			FROM roles r
-			JOIN role_ancestors ra ON r.id = ra.parent_id
-		),
-		inherited_permissions AS (
-			SELECT DISTINCT p.id, p.module, p.action, 
-				   ra.id as from_role_id, ra.name as from_role_name
-			FROM permissions p
-			JOIN role_permissions rp ON p.id = rp.permission_id
-			JOIN role_ancestors ra ON rp.role_id = ra.id
-			WHERE ra.level > 1
-		)
-		SELECT id, module, action, from_role_id, from_role_name
-		FROM inherited_permissions
-		ORDER BY module, action
-	`
-
-	rows, err := s.db.Raw(query, roleID).Rows()
+// getRolePermissionInfo 直接付与された権限と親ロールから継承した権限を取得（実行時の権限判定と同じリゾルバーを使用）
+func (s *RoleService) getRolePermissionInfo(roleID uuid.UUID) ([]PermissionInfo, []InheritedPermissionInfo, error) {
+	permissions, err := s.resolver.ResolveRoles([]uuid.UUID{roleID})
 	if err != nil {
-		return nil, errors.NewDatabaseError(err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var permission InheritedPermissionInfo
-		if err := rows.Scan(
-			&permission.ID,
-			&permission.Module,
-			&permission.Action,
-			&permission.InheritedFrom,
-			&permission.FromRoleName,
-		); err != nil {
-			return nil, errors.NewDatabaseError(err)
-		}
-		inheritedPermissions = append(inheritedPermissions, permission)
+		return nil, nil, errors.NewDatabaseError(err)
+	}
+
+	directPermissions := make([]PermissionInfo, 0, len(permissions))
+	inheritedPermissions := make([]InheritedPermissionInfo, 0, len(permissions))
+	for _, permission := range permissions {
+		if !permission.Inherited {
+			directPermissions = append(directPermissions, PermissionInfo{
+				ID:        permission.ID,
+				Module:    permission.Module,
+				Action:    permission.Action,
+				Effect:    permission.Effect,
+				Inherited: false,
+			})
+			continue
+		}
+		inheritedPermissions = append(inheritedPermissions, InheritedPermissionInfo{
+			ID:            permission.ID,
+			Module:        permission.Module,
+			Action:        permission.Action,
+			Effect:        permission.Effect,
+			InheritedFrom: permission.SourceRoleID,
+			FromRoleName:  permission.SourceRoleName,
+		})
 	}
 
-	return inheritedPermissions, nil
+	return directPermissions, inheritedPermissions, nil
 }
 
 // mergePermissions 直接権限と継承権限をマージし、重複を除去
@@ -827,13 +846,15 @@ func (s *RoleService) mergePermissions(direct []PermissionInfo, inherited []Inhe
 		permissionMap[perm.ID] = perm
 	}
 
-	// 継承権限を追加（直接権限が優先）
+	// 継承権限を追加（直接権限が優先、ただし継承した拒否は直接の許可より優先）
 	for _, inherited := range inherited {
-		if _, exists := permissionMap[inherited.ID]; !exists {
+		existing, exists := permissionMap[inherited.ID]
+		if !exists || (existing.Effect != models.PermissionEffectDeny && inherited.Effect == models.PermissionEffectDeny) {
 			permissionMap[inherited.ID] = PermissionInfo{
 				ID:        inherited.ID,
 				Module:    inherited.Module,
 				Action:    inherited.Action,
+				Effect:    inherited.Effect,
 				Inherited: true,
 			}
 		}
@@ -31,6 +31,8 @@ func setupTestRole(t *testing.T) (*RoleService, *gorm.DB) {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			name TEXT NOT NULL,
 			parent_id TEXT,
+			requires_approval BOOLEAN NOT NULL DEFAULT false,
+			requires_mfa BOOLEAN NOT NULL DEFAULT false,
 			FOREIGN KEY (parent_id) REFERENCES roles(id)
 		)
 	`).Error
@@ -52,6 +54,7 @@ func setupTestRole(t *testing.T) (*RoleService, *gorm.DB) {
 		CREATE TABLE IF NOT EXISTS role_permissions (
 			role_id TEXT,
 			permission_id TEXT,
+			effect TEXT NOT NULL DEFAULT 'allow',
 			PRIMARY KEY (role_id, permission_id),
 			FOREIGN KEY (role_id) REFERENCES roles(id),
 			FOREIGN KEY (permission_id) REFERENCES permissions(id)
@@ -354,6 +357,25 @@ func TestRoleService_InputValidation(t *testing.T) {
 			require.NoError(t, err)
 			assert.Len(t, resp.DirectPermissions, 0)
 		})
+
+		t.Run("正常系: ワイルドカード権限の割り当て", func(t *testing.T) {
+			all := createPermissionForRoleTest(t, db, "*", "*")
+			userAll := createPermissionForRoleTest(t, db, "user", "*")
+			req := AssignPermissionsRequest{
+				PermissionIDs: []uuid.UUID{all.ID, userAll.ID},
+				Replace:       true,
+			}
+
+			resp, err := svc.AssignPermissions(testRole.ID, req)
+			require.NoError(t, err)
+			require.Len(t, resp.DirectPermissions, 2)
+
+			keys := []string{
+				resp.DirectPermissions[0].Module + ":" + resp.DirectPermissions[0].Action,
+				resp.DirectPermissions[1].Module + ":" + resp.DirectPermissions[1].Action,
+			}
+			assert.ElementsMatch(t, []string{"*:*", "user:*"}, keys)
+		})
 	})
 }
 
@@ -546,14 +568,14 @@ func TestRoleService_PermissionInheritance(t *testing.T) {
 
 		t.Run("権限継承メソッドの動作確認", func(t *testing.T) {
 			// 継承権限取得機能の確認（実際の権限データがなくても動作）
-			inherited, err := svc.getInheritedPermissions(childRole.ID)
+			direct, inherited, err := svc.getRolePermissionInfo(childRole.ID)
 			require.NoError(t, err)
 
-			// 権限データがないため継承権限は0件
+			// 権限データがないため直接権限・継承権限は0件
+			assert.Len(t, direct, 0)
 			assert.Len(t, inherited, 0)
 
 			// 権限マージ機能の確認
-			direct := []PermissionInfo{}
 			merged := svc.mergePermissions(direct, inherited)
 			assert.Len(t, merged, 0)
 		})
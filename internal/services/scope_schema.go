@@ -0,0 +1,298 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"erp-access-control-go/models"
+)
+
+// =============================================================================
+// スコープ値のJSONスキーマ
+// =============================================================================
+
+// scopeValueSchemaDocuments スコープタイプ別のscope_valueスキーマ（JSON Schemaのサブセット）
+// 値は単一値または値の配列で指定でき、"*"はワイルドカードとして扱われる
+var scopeValueSchemaDocuments = map[models.ScopeType]string{
+	models.ScopeTypeDepartment: `{
+		"type": "object",
+		"required": ["department_id"],
+		"additionalProperties": false,
+		"properties": {
+			"department_id": {
+				"type": ["string", "array"],
+				"format": "uuid",
+				"minItems": 1,
+				"items": {"type": "string", "format": "uuid"}
+			},
+			"include_children": {"type": "boolean"}
+		}
+	}`,
+	models.ScopeTypeRegion: `{
+		"type": "object",
+		"required": ["region"],
+		"additionalProperties": false,
+		"properties": {
+			"region": {
+				"type": ["string", "array"],
+				"pattern": "^[a-z][a-z0-9_-]{0,49}$",
+				"minItems": 1,
+				"items": {"type": "string", "pattern": "^[a-z][a-z0-9_-]{0,49}$"}
+			},
+			"country": {"type": "string", "pattern": "^[A-Z]{2}$"}
+		}
+	}`,
+	models.ScopeTypeProject: `{
+		"type": "object",
+		"required": ["project_id"],
+		"additionalProperties": false,
+		"properties": {
+			"project_id": {
+				"type": ["string", "array"],
+				"minLength": 1,
+				"maxLength": 100,
+				"minItems": 1,
+				"items": {"type": "string", "minLength": 1, "maxLength": 100}
+			}
+		}
+	}`,
+	models.ScopeTypeLocation: `{
+		"type": "object",
+		"required": ["location_id"],
+		"additionalProperties": false,
+		"properties": {
+			"location_id": {
+				"type": ["string", "array"],
+				"minLength": 1,
+				"maxLength": 100,
+				"minItems": 1,
+				"items": {"type": "string", "minLength": 1, "maxLength": 100}
+			},
+			"region": {"type": "string", "pattern": "^[a-z][a-z0-9_-]{0,49}$"}
+		}
+	}`,
+}
+
+// スコープ値で特別な意味を持つキー
+const (
+	ScopeKeyDepartmentID    = "department_id"    // 部署スコープの対象部署ID
+	ScopeKeyIncludeChildren = "include_children" // trueの場合は配下の部署も対象に含める
+)
+
+// scopeValueSchemas 解析済みスキーマ
+var scopeValueSchemas = mustParseScopeValueSchemas()
+
+// jsonSchema スコープ値検証で使用するJSON Schemaのサブセット
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Format               string                 `json:"format"`
+	Enum                 []interface{}          `json:"enum"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaTypes "type"キー（文字列または文字列配列）
+type schemaTypes []string
+
+// UnmarshalJSON 文字列・配列の両形式を受け付ける
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*t = multiple
+	return nil
+}
+
+// ScopeSchemaViolation スキーマ違反
+type ScopeSchemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// mustParseScopeValueSchemas スキーマ定義を解析（定義誤りは起動時にpanic）
+func mustParseScopeValueSchemas() map[models.ScopeType]*jsonSchema {
+	schemas := make(map[models.ScopeType]*jsonSchema, len(scopeValueSchemaDocuments))
+	for scopeType, document := range scopeValueSchemaDocuments {
+		var schema jsonSchema
+		if err := json.Unmarshal([]byte(document), &schema); err != nil {
+			panic(fmt.Sprintf("invalid scope schema for %s: %v", scopeType, err))
+		}
+		schema.compile()
+		schemas[scopeType] = &schema
+	}
+	return schemas
+}
+
+// compile 正規表現を事前コンパイル
+func (s *jsonSchema) compile() {
+	if s.Pattern != "" {
+		s.pattern = regexp.MustCompile(s.Pattern)
+	}
+	for _, property := range s.Properties {
+		property.compile()
+	}
+	if s.Items != nil {
+		s.Items.compile()
+	}
+}
+
+// GetScopeValueSchemas スコープタイプ別のスキーマ定義を取得
+func GetScopeValueSchemas() map[models.ScopeType]json.RawMessage {
+	result := make(map[models.ScopeType]json.RawMessage, len(scopeValueSchemaDocuments))
+	for scopeType, document := range scopeValueSchemaDocuments {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, []byte(document)); err != nil {
+			continue
+		}
+		result[scopeType] = compacted.Bytes()
+	}
+	return result
+}
+
+// ValidateScopeValue スコープ値をスコープタイプのスキーマで検証
+func ValidateScopeValue(scopeType models.ScopeType, value map[string]interface{}) []ScopeSchemaViolation {
+	schema, ok := scopeValueSchemas[scopeType]
+	if !ok {
+		return []ScopeSchemaViolation{{Path: "scope_type", Message: "unsupported scope type"}}
+	}
+	if value == nil {
+		return []ScopeSchemaViolation{{Path: "scope_value", Message: "is required"}}
+	}
+
+	var violations []ScopeSchemaViolation
+	schema.validate("scope_value", value, &violations)
+	return violations
+}
+
+// validate 値を検証して違反を追加
+func (s *jsonSchema) validate(path string, value interface{}, violations *[]ScopeSchemaViolation) {
+	add := func(format string, args ...interface{}) {
+		*violations = append(*violations, ScopeSchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	valueType := jsonTypeOf(value)
+	if len(s.Type) > 0 && !s.allowsType(valueType) {
+		add("must be of type %s", strings.Join(s.Type, " or "))
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		matched := false
+		for _, candidate := range s.Enum {
+			if candidate == value {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("must be one of %v", s.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, exists := v[key]; !exists {
+				*violations = append(*violations, ScopeSchemaViolation{Path: path + "." + key, Message: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, defined := s.Properties[key]
+			if !defined {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*violations = append(*violations, ScopeSchemaViolation{Path: path + "." + key, Message: "is not allowed"})
+				}
+				continue
+			}
+			property.validate(path+"."+key, v[key], violations)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			add("must contain at least %d items", *s.MinItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+	case string:
+		// ワイルドカードは形式チェックの対象外
+		if v == "*" {
+			return
+		}
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			add("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && len(v) > *s.MaxLength {
+			add("must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			add("must match pattern %s", s.Pattern)
+		}
+		if s.Format == "uuid" {
+			if _, err := uuid.Parse(v); err != nil {
+				add("must be a valid UUID")
+			}
+		}
+	}
+}
+
+// allowsType スキーマが指定の型を許可するか
+func (s *jsonSchema) allowsType(valueType string) bool {
+	for _, t := range s.Type {
+		if t == valueType || (t == "number" && valueType == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf デコード済みJSON値の型名を取得
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case int, int64:
+		return "integer"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}, models.JSONB:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// SessionService ログインセッション（端末ごとのトークン）管理サービス
+type SessionService struct {
+	db                  *gorm.DB
+	logger              *logger.Logger
+	revocationService   *TokenRevocationService
+	refreshTokenService *RefreshTokenService
+}
+
+// NewSessionService 新しいセッション管理サービスを作成
+func NewSessionService(db *gorm.DB, logger *logger.Logger, revocationService *TokenRevocationService, refreshTokenService *RefreshTokenService) *SessionService {
+	return &SessionService{
+		db:                  db,
+		logger:              logger,
+		revocationService:   revocationService,
+		refreshTokenService: refreshTokenService,
+	}
+}
+
+// SessionResponse セッション情報レスポンス
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  *string   `json:"user_agent,omitempty"`
+	IPAddress  *string   `json:"ip_address,omitempty"`
+	IssuedAt   time.Time `json:"issued_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // リクエストに使用したトークンのセッション
+}
+
+// SessionListResponse セッション一覧レスポンス
+type SessionListResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+	Total    int               `json:"total"`
+}
+
+// RevokeSessionsResponse セッション一括無効化レスポンス
+type RevokeSessionsResponse struct {
+	RevokedCount int `json:"revoked_count"`
+}
+
+// StartSession ログイン完了時にセッションを記録
+func (s *SessionService) StartSession(userID, familyID uuid.UUID, jti, ipAddress, userAgent string) error {
+	now := time.Now()
+	session := models.UserSession{
+		UserID:     userID,
+		FamilyID:   familyID,
+		CurrentJTI: jti,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.refreshTokenService.ExpiresIn()),
+	}
+	if ipAddress != "" {
+		session.IPAddress = &ipAddress
+	}
+	if userAgent != "" {
+		session.UserAgent = &userAgent
+	}
+
+	if err := s.db.Create(&session).Error; err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	return nil
+}
+
+// TouchSession トークンリフレッシュ時に最新のアクセストークンと最終利用日時を記録
+// 有効期限はローテーションで発行したリフレッシュトークンに合わせて延長する
+// セッション無効化で失効させるのは最新のJTIのみのため、以前のアクセストークンはここで失効させる
+func (s *SessionService) TouchSession(familyID uuid.UUID, jti string) error {
+	var session models.UserSession
+	if err := s.db.Where("family_id = ?", familyID).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil // セッション記録の導入前に発行されたファミリー
+		}
+		return errors.NewDatabaseError(err)
+	}
+
+	expiresAt := time.Now().Add(s.refreshTokenService.ExpiresIn())
+	if err := models.TouchUserSession(s.db, familyID, jti, expiresAt); err != nil {
+		return errors.NewDatabaseError(err)
+	}
+
+	if session.CurrentJTI != jti {
+		if err := s.revocationService.RevokeToken(session.CurrentJTI, session.UserID, "token_rotated"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListSessions ユーザーの有効なセッション一覧を取得（currentJTIのセッションをcurrentとする）
+func (s *SessionService) ListSessions(userID uuid.UUID, currentJTI string) (*SessionListResponse, error) {
+	sessions, err := models.FindActiveUserSessions(s.db, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	responses := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			IssuedAt:   session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    currentJTI != "" && session.CurrentJTI == currentJTI,
+		}
+	}
+
+	return &SessionListResponse{Sessions: responses, Total: len(responses)}, nil
+}
+
+// RevokeSession セッションを無効化（最新のアクセストークンとリフレッシュトークンを失効）
+func (s *SessionService) RevokeSession(userID, sessionID uuid.UUID, reason string) error {
+	session, err := models.FindActiveUserSession(s.db, userID, sessionID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewNotFoundError("Session", "session not found or already revoked")
+		}
+		return errors.NewDatabaseError(err)
+	}
+
+	if err := s.revoke(session, reason); err != nil {
+		return err
+	}
+
+	s.logger.Info("Session revoked", map[string]interface{}{
+		"user_id":    userID,
+		"session_id": sessionID,
+		"reason":     reason,
+	})
+	return nil
+}
+
+// RevokeOtherSessions currentJTIのセッション以外をすべて無効化（currentJTIが空の場合は全セッション）
+func (s *SessionService) RevokeOtherSessions(userID uuid.UUID, currentJTI, reason string) (*RevokeSessionsResponse, error) {
+	sessions, err := models.FindActiveUserSessions(s.db, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	revoked := 0
+	for i := range sessions {
+		if currentJTI != "" && sessions[i].CurrentJTI == currentJTI {
+			continue
+		}
+		if err := s.revoke(&sessions[i], reason); err != nil {
+			return nil, err
+		}
+		revoked++
+	}
+
+	s.logger.Info("Sessions revoked", map[string]interface{}{
+		"user_id":       userID,
+		"revoked_count": revoked,
+		"reason":        reason,
+	})
+	return &RevokeSessionsResponse{RevokedCount: revoked}, nil
+}
+
+// CleanupExpiredSessions 有効期限を過ぎたセッションを削除（定期ジョブ用）
+func (s *SessionService) CleanupExpiredSessions() (int64, error) {
+	deleted, err := models.CleanupExpiredUserSessions(s.db)
+	if err != nil {
+		return 0, errors.NewDatabaseError(err)
+	}
+	return deleted, nil
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// revoke セッションのトークンを失効させて無効化済みにする
+func (s *SessionService) revoke(session *models.UserSession, reason string) error {
+	if err := s.revocationService.RevokeToken(session.CurrentJTI, session.UserID, reason); err != nil {
+		return err
+	}
+	if err := s.refreshTokenService.RevokeFamily(session.FamilyID, reason); err != nil {
+		return err
+	}
+	if err := models.RevokeUserSession(s.db, session.ID, reason); err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	return nil
+}
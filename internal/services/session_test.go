@@ -0,0 +1,234 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// setupTestSession セッション記録を有効にした認証サービスを作成
+func setupTestSession(t *testing.T) (*AuthService, *SessionService) {
+	service, db := setupTestAuth(t)
+	sessionService := NewSessionService(db, logger.NewLogger(), service.revocationService, service.refreshTokenService)
+	service.SetSessionService(sessionService)
+	service.refreshTokenService.SetRevocationService(service.revocationService)
+	return service, sessionService
+}
+
+// loginSessionTestUser 指定した端末情報でログイン
+func loginSessionTestUser(t *testing.T, service *AuthService, email, ipAddress, userAgent string) *LoginResponse {
+	response, err := service.Login(LoginRequest{
+		Email:     email,
+		Password:  "password123",
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+	require.NoError(t, err)
+	return response
+}
+
+// sessionTestJTI アクセストークンのJTIを取得
+func sessionTestJTI(t *testing.T, service *AuthService, token string) string {
+	jti, err := service.jwtService.GetTokenID(token)
+	require.NoError(t, err)
+	return jti
+}
+
+func TestSessionService_ListSessions(t *testing.T) {
+	service, sessionService := setupTestSession(t)
+	userID := createAuthTestUser(t, service.db, "session-list@example.com", "password123")
+
+	laptop := loginSessionTestUser(t, service, "session-list@example.com", "192.0.2.10", "Laptop Browser")
+	time.Sleep(10 * time.Millisecond)
+	phone := loginSessionTestUser(t, service, "session-list@example.com", "192.0.2.20", "Phone App")
+
+	list, err := sessionService.ListSessions(userID, sessionTestJTI(t, service, phone.Token))
+	require.NoError(t, err)
+	require.Equal(t, 2, list.Total)
+
+	assert.Equal(t, "Phone App", *list.Sessions[0].UserAgent, "最終利用日時の新しい順")
+	assert.Equal(t, "192.0.2.20", *list.Sessions[0].IPAddress)
+	assert.True(t, list.Sessions[0].Current)
+	assert.False(t, list.Sessions[1].Current)
+
+	t.Run("リフレッシュで最新のアクセストークンに追従", func(t *testing.T) {
+		refreshed, err := service.RefreshToken(laptop.RefreshToken)
+		require.NoError(t, err)
+
+		list, err := sessionService.ListSessions(userID, sessionTestJTI(t, service, refreshed.Token))
+		require.NoError(t, err)
+		require.Equal(t, 2, list.Total)
+		assert.Equal(t, "Laptop Browser", *list.Sessions[0].UserAgent)
+		assert.True(t, list.Sessions[0].Current)
+	})
+
+	t.Run("ログアウトしたセッションは一覧に含めない", func(t *testing.T) {
+		require.NoError(t, service.LogoutWithToken(phone.RefreshToken))
+
+		list, err := sessionService.ListSessions(userID, "")
+		require.NoError(t, err)
+		require.Equal(t, 1, list.Total)
+		assert.Equal(t, "Laptop Browser", *list.Sessions[0].UserAgent)
+	})
+}
+
+// TestSessionService_RefreshExtendsSession リフレッシュでセッションの有効期限が新しいリフレッシュトークンに合わせて延長されることのテスト
+func TestSessionService_RefreshExtendsSession(t *testing.T) {
+	service, sessionService := setupTestSession(t)
+	userID := createAuthTestUser(t, service.db, "session-extend@example.com", "password123")
+	login := loginSessionTestUser(t, service, "session-extend@example.com", "192.0.2.60", "Long Running")
+
+	// ログイン時の有効期限が間もなく切れる状態にする
+	require.NoError(t, service.db.Exec("UPDATE user_sessions SET expires_at = ? WHERE user_id = ?",
+		time.Now().Add(50*time.Millisecond), userID.String()).Error)
+
+	refreshed, err := service.RefreshToken(login.RefreshToken)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	list, err := sessionService.ListSessions(userID, sessionTestJTI(t, service, refreshed.Token))
+	require.NoError(t, err)
+	require.Equal(t, 1, list.Total, "リフレッシュ後のセッションは有効")
+	assert.True(t, list.Sessions[0].Current)
+	assert.WithinDuration(t, time.Now().Add(service.refreshTokenService.ExpiresIn()), list.Sessions[0].ExpiresAt, time.Minute)
+
+	deleted, err := sessionService.CleanupExpiredSessions()
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	require.NoError(t, sessionService.RevokeSession(userID, list.Sessions[0].ID, "user_revoked_session"))
+}
+
+func TestSessionService_RevokeSession(t *testing.T) {
+	service, sessionService := setupTestSession(t)
+	userID := createAuthTestUser(t, service.db, "session-revoke@example.com", "password123")
+	otherID := createAuthTestUser(t, service.db, "session-other@example.com", "password123")
+
+	target := loginSessionTestUser(t, service, "session-revoke@example.com", "192.0.2.30", "Old Device")
+	loginSessionTestUser(t, service, "session-other@example.com", "192.0.2.40", "Other User")
+
+	list, err := sessionService.ListSessions(userID, "")
+	require.NoError(t, err)
+	require.Equal(t, 1, list.Total)
+	sessionID := list.Sessions[0].ID
+
+	t.Run("他のユーザーのセッションは無効化できない", func(t *testing.T) {
+		err := sessionService.RevokeSession(otherID, sessionID, "user_revoked_session")
+		assert.True(t, errors.IsNotFound(err))
+	})
+
+	require.NoError(t, sessionService.RevokeSession(userID, sessionID, "user_revoked_session"))
+
+	t.Run("アクセストークンとリフレッシュトークンを失効", func(t *testing.T) {
+		revoked, err := service.revocationService.IsTokenRevoked(sessionTestJTI(t, service, target.Token))
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		_, err = service.RefreshToken(target.RefreshToken)
+		require.Error(t, err)
+	})
+
+	t.Run("無効化済みのセッションは見つからない", func(t *testing.T) {
+		list, err := sessionService.ListSessions(userID, "")
+		require.NoError(t, err)
+		assert.Equal(t, 0, list.Total)
+
+		err = sessionService.RevokeSession(userID, sessionID, "user_revoked_session")
+		assert.True(t, errors.IsNotFound(err))
+
+		err = sessionService.RevokeSession(userID, uuid.New(), "user_revoked_session")
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
+
+// TestSessionService_RevokeSessionAfterRefresh リフレッシュ前に発行したアクセストークンも失効することのテスト
+func TestSessionService_RevokeSessionAfterRefresh(t *testing.T) {
+	service, sessionService := setupTestSession(t)
+	userID := createAuthTestUser(t, service.db, "session-rotated@example.com", "password123")
+
+	login := loginSessionTestUser(t, service, "session-rotated@example.com", "192.0.2.70", "Rotating Device")
+	refreshed, err := service.RefreshToken(login.RefreshToken)
+	require.NoError(t, err)
+
+	revoked, err := service.revocationService.IsTokenRevoked(sessionTestJTI(t, service, login.Token))
+	require.NoError(t, err)
+	assert.True(t, revoked, "ローテーション前のアクセストークンは失効")
+
+	list, err := sessionService.ListSessions(userID, "")
+	require.NoError(t, err)
+	require.Equal(t, 1, list.Total)
+	require.NoError(t, sessionService.RevokeSession(userID, list.Sessions[0].ID, "user_revoked_session"))
+
+	revoked, err = service.revocationService.IsTokenRevoked(sessionTestJTI(t, service, refreshed.Token))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+// TestSessionService_RevokeOnLogoutAndReuse ログアウト・リフレッシュトークンの再利用検知でセッションのアクセストークンも失効することのテスト
+func TestSessionService_RevokeOnLogoutAndReuse(t *testing.T) {
+	service, sessionService := setupTestSession(t)
+	userID := createAuthTestUser(t, service.db, "session-reuse@example.com", "password123")
+
+	isRevoked := func(token string) bool {
+		revoked, err := service.revocationService.IsTokenRevoked(sessionTestJTI(t, service, token))
+		require.NoError(t, err)
+		return revoked
+	}
+
+	t.Run("ログアウト", func(t *testing.T) {
+		login := loginSessionTestUser(t, service, "session-reuse@example.com", "192.0.2.80", "Logout Device")
+		require.NoError(t, service.LogoutWithToken(login.RefreshToken))
+		assert.True(t, isRevoked(login.Token))
+	})
+
+	t.Run("再利用検知", func(t *testing.T) {
+		login := loginSessionTestUser(t, service, "session-reuse@example.com", "192.0.2.81", "Stolen Device")
+		refreshed, err := service.RefreshToken(login.RefreshToken)
+		require.NoError(t, err)
+		require.False(t, isRevoked(refreshed.Token))
+
+		_, err = service.RefreshToken(login.RefreshToken)
+		require.Error(t, err)
+		assert.True(t, isRevoked(refreshed.Token), "再利用を検知したファミリーの最新のアクセストークンを失効")
+	})
+
+	list, err := sessionService.ListSessions(userID, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, list.Total)
+}
+
+func TestSessionService_RevokeOtherSessions(t *testing.T) {
+	service, sessionService := setupTestSession(t)
+	userID := createAuthTestUser(t, service.db, "session-others@example.com", "password123")
+
+	current := loginSessionTestUser(t, service, "session-others@example.com", "192.0.2.50", "Current")
+	first := loginSessionTestUser(t, service, "session-others@example.com", "192.0.2.51", "First")
+	second := loginSessionTestUser(t, service, "session-others@example.com", "192.0.2.52", "Second")
+
+	result, err := sessionService.RevokeOtherSessions(userID, sessionTestJTI(t, service, current.Token), "user_revoked_other_sessions")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.RevokedCount)
+
+	for _, other := range []*LoginResponse{first, second} {
+		_, err := service.RefreshToken(other.RefreshToken)
+		require.Error(t, err)
+	}
+	_, err = service.RefreshToken(current.RefreshToken)
+	require.NoError(t, err, "現在のセッションは継続")
+
+	t.Run("JTI未指定の場合は全セッションを無効化", func(t *testing.T) {
+		result, err := sessionService.RevokeOtherSessions(userID, "", "admin_revoked_sessions")
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.RevokedCount)
+
+		list, err := sessionService.ListSessions(userID, "")
+		require.NoError(t, err)
+		assert.Equal(t, 0, list.Total)
+	})
+}
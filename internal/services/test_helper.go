@@ -38,6 +38,10 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			status TEXT DEFAULT 'active',
 			department_id TEXT,
 			primary_role_id TEXT,
+			failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+			locked_until DATETIME,
+			last_login_at DATETIME,
+			password_changed_at DATETIME,
 			FOREIGN KEY (department_id) REFERENCES departments(id)
 		)
 	`).Error
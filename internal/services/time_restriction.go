@@ -0,0 +1,508 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// 営業時間プリセットのデフォルト値
+const (
+	DefaultBusinessStartHour = 9
+	DefaultBusinessEndHour   = 18
+	DefaultBusinessTimezone  = "Asia/Tokyo"
+)
+
+// DefaultBusinessWeekdays 営業日のデフォルト（月〜金、1=日曜日）
+var DefaultBusinessWeekdays = []int{2, 3, 4, 5, 6}
+
+// timeOfDayInputLayouts リクエストで受け付ける時刻形式
+var timeOfDayInputLayouts = []string{"15:04", "15:04:05"}
+
+// TimeRestrictionService 時間制限管理サービス
+type TimeRestrictionService struct {
+	db                *gorm.DB
+	logger            *logger.Logger
+	permissionService *PermissionService
+}
+
+// NewTimeRestrictionService 新しい時間制限サービスを作成
+func NewTimeRestrictionService(db *gorm.DB, logger *logger.Logger) *TimeRestrictionService {
+	return &TimeRestrictionService{
+		db:                db,
+		logger:            logger,
+		permissionService: NewPermissionService(db, logger),
+	}
+}
+
+// SetPermissionService ドライランで権限の保持を判定する権限サービスを設定（権限キャッシュを共有する場合）
+func (s *TimeRestrictionService) SetPermissionService(permissionService *PermissionService) {
+	s.permissionService = permissionService
+}
+
+// CreateTimeRestrictionRequest 時間制限作成リクエスト
+type CreateTimeRestrictionRequest struct {
+	ResourceType string  `json:"resource_type" binding:"required"`
+	StartTime    *string `json:"start_time"` // HH:MM または HH:MM:SS
+	EndTime      *string `json:"end_time"`   // HH:MM または HH:MM:SS
+	AllowedDays  []int   `json:"allowed_days"`
+	Timezone     string  `json:"timezone"`
+}
+
+// UpdateTimeRestrictionRequest 時間制限更新リクエスト
+// start_time・end_timeに空文字列を指定すると制限を解除
+type UpdateTimeRestrictionRequest struct {
+	ResourceType *string `json:"resource_type"`
+	StartTime    *string `json:"start_time"`
+	EndTime      *string `json:"end_time"`
+	AllowedDays  *[]int  `json:"allowed_days"`
+	Timezone     *string `json:"timezone"`
+}
+
+// BusinessHoursPresetRequest 営業時間プリセット適用リクエスト
+type BusinessHoursPresetRequest struct {
+	ResourceTypes   []string `json:"resource_types" binding:"required,min=1"`
+	StartHour       *int     `json:"start_hour" binding:"omitempty,min=0,max=23"`
+	EndHour         *int     `json:"end_hour" binding:"omitempty,min=1,max=24"`
+	Weekdays        []int    `json:"weekdays"`
+	Timezone        string   `json:"timezone"`
+	ReplaceExisting bool     `json:"replace_existing"` // 対象リソースの既存制限を置き換える
+}
+
+// TimeAccessCheckRequest 時間制限ドライランのリクエスト
+type TimeAccessCheckRequest struct {
+	ResourceType string     `form:"resource_type"`
+	Permission   string     `form:"permission"` // resource_typeの代わりに権限（module:action）で指定可能
+	At           *time.Time `form:"at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// TimeRestrictionResponse 時間制限レスポンス
+type TimeRestrictionResponse struct {
+	ID              int       `json:"id"`
+	UserID          uuid.UUID `json:"user_id"`
+	ResourceType    string    `json:"resource_type"`
+	StartTime       *string   `json:"start_time,omitempty"`
+	EndTime         *string   `json:"end_time,omitempty"`
+	AllowedDays     []int64   `json:"allowed_days,omitempty"`
+	AllowedDayNames []string  `json:"allowed_day_names,omitempty"`
+	Timezone        string    `json:"timezone"`
+	CreatedAt       string    `json:"created_at"`
+}
+
+// TimeRestrictionListResponse 時間制限一覧レスポンス
+type TimeRestrictionListResponse struct {
+	TimeRestrictions []TimeRestrictionResponse `json:"time_restrictions"`
+	Total            int                       `json:"total"`
+}
+
+// TimeRestrictionEvaluation 個々の時間制限の評価結果
+type TimeRestrictionEvaluation struct {
+	TimeRestrictionResponse
+	LocalTime   string `json:"local_time"` // 制限のタイムゾーンでの判定時刻
+	AllowedDay  bool   `json:"allowed_day"`
+	AllowedTime bool   `json:"allowed_time"`
+	Allowed     bool   `json:"allowed"`
+}
+
+// TimeAccessCheckResponse 時間制限ドライランのレスポンス
+type TimeAccessCheckResponse struct {
+	UserID        uuid.UUID                   `json:"user_id"`
+	ResourceType  string                      `json:"resource_type"`
+	Permission    string                      `json:"permission,omitempty"`
+	HasPermission *bool                       `json:"has_permission,omitempty"` // 権限を指定した場合、実効権限として保持しているか
+	CheckedAt     string                      `json:"checked_at"`
+	Allowed       bool                        `json:"allowed"`
+	Restricted    bool                        `json:"restricted"` // 時間制限が設定されているか
+	Evaluations   []TimeRestrictionEvaluation `json:"evaluations"`
+}
+
+// CreateTimeRestriction ユーザーに時間制限を作成
+func (s *TimeRestrictionService) CreateTimeRestriction(userID uuid.UUID, req CreateTimeRestrictionRequest) (*TimeRestrictionResponse, error) {
+	s.logger.Info("Creating time restriction", map[string]interface{}{
+		"user_id":       userID,
+		"resource_type": req.ResourceType,
+	})
+
+	if err := ensureUserExists(s.db, userID); err != nil {
+		return nil, err
+	}
+
+	restriction := models.TimeRestriction{
+		UserID:       userID,
+		ResourceType: req.ResourceType,
+		Timezone:     req.Timezone,
+	}
+	if restriction.Timezone == "" {
+		restriction.Timezone = "UTC"
+	}
+	restriction.SetAllowedDaysFromInts(req.AllowedDays)
+
+	var err error
+	if restriction.StartTime, err = parseTimeOfDay("start_time", req.StartTime); err != nil {
+		return nil, err
+	}
+	if restriction.EndTime, err = parseTimeOfDay("end_time", req.EndTime); err != nil {
+		return nil, err
+	}
+
+	if err := validateTimeRestriction(&restriction); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Omit("User").Create(&restriction).Error; err != nil {
+		s.logger.Error("Failed to create time restriction", err, map[string]interface{}{
+			"user_id":       userID,
+			"resource_type": req.ResourceType,
+		})
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("Time restriction created successfully", map[string]interface{}{
+		"time_restriction_id": restriction.ID,
+		"user_id":             userID,
+		"resource_type":       restriction.ResourceType,
+	})
+
+	return convertToTimeRestrictionResponse(&restriction), nil
+}
+
+// GetTimeRestrictions ユーザーの時間制限一覧を取得
+func (s *TimeRestrictionService) GetTimeRestrictions(userID uuid.UUID) (*TimeRestrictionListResponse, error) {
+	if err := ensureUserExists(s.db, userID); err != nil {
+		return nil, err
+	}
+
+	var restrictions []models.TimeRestriction
+	if err := s.db.Where("user_id = ?", userID).Order("resource_type ASC, id ASC").Find(&restrictions).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	responses := make([]TimeRestrictionResponse, len(restrictions))
+	for i := range restrictions {
+		responses[i] = *convertToTimeRestrictionResponse(&restrictions[i])
+	}
+
+	return &TimeRestrictionListResponse{
+		TimeRestrictions: responses,
+		Total:            len(responses),
+	}, nil
+}
+
+// GetTimeRestriction ユーザーの時間制限詳細を取得
+func (s *TimeRestrictionService) GetTimeRestriction(userID uuid.UUID, restrictionID int) (*TimeRestrictionResponse, error) {
+	restriction, err := s.findTimeRestriction(userID, restrictionID)
+	if err != nil {
+		return nil, err
+	}
+	return convertToTimeRestrictionResponse(restriction), nil
+}
+
+// UpdateTimeRestriction ユーザーの時間制限を更新
+func (s *TimeRestrictionService) UpdateTimeRestriction(userID uuid.UUID, restrictionID int, req UpdateTimeRestrictionRequest) (*TimeRestrictionResponse, error) {
+	s.logger.Info("Updating time restriction", map[string]interface{}{
+		"time_restriction_id": restrictionID,
+		"user_id":             userID,
+	})
+
+	restriction, err := s.findTimeRestriction(userID, restrictionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ResourceType != nil {
+		restriction.ResourceType = *req.ResourceType
+	}
+	if req.Timezone != nil {
+		restriction.Timezone = *req.Timezone
+	}
+	if req.AllowedDays != nil {
+		restriction.SetAllowedDaysFromInts(*req.AllowedDays)
+	}
+	if req.StartTime != nil {
+		if restriction.StartTime, err = parseTimeOfDay("start_time", req.StartTime); err != nil {
+			return nil, err
+		}
+	}
+	if req.EndTime != nil {
+		if restriction.EndTime, err = parseTimeOfDay("end_time", req.EndTime); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateTimeRestriction(restriction); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(restriction).
+		Select("resource_type", "start_time", "end_time", "allowed_days", "timezone").
+		Updates(restriction).Error; err != nil {
+		s.logger.Error("Failed to update time restriction", err, map[string]interface{}{
+			"time_restriction_id": restrictionID,
+			"user_id":             userID,
+		})
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("Time restriction updated successfully", map[string]interface{}{
+		"time_restriction_id": restrictionID,
+		"user_id":             userID,
+	})
+
+	return convertToTimeRestrictionResponse(restriction), nil
+}
+
+// DeleteTimeRestriction ユーザーの時間制限を削除
+func (s *TimeRestrictionService) DeleteTimeRestriction(userID uuid.UUID, restrictionID int) error {
+	if _, err := s.findTimeRestriction(userID, restrictionID); err != nil {
+		return err
+	}
+
+	if err := s.db.Where("id = ? AND user_id = ?", restrictionID, userID).Delete(&models.TimeRestriction{}).Error; err != nil {
+		s.logger.Error("Failed to delete time restriction", err, map[string]interface{}{
+			"time_restriction_id": restrictionID,
+			"user_id":             userID,
+		})
+		return errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("Time restriction deleted successfully", map[string]interface{}{
+		"time_restriction_id": restrictionID,
+		"user_id":             userID,
+	})
+
+	return nil
+}
+
+// ApplyBusinessHoursPreset 営業時間プリセットを指定リソースに適用
+func (s *TimeRestrictionService) ApplyBusinessHoursPreset(userID uuid.UUID, req BusinessHoursPresetRequest) (*TimeRestrictionListResponse, error) {
+	s.logger.Info("Applying business hours preset", map[string]interface{}{
+		"user_id":          userID,
+		"resource_types":   req.ResourceTypes,
+		"replace_existing": req.ReplaceExisting,
+	})
+
+	if err := ensureUserExists(s.db, userID); err != nil {
+		return nil, err
+	}
+
+	startHour, endHour := DefaultBusinessStartHour, DefaultBusinessEndHour
+	if req.StartHour != nil {
+		startHour = *req.StartHour
+	}
+	if req.EndHour != nil {
+		endHour = *req.EndHour
+	}
+	weekdays := req.Weekdays
+	if len(weekdays) == 0 {
+		weekdays = DefaultBusinessWeekdays
+	}
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = DefaultBusinessTimezone
+	}
+
+	template, err := models.GetBusinessHoursRestriction(startHour, endHour, weekdays, timezone)
+	if err != nil {
+		return nil, errors.NewValidationError("preset", err.Error())
+	}
+	// 24時は終日（23:59:59）として扱う
+	if endHour == 24 {
+		endOfDay := time.Date(0, 1, 1, 23, 59, 59, 0, time.UTC)
+		template.EndTime = &endOfDay
+	}
+
+	restrictions := make([]models.TimeRestriction, 0, len(req.ResourceTypes))
+	for _, resourceType := range req.ResourceTypes {
+		restriction := template
+		restriction.UserID = userID
+		restriction.ResourceType = resourceType
+		if err := validateTimeRestriction(&restriction); err != nil {
+			return nil, err
+		}
+		restrictions = append(restrictions, restriction)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if req.ReplaceExisting {
+			if err := tx.Where("user_id = ? AND resource_type IN ?", userID, req.ResourceTypes).
+				Delete(&models.TimeRestriction{}).Error; err != nil {
+				return err
+			}
+		}
+		for i := range restrictions {
+			if err := tx.Omit("User").Create(&restrictions[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to apply business hours preset", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	responses := make([]TimeRestrictionResponse, len(restrictions))
+	for i := range restrictions {
+		responses[i] = *convertToTimeRestrictionResponse(&restrictions[i])
+	}
+
+	s.logger.Info("Business hours preset applied successfully", map[string]interface{}{
+		"user_id": userID,
+		"created": len(responses),
+	})
+
+	return &TimeRestrictionListResponse{
+		TimeRestrictions: responses,
+		Total:            len(responses),
+	}, nil
+}
+
+// CheckTimeAccess 指定時刻にリソースへアクセス可能かを判定（ドライラン）
+// 権限を指定した場合はCheckPermissionと同様に、実効権限として保持していなければ時間制限に関わらず拒否と判定する
+func (s *TimeRestrictionService) CheckTimeAccess(userID uuid.UUID, req TimeAccessCheckRequest) (*TimeAccessCheckResponse, error) {
+	if req.Permission != "" && !s.permissionService.ValidatePermission(req.Permission) {
+		return nil, errors.NewValidationError("permission", "Permission must be in module:action format")
+	}
+
+	resourceType := req.ResourceType
+	if resourceType == "" && req.Permission != "" {
+		resourceType = ResourceTypeForPermission(req.Permission)
+		if resourceType == "" {
+			return nil, errors.NewValidationError("permission", "Permission does not map to a resource type")
+		}
+	}
+	if resourceType == "" {
+		return nil, errors.NewValidationError("resource_type", "resource_type or permission is required")
+	}
+
+	if err := ensureUserExists(s.db, userID); err != nil {
+		return nil, err
+	}
+
+	checkTime := time.Now()
+	if req.At != nil {
+		checkTime = *req.At
+	}
+
+	restrictions, err := models.FindTimeRestrictionsByUserAndResource(s.db, userID, resourceType)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	response := &TimeAccessCheckResponse{
+		UserID:       userID,
+		ResourceType: resourceType,
+		Permission:   req.Permission,
+		CheckedAt:    checkTime.Format(time.RFC3339),
+		Allowed:      len(restrictions) == 0, // 制限がなければ許可
+		Restricted:   len(restrictions) > 0,
+		Evaluations:  make([]TimeRestrictionEvaluation, 0, len(restrictions)),
+	}
+
+	// いずれかの制限に合致すれば許可（models.CheckTimeAccessと同じ判定）
+	for i := range restrictions {
+		restriction := &restrictions[i]
+		evaluation := TimeRestrictionEvaluation{
+			TimeRestrictionResponse: *convertToTimeRestrictionResponse(restriction),
+			AllowedDay:              restriction.IsAllowedDay(checkTime),
+			AllowedTime:             restriction.IsAllowedTime(checkTime),
+		}
+		evaluation.Allowed = evaluation.AllowedDay && evaluation.AllowedTime
+		if location, err := time.LoadLocation(restriction.Timezone); err == nil {
+			evaluation.LocalTime = checkTime.In(location).Format(time.RFC3339)
+		}
+		if evaluation.Allowed {
+			response.Allowed = true
+		}
+		response.Evaluations = append(response.Evaluations, evaluation)
+	}
+
+	if req.Permission != "" {
+		permissions, err := s.permissionService.GetUserPermissions(userID)
+		if err != nil {
+			return nil, err
+		}
+		hasPermission := HasPermission(permissions, req.Permission)
+		response.HasPermission = &hasPermission
+		response.Allowed = response.Allowed && hasPermission
+	}
+
+	return response, nil
+}
+
+// =============================================================================
+// 内部ヘルパー
+// =============================================================================
+
+// findTimeRestriction ユーザーに紐づく時間制限を取得
+func (s *TimeRestrictionService) findTimeRestriction(userID uuid.UUID, restrictionID int) (*models.TimeRestriction, error) {
+	var restriction models.TimeRestriction
+	if err := s.db.Where("id = ? AND user_id = ?", restrictionID, userID).First(&restriction).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("TimeRestriction", "Time restriction not found")
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+	return &restriction, nil
+}
+
+// validateTimeRestriction 時間制限の内容を検証
+func validateTimeRestriction(restriction *models.TimeRestriction) error {
+	if !restriction.IsValidResourceType() {
+		return errors.NewValidationError("resource_type", "Invalid resource type")
+	}
+	if !restriction.IsValidTimezone() {
+		return errors.NewValidationError("timezone", "Invalid timezone")
+	}
+	if !restriction.IsValidAllowedDays() {
+		return errors.NewValidationError("allowed_days", "Allowed days must be between 1 (Sunday) and 7 (Saturday)")
+	}
+	// 日付をまたぐ時間帯は未対応のため開始 < 終了を必須とする
+	if restriction.StartTime != nil && restriction.EndTime != nil && !restriction.StartTime.Before(*restriction.EndTime) {
+		return errors.NewValidationError("end_time", "End time must be after start time")
+	}
+	return nil
+}
+
+// parseTimeOfDay HH:MM(:SS)形式の時刻を解析（空文字列は制限なし）
+func parseTimeOfDay(field string, value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	for _, layout := range timeOfDayInputLayouts {
+		if parsed, err := time.Parse(layout, *value); err == nil {
+			t := time.Date(0, 1, 1, parsed.Hour(), parsed.Minute(), parsed.Second(), 0, time.UTC)
+			return &t, nil
+		}
+	}
+	return nil, errors.NewValidationError(field, "Time must be in HH:MM or HH:MM:SS format")
+}
+
+// convertToTimeRestrictionResponse 時間制限モデルをレスポンスに変換
+func convertToTimeRestrictionResponse(restriction *models.TimeRestriction) *TimeRestrictionResponse {
+	response := &TimeRestrictionResponse{
+		ID:              restriction.ID,
+		UserID:          restriction.UserID,
+		ResourceType:    restriction.ResourceType,
+		AllowedDays:     restriction.AllowedDays,
+		AllowedDayNames: restriction.GetAllowedDaysAsStrings(),
+		Timezone:        restriction.Timezone,
+		CreatedAt:       restriction.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if restriction.StartTime != nil {
+		startTime := restriction.StartTime.Format("15:04:05")
+		response.StartTime = &startTime
+	}
+	if restriction.EndTime != nil {
+		endTime := restriction.EndTime.Format("15:04:05")
+		response.EndTime = &endTime
+	}
+	return response
+}
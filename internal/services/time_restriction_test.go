@@ -0,0 +1,243 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// setupTestTimeRestriction 時間制限テスト用のサービスとユーザーを作成
+func setupTestTimeRestriction(t *testing.T) (*TimeRestrictionService, *gorm.DB, uuid.UUID) {
+	db := setupTestDB(t)
+	setupTestTimeRestrictions(t, db)
+
+	userID := uuid.New()
+	err := db.Exec("INSERT INTO users (id, name, email, status) VALUES (?, ?, ?, 'active')",
+		userID.String(), "時間制限ユーザー", userID.String()+"@example.com").Error
+	require.NoError(t, err)
+
+	return NewTimeRestrictionService(db, logger.NewLogger()), db, userID
+}
+
+func TestTimeRestrictionService_CRUD(t *testing.T) {
+	service, _, userID := setupTestTimeRestriction(t)
+
+	start, end := "09:00", "18:00"
+	created, err := service.CreateTimeRestriction(userID, CreateTimeRestrictionRequest{
+		ResourceType: "orders",
+		StartTime:    &start,
+		EndTime:      &end,
+		AllowedDays:  []int{2, 3, 4, 5, 6},
+		Timezone:     "Asia/Tokyo",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "09:00:00", *created.StartTime)
+	assert.Equal(t, "18:00:00", *created.EndTime)
+	assert.Equal(t, []string{"月", "火", "水", "木", "金"}, created.AllowedDayNames)
+
+	t.Run("一覧と詳細", func(t *testing.T) {
+		list, err := service.GetTimeRestrictions(userID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, list.Total)
+
+		detail, err := service.GetTimeRestriction(userID, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "orders", detail.ResourceType)
+		assert.Equal(t, "Asia/Tokyo", detail.Timezone)
+	})
+
+	t.Run("他ユーザーの制限は取得できない", func(t *testing.T) {
+		_, err := service.GetTimeRestriction(uuid.New(), created.ID)
+		assert.True(t, errors.IsNotFound(err))
+	})
+
+	t.Run("更新で終了時刻を解除", func(t *testing.T) {
+		empty := ""
+		timezone := "UTC"
+		updated, err := service.UpdateTimeRestriction(userID, created.ID, UpdateTimeRestrictionRequest{
+			EndTime:  &empty,
+			Timezone: &timezone,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, updated.EndTime)
+		assert.Equal(t, "UTC", updated.Timezone)
+
+		detail, err := service.GetTimeRestriction(userID, created.ID)
+		require.NoError(t, err)
+		assert.Nil(t, detail.EndTime)
+		assert.Equal(t, "09:00:00", *detail.StartTime)
+	})
+
+	t.Run("削除", func(t *testing.T) {
+		require.NoError(t, service.DeleteTimeRestriction(userID, created.ID))
+		_, err := service.GetTimeRestriction(userID, created.ID)
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
+
+func TestTimeRestrictionService_Validation(t *testing.T) {
+	service, _, userID := setupTestTimeRestriction(t)
+
+	start, end, invalid := "18:00", "09:00", "9時"
+
+	tests := []struct {
+		name  string
+		req   CreateTimeRestrictionRequest
+		field string
+	}{
+		{"不正なタイムゾーン", CreateTimeRestrictionRequest{ResourceType: "orders", Timezone: "Mars/Base"}, "timezone"},
+		{"不正な曜日", CreateTimeRestrictionRequest{ResourceType: "orders", AllowedDays: []int{0, 8}}, "allowed_days"},
+		{"不正なリソース種別", CreateTimeRestrictionRequest{ResourceType: "unknown"}, "resource_type"},
+		{"開始が終了より後", CreateTimeRestrictionRequest{ResourceType: "orders", StartTime: &start, EndTime: &end}, "end_time"},
+		{"不正な時刻形式", CreateTimeRestrictionRequest{ResourceType: "orders", StartTime: &invalid}, "start_time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreateTimeRestriction(userID, tt.req)
+			require.Error(t, err)
+			assert.True(t, errors.IsValidationError(err))
+			assert.Equal(t, tt.field, err.(*errors.APIError).Details.Field)
+		})
+	}
+
+	t.Run("存在しないユーザー", func(t *testing.T) {
+		_, err := service.CreateTimeRestriction(uuid.New(), CreateTimeRestrictionRequest{ResourceType: "orders"})
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
+
+func TestTimeRestrictionService_BusinessHoursPreset(t *testing.T) {
+	service, _, userID := setupTestTimeRestriction(t)
+
+	result, err := service.ApplyBusinessHoursPreset(userID, BusinessHoursPresetRequest{
+		ResourceTypes: []string{"orders", "inventory"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Total)
+	for _, restriction := range result.TimeRestrictions {
+		assert.Equal(t, "09:00:00", *restriction.StartTime)
+		assert.Equal(t, "18:00:00", *restriction.EndTime)
+		assert.Equal(t, DefaultBusinessTimezone, restriction.Timezone)
+		assert.Equal(t, []int64{2, 3, 4, 5, 6}, restriction.AllowedDays)
+	}
+
+	t.Run("置き換え指定で既存制限を差し替え", func(t *testing.T) {
+		startHour, endHour := 8, 24
+		_, err := service.ApplyBusinessHoursPreset(userID, BusinessHoursPresetRequest{
+			ResourceTypes:   []string{"orders"},
+			StartHour:       &startHour,
+			EndHour:         &endHour,
+			ReplaceExisting: true,
+		})
+		require.NoError(t, err)
+
+		list, err := service.GetTimeRestrictions(userID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, list.Total)
+		for _, restriction := range list.TimeRestrictions {
+			if restriction.ResourceType == "orders" {
+				assert.Equal(t, "08:00:00", *restriction.StartTime)
+				assert.Equal(t, "23:59:59", *restriction.EndTime)
+			}
+		}
+	})
+
+	t.Run("開始時刻が終了時刻以降はエラー", func(t *testing.T) {
+		startHour, endHour := 18, 9
+		_, err := service.ApplyBusinessHoursPreset(userID, BusinessHoursPresetRequest{
+			ResourceTypes: []string{"reports"},
+			StartHour:     &startHour,
+			EndHour:       &endHour,
+		})
+		assert.True(t, errors.IsValidationError(err))
+	})
+}
+
+func TestTimeRestrictionService_CheckTimeAccess(t *testing.T) {
+	setupTestPermission(t) // 権限の判定に使用するロール・権限テーブルを作成
+	service, db, userID := setupTestTimeRestriction(t)
+
+	_, err := service.ApplyBusinessHoursPreset(userID, BusinessHoursPresetRequest{
+		ResourceTypes: []string{"orders"},
+	})
+	require.NoError(t, err)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	t.Run("営業時間内", func(t *testing.T) {
+		at := time.Date(2025, 1, 6, 10, 0, 0, 0, tokyo) // 月曜日
+		result, err := service.CheckTimeAccess(userID, TimeAccessCheckRequest{ResourceType: "orders", At: &at})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.True(t, result.Restricted)
+		require.Len(t, result.Evaluations, 1)
+		assert.True(t, result.Evaluations[0].Allowed)
+	})
+
+	t.Run("営業時間外（権限指定）", func(t *testing.T) {
+		at := time.Date(2025, 1, 6, 21, 0, 0, 0, tokyo)
+		result, err := service.CheckTimeAccess(userID, TimeAccessCheckRequest{Permission: "orders:update", At: &at})
+		require.NoError(t, err)
+		assert.Equal(t, "orders", result.ResourceType)
+		assert.False(t, result.Allowed)
+		assert.True(t, result.Evaluations[0].AllowedDay)
+		assert.False(t, result.Evaluations[0].AllowedTime)
+	})
+
+	t.Run("権限を保持していない場合は営業時間内でも拒否", func(t *testing.T) {
+		at := time.Date(2025, 1, 6, 10, 0, 0, 0, tokyo)
+		result, err := service.CheckTimeAccess(userID, TimeAccessCheckRequest{Permission: "orders:update", At: &at})
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		require.NotNil(t, result.HasPermission)
+		assert.False(t, *result.HasPermission)
+		assert.True(t, result.Evaluations[0].Allowed)
+	})
+
+	t.Run("保持している権限はCheckPermissionと同じ判定", func(t *testing.T) {
+		role := createRoleForPermissionTest(t, db, "時間制限ドライラン", nil)
+		permission := createPermissionForPermissionTest(t, db, "orders", "*")
+		assignPermissionToRole(t, db, role.ID, permission.ID)
+		err := db.Exec("INSERT INTO user_roles (user_id, role_id, is_active, valid_from) VALUES (?, ?, ?, ?)",
+			userID.String(), role.ID.String(), true, time.Now().Add(-time.Hour)).Error
+		require.NoError(t, err)
+
+		for hour, want := range map[int]bool{10: true, 21: false} {
+			at := time.Date(2025, 1, 6, hour, 0, 0, 0, tokyo)
+			result, err := service.CheckTimeAccess(userID, TimeAccessCheckRequest{Permission: "orders:update", At: &at})
+			require.NoError(t, err)
+			require.NotNil(t, result.HasPermission)
+			assert.True(t, *result.HasPermission)
+			assert.Equal(t, want, result.Allowed, hour)
+		}
+
+		result, err := service.CheckTimeAccess(userID, TimeAccessCheckRequest{ResourceType: "orders"})
+		require.NoError(t, err)
+		assert.Nil(t, result.HasPermission, "リソース種別で指定した場合は権限を判定しない")
+	})
+
+	t.Run("制限のないリソース", func(t *testing.T) {
+		at := time.Date(2025, 1, 5, 23, 0, 0, 0, tokyo)
+		result, err := service.CheckTimeAccess(userID, TimeAccessCheckRequest{ResourceType: "reports", At: &at})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.False(t, result.Restricted)
+	})
+
+	t.Run("リソース未指定はエラー", func(t *testing.T) {
+		_, err := service.CheckTimeAccess(userID, TimeAccessCheckRequest{})
+		assert.True(t, errors.IsValidationError(err))
+
+		_, err = service.CheckTimeAccess(userID, TimeAccessCheckRequest{ResourceType: "orders", Permission: "orders"})
+		assert.True(t, errors.IsValidationError(err), "不正な形式の権限")
+	})
+}
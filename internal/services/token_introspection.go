@@ -0,0 +1,102 @@
+package services
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/jwt"
+)
+
+// TokenIntrospectionService 下流サービス向けのトークンイントロスペクション（RFC 7662）サービス
+type TokenIntrospectionService struct {
+	jwtService        *jwt.Service
+	revocationService *TokenRevocationService
+	clients           map[string][sha256.Size]byte // client_id → シークレットのSHA-256
+}
+
+// NewTokenIntrospectionService 新しいトークンイントロスペクションサービスを作成
+// clientsはイントロスペクションを許可するクライアントのclient_id → シークレット
+func NewTokenIntrospectionService(jwtService *jwt.Service, revocationService *TokenRevocationService, clients map[string]string) *TokenIntrospectionService {
+	hashed := make(map[string][sha256.Size]byte, len(clients))
+	for clientID, secret := range clients {
+		hashed[clientID] = sha256.Sum256([]byte(secret))
+	}
+
+	return &TokenIntrospectionService{
+		jwtService:        jwtService,
+		revocationService: revocationService,
+		clients:           hashed,
+	}
+}
+
+// IntrospectionResponse イントロスペクション結果（非アクティブの場合はactiveのみ）
+type IntrospectionResponse struct {
+	Active      bool           `json:"active"`
+	TokenType   string         `json:"token_type,omitempty"`
+	Subject     string         `json:"sub,omitempty"`
+	UserID      *uuid.UUID     `json:"user_id,omitempty"`
+	Email       string         `json:"email,omitempty"`
+	Scope       string         `json:"scope,omitempty"`       // 権限のスペース区切り
+	Permissions []string       `json:"permissions,omitempty"` // 拒否権限は "!" 付き（許可より優先）
+	ActiveRoles []jwt.RoleInfo `json:"active_roles,omitempty"`
+	Exp         int64          `json:"exp,omitempty"`
+	Iat         int64          `json:"iat,omitempty"`
+	Nbf         int64          `json:"nbf,omitempty"`
+	Jti         string         `json:"jti,omitempty"`
+	Iss         string         `json:"iss,omitempty"`
+}
+
+// AuthenticateClient イントロスペクションを要求したクライアントを認証
+func (s *TokenIntrospectionService) AuthenticateClient(clientID, clientSecret string) bool {
+	expected, ok := s.clients[clientID]
+	// 未登録のクライアントでも同じ比較を行い、応答時間から登録の有無を推測されないようにする
+	actual := sha256.Sum256([]byte(clientSecret))
+	return subtle.ConstantTimeCompare(expected[:], actual[:]) == 1 && ok
+}
+
+// Introspect アクセストークンの署名・有効期限・無効化状態を検証して内容を返す
+// 無効なトークンはエラーではなく active=false として返す
+func (s *TokenIntrospectionService) Introspect(token string) (*IntrospectionResponse, error) {
+	inactive := &IntrospectionResponse{Active: false}
+
+	claims, err := s.jwtService.ValidateToken(token)
+	if err != nil {
+		return inactive, nil
+	}
+
+	// 発行日時がないトークンは一括無効化の判定ができないため非アクティブとする
+	if claims.IssuedAt == nil {
+		return inactive, nil
+	}
+	if err := s.revocationService.ValidateTokenStatus(claims.ID, claims.UserID, claims.IssuedAt.Time); err != nil {
+		if err == errors.ErrInvalidToken {
+			return inactive, nil
+		}
+		return nil, err
+	}
+
+	response := &IntrospectionResponse{
+		Active:      true,
+		TokenType:   "Bearer",
+		Subject:     claims.Subject,
+		UserID:      &claims.UserID,
+		Email:       claims.Email,
+		Scope:       strings.Join(claims.Permissions, " "),
+		Permissions: claims.Permissions,
+		ActiveRoles: claims.ActiveRoles,
+		Iat:         claims.IssuedAt.Unix(),
+		Jti:         claims.ID,
+		Iss:         claims.Issuer,
+	}
+	if claims.ExpiresAt != nil {
+		response.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.NotBefore != nil {
+		response.Nbf = claims.NotBefore.Unix()
+	}
+	return response, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"erp-access-control-go/pkg/jwt"
+)
+
+func TestTokenIntrospectionService_AuthenticateClient(t *testing.T) {
+	service := NewTokenIntrospectionService(jwt.NewService("test-secret", time.Minute), nil, map[string]string{
+		"inventory": "inventory-secret",
+	})
+
+	assert.True(t, service.AuthenticateClient("inventory", "inventory-secret"))
+	assert.False(t, service.AuthenticateClient("inventory", "wrong-secret"))
+	assert.False(t, service.AuthenticateClient("unknown", "inventory-secret"))
+	assert.False(t, service.AuthenticateClient("unknown", ""), "未登録クライアントの空シークレットも拒否")
+}
+
+func TestTokenIntrospectionService_Introspect(t *testing.T) {
+	_, db := setupTestAuth(t)
+	jwtService := jwt.NewService("test-secret", 15*time.Minute)
+	revocationService := NewTokenRevocationService(db)
+	service := NewTokenIntrospectionService(jwtService, revocationService, nil)
+
+	userID := uuid.New()
+	roles := []jwt.RoleInfo{{ID: uuid.New(), Name: "営業担当", Priority: 10}}
+	token, err := jwtService.GenerateToken(userID, "introspect@example.com", []string{"user:read", "order:create"}, nil, roles, &roles[0])
+	require.NoError(t, err)
+
+	t.Run("有効なトークンはクレームを返す", func(t *testing.T) {
+		resp, err := service.Introspect(token)
+		require.NoError(t, err)
+		assert.True(t, resp.Active)
+		require.NotNil(t, resp.UserID)
+		assert.Equal(t, userID, *resp.UserID)
+		assert.Equal(t, userID.String(), resp.Subject)
+		assert.Equal(t, []string{"user:read", "order:create"}, resp.Permissions)
+		assert.Equal(t, "user:read order:create", resp.Scope)
+		assert.Equal(t, roles, resp.ActiveRoles)
+		assert.Greater(t, resp.Exp, time.Now().Unix())
+	})
+
+	t.Run("不正・他の鍵で署名されたトークンは非アクティブ", func(t *testing.T) {
+		resp, err := service.Introspect("not-a-jwt")
+		require.NoError(t, err)
+		assert.Equal(t, &IntrospectionResponse{Active: false}, resp)
+
+		other, err := jwt.NewService("other-secret", time.Minute).GenerateTokenSimple(userID, "introspect@example.com", nil)
+		require.NoError(t, err)
+		resp, err = service.Introspect(other)
+		require.NoError(t, err)
+		assert.False(t, resp.Active)
+	})
+
+	t.Run("無効化されたトークンは非アクティブ", func(t *testing.T) {
+		claims, err := jwtService.ValidateToken(token)
+		require.NoError(t, err)
+		require.NoError(t, revocationService.RevokeToken(claims.ID, userID, "logout"))
+
+		resp, err := service.Introspect(token)
+		require.NoError(t, err)
+		assert.False(t, resp.Active)
+		assert.Nil(t, resp.UserID, "非アクティブの場合はクレームを返さない")
+	})
+
+	t.Run("ユーザーの全トークン無効化後は非アクティブ", func(t *testing.T) {
+		otherUser := uuid.New()
+		issued, err := jwtService.GenerateTokenSimple(otherUser, "other@example.com", nil)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, revocationService.RevokeAllUserTokens(otherUser, "password_reset"))
+
+		resp, err := service.Introspect(issued)
+		require.NoError(t, err)
+		assert.False(t, resp.Active)
+	})
+}
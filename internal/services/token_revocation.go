@@ -10,9 +10,13 @@ import (
 	"erp-access-control-go/pkg/errors"
 )
 
+// revokeAllTokensJTI ユーザー単位の一括無効化を表すJTI
+const revokeAllTokensJTI = "*"
+
 // TokenRevocationService JWTトークン無効化サービス
 type TokenRevocationService struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache *RevocationCache // 無効化状態のキャッシュ（未設定の場合は毎回データベースを参照）
 }
 
 // NewTokenRevocationService 新しいトークン無効化サービスを作成
@@ -20,6 +24,11 @@ func NewTokenRevocationService(db *gorm.DB) *TokenRevocationService {
 	return &TokenRevocationService{db: db}
 }
 
+// SetCache 無効化状態の判定にキャッシュを使用
+func (s *TokenRevocationService) SetCache(cache *RevocationCache) {
+	s.cache = cache
+}
+
 // RevokeToken JTIをrevoked_tokensテーブルに保存してJWTトークンを無効化
 func (s *TokenRevocationService) RevokeToken(jti string, userID uuid.UUID, reason string) error {
 	revokedToken := models.RevokedToken{
@@ -32,6 +41,9 @@ func (s *TokenRevocationService) RevokeToken(jti string, userID uuid.UUID, reaso
 	if err := s.db.Create(&revokedToken).Error; err != nil {
 		return errors.NewDatabaseError(err)
 	}
+	if s.cache != nil {
+		s.cache.Add(revokedToken.TokenJTI, userID, revokedToken.RevokedAt, revokedToken.ExpiresAt)
+	}
 
 	return nil
 }
@@ -53,7 +65,7 @@ func (s *TokenRevocationService) RevokeAllUserTokens(userID uuid.UUID, reason st
 	// We'll store a "revoke_all_before" timestamp for the user
 
 	revokedToken := models.RevokedToken{
-		TokenJTI:  "*", // Special marker for "revoke all"
+		TokenJTI:  revokeAllTokensJTI, // Special marker for "revoke all"
 		UserID:    userID,
 		RevokedAt: time.Now(),
 		ExpiresAt: time.Now().Add(24 * time.Hour), // JWT expiration time
@@ -62,6 +74,14 @@ func (s *TokenRevocationService) RevokeAllUserTokens(userID uuid.UUID, reason st
 	if err := s.db.Create(&revokedToken).Error; err != nil {
 		return errors.NewDatabaseError(err)
 	}
+	if s.cache != nil {
+		s.cache.Add(revokedToken.TokenJTI, userID, revokedToken.RevokedAt, revokedToken.ExpiresAt)
+	}
+
+	// リフレッシュトークンも無効化して再発行できないようにする
+	if _, err := models.RevokeUserRefreshTokens(s.db, userID, reason); err != nil {
+		return errors.NewDatabaseError(err)
+	}
 
 	return nil
 }
@@ -69,7 +89,7 @@ func (s *TokenRevocationService) RevokeAllUserTokens(userID uuid.UUID, reason st
 // IsUserTokensRevoked ユーザーの全トークンが特定時刻以降に無効化されたかチェック
 func (s *TokenRevocationService) IsUserTokensRevoked(userID uuid.UUID, issuedAt time.Time) (bool, error) {
 	var revokedToken models.RevokedToken
-	err := s.db.Where("user_id = ? AND token_jti = ? AND revoked_at > ?", userID, "*", issuedAt).
+	err := s.db.Where("user_id = ? AND token_jti = ? AND revoked_at > ?", userID, revokeAllTokensJTI, issuedAt).
 		First(&revokedToken).Error
 
 	if err != nil {
@@ -89,7 +109,7 @@ func (s *TokenRevocationService) CleanupExpiredTokens(olderThan time.Duration) e
 	// - インデックス最適化 (revoked_at、expires_atの複合インデックス)
 	// - 統計情報収集 (削除件数、実行時間)
 	// - 自動スケジューリング (cron job、定期実行)
-	
+
 	cutoffTime := time.Now().Add(-olderThan)
 
 	if err := s.db.Where("revoked_at < ?", cutoffTime).Delete(&models.RevokedToken{}).Error; err != nil {
@@ -99,6 +119,16 @@ func (s *TokenRevocationService) CleanupExpiredTokens(olderThan time.Duration) e
 	return nil
 }
 
+// PurgeExpiredTokens 有効期限を過ぎた無効化レコードを削除（定期ジョブ用）
+// 対象のトークン自体も期限切れのため、削除しても無効化の判定には影響しない
+func (s *TokenRevocationService) PurgeExpiredTokens() (int64, error) {
+	deleted, err := models.CleanupExpiredTokens(s.db)
+	if err != nil {
+		return 0, errors.NewDatabaseError(err)
+	}
+	return deleted, nil
+}
+
 // GetRevokedTokens ページネーション付きで無効化トークンリストを取得
 func (s *TokenRevocationService) GetRevokedTokens(page, limit int) ([]models.RevokedToken, int64, error) {
 	var tokens []models.RevokedToken
@@ -146,7 +176,17 @@ func (s *TokenRevocationService) GetUserRevokedTokens(userID uuid.UUID, page, li
 }
 
 // ValidateTokenStatus 包括的なトークン状態検証を実行
+// キャッシュが有効な場合はデータベースを参照せずに判定する
 func (s *TokenRevocationService) ValidateTokenStatus(jti string, userID uuid.UUID, issuedAt time.Time) error {
+	if s.cache != nil {
+		if revoked, ok := s.cache.Check(jti, userID, issuedAt); ok {
+			if revoked {
+				return errors.ErrInvalidToken
+			}
+			return nil
+		}
+	}
+
 	// Check if specific token is revoked
 	isRevoked, err := s.IsTokenRevoked(jti)
 	if err != nil {
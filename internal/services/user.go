@@ -2,6 +2,7 @@ package services
 
 import (
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -14,23 +15,36 @@ import (
 
 // UserService ユーザー管理サービス
 type UserService struct {
-	db     *gorm.DB
-	logger *logger.Logger
+	db              *gorm.DB
+	logger          *logger.Logger
+	passwordPolicy  *PasswordPolicyService
+	permissionCache PermissionCache
 }
 
 // NewUserService 新しいユーザーサービスを作成
 func NewUserService(db *gorm.DB, logger *logger.Logger) *UserService {
 	return &UserService{
-		db:     db,
-		logger: logger,
+		db:             db,
+		logger:         logger,
+		passwordPolicy: NewPasswordPolicyService(db, DefaultPasswordPolicy()),
 	}
 }
 
+// SetPasswordPolicyService ユーザー作成・パスワード変更時に適用するパスワードポリシーを設定
+func (s *UserService) SetPasswordPolicyService(passwordPolicy *PasswordPolicyService) {
+	s.passwordPolicy = passwordPolicy
+}
+
+// SetPermissionCache ユーザー削除時に無効化する実効権限のキャッシュを設定
+func (s *UserService) SetPermissionCache(cache PermissionCache) {
+	s.permissionCache = cache
+}
+
 // CreateUserRequest ユーザー作成リクエスト
 type CreateUserRequest struct {
 	Name          string    `json:"name" binding:"required,min=1,max=100"`
 	Email         string    `json:"email" binding:"required,email,max=255"`
-	Password      string    `json:"password" binding:"required,min=6,max=255"`
+	Password      string    `json:"password" binding:"required,max=255"` // 強度はパスワードポリシーで検証
 	DepartmentID  uuid.UUID `json:"department_id" binding:"required"`
 	PrimaryRoleID uuid.UUID `json:"primary_role_id" binding:"required"`
 	Status        string    `json:"status" binding:"omitempty,oneof=active inactive suspended"`
@@ -47,7 +61,7 @@ type UpdateUserRequest struct {
 // ChangePasswordRequest パスワード変更リクエスト
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" binding:"required"`
-	NewPassword     string `json:"new_password" binding:"required,min=6,max=255"`
+	NewPassword     string `json:"new_password" binding:"required,max=255"`
 }
 
 // UserListFilters ユーザー一覧フィルター
@@ -73,6 +87,11 @@ type UserResponse struct {
 	Department    *DeptInfo         `json:"department,omitempty"`
 	PrimaryRole   *RoleInfo         `json:"primary_role,omitempty"`
 	ActiveRoles   []RoleInfo        `json:"active_roles,omitempty"`
+
+	// ログイン状態
+	FailedLoginAttempts int     `json:"failed_login_attempts"`
+	LockedUntil         *string `json:"locked_until,omitempty"`
+	LastLoginAt         *string `json:"last_login_at,omitempty"`
 }
 
 // UserListResponse ユーザー一覧レスポンス
@@ -116,21 +135,30 @@ func (s *UserService) CreateUser(req CreateUserRequest) (*UserResponse, error) {
 		}
 		return nil, errors.NewDatabaseError(err)
 	}
+	// 承認が必要なロールはプライマリロールとして直接付与しない（ユーザーロールの割り当て申請を利用）
+	if role.RequiresApproval {
+		return nil, errors.NewValidationError("primary_role_id", "Role requires approval and must be assigned through a role assignment request")
+	}
 
-	// パスワードハッシュ化
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	// パスワードポリシー検証・ハッシュ化
+	if err := s.passwordPolicy.ValidateNewPassword("password", req.Password, req.Email, nil); err != nil {
+		return nil, err
+	}
+	hashedPassword, err := s.passwordPolicy.HashPassword(req.Password)
 	if err != nil {
-		return nil, errors.NewInternalError("Failed to hash password")
+		return nil, err
 	}
 
 	// ユーザー作成
+	now := time.Now()
 	user := models.User{
-		Name:          req.Name,
-		Email:         req.Email,
-		PasswordHash:  string(hashedPassword),
-		DepartmentID:  req.DepartmentID,
-		PrimaryRoleID: &req.PrimaryRoleID,
-		Status:        models.UserStatus(req.Status),
+		Name:              req.Name,
+		Email:             req.Email,
+		PasswordHash:      hashedPassword,
+		PasswordChangedAt: &now,
+		DepartmentID:      req.DepartmentID,
+		PrimaryRoleID:     &req.PrimaryRoleID,
+		Status:            models.UserStatus(req.Status),
 	}
 
 	// デフォルトステータス設定
@@ -259,6 +287,8 @@ func (s *UserService) DeleteUser(userID uuid.UUID) error {
 		return errors.NewDatabaseError(err)
 	}
 
+	invalidateUserPermissions(s.permissionCache, userID)
+
 	s.logger.Info("User deleted successfully", map[string]interface{}{
 		"user_id": userID,
 	})
@@ -362,6 +392,67 @@ func (s *UserService) ChangeUserStatus(userID uuid.UUID, status models.UserStatu
 	return s.GetUser(userID)
 }
 
+// UnlockUser ログイン失敗によるアカウントロックを解除
+func (s *UserService) UnlockUser(userID uuid.UUID) (*UserResponse, error) {
+	s.logger.Info("Unlocking user", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("User", "User not found")
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	// 失敗回数とロック期限をリセット（ユーザーモデルのフックを経由せずに更新）
+	if err := s.db.Model(&models.User{}).Where("id = ?", userID).UpdateColumns(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	}).Error; err != nil {
+		s.logger.Error("Failed to unlock user", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("User unlocked successfully", map[string]interface{}{
+		"user_id":         userID,
+		"failed_attempts": user.FailedLoginAttempts,
+	})
+
+	return s.GetUser(userID)
+}
+
+// ResetMFA ユーザーのMFA登録を解除（認証アプリ紛失時など。次回ログイン時に再登録）
+func (s *UserService) ResetMFA(userID uuid.UUID) error {
+	s.logger.Info("Resetting user MFA", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewNotFoundError("User", "User not found")
+		}
+		return errors.NewDatabaseError(err)
+	}
+
+	if err := models.DeleteUserMFA(s.db, userID); err != nil {
+		s.logger.Error("Failed to reset user MFA", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("User MFA reset successfully", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	return nil
+}
+
 // ChangePassword ユーザーのパスワードを変更
 func (s *UserService) ChangePassword(userID uuid.UUID, req ChangePasswordRequest) error {
 	s.logger.Info("Changing user password", map[string]interface{}{
@@ -381,14 +472,21 @@ func (s *UserService) ChangePassword(userID uuid.UUID, req ChangePasswordRequest
 		return errors.NewValidationError("current_password", "Current password is incorrect")
 	}
 
+	// パスワードポリシー・履歴の検証
+	if err := s.passwordPolicy.ValidateNewPassword("new_password", req.NewPassword, user.Email, &user); err != nil {
+		return err
+	}
+
 	// 新しいパスワードハッシュ化
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwordPolicy.HashPassword(req.NewPassword)
 	if err != nil {
-		return errors.NewInternalError("Failed to hash password")
+		return err
 	}
 
-	// パスワード更新
-	if err := s.db.Model(&user).Update("password_hash", string(hashedPassword)).Error; err != nil {
+	// パスワード更新（旧パスワードは履歴に追加）
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.passwordPolicy.SetPassword(tx, &user, hashedPassword)
+	}); err != nil {
 		s.logger.Error("Failed to change password", err, map[string]interface{}{
 			"user_id": userID,
 		})
@@ -402,6 +500,22 @@ func (s *UserService) ChangePassword(userID uuid.UUID, req ChangePasswordRequest
 	return nil
 }
 
+// GetUserScopeAttributes スコープ照合用のユーザー属性を取得（スコープ付き権限チェックのリソースローダー）
+func (s *UserService) GetUserScopeAttributes(userID uuid.UUID) (map[string]interface{}, error) {
+	var user models.User
+	if err := s.db.Select("id", "department_id").First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("User", "User not found")
+		}
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return map[string]interface{}{
+		"user_id":       user.ID.String(),
+		"department_id": user.DepartmentID.String(),
+	}, nil
+}
+
 // convertToUserResponse models.UserをUserResponseに変換
 func (s *UserService) convertToUserResponse(user *models.User) *UserResponse {
 	response := &UserResponse{
@@ -413,6 +527,18 @@ func (s *UserService) convertToUserResponse(user *models.User) *UserResponse {
 		PrimaryRoleID: user.PrimaryRoleID,
 		CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:     user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+
+		FailedLoginAttempts: user.FailedLoginAttempts,
+	}
+
+	// ログイン状態追加
+	if user.LockedUntil != nil {
+		lockedUntil := user.LockedUntil.Format("2006-01-02T15:04:05Z07:00")
+		response.LockedUntil = &lockedUntil
+	}
+	if user.LastLoginAt != nil {
+		lastLoginAt := user.LastLoginAt.Format("2006-01-02T15:04:05Z07:00")
+		response.LastLoginAt = &lastLoginAt
 	}
 
 	// Department情報追加
@@ -447,3 +573,15 @@ func (s *UserService) convertToUserResponse(user *models.User) *UserResponse {
 
 	return response
 }
+
+// ensureUserExists ユーザーの存在確認（存在しない場合はNOT_FOUNDエラー）
+func ensureUserExists(db *gorm.DB, userID uuid.UUID) error {
+	var count int64
+	if err := db.Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	if count == 0 {
+		return errors.NewNotFoundError("User", "User not found")
+	}
+	return nil
+}
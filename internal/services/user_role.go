@@ -1,6 +1,8 @@
 package services
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,9 +12,14 @@ import (
 	"erp-access-control-go/pkg/errors"
 )
 
+// UserRoleApprovalResourceType ロール付与申請の承認フローのリソース種別
+const UserRoleApprovalResourceType = "user_role"
+
 // UserRoleService 複数ロール管理サービス
 type UserRoleService struct {
-	db *gorm.DB
+	db              *gorm.DB
+	approvalService *ApprovalService
+	permissionCache PermissionCache
 }
 
 // NewUserRoleService 新しいユーザーロールサービスを作成
@@ -22,7 +29,38 @@ func NewUserRoleService(db *gorm.DB) *UserRoleService {
 	}
 }
 
+// SetApprovalService 承認が必要なロールの付与に使用する承認サービスを設定
+func (s *UserRoleService) SetApprovalService(approvalService *ApprovalService) {
+	s.approvalService = approvalService
+	approvalService.RegisterFinalizer(UserRoleApprovalResourceType, s.finalizeRoleAssignment)
+}
+
+// SetPermissionCache ロールの付与・取り消し時に無効化する実効権限のキャッシュを設定
+func (s *UserRoleService) SetPermissionCache(cache PermissionCache) {
+	s.permissionCache = cache
+}
+
+// RoleAssignmentResult ロール割り当て結果
+// 承認が必要なロールの場合はUserRoleの代わりに承認申請を返す
+type RoleAssignmentResult struct {
+	UserRole        *models.UserRole
+	ApprovalRequest *ApprovalRequestResponse
+}
+
+// roleAssignmentPayload ロール付与申請の申請内容
+// Extensionがtrueの場合は付与済みのロールの有効期限延長の申請
+type roleAssignmentPayload struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	RoleID    uuid.UUID  `json:"role_id"`
+	ValidFrom time.Time  `json:"valid_from"`
+	ValidTo   *time.Time `json:"valid_to,omitempty"`
+	Priority  int        `json:"priority"`
+	Reason    string     `json:"reason,omitempty"`
+	Extension bool       `json:"extension,omitempty"`
+}
+
 // AssignRole ユーザーにロールを割り当て
+// 承認が必要なロールは承認申請を作成し、最終承認時にUserRoleを作成する
 func (s *UserRoleService) AssignRole(
 	userID, roleID uuid.UUID,
 	validFrom time.Time,
@@ -30,7 +68,7 @@ func (s *UserRoleService) AssignRole(
 	priority int,
 	assignedBy uuid.UUID,
 	reason string,
-) (*models.UserRole, error) {
+) (*RoleAssignmentResult, error) {
 	// ユーザー存在確認
 	var user models.User
 	if err := s.db.First(&user, userID).Error; err != nil {
@@ -49,40 +87,215 @@ func (s *UserRoleService) AssignRole(
 		return nil, errors.NewDatabaseError(err)
 	}
 
-	// 重複チェック（アクティブなロール）
-	var count int64
-	err := s.db.Model(&models.UserRole{}).
-		Where("user_id = ? AND role_id = ? AND is_active = ?", userID, roleID, true).
-		Count(&count).Error
+	if role.RequiresApproval {
+		request, err := s.requestRoleAssignment(&user, &role, roleAssignmentPayload{
+			UserID:    userID,
+			RoleID:    roleID,
+			ValidFrom: validFrom,
+			ValidTo:   validTo,
+			Priority:  priority,
+			Reason:    reason,
+		}, assignedBy)
+		if err != nil {
+			return nil, err
+		}
+		return &RoleAssignmentResult{ApprovalRequest: request}, nil
+	}
+
+	userRole, err := s.grantRole(s.db, roleAssignmentPayload{
+		UserID:    userID,
+		RoleID:    roleID,
+		ValidFrom: validFrom,
+		ValidTo:   validTo,
+		Priority:  priority,
+		Reason:    reason,
+	}, assignedBy)
 	if err != nil {
+		return nil, err
+	}
+
+	// ロール情報をPreload
+	if err := s.db.Preload("Role").First(userRole, userRole.ID).Error; err != nil {
 		return nil, errors.NewDatabaseError(err)
 	}
-	if count > 0 {
-		return nil, errors.NewValidationError("role", "User already has this role assigned")
+
+	return &RoleAssignmentResult{UserRole: userRole}, nil
+}
+
+// grantRole UserRoleを作成
+func (s *UserRoleService) grantRole(db *gorm.DB, assignment roleAssignmentPayload, assignedBy uuid.UUID) (*models.UserRole, error) {
+	if err := ensureRoleNotAssigned(db, assignment.UserID, assignment.RoleID); err != nil {
+		return nil, err
 	}
 
 	// UserRoleを作成
 	userRole := &models.UserRole{
-		UserID:         userID,
-		RoleID:         roleID,
-		ValidFrom:      validFrom,
-		ValidTo:        validTo,
-		Priority:       priority,
+		UserID:         assignment.UserID,
+		RoleID:         assignment.RoleID,
+		ValidFrom:      assignment.ValidFrom,
+		ValidTo:        assignment.ValidTo,
+		Priority:       assignment.Priority,
 		IsActive:       true,
 		AssignedBy:     &assignedBy,
-		AssignedReason: reason,
+		AssignedReason: assignment.Reason,
 	}
 
-	if err := s.db.Create(userRole).Error; err != nil {
+	if err := db.Omit("User", "Role", "AssignedByUser").Create(userRole).Error; err != nil {
 		return nil, errors.NewDatabaseError(err)
 	}
+	invalidateUserPermissions(s.permissionCache, assignment.UserID)
 
-	// ロール情報をPreload
-	if err := s.db.Preload("Role").First(userRole, userRole.ID).Error; err != nil {
+	return userRole, nil
+}
+
+// ensureRoleNotAssigned 同じロールがアクティブな状態で割り当て済みでないか確認
+func ensureRoleNotAssigned(db *gorm.DB, userID, roleID uuid.UUID) error {
+	var count int64
+	err := db.Model(&models.UserRole{}).
+		Where("user_id = ? AND role_id = ? AND is_active = ?", userID, roleID, true).
+		Count(&count).Error
+	if err != nil {
+		return errors.NewDatabaseError(err)
+	}
+	if count > 0 {
+		return errors.NewValidationError("role", "User already has this role assigned")
+	}
+	return nil
+}
+
+// requestRoleAssignment ロール付与（有効期限延長を含む）の承認申請を作成
+// 承認フロー照合用のスコープ条件にはロールID・ロール名・対象ユーザーの部署IDを設定
+func (s *UserRoleService) requestRoleAssignment(user *models.User, role *models.Role, assignment roleAssignmentPayload, requestedBy uuid.UUID) (*ApprovalRequestResponse, error) {
+	if s.approvalService == nil {
+		return nil, errors.NewInternalError("approval service is not configured")
+	}
+
+	// 同じユーザー・ロールの申請が進行中でないか確認
+	var inProgress []models.ApprovalRequest
+	err := s.db.Where("resource_type = ? AND resource_id = ? AND status IN ?",
+		UserRoleApprovalResourceType, user.ID.String(),
+		[]models.ApprovalRequestStatus{models.ApprovalStatusPending, models.ApprovalStatusReturned}).
+		Find(&inProgress).Error
+	if err != nil {
 		return nil, errors.NewDatabaseError(err)
 	}
+	for _, request := range inProgress {
+		if request.Scope["role_id"] == role.ID.String() {
+			return nil, errors.NewValidationError("role", "A role assignment request for this role is already in progress")
+		}
+	}
 
-	return userRole, nil
+	title := fmt.Sprintf("ロール付与: %s → %s", role.Name, user.Name)
+	if assignment.Extension {
+		title = fmt.Sprintf("ロール期限延長: %s → %s", role.Name, user.Name)
+	} else if err := ensureRoleNotAssigned(s.db, user.ID, role.ID); err != nil {
+		return nil, err
+	}
+
+	payload, err := toJSONMap(assignment)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	resourceID := user.ID.String()
+
+	return s.approvalService.submit(requestedBy, SubmitApprovalRequest{
+		ResourceType: UserRoleApprovalResourceType,
+		ResourceID:   &resourceID,
+		Title:        title,
+		Payload:      payload,
+		Scope: map[string]interface{}{
+			"role_id":       role.ID.String(),
+			"role_name":     role.Name,
+			"department_id": user.DepartmentID.String(),
+		},
+		Comment: assignment.Reason,
+	})
+}
+
+// finalizeRoleAssignment 最終承認されたロール付与申請からUserRoleを作成（期限延長の申請は付与済みのUserRoleを更新）
+func (s *UserRoleService) finalizeRoleAssignment(tx *gorm.DB, request *models.ApprovalRequest) error {
+	var assignment roleAssignmentPayload
+	if err := fromJSONMap(request.Payload, &assignment); err != nil {
+		return errors.NewInternalError(fmt.Sprintf("invalid role assignment payload: %v", err))
+	}
+
+	if assignment.Extension {
+		return s.extendGrantedRole(tx, assignment, request.RequestedBy)
+	}
+	_, err := s.grantRole(tx, assignment, request.RequestedBy)
+	return err
+}
+
+// extendGrantedRole 承認された期限延長を付与済みのUserRoleに反映
+func (s *UserRoleService) extendGrantedRole(db *gorm.DB, assignment roleAssignmentPayload, extendedBy uuid.UUID) error {
+	result := db.Model(&models.UserRole{}).
+		Where("user_id = ? AND role_id = ? AND is_active = ?", assignment.UserID, assignment.RoleID, true).
+		Updates(map[string]interface{}{
+			"valid_to":        assignment.ValidTo,
+			"priority":        assignment.Priority,
+			"assigned_by":     extendedBy,
+			"assigned_reason": assignment.Reason,
+		})
+	if result.Error != nil {
+		return errors.NewDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundError("UserRole", "Active user role not found")
+	}
+	invalidateUserPermissions(s.permissionCache, assignment.UserID)
+	return nil
+}
+
+// requestRoleExtension 承認が必要なロールの有効期限を延長する場合は承認申請を作成
+// 延長にあたらない場合・承認が不要なロールの場合はnilを返す
+func (s *UserRoleService) requestRoleExtension(userRole *models.UserRole, validTo *time.Time, priority int, requestedBy uuid.UUID, reason string) (*ApprovalRequestResponse, error) {
+	if !userRole.Role.RequiresApproval || !isValidityExtension(userRole.ValidTo, validTo) {
+		return nil, nil
+	}
+
+	var user models.User
+	if err := s.db.First(&user, userRole.UserID).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	return s.requestRoleAssignment(&user, &userRole.Role, roleAssignmentPayload{
+		UserID:    userRole.UserID,
+		RoleID:    userRole.RoleID,
+		ValidFrom: userRole.ValidFrom,
+		ValidTo:   validTo,
+		Priority:  priority,
+		Reason:    reason,
+		Extension: true,
+	}, requestedBy)
+}
+
+// isValidityExtension 有効期限の変更が延長（期限の延長・無期限化）にあたるか
+func isValidityExtension(current, requested *time.Time) bool {
+	if current == nil {
+		return false
+	}
+	return requested == nil || requested.After(*current)
+}
+
+// toJSONMap 構造体をJSONオブジェクト（map）に変換
+func toJSONMap(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fromJSONMap JSONオブジェクト（map）を構造体に変換
+func fromJSONMap(value map[string]interface{}, target interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
 }
 
 // RevokeRole ユーザーのロールを取り消し
@@ -114,18 +327,20 @@ func (s *UserRoleService) RevokeRole(
 	if err := s.db.Save(&userRole).Error; err != nil {
 		return nil, errors.NewDatabaseError(err)
 	}
+	invalidateUserPermissions(s.permissionCache, userID)
 
 	return &userRole, nil
 }
 
 // UpdateRole ユーザーロールを更新
+// 承認が必要なロールの有効期限を延長する場合は承認申請を作成し、最終承認時に更新する
 func (s *UserRoleService) UpdateRole(
 	userID, roleID uuid.UUID,
 	priority *int,
 	validTo *time.Time,
 	updatedBy uuid.UUID,
 	reason string,
-) (*models.UserRole, error) {
+) (*RoleAssignmentResult, error) {
 	var userRole models.UserRole
 
 	// アクティブなUserRoleを検索
@@ -139,6 +354,20 @@ func (s *UserRoleService) UpdateRole(
 		return nil, errors.NewDatabaseError(err)
 	}
 
+	if validTo != nil {
+		newPriority := userRole.Priority
+		if priority != nil {
+			newPriority = *priority
+		}
+		request, err := s.requestRoleExtension(&userRole, validTo, newPriority, updatedBy, reason)
+		if err != nil {
+			return nil, err
+		}
+		if request != nil {
+			return &RoleAssignmentResult{ApprovalRequest: request}, nil
+		}
+	}
+
 	// 更新処理
 	updates := make(map[string]interface{})
 	if priority != nil {
@@ -156,6 +385,9 @@ func (s *UserRoleService) UpdateRole(
 		if err := s.db.Model(&userRole).Updates(updates).Error; err != nil {
 			return nil, errors.NewDatabaseError(err)
 		}
+		if validTo != nil {
+			invalidateUserPermissions(s.permissionCache, userID)
+		}
 	}
 
 	// 更新後のデータを再取得
@@ -163,7 +395,7 @@ func (s *UserRoleService) UpdateRole(
 		return nil, errors.NewDatabaseError(err)
 	}
 
-	return &userRole, nil
+	return &RoleAssignmentResult{UserRole: &userRole}, nil
 }
 
 // GetUserRoles ユーザーのロール一覧を取得（全て）
@@ -214,13 +446,14 @@ func (s *UserRoleService) GetUserRole(userID, roleID uuid.UUID) (*models.UserRol
 	return &userRole, nil
 }
 
-// ExtendRole ロール期限を延長
+// ExtendRole ロール期限を延長（newValidToがnilの場合は無期限）
+// 承認が必要なロールは承認申請を作成し、最終承認時に延長する
 func (s *UserRoleService) ExtendRole(
 	userID, roleID uuid.UUID,
 	newValidTo *time.Time,
 	extendedBy uuid.UUID,
 	reason string,
-) (*models.UserRole, error) {
+) (*RoleAssignmentResult, error) {
 	var userRole models.UserRole
 
 	// アクティブなUserRoleを検索
@@ -234,6 +467,14 @@ func (s *UserRoleService) ExtendRole(
 		return nil, errors.NewDatabaseError(err)
 	}
 
+	request, err := s.requestRoleExtension(&userRole, newValidTo, userRole.Priority, extendedBy, reason)
+	if err != nil {
+		return nil, err
+	}
+	if request != nil {
+		return &RoleAssignmentResult{ApprovalRequest: request}, nil
+	}
+
 	// 期限延長処理
 	userRole.ValidTo = newValidTo
 	userRole.AssignedBy = &extendedBy
@@ -242,8 +483,9 @@ func (s *UserRoleService) ExtendRole(
 	if err := s.db.Save(&userRole).Error; err != nil {
 		return nil, errors.NewDatabaseError(err)
 	}
+	invalidateUserPermissions(s.permissionCache, userID)
 
-	return &userRole, nil
+	return &RoleAssignmentResult{UserRole: &userRole}, nil
 }
 
 // UpdatePriority ロール優先度を更新
@@ -278,23 +520,24 @@ func (s *UserRoleService) UpdatePriority(
 	return &userRole, nil
 }
 
-// CleanupExpiredRoles 期限切れロールの自動無効化
-func (s *UserRoleService) CleanupExpiredRoles() error {
+// CleanupExpiredRoles 期限切れロールの自動無効化（無効化した件数を返す）
+// 実効権限のキャッシュはvalid_toで失効するため、ここでは無効化しない
+func (s *UserRoleService) CleanupExpiredRoles() (int64, error) {
 	now := time.Now()
 
-	err := s.db.Model(&models.UserRole{}).
+	result := s.db.Model(&models.UserRole{}).
 		Where("is_active = ? AND valid_to IS NOT NULL AND valid_to < ?", true, now).
 		Updates(map[string]interface{}{
 			"is_active":       false,
 			"assigned_reason": "auto_expired",
 			"updated_at":      now,
-		}).Error
+		})
 
-	if err != nil {
-		return errors.NewDatabaseError(err)
+	if result.Error != nil {
+		return 0, errors.NewDatabaseError(result.Error)
 	}
 
-	return nil
+	return result.RowsAffected, nil
 }
 
 // GetUserRoleStats ユーザーロール統計情報を取得
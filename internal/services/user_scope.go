@@ -0,0 +1,395 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// UserScopeService ユーザースコープ管理サービス
+type UserScopeService struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewUserScopeService 新しいユーザースコープサービスを作成
+func NewUserScopeService(db *gorm.DB, logger *logger.Logger) *UserScopeService {
+	return &UserScopeService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// UserScopeItem スコープ1件分の指定
+type UserScopeItem struct {
+	ResourceID *string                `json:"resource_id"`
+	ScopeType  models.ScopeType       `json:"scope_type" binding:"required"`
+	ScopeValue map[string]interface{} `json:"scope_value" binding:"required"`
+}
+
+// AddUserScopeRequest スコープ追加リクエスト
+type AddUserScopeRequest struct {
+	ResourceType string `json:"resource_type" binding:"required"`
+	UserScopeItem
+}
+
+// ReplaceUserScopesRequest リソース種別単位のスコープ置換リクエスト
+// scopesが空の場合は該当リソース種別のスコープをすべて削除
+type ReplaceUserScopesRequest struct {
+	ResourceType string          `json:"resource_type" binding:"required"`
+	Scopes       []UserScopeItem `json:"scopes" binding:"dive"`
+}
+
+// ScopeUsersRequest スコープ到達ユーザー検索リクエスト
+type ScopeUsersRequest struct {
+	ResourceType string                 `json:"resource_type"`
+	ScopeType    models.ScopeType       `json:"scope_type,omitempty"`
+	Scope        map[string]interface{} `json:"scope"`
+}
+
+// UserScopeResponse ユーザースコープレスポンス
+type UserScopeResponse struct {
+	ID           int                    `json:"id"`
+	UserID       uuid.UUID              `json:"user_id"`
+	ResourceType string                 `json:"resource_type"`
+	ResourceID   *string                `json:"resource_id,omitempty"`
+	ScopeType    models.ScopeType       `json:"scope_type"`
+	ScopeValue   map[string]interface{} `json:"scope_value"`
+	CreatedAt    string                 `json:"created_at"`
+}
+
+// UserScopeListResponse ユーザースコープ一覧レスポンス
+type UserScopeListResponse struct {
+	Scopes []UserScopeResponse `json:"scopes"`
+	Total  int                 `json:"total"`
+}
+
+// ScopeUserMatch スコープに到達可能なユーザー
+type ScopeUserMatch struct {
+	ID              uuid.UUID         `json:"id"`
+	Name            string            `json:"name"`
+	Email           string            `json:"email"`
+	Status          models.UserStatus `json:"status"`
+	MatchedScopeIDs []int             `json:"matched_scope_ids"`
+}
+
+// ScopeUsersResponse スコープ到達ユーザー検索レスポンス
+type ScopeUsersResponse struct {
+	ResourceType string                 `json:"resource_type"`
+	ScopeType    models.ScopeType       `json:"scope_type,omitempty"`
+	Scope        map[string]interface{} `json:"scope"`
+	Users        []ScopeUserMatch       `json:"users"`
+	Total        int                    `json:"total"`
+}
+
+// GetUserScopes ユーザーのスコープ一覧を取得（resourceTypeが空の場合は全件）
+func (s *UserScopeService) GetUserScopes(userID uuid.UUID, resourceType string) (*UserScopeListResponse, error) {
+	if err := ensureUserExists(s.db, userID); err != nil {
+		return nil, err
+	}
+
+	query := s.db.Where("user_id = ?", userID)
+	if resourceType != "" {
+		query = query.Where("resource_type = ?", resourceType)
+	}
+
+	var scopes []models.UserScope
+	if err := query.Order("resource_type ASC, id ASC").Find(&scopes).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return convertToUserScopeListResponse(scopes), nil
+}
+
+// AddUserScope ユーザーにスコープを追加
+func (s *UserScopeService) AddUserScope(userID uuid.UUID, req AddUserScopeRequest) (*UserScopeResponse, error) {
+	s.logger.Info("Adding user scope", map[string]interface{}{
+		"user_id":       userID,
+		"resource_type": req.ResourceType,
+		"scope_type":    req.ScopeType,
+	})
+
+	if err := ensureUserExists(s.db, userID); err != nil {
+		return nil, err
+	}
+
+	scope, err := buildUserScope(userID, req.ResourceType, req.UserScopeItem)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一スコープの重複チェック
+	existing, err := models.FindUserScopesByUserAndResource(s.db, userID, req.ResourceType)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+	for i := range existing {
+		if isSameUserScope(&existing[i], scope) {
+			return nil, errors.NewValidationError("scope_value", "Scope already exists")
+		}
+	}
+
+	if err := s.db.Omit("User").Create(scope).Error; err != nil {
+		s.logger.Error("Failed to add user scope", err, map[string]interface{}{
+			"user_id":       userID,
+			"resource_type": req.ResourceType,
+		})
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("User scope added successfully", map[string]interface{}{
+		"scope_id":      scope.ID,
+		"user_id":       userID,
+		"resource_type": scope.ResourceType,
+	})
+
+	return convertToUserScopeResponse(scope), nil
+}
+
+// ReplaceUserScopes リソース種別のスコープを指定内容で置き換え
+func (s *UserScopeService) ReplaceUserScopes(userID uuid.UUID, req ReplaceUserScopesRequest) (*UserScopeListResponse, error) {
+	s.logger.Info("Replacing user scopes", map[string]interface{}{
+		"user_id":       userID,
+		"resource_type": req.ResourceType,
+		"scope_count":   len(req.Scopes),
+	})
+
+	if err := ensureUserExists(s.db, userID); err != nil {
+		return nil, err
+	}
+
+	scopes := make([]models.UserScope, 0, len(req.Scopes))
+	for i, item := range req.Scopes {
+		scope, err := buildUserScope(userID, req.ResourceType, item)
+		if err != nil {
+			if apiErr, ok := err.(*errors.APIError); ok {
+				apiErr.Details.Field = fmt.Sprintf("scopes[%d].%s", i, apiErr.Details.Field)
+			}
+			return nil, err
+		}
+		for j := range scopes {
+			if isSameUserScope(&scopes[j], scope) {
+				return nil, errors.NewValidationError(fmt.Sprintf("scopes[%d]", i), "Duplicate scope")
+			}
+		}
+		scopes = append(scopes, *scope)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := models.DeleteUserScopesByUserAndResource(tx, userID, req.ResourceType); err != nil {
+			return err
+		}
+		for i := range scopes {
+			if err := tx.Omit("User").Create(&scopes[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to replace user scopes", err, map[string]interface{}{
+			"user_id":       userID,
+			"resource_type": req.ResourceType,
+		})
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	s.logger.Info("User scopes replaced successfully", map[string]interface{}{
+		"user_id":       userID,
+		"resource_type": req.ResourceType,
+		"scope_count":   len(scopes),
+	})
+
+	return convertToUserScopeListResponse(scopes), nil
+}
+
+// DeleteUserScope ユーザーのスコープを1件削除
+func (s *UserScopeService) DeleteUserScope(userID uuid.UUID, scopeID int) error {
+	result := s.db.Where("id = ? AND user_id = ?", scopeID, userID).Delete(&models.UserScope{})
+	if result.Error != nil {
+		return errors.NewDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundError("UserScope", "User scope not found")
+	}
+
+	s.logger.Info("User scope deleted successfully", map[string]interface{}{
+		"scope_id": scopeID,
+		"user_id":  userID,
+	})
+
+	return nil
+}
+
+// DeleteUserScopesByResource ユーザーの特定リソース種別のスコープをすべて削除
+func (s *UserScopeService) DeleteUserScopesByResource(userID uuid.UUID, resourceType string) (int64, error) {
+	if err := ensureUserExists(s.db, userID); err != nil {
+		return 0, err
+	}
+
+	result := s.db.Where("user_id = ? AND resource_type = ?", userID, resourceType).Delete(&models.UserScope{})
+	if result.Error != nil {
+		return 0, errors.NewDatabaseError(result.Error)
+	}
+
+	s.logger.Info("User scopes deleted by resource type", map[string]interface{}{
+		"user_id":       userID,
+		"resource_type": resourceType,
+		"deleted":       result.RowsAffected,
+	})
+
+	return result.RowsAffected, nil
+}
+
+// FindUsersByScope 指定スコープ（例: {"region": "kanto"}）に到達可能なユーザーを検索
+// 判定はCheckPermissionWithScopeと同じ評価ロジック（配列・ワイルドカード対応）を使用する
+func (s *UserScopeService) FindUsersByScope(req ScopeUsersRequest) (*ScopeUsersResponse, error) {
+	if req.ResourceType == "" {
+		return nil, errors.NewValidationError("resource_type", "resource_type is required")
+	}
+	if len(req.Scope) == 0 {
+		return nil, errors.NewValidationError("scope", "scope must be a non-empty JSON object")
+	}
+	if req.ScopeType != "" && !models.ValidateScopeType(req.ScopeType) {
+		return nil, errors.NewValidationError("scope_type", "Invalid scope type")
+	}
+
+	query := s.db.Where("resource_type = ?", req.ResourceType)
+	if req.ScopeType != "" {
+		query = query.Where("scope_type = ?", req.ScopeType)
+	}
+
+	var scopes []models.UserScope
+	if err := query.Order("id ASC").Find(&scopes).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	matched := make(map[uuid.UUID][]int)
+	var userIDs []uuid.UUID
+	for i := range scopes {
+		scopeJSON, err := json.Marshal(scopes[i].ScopeValue)
+		if err != nil {
+			continue
+		}
+		if !evaluateScope(scopeJSON, req.Scope) {
+			continue
+		}
+		if _, exists := matched[scopes[i].UserID]; !exists {
+			userIDs = append(userIDs, scopes[i].UserID)
+		}
+		matched[scopes[i].UserID] = append(matched[scopes[i].UserID], scopes[i].ID)
+	}
+
+	response := &ScopeUsersResponse{
+		ResourceType: req.ResourceType,
+		ScopeType:    req.ScopeType,
+		Scope:        req.Scope,
+		Users:        []ScopeUserMatch{},
+	}
+	if len(userIDs) == 0 {
+		return response, nil
+	}
+
+	var users []models.User
+	if err := s.db.Where("id IN ?", userIDs).Order("name ASC").Find(&users).Error; err != nil {
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	for _, user := range users {
+		response.Users = append(response.Users, ScopeUserMatch{
+			ID:              user.ID,
+			Name:            user.Name,
+			Email:           user.Email,
+			Status:          user.Status,
+			MatchedScopeIDs: matched[user.ID],
+		})
+	}
+	response.Total = len(response.Users)
+
+	return response, nil
+}
+
+// =============================================================================
+// 内部ヘルパー
+// =============================================================================
+
+// buildUserScope リクエストからスコープを組み立てて検証
+func buildUserScope(userID uuid.UUID, resourceType string, item UserScopeItem) (*models.UserScope, error) {
+	scope := &models.UserScope{
+		UserID:       userID,
+		ResourceType: resourceType,
+		ResourceID:   item.ResourceID,
+		ScopeType:    item.ScopeType,
+		ScopeValue:   models.JSONB(item.ScopeValue),
+	}
+
+	if !scope.IsValidResourceType() {
+		return nil, errors.NewValidationError("resource_type", "Invalid resource type")
+	}
+	if !models.ValidateScopeType(scope.ScopeType) {
+		return nil, errors.NewValidationError("scope_type", "Scope type must be one of department, region, project, location")
+	}
+	if violations := ValidateScopeValue(scope.ScopeType, item.ScopeValue); len(violations) > 0 {
+		reasons := make([]string, len(violations))
+		for i, violation := range violations {
+			reasons[i] = violation.Path + " " + violation.Message
+		}
+		return nil, errors.NewValidationError("scope_value", strings.Join(reasons, "; "))
+	}
+
+	return scope, nil
+}
+
+// isSameUserScope 同一内容のスコープかチェック
+func isSameUserScope(a, b *models.UserScope) bool {
+	if a.ResourceType != b.ResourceType || a.ScopeType != b.ScopeType {
+		return false
+	}
+	if (a.ResourceID == nil) != (b.ResourceID == nil) || (a.ResourceID != nil && *a.ResourceID != *b.ResourceID) {
+		return false
+	}
+	// DB由来とリクエスト由来で数値型等が異なるためJSON経由で比較
+	left, errLeft := json.Marshal(a.ScopeValue)
+	right, errRight := json.Marshal(b.ScopeValue)
+	if errLeft != nil || errRight != nil {
+		return false
+	}
+	var leftValue, rightValue interface{}
+	if json.Unmarshal(left, &leftValue) != nil || json.Unmarshal(right, &rightValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(leftValue, rightValue)
+}
+
+// convertToUserScopeResponse スコープモデルをレスポンスに変換
+func convertToUserScopeResponse(scope *models.UserScope) *UserScopeResponse {
+	return &UserScopeResponse{
+		ID:           scope.ID,
+		UserID:       scope.UserID,
+		ResourceType: scope.ResourceType,
+		ResourceID:   scope.ResourceID,
+		ScopeType:    scope.ScopeType,
+		ScopeValue:   scope.ScopeValue,
+		CreatedAt:    scope.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// convertToUserScopeListResponse スコープ一覧をレスポンスに変換
+func convertToUserScopeListResponse(scopes []models.UserScope) *UserScopeListResponse {
+	responses := make([]UserScopeResponse, len(scopes))
+	for i := range scopes {
+		responses[i] = *convertToUserScopeResponse(&scopes[i])
+	}
+	return &UserScopeListResponse{
+		Scopes: responses,
+		Total:  len(responses),
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"erp-access-control-go/models"
+	"erp-access-control-go/pkg/errors"
+	"erp-access-control-go/pkg/logger"
+)
+
+// setupTestUserScope ユーザースコープテスト用のサービスとDBを作成
+func setupTestUserScope(t *testing.T) (*UserScopeService, *gorm.DB) {
+	db := setupTestDB(t)
+
+	err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS user_scopes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			resource_type TEXT NOT NULL,
+			resource_id TEXT,
+			scope_type TEXT NOT NULL,
+			scope_value TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`).Error
+	require.NoError(t, err)
+	db.Exec("DELETE FROM user_scopes")
+
+	return NewUserScopeService(db, logger.NewLogger()), db
+}
+
+// createUserForScopeTest テスト用ユーザー作成ヘルパー
+func createUserForScopeTest(t *testing.T, db *gorm.DB, name string) uuid.UUID {
+	userID := uuid.New()
+	err := db.Exec("INSERT INTO users (id, name, email, status) VALUES (?, ?, ?, 'active')",
+		userID.String(), name, userID.String()+"@example.com").Error
+	require.NoError(t, err)
+	return userID
+}
+
+func TestValidateScopeValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		scopeType models.ScopeType
+		value     map[string]interface{}
+		wantPaths []string
+	}{
+		{"地域（単一）", models.ScopeTypeRegion, map[string]interface{}{"region": "kanto"}, nil},
+		{"地域（配列）", models.ScopeTypeRegion, map[string]interface{}{"region": []interface{}{"kanto", "kansai"}}, nil},
+		{"地域（ワイルドカード）", models.ScopeTypeRegion, map[string]interface{}{"region": "*"}, nil},
+		{"地域の形式不正", models.ScopeTypeRegion, map[string]interface{}{"region": "Kanto!"}, []string{"scope_value.region"}},
+		{"必須キー欠落と未定義キー", models.ScopeTypeRegion, map[string]interface{}{"area": "kanto"}, []string{"scope_value.region", "scope_value.area"}},
+		{"部署IDはUUID", models.ScopeTypeDepartment, map[string]interface{}{"department_id": "sales"}, []string{"scope_value.department_id"}},
+		{"部署ID配列の要素検証", models.ScopeTypeDepartment, map[string]interface{}{"department_id": []interface{}{uuid.New().String(), 1.0}}, []string{"scope_value.department_id[1]"}},
+		{"空配列", models.ScopeTypeProject, map[string]interface{}{"project_id": []interface{}{}}, []string{"scope_value.project_id"}},
+		{"型不一致", models.ScopeTypeLocation, map[string]interface{}{"location_id": 10.0}, []string{"scope_value.location_id"}},
+		{"未対応のスコープタイプ", models.ScopeType("team"), map[string]interface{}{"team": "a"}, []string{"scope_type"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := ValidateScopeValue(tt.scopeType, tt.value)
+			paths := make([]string, len(violations))
+			for i, violation := range violations {
+				paths[i] = violation.Path
+			}
+			assert.ElementsMatch(t, tt.wantPaths, paths)
+		})
+	}
+
+	assert.Len(t, GetScopeValueSchemas(), 4)
+}
+
+func TestUserScopeService_AddReplaceDelete(t *testing.T) {
+	service, db := setupTestUserScope(t)
+	userID := createUserForScopeTest(t, db, "スコープユーザー")
+
+	scope, err := service.AddUserScope(userID, AddUserScopeRequest{
+		ResourceType: "orders",
+		UserScopeItem: UserScopeItem{
+			ScopeType:  models.ScopeTypeRegion,
+			ScopeValue: map[string]interface{}{"region": "kanto"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "kanto", scope.ScopeValue["region"])
+
+	t.Run("同一スコープの重複追加はエラー", func(t *testing.T) {
+		_, err := service.AddUserScope(userID, AddUserScopeRequest{
+			ResourceType: "orders",
+			UserScopeItem: UserScopeItem{
+				ScopeType:  models.ScopeTypeRegion,
+				ScopeValue: map[string]interface{}{"region": "kanto"},
+			},
+		})
+		assert.True(t, errors.IsValidationError(err))
+	})
+
+	t.Run("スキーマ違反はエラー", func(t *testing.T) {
+		_, err := service.AddUserScope(userID, AddUserScopeRequest{
+			ResourceType: "orders",
+			UserScopeItem: UserScopeItem{
+				ScopeType:  models.ScopeTypeDepartment,
+				ScopeValue: map[string]interface{}{"department_id": "not-a-uuid"},
+			},
+		})
+		require.Error(t, err)
+		assert.Equal(t, "scope_value", err.(*errors.APIError).Details.Field)
+	})
+
+	t.Run("リソース種別単位で置換", func(t *testing.T) {
+		_, err := service.AddUserScope(userID, AddUserScopeRequest{
+			ResourceType: "inventory",
+			UserScopeItem: UserScopeItem{
+				ScopeType:  models.ScopeTypeLocation,
+				ScopeValue: map[string]interface{}{"location_id": "wh-01"},
+			},
+		})
+		require.NoError(t, err)
+
+		replaced, err := service.ReplaceUserScopes(userID, ReplaceUserScopesRequest{
+			ResourceType: "orders",
+			Scopes: []UserScopeItem{
+				{ScopeType: models.ScopeTypeRegion, ScopeValue: map[string]interface{}{"region": []interface{}{"kansai", "kyushu"}}},
+				{ScopeType: models.ScopeTypeProject, ScopeValue: map[string]interface{}{"project_id": "prj-001"}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, replaced.Total)
+
+		orders, err := service.GetUserScopes(userID, "orders")
+		require.NoError(t, err)
+		assert.Equal(t, 2, orders.Total)
+
+		all, err := service.GetUserScopes(userID, "")
+		require.NoError(t, err)
+		assert.Equal(t, 3, all.Total)
+	})
+
+	t.Run("置換内容の検証エラーは既存スコープを変更しない", func(t *testing.T) {
+		_, err := service.ReplaceUserScopes(userID, ReplaceUserScopesRequest{
+			ResourceType: "orders",
+			Scopes: []UserScopeItem{
+				{ScopeType: models.ScopeTypeRegion, ScopeValue: map[string]interface{}{"region": "tohoku"}},
+				{ScopeType: models.ScopeTypeRegion, ScopeValue: map[string]interface{}{"region": 1.0}},
+			},
+		})
+		require.Error(t, err)
+		assert.Equal(t, "scopes[1].scope_value", err.(*errors.APIError).Details.Field)
+
+		orders, err := service.GetUserScopes(userID, "orders")
+		require.NoError(t, err)
+		assert.Equal(t, 2, orders.Total)
+	})
+
+	t.Run("1件削除とリソース種別単位の削除", func(t *testing.T) {
+		inventory, err := service.GetUserScopes(userID, "inventory")
+		require.NoError(t, err)
+		require.Equal(t, 1, inventory.Total)
+
+		require.NoError(t, service.DeleteUserScope(userID, inventory.Scopes[0].ID))
+		assert.True(t, errors.IsNotFound(service.DeleteUserScope(userID, inventory.Scopes[0].ID)))
+
+		deleted, err := service.DeleteUserScopesByResource(userID, "orders")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+
+		all, err := service.GetUserScopes(userID, "")
+		require.NoError(t, err)
+		assert.Equal(t, 0, all.Total)
+	})
+
+	t.Run("存在しないユーザー", func(t *testing.T) {
+		_, err := service.GetUserScopes(uuid.New(), "")
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
+
+func TestUserScopeService_FindUsersByScope(t *testing.T) {
+	service, db := setupTestUserScope(t)
+
+	kanto := createUserForScopeTest(t, db, "関東担当")
+	multi := createUserForScopeTest(t, db, "複数地域担当")
+	all := createUserForScopeTest(t, db, "全国担当")
+	kansai := createUserForScopeTest(t, db, "関西担当")
+
+	addScope := func(userID uuid.UUID, resourceType string, value interface{}) {
+		_, err := service.AddUserScope(userID, AddUserScopeRequest{
+			ResourceType: resourceType,
+			UserScopeItem: UserScopeItem{
+				ScopeType:  models.ScopeTypeRegion,
+				ScopeValue: map[string]interface{}{"region": value},
+			},
+		})
+		require.NoError(t, err)
+	}
+	addScope(kanto, "orders", "kanto")
+	addScope(multi, "orders", []interface{}{"kansai", "kanto"})
+	addScope(all, "orders", "*")
+	addScope(kansai, "orders", "kansai")
+	addScope(kansai, "inventory", "kanto") // 別リソース種別は対象外
+
+	result, err := service.FindUsersByScope(ScopeUsersRequest{
+		ResourceType: "orders",
+		Scope:        map[string]interface{}{"region": "kanto"},
+	})
+	require.NoError(t, err)
+
+	ids := make([]uuid.UUID, len(result.Users))
+	for i, user := range result.Users {
+		ids[i] = user.ID
+		assert.Len(t, user.MatchedScopeIDs, 1)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{kanto, multi, all}, ids)
+	assert.Equal(t, 3, result.Total)
+
+	t.Run("該当なし", func(t *testing.T) {
+		result, err := service.FindUsersByScope(ScopeUsersRequest{
+			ResourceType: "orders",
+			ScopeType:    models.ScopeTypeProject,
+			Scope:        map[string]interface{}{"region": "kanto"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Total)
+		assert.NotNil(t, result.Users)
+	})
+
+	t.Run("スコープ未指定はエラー", func(t *testing.T) {
+		_, err := service.FindUsersByScope(ScopeUsersRequest{ResourceType: "orders"})
+		assert.True(t, errors.IsValidationError(err))
+	})
+}
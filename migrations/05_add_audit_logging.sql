@@ -0,0 +1,10 @@
+-- 🔧 マイグレーション: 監査ログ記録ミドルウェア対応
+-- 未認証リクエスト（トークンなし・不正トークン・ログイン失敗）も監査対象とするため
+-- audit_logs.user_id を NULL 許容に変更
+
+ALTER TABLE audit_logs ALTER COLUMN user_id DROP NOT NULL;
+
+-- 理由コード検索用インデックス（DENIED/ERROR の分析用）
+CREATE INDEX IF NOT EXISTS idx_audit_logs_reason_code ON audit_logs(reason_code) WHERE reason_code IS NOT NULL;
+
+COMMENT ON COLUMN audit_logs.user_id IS '操作ユーザー（未認証リクエストの場合はNULL）';
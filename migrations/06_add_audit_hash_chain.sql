@@ -0,0 +1,49 @@
+-- 🔧 マイグレーション: 監査ログの改ざん検知（ハッシュチェーン）
+-- 各監査ログに「直前のログのハッシュ + 自身の内容」のSHA-256を保持し、
+-- 署名付きチェックポイントでオフライン検証を可能にする
+
+-- =============================================================================
+-- audit_logs: ハッシュチェーン列追加
+-- =============================================================================
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+COMMENT ON COLUMN audit_logs.prev_hash IS '直前の監査ログのハッシュ（チェーン先頭は空文字列）';
+COMMENT ON COLUMN audit_logs.hash IS '自身の内容とprev_hashのSHA-256（NULLは本マイグレーション以前の未連結ログ）';
+
+-- =============================================================================
+-- audit_checkpoints: 署名付きチェックポイント
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+  id SERIAL PRIMARY KEY,
+  last_log_id INTEGER NOT NULL,
+  last_hash VARCHAR(64) NOT NULL,
+  log_count BIGINT NOT NULL,
+  payload TEXT NOT NULL,
+  signature TEXT NOT NULL,
+  key_id VARCHAR(32) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_last_log_id ON audit_checkpoints(last_log_id);
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_key_id ON audit_checkpoints(key_id);
+
+-- =============================================================================
+-- 改ざん防止: ユーザー削除時の連鎖削除を解除し、監査ログの更新・削除を禁止
+-- =============================================================================
+
+-- ユーザー削除で監査ログが消えるとチェーンが切れるため外部キーを削除（user_idは記録として残す）
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_user_id_fkey;
+
+CREATE OR REPLACE FUNCTION prevent_audit_log_modification() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_logs_append_only ON audit_logs;
+CREATE TRIGGER trg_audit_logs_append_only
+  BEFORE UPDATE OR DELETE ON audit_logs
+  FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_modification();
@@ -0,0 +1,50 @@
+-- 🔧 マイグレーション: 承認申請インスタンス
+-- approval_states で定義された承認フローに沿って進行する申請と、
+-- 各ステップでの承認・却下・差し戻しの履歴を保持する
+
+-- =============================================================================
+-- approval_requests: 承認申請
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS approval_requests (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  resource_type VARCHAR(50) NOT NULL,
+  resource_id VARCHAR(255),
+  title VARCHAR(255) NOT NULL,
+  payload JSONB,
+  scope JSONB,
+  requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status VARCHAR(20) NOT NULL DEFAULT 'pending',
+  current_step INTEGER NOT NULL DEFAULT 0,
+  completed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT chk_approval_requests_status CHECK (status IN ('pending', 'approved', 'rejected', 'returned', 'cancelled')),
+  CONSTRAINT chk_approval_requests_current_step CHECK (current_step >= 0)
+);
+
+COMMENT ON COLUMN approval_requests.scope IS '承認フロー解決に使用するスコープ条件（approval_states.scopeと照合）';
+COMMENT ON COLUMN approval_requests.current_step IS '承認待ちのstep_order（完了・差し戻し時は0）';
+
+CREATE INDEX IF NOT EXISTS idx_approval_requests_status_step ON approval_requests(status, current_step);
+CREATE INDEX IF NOT EXISTS idx_approval_requests_requested_by ON approval_requests(requested_by);
+CREATE INDEX IF NOT EXISTS idx_approval_requests_resource ON approval_requests(resource_type, resource_id);
+
+-- =============================================================================
+-- approval_actions: 承認操作履歴
+-- =============================================================================
+
+CREATE TABLE IF NOT EXISTS approval_actions (
+  id SERIAL PRIMARY KEY,
+  request_id UUID NOT NULL REFERENCES approval_requests(id) ON DELETE CASCADE,
+  step_order INTEGER NOT NULL DEFAULT 0,
+  approval_state_id INTEGER REFERENCES approval_states(id) ON DELETE SET NULL,
+  actor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  action VARCHAR(20) NOT NULL,
+  comment TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT chk_approval_actions_action CHECK (action IN ('submit', 'approve', 'reject', 'return', 'resubmit', 'cancel'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_actions_request ON approval_actions(request_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_approval_actions_actor ON approval_actions(actor_id);
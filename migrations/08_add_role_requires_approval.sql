@@ -0,0 +1,7 @@
+-- 🔧 マイグレーション: 承認が必要なロール
+-- requires_approval が true のロールは、付与時に承認申請（resource_type = 'user_role'）を作成し、
+-- approval_states の承認フローで最終承認された時点で user_roles に登録する
+
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS requires_approval BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN roles.requires_approval IS 'trueの場合、ロール付与に承認フロー（resource_type = user_role）の最終承認が必要';
@@ -0,0 +1,24 @@
+-- 🔧 マイグレーション: リフレッシュトークン
+-- 短命なアクセストークン（JWT）と長命な不透明リフレッシュトークンのペアで認証する
+-- リフレッシュトークンはSHA-256ハッシュのみ保存し、使用のたびに同じファミリー内で再発行（ローテーション）する
+-- 使用済みトークンが再提示された場合は漏洩とみなしファミリー全体を無効化する
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  family_id UUID NOT NULL,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  expires_at TIMESTAMPTZ NOT NULL,
+  used_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ,
+  revoked_reason VARCHAR(50),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN refresh_tokens.family_id IS 'ログイン単位のトークンファミリー（ローテーションで引き継ぐ）';
+COMMENT ON COLUMN refresh_tokens.token_hash IS 'リフレッシュトークンのSHA-256ハッシュ（16進）';
+COMMENT ON COLUMN refresh_tokens.used_at IS 'ローテーションで使用済みになった日時（再提示はファミリー無効化）';
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires ON refresh_tokens(expires_at);
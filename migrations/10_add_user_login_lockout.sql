@@ -0,0 +1,13 @@
+-- 🔧 マイグレーション: ログイン試行回数によるアカウントロックアウト
+-- 連続失敗回数が閾値に達するとロックし、以降の失敗ごとにロック期間を倍増させる（上限あり）
+-- ログイン成功または管理者によるロック解除で失敗回数をリセットする
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN users.failed_login_attempts IS '連続ログイン失敗回数（成功・ロック解除でリセット）';
+COMMENT ON COLUMN users.locked_until IS 'ロックアウト期限（NULLまたは過去日時の場合はロックなし）';
+COMMENT ON COLUMN users.last_login_at IS '最終ログイン成功日時';
+
+CREATE INDEX IF NOT EXISTS idx_users_locked_until ON users(locked_until) WHERE locked_until IS NOT NULL;
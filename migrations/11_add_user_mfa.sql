@@ -0,0 +1,51 @@
+-- 🔧 マイグレーション: TOTP多要素認証
+-- RFC 6238 のTOTPによる二段階ログインを導入する
+-- パスワード認証後にMFAチャレンジトークンを発行し、/auth/mfa/verify でTOTPコードまたはリカバリーコードを検証してJWTを発行する
+-- リカバリーコード・チャレンジトークンはSHA-256ハッシュのみ保存する
+
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS requires_mfa BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN roles.requires_mfa IS 'trueの場合、このロールを持つユーザーはログインにMFAが必要（未登録の場合はログイン時に登録を要求）';
+
+-- system:admin の実効権限（親ロールからの継承・ワイルドカードを含む）を持つユーザーは requires_mfa に関わらずログイン時にMFA必須と判定する
+
+CREATE TABLE IF NOT EXISTS user_mfa (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+  secret VARCHAR(64) NOT NULL,
+  confirmed_at TIMESTAMPTZ,
+  last_used_step BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN user_mfa.secret IS 'TOTPシークレット（Base32）';
+COMMENT ON COLUMN user_mfa.confirmed_at IS '確認コードの検証で登録が完了した日時（NULLは登録途中）';
+COMMENT ON COLUMN user_mfa.last_used_step IS '最後に使用したTOTPタイムステップ（同一コードの再利用防止）';
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  code_hash VARCHAR(64) NOT NULL UNIQUE,
+  used_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN mfa_recovery_codes.code_hash IS 'リカバリーコードのSHA-256ハッシュ（16進）';
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON mfa_recovery_codes(user_id);
+
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  expires_at TIMESTAMPTZ NOT NULL,
+  failed_attempts INTEGER NOT NULL DEFAULT 0,
+  used_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN mfa_challenges.token_hash IS 'MFAチャレンジトークンのSHA-256ハッシュ（16進）';
+COMMENT ON COLUMN mfa_challenges.failed_attempts IS 'コード検証の失敗回数（上限到達でチャレンジ無効）';
+
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_user ON mfa_challenges(user_id);
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_expires ON mfa_challenges(expires_at);
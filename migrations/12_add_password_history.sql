@@ -0,0 +1,18 @@
+-- 🔧 マイグレーション: パスワードポリシーとパスワード履歴
+-- パスワード変更時に直近N件の履歴（bcryptハッシュ）と照合して再利用を禁止する
+-- password_changed_at からパスワードの有効期限（最大日数）を判定する
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN users.password_changed_at IS '最終パスワード変更日時（NULLの場合はcreated_atを使用）';
+
+CREATE TABLE IF NOT EXISTS password_history (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  password_hash TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE password_history IS '過去のパスワードハッシュ（再利用禁止の判定用、ポリシーの履歴件数を超えた分は削除）';
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history(user_id, created_at DESC);
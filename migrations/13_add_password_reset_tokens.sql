@@ -0,0 +1,21 @@
+-- 🔧 マイグレーション: パスワード再設定トークン
+-- /auth/password/forgot で発行し、メールで送付したリンクから /auth/password/reset で使用する
+-- トークンはSHA-256ハッシュのみ保存し、1回限り・有効期限付きで使用できる
+-- 新しい再設定要求があった場合は同じユーザーの未使用トークンを無効化する
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  expires_at TIMESTAMPTZ NOT NULL,
+  used_at TIMESTAMPTZ,
+  requested_ip VARCHAR(45),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN password_reset_tokens.token_hash IS 'パスワード再設定トークンのSHA-256ハッシュ（16進）';
+COMMENT ON COLUMN password_reset_tokens.used_at IS '使用済み、または新しい再設定要求で無効化された日時';
+COMMENT ON COLUMN password_reset_tokens.requested_ip IS '再設定を要求したクライアントのIPアドレス';
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user ON password_reset_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_expires ON password_reset_tokens(expires_at);
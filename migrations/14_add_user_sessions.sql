@@ -0,0 +1,26 @@
+-- 🔧 マイグレーション: ログインセッション
+-- ログイン（リフレッシュトークンのファミリー）単位で端末・IP・発行日時・最終利用日時を記録する
+-- 最新のアクセストークンのJTIを保持し、セッションを個別に無効化できるようにする
+-- ログアウト・再利用検知・全トークン無効化はリフレッシュトークン側の状態で判定する
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  family_id UUID NOT NULL UNIQUE,
+  current_jti VARCHAR(255) NOT NULL,
+  user_agent TEXT,
+  ip_address VARCHAR(45),
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  revoked_at TIMESTAMPTZ,
+  revoked_reason VARCHAR(50),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN user_sessions.family_id IS 'リフレッシュトークンのファミリーID（refresh_tokens.family_id）';
+COMMENT ON COLUMN user_sessions.current_jti IS '最新のアクセストークンのJTI（無効化時にrevoked_tokensへ登録）';
+COMMENT ON COLUMN user_sessions.last_seen_at IS 'ログイン・トークンリフレッシュ時に更新';
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions(user_id, last_seen_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_jti ON user_sessions(current_jti);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_expires ON user_sessions(expires_at);
@@ -0,0 +1,20 @@
+-- 🔧 マイグレーション: トークン無効化キャッシュ対応
+-- ユーザー単位の一括無効化（token_jti = '*'）を複数回・複数ユーザーで記録できるよう、一意制約を個別トークンのみに限定する
+-- 各レプリカの無効化キャッシュが revoked_at で差分を取得するためのインデックスを追加
+
+ALTER TABLE revoked_tokens DROP CONSTRAINT IF EXISTS revoked_tokens_token_jti_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revoked_tokens_jti_unique ON revoked_tokens(token_jti) WHERE token_jti <> '*';
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_user_all ON revoked_tokens(user_id, revoked_at) WHERE token_jti = '*';
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_revoked_at ON revoked_tokens(revoked_at);
+
+COMMENT ON COLUMN revoked_tokens.token_jti IS 'JWT ID（''*'' はユーザー単位の一括無効化）';
+
+-- トークン無効化関数（一意制約の変更に合わせて競合条件を更新）
+CREATE OR REPLACE FUNCTION revoke_token(jti TEXT, user_uuid UUID, exp_timestamp TIMESTAMPTZ)
+RETURNS VOID AS $$
+BEGIN
+  INSERT INTO revoked_tokens (token_jti, user_id, expires_at)
+  VALUES (jti, user_uuid, exp_timestamp)
+  ON CONFLICT (token_jti) WHERE token_jti <> '*' DO NOTHING;
+END;
+$$ LANGUAGE plpgsql;
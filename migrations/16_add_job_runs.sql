@@ -0,0 +1,17 @@
+-- 🔧 マイグレーション: 定期ジョブの実行結果
+-- 期限切れの無効化トークン・ロール等を整理する定期ジョブの最終実行結果をジョブ名ごとに保持する
+-- 同時実行はPostgreSQLのアドバイザリロックで1レプリカに限定し、実行したレプリカが結果を記録する
+
+CREATE TABLE IF NOT EXISTS job_runs (
+  name VARCHAR(100) PRIMARY KEY,
+  last_started_at TIMESTAMPTZ NOT NULL,
+  last_finished_at TIMESTAMPTZ NOT NULL,
+  last_status VARCHAR(20) NOT NULL CHECK (last_status IN ('success', 'failed')),
+  last_error TEXT,
+  last_affected BIGINT NOT NULL DEFAULT 0,
+  last_instance VARCHAR(255) NOT NULL
+);
+
+COMMENT ON TABLE job_runs IS '定期ジョブの最終実行結果';
+COMMENT ON COLUMN job_runs.last_affected IS '削除・更新した件数';
+COMMENT ON COLUMN job_runs.last_instance IS '実行したレプリカのホスト名';
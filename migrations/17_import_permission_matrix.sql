@@ -0,0 +1,106 @@
+-- 🔧 マイグレーション: 権限マトリックスのデータベース移行
+-- これまでGoソース（services.PermissionMatrix）でロール名に紐付けていた権限を role_permissions に登録する
+-- 実行時の権限判定は role_permissions のみを参照するため、ロール名を変更しても権限は変わらない
+-- ワイルドカード（*:*、user:* など）も permissions の1行として登録し、/api/v1/roles/:id/permissions で編集できる
+
+-- 旧マトリックスの権限を同名のロールへ登録（冪等・登録した件数を返す）
+-- ロールを後から作成する環境（シード投入など）では、ロール作成後に SELECT import_legacy_permission_matrix(); を実行する
+CREATE OR REPLACE FUNCTION import_legacy_permission_matrix()
+RETURNS INTEGER AS $$
+DECLARE
+  inserted_count INTEGER;
+BEGIN
+  CREATE TEMP TABLE IF NOT EXISTS legacy_permission_matrix (
+    role_name TEXT NOT NULL,
+    module TEXT NOT NULL,
+    action TEXT NOT NULL
+  ) ON COMMIT DROP;
+  TRUNCATE legacy_permission_matrix;
+
+  INSERT INTO legacy_permission_matrix (role_name, module, action) VALUES
+  -- システム管理者（全権限）
+  ('システム管理者', '*', '*'),
+  -- 部門管理者（システム管理以外）
+  ('部門管理者', 'user', 'create'),
+  ('部門管理者', 'user', 'read'),
+  ('部門管理者', 'user', 'update'),
+  ('部門管理者', 'user', 'delete'),
+  ('部門管理者', 'user', 'list'),
+  ('部門管理者', 'department', 'create'),
+  ('部門管理者', 'department', 'read'),
+  ('部門管理者', 'department', 'update'),
+  ('部門管理者', 'department', 'delete'),
+  ('部門管理者', 'department', 'list'),
+  ('部門管理者', 'role', 'create'),
+  ('部門管理者', 'role', 'read'),
+  ('部門管理者', 'role', 'update'),
+  ('部門管理者', 'role', 'delete'),
+  ('部門管理者', 'role', 'list'),
+  ('部門管理者', 'permission', 'read'),
+  ('部門管理者', 'permission', 'list'),
+  ('部門管理者', 'audit', 'read'),
+  ('部門管理者', 'audit', 'list'),
+  -- 開発者
+  ('開発者', 'user', 'read'),
+  ('開発者', 'user', 'update'),
+  ('開発者', 'user', 'list'),
+  ('開発者', 'department', 'read'),
+  ('開発者', 'department', 'list'),
+  ('開発者', 'role', 'read'),
+  ('開発者', 'role', 'list'),
+  ('開発者', 'audit', 'read'),
+  -- 一般ユーザー
+  ('一般ユーザー', 'user', 'read'),
+  ('一般ユーザー', 'department', 'read'),
+  ('一般ユーザー', 'role', 'read'),
+  -- ゲストユーザー
+  ('ゲストユーザー', 'user', 'read'),
+  ('ゲストユーザー', 'user', 'list'),
+  ('ゲストユーザー', 'department', 'read'),
+  ('ゲストユーザー', 'department', 'list'),
+  ('ゲストユーザー', 'role', 'read'),
+  ('ゲストユーザー', 'role', 'list'),
+  -- プロジェクトマネージャー
+  ('プロジェクトマネージャー', 'user', 'read'),
+  ('プロジェクトマネージャー', 'user', 'update'),
+  ('プロジェクトマネージャー', 'user', 'list'),
+  ('プロジェクトマネージャー', 'department', 'read'),
+  ('プロジェクトマネージャー', 'department', 'update'),
+  ('プロジェクトマネージャー', 'department', 'list'),
+  ('プロジェクトマネージャー', 'role', 'read'),
+  ('プロジェクトマネージャー', 'role', 'update'),
+  ('プロジェクトマネージャー', 'role', 'list'),
+  ('プロジェクトマネージャー', 'audit', 'read'),
+  -- テスター
+  ('テスター', 'user', 'read'),
+  ('テスター', 'user', 'list'),
+  ('テスター', 'department', 'read'),
+  ('テスター', 'department', 'list'),
+  ('テスター', 'role', 'read'),
+  ('テスター', 'role', 'list'),
+  ('テスター', 'audit', 'read');
+
+  -- 権限（ワイルドカードを含む）
+  INSERT INTO permissions (module, action)
+  SELECT DISTINCT m.module, m.action
+  FROM legacy_permission_matrix m
+  ON CONFLICT (module, action) DO NOTHING;
+
+  -- ロール-権限の関連付け（既存のロールのみ）
+  INSERT INTO role_permissions (role_id, permission_id)
+  SELECT r.id, p.id
+  FROM legacy_permission_matrix m
+  JOIN roles r ON r.name = m.role_name
+  JOIN permissions p ON p.module = m.module AND p.action = m.action
+  ON CONFLICT (role_id, permission_id) DO NOTHING;
+  GET DIAGNOSTICS inserted_count = ROW_COUNT;
+
+  TRUNCATE legacy_permission_matrix;
+  RETURN inserted_count;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON FUNCTION import_legacy_permission_matrix() IS '旧権限マトリックス（ロール名→権限）を role_permissions へ登録（冪等）';
+
+-- 既存のロールへ登録
+SELECT import_legacy_permission_matrix();
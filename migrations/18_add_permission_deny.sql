@@ -0,0 +1,54 @@
+-- 🔧 マイグレーション: 拒否権限
+-- role_permissions.effect = 'deny' の付与は、親ロールからの継承・他のロール・ワイルドカード（*:* など）による許可より優先する
+-- 判定の優先順位: 明示的な拒否 > 明示的な許可 > ワイルドカードによる許可
+-- 拒否は module:action を特定した権限のみ（ワイルドカード権限は拒否に使用できない）
+
+ALTER TABLE role_permissions ADD COLUMN IF NOT EXISTS effect VARCHAR(10) NOT NULL DEFAULT 'allow';
+
+ALTER TABLE role_permissions DROP CONSTRAINT IF EXISTS chk_role_permissions_effect;
+ALTER TABLE role_permissions ADD CONSTRAINT chk_role_permissions_effect CHECK (effect IN ('allow', 'deny'));
+
+COMMENT ON COLUMN role_permissions.effect IS 'allow: 許可 / deny: 拒否（同じ権限の許可より優先）';
+
+-- 許可のみを返すビュー・関数は拒否の付与を含めない（拒否を含む判定はアプリケーションで行う）
+CREATE OR REPLACE VIEW user_permissions_view AS
+SELECT 
+  u.id as user_id,
+  u.name as user_name,
+  u.email,
+  d.name as department_name,
+  r.name as role_name,
+  p.module,
+  p.action,
+  u.status as user_status
+FROM users u
+JOIN departments d ON u.department_id = d.id
+JOIN user_roles ur ON u.id = ur.user_id
+JOIN roles r ON ur.role_id = r.id
+JOIN role_permissions rp ON r.id = rp.role_id AND rp.effect = 'allow'
+JOIN permissions p ON rp.permission_id = p.id
+WHERE u.status = 'active' 
+  AND ur.is_active = true
+  AND ur.valid_from <= NOW()
+  AND (ur.valid_to IS NULL OR ur.valid_to > NOW());
+
+CREATE OR REPLACE FUNCTION get_user_all_permissions(user_uuid UUID)
+RETURNS TABLE(module TEXT, action TEXT) AS $$
+BEGIN
+  RETURN QUERY
+  WITH user_role_hierarchy AS (
+    SELECT rh.id
+    FROM users u
+    JOIN user_roles ur ON u.id = ur.user_id
+    JOIN role_hierarchy rh ON (ur.role_id = rh.id OR ur.role_id = ANY(rh.path))
+    WHERE u.id = user_uuid
+      AND ur.is_active = true
+      AND ur.valid_from <= NOW()
+      AND (ur.valid_to IS NULL OR ur.valid_to > NOW())
+  )
+  SELECT DISTINCT p.module, p.action
+  FROM user_role_hierarchy urh
+  JOIN role_permissions rp ON urh.id = rp.role_id AND rp.effect = 'allow'
+  JOIN permissions p ON rp.permission_id = p.id;
+END;
+$$ LANGUAGE plpgsql;
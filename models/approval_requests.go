@@ -0,0 +1,138 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApprovalRequestStatus 承認申請ステータス
+type ApprovalRequestStatus string
+
+const (
+	ApprovalStatusPending   ApprovalRequestStatus = "pending"
+	ApprovalStatusApproved  ApprovalRequestStatus = "approved"
+	ApprovalStatusRejected  ApprovalRequestStatus = "rejected"
+	ApprovalStatusReturned  ApprovalRequestStatus = "returned"
+	ApprovalStatusCancelled ApprovalRequestStatus = "cancelled"
+)
+
+// ApprovalActionType 承認操作の種別
+type ApprovalActionType string
+
+const (
+	ApprovalActionSubmit   ApprovalActionType = "submit"
+	ApprovalActionApprove  ApprovalActionType = "approve"
+	ApprovalActionReject   ApprovalActionType = "reject"
+	ApprovalActionReturn   ApprovalActionType = "return"
+	ApprovalActionResubmit ApprovalActionType = "resubmit"
+	ApprovalActionCancel   ApprovalActionType = "cancel"
+)
+
+// ApprovalRequest 承認申請テーブル
+type ApprovalRequest struct {
+	BaseModelWithUpdate
+	ResourceType string                `gorm:"size:50;not null;index" json:"resource_type"`
+	ResourceID   *string               `gorm:"size:255" json:"resource_id,omitempty"`
+	Title        string                `gorm:"size:255;not null" json:"title"`
+	Payload      JSONB                 `gorm:"type:jsonb" json:"payload,omitempty"` // 申請内容
+	Scope        JSONB                 `gorm:"type:jsonb" json:"scope,omitempty"`   // 承認フロー解決用のスコープ条件
+	RequestedBy  uuid.UUID             `gorm:"type:uuid;not null;index" json:"requested_by"`
+	Status       ApprovalRequestStatus `gorm:"size:20;not null;default:'pending'" json:"status"`
+	CurrentStep  int                   `gorm:"not null;default:0" json:"current_step"` // 承認待ちのstep_order（完了・差し戻し時は0）
+	CompletedAt  *time.Time            `json:"completed_at,omitempty"`
+
+	// リレーション
+	Requester User             `gorm:"foreignKey:RequestedBy;constraint:OnDelete:CASCADE" json:"requester,omitempty"`
+	Actions   []ApprovalAction `gorm:"foreignKey:RequestID;constraint:OnDelete:CASCADE" json:"actions,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (ApprovalRequest) TableName() string {
+	return "approval_requests"
+}
+
+// BeforeCreate 作成前のバリデーション
+func (ar *ApprovalRequest) BeforeCreate(tx *gorm.DB) error {
+	if ar.ResourceType == "" || ar.RequestedBy == uuid.Nil || !ar.Status.IsValid() {
+		return gorm.ErrInvalidValue
+	}
+	return nil
+}
+
+// IsValid ステータスが有効かチェック
+func (s ApprovalRequestStatus) IsValid() bool {
+	switch s {
+	case ApprovalStatusPending, ApprovalStatusApproved, ApprovalStatusRejected,
+		ApprovalStatusReturned, ApprovalStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsPending 承認待ちかチェック
+func (ar *ApprovalRequest) IsPending() bool {
+	return ar.Status == ApprovalStatusPending
+}
+
+// IsClosed 承認・却下・取り下げ済みかチェック
+func (ar *ApprovalRequest) IsClosed() bool {
+	return ar.Status == ApprovalStatusApproved ||
+		ar.Status == ApprovalStatusRejected ||
+		ar.Status == ApprovalStatusCancelled
+}
+
+// ApprovalAction 承認操作履歴テーブル
+type ApprovalAction struct {
+	ID              int                `gorm:"primaryKey;autoIncrement" json:"id"`
+	RequestID       uuid.UUID          `gorm:"type:uuid;not null;index" json:"request_id"`
+	StepOrder       int                `gorm:"not null;default:0" json:"step_order"`
+	ApprovalStateID *int               `json:"approval_state_id,omitempty"`
+	ActorID         uuid.UUID          `gorm:"type:uuid;not null;index" json:"actor_id"`
+	Action          ApprovalActionType `gorm:"size:20;not null" json:"action"`
+	Comment         string             `gorm:"type:text" json:"comment,omitempty"`
+	CreatedAt       time.Time          `gorm:"autoCreateTime" json:"created_at"`
+
+	// リレーション
+	Actor User `gorm:"foreignKey:ActorID;constraint:OnDelete:CASCADE" json:"actor,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (ApprovalAction) TableName() string {
+	return "approval_actions"
+}
+
+// =============================================================================
+// クエリ用ヘルパー関数
+// =============================================================================
+
+// FindApprovalRequestByID IDで承認申請を検索（操作履歴を含む）
+func FindApprovalRequestByID(db *gorm.DB, id uuid.UUID) (*ApprovalRequest, error) {
+	var request ApprovalRequest
+	err := db.Preload("Actions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).Where("id = ?", id).First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindApprovalRequestsByRequester 申請者で承認申請を検索
+func FindApprovalRequestsByRequester(db *gorm.DB, userID uuid.UUID) ([]ApprovalRequest, error) {
+	var requests []ApprovalRequest
+	err := db.Where("requested_by = ?", userID).
+		Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+// FindPendingApprovalRequestsByStep 指定ステップで承認待ちの申請を検索（操作履歴を含む）
+func FindPendingApprovalRequestsByStep(db *gorm.DB, stepOrders []int) ([]ApprovalRequest, error) {
+	var requests []ApprovalRequest
+	err := db.Preload("Actions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).Where("status = ? AND current_step IN ?", ApprovalStatusPending, stepOrders).
+		Order("created_at ASC").Find(&requests).Error
+	return requests, err
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/google/uuid"
@@ -72,6 +73,7 @@ func (as *ApprovalState) IsValidResourceType() bool {
 		"dashboard", "settings", "finance", "hr",
 		"projects", "locations", "assets", "contracts",
 		"purchases", "expenses", "budgets", "invoices",
+		"user_role",
 	}
 
 	for _, resourceType := range validResourceTypes {
@@ -100,7 +102,7 @@ func (as *ApprovalState) MatchesScope(conditions JSONB) bool {
 	// 承認状態のスコープ条件がすべて満たされているかチェック
 	for key, expectedValue := range as.Scope {
 		actualValue, exists := conditions[key]
-		if !exists || actualValue != expectedValue {
+		if !exists || !reflect.DeepEqual(actualValue, expectedValue) {
 			return false
 		}
 	}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditCheckpoint 監査ログハッシュチェーンの署名付きチェックポイントテーブル
+type AuditCheckpoint struct {
+	ID        int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	LastLogID int       `gorm:"not null;index" json:"last_log_id"`    // チェックポイント時点の最終監査ログID
+	LastHash  string    `gorm:"size:64;not null" json:"last_hash"`    // 最終監査ログのハッシュ
+	LogCount  int64     `gorm:"not null" json:"log_count"`            // LastLogIDまでの連結済み監査ログ件数
+	Payload   string    `gorm:"not null" json:"payload"`              // 署名対象ペイロード（JSON）
+	Signature string    `gorm:"not null" json:"signature"`            // ペイロードのEd25519署名（Base64）
+	KeyID     string    `gorm:"size:32;not null;index" json:"key_id"` // 署名鍵の識別子
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName テーブル名を指定
+func (AuditCheckpoint) TableName() string {
+	return "audit_checkpoints"
+}
+
+// BeforeCreate 作成前のバリデーション
+func (ac *AuditCheckpoint) BeforeCreate(tx *gorm.DB) error {
+	if ac.LastLogID < 1 || ac.LastHash == "" || ac.Payload == "" || ac.Signature == "" {
+		return gorm.ErrInvalidValue
+	}
+	return nil
+}
+
+// FindLatestAuditCheckpoint 最新のチェックポイントを取得
+func FindLatestAuditCheckpoint(db *gorm.DB) (*AuditCheckpoint, error) {
+	var checkpoint AuditCheckpoint
+	err := db.Order("id DESC").First(&checkpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
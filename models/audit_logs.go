@@ -1,29 +1,48 @@
 package models
 
 import (
-	"net"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// 監査ログの理由コード（プレフィックスでカテゴリ分け: GetReasonCodeCategory参照）
+const (
+	ReasonCodeAuthMissingToken      = "AUTH_MISSING_TOKEN"      // Authorizationヘッダーなし
+	ReasonCodeAuthInvalidToken      = "AUTH_INVALID_TOKEN"      // トークン形式不正・検証失敗
+	ReasonCodeAuthTokenRevoked      = "AUTH_TOKEN_REVOKED"      // 無効化済みトークン
+	ReasonCodeAuthBadCredentials    = "AUTH_BAD_CREDENTIALS"    // メールアドレスまたはパスワード不一致
+	ReasonCodeAuthAccountLocked     = "AUTH_ACCOUNT_LOCKED"     // ログイン失敗によるロックアウト中
+	ReasonCodeAuthAccountInactive   = "AUTH_ACCOUNT_INACTIVE"   // 無効・停止中のアカウント
+	ReasonCodeAuthMFAInvalid        = "AUTH_MFA_INVALID"        // MFAコード・リカバリーコード不一致
+	ReasonCodePermMissingPermission = "PERM_MISSING_PERMISSION" // 必要権限不足
+	ReasonCodePermNoPermissions     = "PERM_NO_PERMISSIONS"     // コンテキストに権限情報なし
+	ReasonCodePermTimeRestricted    = "PERM_TIME_RESTRICTED"    // 時間制限外
+	ReasonCodePermScopeDenied       = "PERM_SCOPE_DENIED"       // スコープ外のリソース
+)
+
 // AuditLog 監査ログテーブル
 type AuditLog struct {
-	ID           int         `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID       uuid.UUID   `gorm:"type:uuid;not null;index" json:"user_id"`
-	Action       string      `gorm:"not null" json:"action"`
-	ResourceType string      `gorm:"not null;index" json:"resource_type"`
-	ResourceID   string      `gorm:"not null;index" json:"resource_id"`
-	Result       AuditResult `gorm:"not null;check:result IN ('SUCCESS','DENIED','ERROR')" json:"result"`
-	Reason       *string     `json:"reason,omitempty"`
-	ReasonCode   *string     `gorm:"index" json:"reason_code,omitempty"`
-	IPAddress    *net.IP     `gorm:"type:inet" json:"ip_address,omitempty"`
-	UserAgent    *string     `json:"user_agent,omitempty"`
-	Timestamp    time.Time   `gorm:"not null;default:now();index" json:"timestamp"`
+	ID           int          `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       *uuid.UUID   `gorm:"type:uuid;index" json:"user_id,omitempty"` // 未認証リクエストの場合はNULL
+	Action       string       `gorm:"not null" json:"action"`
+	ResourceType string       `gorm:"not null;index" json:"resource_type"`
+	ResourceID   string       `gorm:"not null;index" json:"resource_id"`
+	Result       AuditResult  `gorm:"not null;check:result IN ('SUCCESS','DENIED','ERROR')" json:"result"`
+	Reason       *string      `json:"reason,omitempty"`
+	ReasonCode   *string      `gorm:"index" json:"reason_code,omitempty"`
+	IPAddress    *InetAddress `gorm:"type:inet" json:"ip_address,omitempty"`
+	UserAgent    *string      `json:"user_agent,omitempty"`
+	Timestamp    time.Time    `gorm:"not null;default:now();index" json:"timestamp"`
+	PrevHash     *string      `gorm:"size:64" json:"prev_hash,omitempty"` // 直前の監査ログのハッシュ（チェーン先頭は空文字列）
+	Hash         *string      `gorm:"size:64" json:"hash,omitempty"`      // 自身の内容とPrevHashのSHA-256
 
 	// リレーション
-	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+	User *User `gorm:"foreignKey:UserID;constraint:false" json:"user,omitempty"` // ユーザー削除後も監査ログは保持
 }
 
 // TableName テーブル名を指定
@@ -48,7 +67,8 @@ func (al *AuditLog) BeforeCreate(tx *gorm.DB) error {
 		return gorm.ErrInvalidValue
 	}
 
-	return nil
+	// ハッシュチェーンへの連結
+	return al.sealChain(tx)
 }
 
 // BeforeUpdate 更新前のバリデーション
@@ -146,8 +166,8 @@ func (al *AuditLog) SetIPAddressFromString(ipStr string) error {
 		return nil
 	}
 
-	ip := net.ParseIP(ipStr)
-	if ip == nil {
+	ip, err := ParseInetAddress(ipStr)
+	if err != nil {
 		return gorm.ErrInvalidValue
 	}
 
@@ -178,6 +198,107 @@ func (al *AuditLog) GetReasonCodeCategory() string {
 	}
 }
 
+// =============================================================================
+// ハッシュチェーン
+// =============================================================================
+
+// AuditChainLockKey 監査ログ追記を直列化するアドバイザリロックのキー
+const AuditChainLockKey int64 = 0x6175646974 // "audit"
+
+// auditHashPayload ハッシュ計算対象（フィールド順固定のJSONとしてシリアライズ）
+type auditHashPayload struct {
+	PrevHash     string `json:"prev_hash"`
+	UserID       string `json:"user_id"`
+	Action       string `json:"action"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	Result       string `json:"result"`
+	Reason       string `json:"reason"`
+	ReasonCode   string `json:"reason_code"`
+	IPAddress    string `json:"ip_address"`
+	UserAgent    string `json:"user_agent"`
+	Timestamp    int64  `json:"timestamp"` // UNIXマイクロ秒
+}
+
+// ComputeHash 内容と直前のハッシュからSHA-256（16進）を計算
+// オフライン検証用の仕様: auditHashPayloadのJSON（フィールド順固定・NULLは空文字列）のSHA-256
+func (al *AuditLog) ComputeHash(prevHash string) string {
+	payload := auditHashPayload{
+		PrevHash:     prevHash,
+		Action:       al.Action,
+		ResourceType: al.ResourceType,
+		ResourceID:   al.ResourceID,
+		Result:       string(al.Result),
+		IPAddress:    al.GetIPAddressString(),
+		Timestamp:    al.Timestamp.UnixMicro(),
+	}
+	if al.UserID != nil {
+		payload.UserID = al.UserID.String()
+	}
+	if al.Reason != nil {
+		payload.Reason = *al.Reason
+	}
+	if al.ReasonCode != nil {
+		payload.ReasonCode = *al.ReasonCode
+	}
+	if al.UserAgent != nil {
+		payload.UserAgent = *al.UserAgent
+	}
+
+	// 文字列と数値のみの構造体のためMarshalは失敗しない
+	data, _ := json.Marshal(payload)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// IsSealed ハッシュチェーンに連結済みかチェック
+func (al *AuditLog) IsSealed() bool {
+	return al.Hash != nil
+}
+
+// VerifyHash 保存されたハッシュが内容と一致するかチェック
+func (al *AuditLog) VerifyHash() bool {
+	if al.Hash == nil {
+		return false
+	}
+	prevHash := ""
+	if al.PrevHash != nil {
+		prevHash = *al.PrevHash
+	}
+	return al.ComputeHash(prevHash) == *al.Hash
+}
+
+// sealChain 直前の監査ログのハッシュを取得して自身のハッシュを設定
+// 同時書き込みでチェーンが分岐しないよう、PostgreSQLではトランザクション単位のアドバイザリロックで直列化する
+func (al *AuditLog) sealChain(tx *gorm.DB) error {
+	if tx.Dialector.Name() == "postgres" {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", AuditChainLockKey).Error; err != nil {
+			return err
+		}
+	}
+
+	// DBとの往復で精度が落ちないようマイクロ秒に丸める
+	if al.Timestamp.IsZero() {
+		al.Timestamp = time.Now()
+	}
+	al.Timestamp = al.Timestamp.Truncate(time.Microsecond)
+
+	var last AuditLog
+	prevHash := ""
+	err := tx.Select("id", "hash").Order("id DESC").Limit(1).Find(&last).Error
+	if err != nil {
+		return err
+	}
+	if last.Hash != nil {
+		prevHash = *last.Hash
+	}
+
+	hash := al.ComputeHash(prevHash)
+	al.PrevHash = &prevHash
+	al.Hash = &hash
+	return nil
+}
+
 // =============================================================================
 // クエリ用ヘルパー関数
 // =============================================================================
@@ -276,10 +397,9 @@ func FindAuditLogsByIPAddress(db *gorm.DB, ipAddress string, limit int) ([]Audit
 // 監査ログ作成用ヘルパー関数
 // =============================================================================
 
-// CreateAuditLog 監査ログを作成
+// CreateAuditLog 監査ログを作成（userIDがuuid.Nilの場合は未認証として記録）
 func CreateAuditLog(db *gorm.DB, userID uuid.UUID, action, resourceType, resourceID string, result AuditResult, reason, reasonCode *string, ipAddress *string, userAgent *string) (*AuditLog, error) {
 	auditLog := &AuditLog{
-		UserID:       userID,
 		Action:       action,
 		ResourceType: resourceType,
 		ResourceID:   resourceID,
@@ -290,6 +410,10 @@ func CreateAuditLog(db *gorm.DB, userID uuid.UUID, action, resourceType, resourc
 		Timestamp:    time.Now(),
 	}
 
+	if userID != uuid.Nil {
+		auditLog.UserID = &userID
+	}
+
 	// IPアドレスを設定
 	if ipAddress != nil {
 		err := auditLog.SetIPAddressFromString(*ipAddress)
@@ -335,6 +459,9 @@ func GetAuditStats(db *gorm.DB, startTime, endTime *time.Time) (map[string]inter
 		query = query.Where("timestamp BETWEEN ? AND ?", *startTime, *endTime)
 	}
 
+	// 集計ごとにSelect/Groupが累積しないよう条件のみを共有
+	query = query.Session(&gorm.Session{})
+
 	// 結果別統計
 	var resultStats []struct {
 		Result AuditResult `json:"result"`
@@ -390,6 +517,9 @@ func GetUserActivityStats(db *gorm.DB, userID uuid.UUID, startTime, endTime *tim
 		query = query.Where("timestamp BETWEEN ? AND ?", *startTime, *endTime)
 	}
 
+	// 集計ごとにSelect/Groupが累積しないよう条件のみを共有
+	query = query.Session(&gorm.Session{})
+
 	// アクション別統計
 	var actionStats []struct {
 		Action string `json:"action"`
@@ -4,6 +4,8 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -105,6 +107,82 @@ func (a *IntArray) Scan(value interface{}) error {
 	return (*pq.Int64Array)(a).Scan(value)
 }
 
+// InetAddress PostgreSQLのINET型に対応
+// 文字列形式で読み書きする（pgxはINETを文字列で返すため、net.IPのままではバイト列として読み込まれる）
+type InetAddress net.IP
+
+// ParseInetAddress 文字列からInetAddressをパース（"192.0.2.1/32" などのネットマスク付きも可）
+func ParseInetAddress(s string) (InetAddress, error) {
+	if ip, _, err := net.ParseCIDR(s); err == nil {
+		return InetAddress(ip), nil
+	}
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return nil, errors.New("invalid inet address: " + s)
+	}
+	return InetAddress(ip), nil
+}
+
+// String 正規化した文字列表現（IPv4は "192.0.2.1" 形式）
+func (a InetAddress) String() string {
+	return net.IP(a).String()
+}
+
+// Value InetAddressのdriver.Valuer実装
+func (a InetAddress) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	return a.String(), nil
+}
+
+// Scan InetAddressのdatabase/sql.Scanner実装
+func (a *InetAddress) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case string:
+		text = v
+	case []byte:
+		// 文字列形式で保存される前のバイナリ形式（4・16バイト）にも対応
+		if parsed, err := ParseInetAddress(string(v)); err == nil {
+			*a = parsed
+			return nil
+		}
+		if len(v) == net.IPv4len || len(v) == net.IPv6len {
+			*a = InetAddress(append(net.IP(nil), v...))
+			return nil
+		}
+		text = string(v)
+	default:
+		return errors.New("cannot scan into InetAddress")
+	}
+
+	parsed, err := ParseInetAddress(text)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// MarshalText JSONでは文字列として出力
+func (a InetAddress) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText 文字列から復元
+func (a *InetAddress) UnmarshalText(text []byte) error {
+	parsed, err := ParseInetAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
 // =============================================================================
 // ヘルパー関数
 // =============================================================================
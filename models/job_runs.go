@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ジョブの実行結果
+const (
+	JobRunStatusSuccess = "success"
+	JobRunStatusFailed  = "failed"
+)
+
+// JobRun 定期ジョブの最終実行結果テーブル（ジョブ名ごとに1行）
+// 実行したレプリカに関わらず、管理画面から最新の結果を参照できるようにする
+type JobRun struct {
+	Name           string    `gorm:"primaryKey;size:100" json:"name"`
+	LastStartedAt  time.Time `gorm:"not null" json:"last_started_at"`
+	LastFinishedAt time.Time `gorm:"not null" json:"last_finished_at"`
+	LastStatus     string    `gorm:"size:20;not null" json:"last_status"`
+	LastError      *string   `gorm:"type:text" json:"last_error,omitempty"`
+	LastAffected   int64     `gorm:"not null;default:0" json:"last_affected"` // 削除・更新した件数
+	LastInstance   string    `gorm:"size:255;not null" json:"last_instance"`  // 実行したレプリカのホスト名
+}
+
+// TableName テーブル名を指定
+func (JobRun) TableName() string {
+	return "job_runs"
+}
+
+// =============================================================================
+// クエリ用ヘルパー関数
+// =============================================================================
+
+// FindJobRuns 全ジョブの最終実行結果を取得
+func FindJobRuns(db *gorm.DB) ([]JobRun, error) {
+	var runs []JobRun
+	err := db.Order("name").Find(&runs).Error
+	return runs, err
+}
+
+// FindJobRun ジョブの最終実行結果を取得（未実行の場合はnil）
+func FindJobRun(db *gorm.DB, name string) (*JobRun, error) {
+	var runs []JobRun
+	if err := db.Where("name = ?", name).Limit(1).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return &runs[0], nil
+}
+
+// SaveJobRun ジョブの最終実行結果を保存（既存の結果は上書き）
+func SaveJobRun(db *gorm.DB, run *JobRun) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		UpdateAll: true,
+	}).Create(run).Error
+}
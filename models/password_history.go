@@ -0,0 +1,32 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordHistory パスワード履歴テーブル（再利用禁止の判定用）
+type PasswordHistory struct {
+	BaseModel
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	PasswordHash string    `gorm:"not null" json:"-"`
+}
+
+// TableName テーブル名を指定
+func (PasswordHistory) TableName() string {
+	return "password_history"
+}
+
+// FindRecentPasswordHistory ユーザーの直近のパスワード履歴を新しい順に取得
+func FindRecentPasswordHistory(db *gorm.DB, userID uuid.UUID, limit int) ([]PasswordHistory, error) {
+	var history []PasswordHistory
+	err := db.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&history).Error
+	return history, err
+}
+
+// TrimPasswordHistory 直近keep件を残して古いパスワード履歴を削除
+func TrimPasswordHistory(db *gorm.DB, userID uuid.UUID, keep int) error {
+	recent := db.Model(&PasswordHistory{}).Select("id").
+		Where("user_id = ?", userID).Order("created_at DESC").Limit(keep)
+	return db.Where("user_id = ? AND id NOT IN (?)", userID, recent).Delete(&PasswordHistory{}).Error
+}
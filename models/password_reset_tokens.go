@@ -0,0 +1,80 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordResetToken パスワード再設定トークンテーブル（トークン本体は保存せずハッシュのみ保持）
+type PasswordResetToken struct {
+	BaseModel
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash   string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt   time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at,omitempty"` // 使用済み・新しい再設定要求で無効化済み
+	RequestedIP *string    `gorm:"size:45" json:"requested_ip,omitempty"`
+
+	// リレーション
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// BeforeCreate 作成前のバリデーション
+func (t *PasswordResetToken) BeforeCreate(tx *gorm.DB) error {
+	if t.UserID == uuid.Nil || t.TokenHash == "" {
+		return gorm.ErrInvalidValue
+	}
+	if t.ExpiresAt.Before(time.Now()) {
+		return gorm.ErrInvalidValue
+	}
+	return nil
+}
+
+// IsUsable 未使用かつ有効期限内のトークンかチェック
+func (t *PasswordResetToken) IsUsable() bool {
+	return t.UsedAt == nil && t.ExpiresAt.After(time.Now())
+}
+
+// =============================================================================
+// クエリ用ヘルパー関数
+// =============================================================================
+
+// HashPasswordResetToken パスワード再設定トークンの保存用ハッシュを計算
+func HashPasswordResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// FindPasswordResetTokenByToken トークン文字列からパスワード再設定トークンを検索
+func FindPasswordResetTokenByToken(db *gorm.DB, token string) (*PasswordResetToken, error) {
+	var resetToken PasswordResetToken
+	if err := db.Where("token_hash = ?", HashPasswordResetToken(token)).First(&resetToken).Error; err != nil {
+		return nil, err
+	}
+	return &resetToken, nil
+}
+
+// =============================================================================
+// トークン管理用ヘルパー関数
+// =============================================================================
+
+// InvalidateUserPasswordResetTokens ユーザーの未使用トークンをすべて使用済みにする
+func InvalidateUserPasswordResetTokens(db *gorm.DB, userID uuid.UUID) error {
+	return db.Model(&PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
+
+// CleanupExpiredPasswordResetTokens 期限切れのパスワード再設定トークンを削除
+func CleanupExpiredPasswordResetTokens(db *gorm.DB) (int64, error) {
+	result := db.Where("expires_at < ?", time.Now()).Delete(&PasswordResetToken{})
+	return result.RowsAffected, result.Error
+}
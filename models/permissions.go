@@ -22,11 +22,20 @@ func (Permission) TableName() string {
 	return "permissions"
 }
 
+// PermissionEffect ロールへの権限付与の効果
+type PermissionEffect string
+
+const (
+	PermissionEffectAllow PermissionEffect = "allow"
+	PermissionEffectDeny  PermissionEffect = "deny" // 継承・他ロール・ワイルドカードによる許可より優先
+)
+
 // RolePermission ロール-権限の関連テーブル
 type RolePermission struct {
-	RoleID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"role_id"`
-	PermissionID uuid.UUID `gorm:"type:uuid;primaryKey" json:"permission_id"`
-	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	RoleID       uuid.UUID        `gorm:"type:uuid;primaryKey" json:"role_id"`
+	PermissionID uuid.UUID        `gorm:"type:uuid;primaryKey" json:"permission_id"`
+	Effect       PermissionEffect `gorm:"type:varchar(10);not null;default:allow" json:"effect"`
+	CreatedAt    time.Time        `gorm:"autoCreateTime" json:"created_at"`
 
 	// リレーション
 	Role       Role       `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE" json:"role,omitempty"`
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken リフレッシュトークンテーブル（トークン本体は保存せずハッシュのみ保持）
+type RefreshToken struct {
+	BaseModel
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	FamilyID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"family_id"` // ログイン単位のトークンファミリー
+	TokenHash     string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt     time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at,omitempty"` // ローテーション済み
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason *string    `gorm:"size:50" json:"revoked_reason,omitempty"`
+
+	// リレーション
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// BeforeCreate 作成前のバリデーション
+func (rt *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if rt.UserID == uuid.Nil || rt.FamilyID == uuid.Nil || rt.TokenHash == "" {
+		return gorm.ErrInvalidValue
+	}
+	if rt.ExpiresAt.Before(time.Now()) {
+		return gorm.ErrInvalidValue
+	}
+	return nil
+}
+
+// =============================================================================
+// トークン状態のメソッド
+// =============================================================================
+
+// IsExpired トークンが期限切れかチェック
+func (rt *RefreshToken) IsExpired() bool {
+	return rt.ExpiresAt.Before(time.Now())
+}
+
+// IsUsed ローテーション済みかチェック
+func (rt *RefreshToken) IsUsed() bool {
+	return rt.UsedAt != nil
+}
+
+// IsRevoked 無効化されているかチェック
+func (rt *RefreshToken) IsRevoked() bool {
+	return rt.RevokedAt != nil
+}
+
+// IsActive 使用可能なトークンかチェック
+func (rt *RefreshToken) IsActive() bool {
+	return !rt.IsExpired() && !rt.IsUsed() && !rt.IsRevoked()
+}
+
+// =============================================================================
+// クエリ用ヘルパー関数
+// =============================================================================
+
+// HashRefreshToken リフレッシュトークンの保存用ハッシュを計算
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// FindRefreshTokenByToken トークン文字列からリフレッシュトークンを検索
+func FindRefreshTokenByToken(db *gorm.DB, token string) (*RefreshToken, error) {
+	var refreshToken RefreshToken
+	err := db.Where("token_hash = ?", HashRefreshToken(token)).First(&refreshToken).Error
+	if err != nil {
+		return nil, err
+	}
+	return &refreshToken, nil
+}
+
+// FindActiveRefreshTokensByUser ユーザーの使用可能なリフレッシュトークンを検索
+func FindActiveRefreshTokensByUser(db *gorm.DB, userID uuid.UUID) ([]RefreshToken, error) {
+	var refreshTokens []RefreshToken
+	err := db.Where("user_id = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").Find(&refreshTokens).Error
+	return refreshTokens, err
+}
+
+// =============================================================================
+// トークン管理用ヘルパー関数
+// =============================================================================
+
+// RevokeRefreshTokenFamily ファミリー内の未無効化トークンをすべて無効化
+func RevokeRefreshTokenFamily(db *gorm.DB, familyID uuid.UUID, reason string) (int64, error) {
+	result := db.Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.RowsAffected, result.Error
+}
+
+// RevokeUserRefreshTokens ユーザーの未無効化トークンをすべて無効化
+func RevokeUserRefreshTokens(db *gorm.DB, userID uuid.UUID, reason string) (int64, error) {
+	result := db.Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.RowsAffected, result.Error
+}
+
+// CleanupExpiredRefreshTokens 期限切れのリフレッシュトークンを削除
+func CleanupExpiredRefreshTokens(db *gorm.DB) (int64, error) {
+	result := db.Where("expires_at < ?", time.Now()).Delete(&RefreshToken{})
+	return result.RowsAffected, result.Error
+}
@@ -10,7 +10,7 @@ import (
 // RevokedToken 無効化されたJWTトークンテーブル
 type RevokedToken struct {
 	ID        int       `gorm:"primaryKey;autoIncrement" json:"id"`
-	TokenJTI  string    `gorm:"uniqueIndex;not null" json:"token_jti"` // JWT ID
+	TokenJTI  string    `gorm:"index;not null" json:"token_jti"` // JWT ID（"*" はユーザー単位の一括無効化）
 	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
 	RevokedAt time.Time `gorm:"autoCreateTime" json:"revoked_at"`
 	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
@@ -8,8 +8,10 @@ import (
 // Role ロールテーブル
 type Role struct {
 	BaseModel
-	Name     string     `gorm:"not null" json:"name"`
-	ParentID *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Name             string     `gorm:"not null" json:"name"`
+	ParentID         *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	RequiresApproval bool       `gorm:"not null;default:false" json:"requires_approval"` // 付与に承認フローの最終承認が必要
+	RequiresMFA      bool       `gorm:"not null;default:false" json:"requires_mfa"`      // 保持ユーザーのログインにMFAが必要
 
 	// リレーション
 	Parent           *Role             `gorm:"foreignKey:ParentID;constraint:OnDelete:SET NULL" json:"parent,omitempty"`
@@ -1,19 +1,27 @@
 package models
 
 import (
+	"context"
+	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
+func init() {
+	schema.RegisterSerializer("timeofday", TimeOfDaySerializer{})
+}
+
 // TimeRestriction 時間制限テーブル
 type TimeRestriction struct {
 	ID           int        `gorm:"primaryKey;autoIncrement" json:"id"`
 	UserID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
 	ResourceType string     `gorm:"not null;index" json:"resource_type"`
-	StartTime    *time.Time `gorm:"type:time" json:"start_time,omitempty"`
-	EndTime      *time.Time `gorm:"type:time" json:"end_time,omitempty"`
+	StartTime    *time.Time `gorm:"type:time;serializer:timeofday" json:"start_time,omitempty"`
+	EndTime      *time.Time `gorm:"type:time;serializer:timeofday" json:"end_time,omitempty"`
 	AllowedDays  IntArray   `gorm:"type:integer[]" json:"allowed_days,omitempty"`
 	Timezone     string     `gorm:"default:'UTC'" json:"timezone"`
 	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
@@ -67,6 +75,65 @@ func (tr *TimeRestriction) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
+// =============================================================================
+// TIME型のシリアライザ
+// =============================================================================
+
+// timeOfDayLayouts TIME型として受け付ける文字列形式（PostgreSQLのtime型・SQLiteの日時文字列）
+var timeOfDayLayouts = []string{
+	"15:04:05.999999",
+	"15:04:05",
+	"15:04",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+}
+
+// TimeOfDaySerializer TIME型カラムと*time.Timeを相互変換
+// pgxはtime型を文字列で返すため、time.Timeへの直接のScanができない
+type TimeOfDaySerializer struct{}
+
+// Scan DB値を*time.Time（0000-01-01 UTC基準）に変換
+func (TimeOfDaySerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var raw string
+	switch v := dbValue.(type) {
+	case nil:
+		return field.Set(ctx, dst, (*time.Time)(nil))
+	case time.Time:
+		t := time.Date(0, 1, 1, v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), time.UTC)
+		return field.Set(ctx, dst, &t)
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("unsupported time of day value: %#v", dbValue)
+	}
+
+	for _, layout := range timeOfDayLayouts {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			t := time.Date(0, 1, 1, parsed.Hour(), parsed.Minute(), parsed.Second(), parsed.Nanosecond(), time.UTC)
+			return field.Set(ctx, dst, &t)
+		}
+	}
+	return fmt.Errorf("invalid time of day: %s", raw)
+}
+
+// Value *time.TimeをHH:MM:SS形式に変換
+func (TimeOfDaySerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	switch v := fieldValue.(type) {
+	case *time.Time:
+		if v == nil {
+			return nil, nil
+		}
+		return v.Format("15:04:05"), nil
+	case time.Time:
+		return v.Format("15:04:05"), nil
+	default:
+		return nil, fmt.Errorf("invalid field type %#v for TimeOfDaySerializer", fieldValue)
+	}
+}
+
 // =============================================================================
 // 時間制限管理のメソッド
 // =============================================================================
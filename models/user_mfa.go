@@ -0,0 +1,120 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserMFA ユーザーのTOTP多要素認証設定テーブル
+type UserMFA struct {
+	BaseModel
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Secret       string     `gorm:"size:64;not null" json:"-"`   // TODO: シークレットの暗号化保存
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`      // NULLは登録途中
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"` // 同一コードの再利用防止
+
+	// リレーション
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// IsConfirmed 登録が完了しているかチェック
+func (m *UserMFA) IsConfirmed() bool {
+	return m.ConfirmedAt != nil
+}
+
+// MFARecoveryCode MFAリカバリーコードテーブル（コード本体は保存せずハッシュのみ保持）
+type MFARecoveryCode struct {
+	BaseModel
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	UsedAt   *time.Time `json:"used_at,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MFAChallenge パスワード認証後のMFAチャレンジテーブル（トークン本体は保存せずハッシュのみ保持）
+type MFAChallenge struct {
+	BaseModel
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash      string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt      time.Time  `gorm:"not null;index" json:"expires_at"`
+	FailedAttempts int        `gorm:"not null;default:0" json:"failed_attempts"`
+	UsedAt         *time.Time `json:"used_at,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (MFAChallenge) TableName() string {
+	return "mfa_challenges"
+}
+
+// BeforeCreate 作成前のバリデーション
+func (c *MFAChallenge) BeforeCreate(tx *gorm.DB) error {
+	if c.UserID == uuid.Nil || c.TokenHash == "" {
+		return gorm.ErrInvalidValue
+	}
+	return nil
+}
+
+// IsUsable 検証に使用可能なチャレンジかチェック
+func (c *MFAChallenge) IsUsable(maxAttempts int) bool {
+	return c.UsedAt == nil && c.ExpiresAt.After(time.Now()) && c.FailedAttempts < maxAttempts
+}
+
+// =============================================================================
+// クエリ用ヘルパー関数
+// =============================================================================
+
+// HashMFASecretValue リカバリーコード・チャレンジトークンの保存用ハッシュを計算
+func HashMFASecretValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// FindUserMFA ユーザーのMFA設定を検索
+func FindUserMFA(db *gorm.DB, userID uuid.UUID) (*UserMFA, error) {
+	var mfa UserMFA
+	if err := db.Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// FindMFAChallengeByToken トークン文字列からMFAチャレンジを検索
+func FindMFAChallengeByToken(db *gorm.DB, token string) (*MFAChallenge, error) {
+	var challenge MFAChallenge
+	if err := db.Where("token_hash = ?", HashMFASecretValue(token)).First(&challenge).Error; err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// CountUnusedRecoveryCodes 未使用のリカバリーコード数を取得
+func CountUnusedRecoveryCodes(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&MFARecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// DeleteUserMFA ユーザーのMFA設定・リカバリーコード・チャレンジをすべて削除
+func DeleteUserMFA(db *gorm.DB, userID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&MFAChallenge{}, &MFARecoveryCode{}, &UserMFA{}} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserSession ログインセッションテーブル（リフレッシュトークンのファミリー単位）
+// 発行したアクセストークンのうち最新のJTIを保持し、セッション無効化時に失効させる
+type UserSession struct {
+	BaseModel
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	FamilyID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"-"` // リフレッシュトークンのファミリー
+	CurrentJTI    string     `gorm:"size:255;not null;index" json:"-"`        // 最新のアクセストークンのJTI
+	UserAgent     *string    `gorm:"type:text" json:"user_agent,omitempty"`
+	IPAddress     *string    `gorm:"size:45" json:"ip_address,omitempty"`
+	LastSeenAt    time.Time  `gorm:"not null" json:"last_seen_at"` // ログイン・トークンリフレッシュ時に更新
+	ExpiresAt     time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason *string    `gorm:"size:50" json:"revoked_reason,omitempty"`
+
+	// リレーション
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// TableName テーブル名を指定
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// BeforeCreate 作成前のバリデーション
+func (s *UserSession) BeforeCreate(tx *gorm.DB) error {
+	if s.UserID == uuid.Nil || s.FamilyID == uuid.Nil || s.CurrentJTI == "" {
+		return gorm.ErrInvalidValue
+	}
+	return nil
+}
+
+// =============================================================================
+// クエリ用ヘルパー関数
+// =============================================================================
+
+// activeSessionScope 無効化されておらず、使用可能なリフレッシュトークンが残っているセッション
+// ログアウト・再利用検知・全トークン無効化はリフレッシュトークン側で反映される
+func activeSessionScope(db *gorm.DB) *gorm.DB {
+	now := time.Now()
+	return db.Where("user_sessions.revoked_at IS NULL AND user_sessions.expires_at > ?", now).
+		Where(`EXISTS (SELECT 1 FROM refresh_tokens rt WHERE rt.family_id = user_sessions.family_id
+			AND rt.used_at IS NULL AND rt.revoked_at IS NULL AND rt.expires_at > ?)`, now)
+}
+
+// FindActiveUserSessions ユーザーの有効なセッションを最終利用日時の新しい順に取得
+func FindActiveUserSessions(db *gorm.DB, userID uuid.UUID) ([]UserSession, error) {
+	var sessions []UserSession
+	err := db.Scopes(activeSessionScope).Where("user_sessions.user_id = ?", userID).
+		Order("user_sessions.last_seen_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// FindActiveUserSession ユーザーの有効なセッションをIDで取得
+func FindActiveUserSession(db *gorm.DB, userID, sessionID uuid.UUID) (*UserSession, error) {
+	var session UserSession
+	err := db.Scopes(activeSessionScope).
+		Where("user_sessions.id = ? AND user_sessions.user_id = ?", sessionID, userID).
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// =============================================================================
+// セッション管理用ヘルパー関数
+// =============================================================================
+
+// TouchUserSession トークンリフレッシュ時に最新のJTI・最終利用日時と、新しいリフレッシュトークンの有効期限を更新
+func TouchUserSession(db *gorm.DB, familyID uuid.UUID, jti string, expiresAt time.Time) error {
+	return db.Model(&UserSession{}).Where("family_id = ?", familyID).
+		Updates(map[string]interface{}{"current_jti": jti, "last_seen_at": time.Now(), "expires_at": expiresAt}).Error
+}
+
+// RevokeUserSession セッションを無効化済みにする
+func RevokeUserSession(db *gorm.DB, sessionID uuid.UUID, reason string) error {
+	return db.Model(&UserSession{}).Where("id = ? AND revoked_at IS NULL", sessionID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+// CleanupExpiredUserSessions 有効期限を過ぎたセッションを削除
+func CleanupExpiredUserSessions(db *gorm.DB) (int64, error) {
+	result := db.Where("expires_at < ?", time.Now()).Delete(&UserSession{})
+	return result.RowsAffected, result.Error
+}
@@ -20,9 +20,13 @@ type User struct {
 	PrimaryRoleID *uuid.UUID `gorm:"type:uuid;index" json:"primary_role_id,omitempty"` // メインロール
 	Status        UserStatus `gorm:"not null;default:'active';check:status IN ('active','inactive','suspended')" json:"status"`
 
+	// ログイン履歴・ロックアウト
+	FailedLoginAttempts int        `gorm:"not null;default:0" json:"failed_login_attempts"` // 連続ログイン失敗回数
+	LockedUntil         *time.Time `json:"locked_until,omitempty"`                          // ロックアウト期限
+	LastLoginAt         *time.Time `json:"last_login_at,omitempty"`
+	PasswordChangedAt   *time.Time `json:"password_changed_at,omitempty"` // 最終パスワード変更日時（有効期限の判定）
+
 	// TODO: アーキテクチャ改善
-	// - パスワード強度追跡: PasswordSetAt, LastPasswordChange
-	// - ログイン履歴: LastLoginAt, LoginAttempts, LockoutUntil
 	// - プロファイル拡張: FirstName, LastName, PhoneNumber, Timezone
 
 	// リレーション
@@ -119,6 +123,11 @@ func (u *User) IsActive() bool {
 	return u.Status == UserStatusActive
 }
 
+// IsLockedOut ログイン失敗によりロックアウト中かどうかを判定
+func (u *User) IsLockedOut() bool {
+	return u.LockedUntil != nil && u.LockedUntil.After(time.Now())
+}
+
 // IsInactive 非アクティブなユーザーかどうかを判定
 func (u *User) IsInactive() bool {
 	return u.Status == UserStatusInactive
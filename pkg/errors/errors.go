@@ -3,12 +3,21 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // ErrorDetails エラーの詳細情報を表現
 type ErrorDetails struct {
-	Field  string `json:"field,omitempty"`  // エラーが発生したフィールド
-	Reason string `json:"reason,omitempty"` // エラーの具体的な理由
+	Field      string      `json:"field,omitempty"`      // エラーが発生したフィールド
+	Reason     string      `json:"reason,omitempty"`     // エラーの具体的な理由
+	Violations []Violation `json:"violations,omitempty"` // 違反したルールの一覧（複数ルールを一括検証する場合）
+}
+
+// Violation 違反したバリデーションルール
+type Violation struct {
+	Rule    string `json:"rule"`    // ルール識別子（例: min_length）
+	Message string `json:"message"` // 人間が読みやすい説明
 }
 
 // APIError 構造化されたAPIエラーを表現
@@ -33,11 +42,13 @@ const (
 	ErrCodeInvalidToken   = "INVALID_TOKEN"        // 無効なトークン
 	ErrCodeExpiredToken   = "EXPIRED_TOKEN"        // 期限切れトークン
 	ErrCodeRevokedToken   = "REVOKED_TOKEN"        // 無効化されたトークン
+	ErrCodeAccountLocked  = "ACCOUNT_LOCKED"       // ロックアウト中のアカウント
 
 	// 認可関連エラー
 	ErrCodeAuthorization     = "AUTHORIZATION_ERROR" // 認可エラー
 	ErrCodePermissionDenied  = "PERMISSION_DENIED"   // 権限不足
 	ErrCodeInsufficientScope = "INSUFFICIENT_SCOPE"  // スコープ不足
+	ErrCodeTimeRestricted    = "TIME_RESTRICTED"     // 時間制限外
 
 	// バリデーション関連エラー
 	ErrCodeValidation   = "VALIDATION_ERROR" // バリデーションエラー
@@ -149,6 +160,18 @@ func NewAuthenticationError(reason string) *APIError {
 	}
 }
 
+// NewAccountLockedError ロックアウト中のアカウントへのログイン拒否エラーを作成
+func NewAccountLockedError(lockedUntil time.Time) *APIError {
+	return &APIError{
+		Code:    ErrCodeAccountLocked,
+		Message: "Account is temporarily locked",
+		Details: ErrorDetails{
+			Reason: fmt.Sprintf("Too many failed login attempts; try again after %s", lockedUntil.UTC().Format(time.RFC3339)),
+		},
+		Status: http.StatusUnauthorized,
+	}
+}
+
 // NewAuthorizationError 認可エラーを作成
 func NewAuthorizationError(reason string) *APIError {
 	return &APIError{
@@ -161,6 +184,32 @@ func NewAuthorizationError(reason string) *APIError {
 	}
 }
 
+// NewTimeRestrictedError 時間制限によるアクセス拒否エラーを作成
+func NewTimeRestrictedError(resourceType string) *APIError {
+	return &APIError{
+		Code:    ErrCodeTimeRestricted,
+		Message: "Access is not allowed at this time",
+		Details: ErrorDetails{
+			Field:  "resource_type",
+			Reason: fmt.Sprintf("Access to %s is outside the allowed time window", resourceType),
+		},
+		Status: http.StatusForbidden,
+	}
+}
+
+// NewInsufficientScopeError スコープ外のリソースへのアクセス拒否エラーを作成
+func NewInsufficientScopeError(permission string) *APIError {
+	return &APIError{
+		Code:    ErrCodeInsufficientScope,
+		Message: "Resource is outside of your scope",
+		Details: ErrorDetails{
+			Field:  "permission",
+			Reason: fmt.Sprintf("Permission %s is not granted for this resource scope", permission),
+		},
+		Status: http.StatusForbidden,
+	}
+}
+
 // NewDatabaseError データベースエラーを作成
 func NewDatabaseError(err error) *APIError {
 	return &APIError{
@@ -197,6 +246,25 @@ func NewInternalError(reason string) *APIError {
 	}
 }
 
+// NewViolationsError 違反したすべてのルールを含むバリデーションエラーを作成
+func NewViolationsError(field string, violations []Violation) *APIError {
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.Message
+	}
+
+	return &APIError{
+		Code:    ErrCodeValidation,
+		Message: "Validation failed",
+		Details: ErrorDetails{
+			Field:      field,
+			Reason:     strings.Join(messages, "; "),
+			Violations: violations,
+		},
+		Status: http.StatusBadRequest,
+	}
+}
+
 // NewNotFoundError リソース未発見エラーを作成
 func NewNotFoundError(resource, reason string) *APIError {
 	return &APIError{
@@ -233,6 +301,14 @@ func IsAuthenticationError(err error) bool {
 	return false
 }
 
+// IsAccountLockedError エラーがロックアウトエラーかどうかを判定
+func IsAccountLockedError(err error) bool {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.Code == ErrCodeAccountLocked
+	}
+	return false
+}
+
 // IsAuthorizationError エラーが認可エラーかどうかを判定
 func IsAuthorizationError(err error) bool {
 	if apiErr, ok := err.(*APIError); ok {
@@ -240,3 +316,19 @@ func IsAuthorizationError(err error) bool {
 	}
 	return false
 }
+
+// IsInsufficientScopeError エラーがスコープ不足エラーかどうかを判定
+func IsInsufficientScopeError(err error) bool {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.Code == ErrCodeInsufficientScope
+	}
+	return false
+}
+
+// IsTimeRestrictedError エラーが時間制限エラーかどうかを判定
+func IsTimeRestrictedError(err error) bool {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.Code == ErrCodeTimeRestricted
+	}
+	return false
+}
@@ -46,6 +46,21 @@ func TestNewValidationError(t *testing.T) {
 	}
 }
 
+func TestNewViolationsError(t *testing.T) {
+	violations := []Violation{
+		{Rule: "min_length", Message: "must be at least 12 characters"},
+		{Rule: "require_digit", Message: "must contain a digit"},
+	}
+
+	err := NewViolationsError("password", violations)
+	assert.Equal(t, ErrCodeValidation, err.Code)
+	assert.Equal(t, 400, err.Status)
+	assert.Equal(t, "password", err.Details.Field)
+	assert.Equal(t, "must be at least 12 characters; must contain a digit", err.Details.Reason)
+	assert.Equal(t, violations, err.Details.Violations)
+	assert.True(t, IsValidationError(err))
+}
+
 func TestNewNotFoundError(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -251,6 +266,26 @@ func TestErrorTypeChecks(t *testing.T) {
 			},
 			expected: []bool{false, false, false, true},
 		},
+		{
+			name: "時間制限エラー",
+			err:  NewTimeRestrictedError("users"),
+			checks: []func(error) bool{
+				IsAuthorizationError,
+				IsTimeRestrictedError,
+				IsNotFound,
+			},
+			expected: []bool{false, true, false},
+		},
+		{
+			name: "スコープ不足エラー",
+			err:  NewInsufficientScopeError("user:update"),
+			checks: []func(error) bool{
+				IsAuthorizationError,
+				IsInsufficientScopeError,
+				IsTimeRestrictedError,
+			},
+			expected: []bool{false, true, false},
+		},
 	}
 
 	for _, tt := range tests {
@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -20,7 +21,7 @@ type RoleInfo struct {
 type CustomClaims struct {
 	UserID         uuid.UUID  `json:"user_id"`
 	Email          string     `json:"email"`
-	Permissions    []string   `json:"permissions"`
+	Permissions    []string   `json:"permissions"` // 拒否権限は "!" 付き（例: "!finance:export"、許可より優先）
 	PrimaryRoleID  *uuid.UUID `json:"primary_role_id,omitempty"`
 	ActiveRoles    []RoleInfo `json:"active_roles,omitempty"`
 	HighestRole    *RoleInfo  `json:"highest_role,omitempty"`
@@ -28,12 +29,16 @@ type CustomClaims struct {
 }
 
 // Service JWT操作を担当するサービス
+// 共有シークレット（HS256）または非対称鍵（RS256/EdDSA）で署名する
 type Service struct {
 	secretKey []byte
 	expiresIn time.Duration
+
+	signingKey       *Key            // 非対称鍵で署名する場合のみ設定
+	verificationKeys map[string]*Key // kid → 検証鍵（署名鍵とローテーション前の鍵）
 }
 
-// NewService 新しいJWTサービスインスタンスを作成
+// NewService 新しいJWTサービスインスタンスを作成（HS256）
 func NewService(secret string, expiresIn time.Duration) *Service {
 	return &Service{
 		secretKey: []byte(secret),
@@ -41,6 +46,30 @@ func NewService(secret string, expiresIn time.Duration) *Service {
 	}
 }
 
+// NewAsymmetricService 非対称鍵で署名するJWTサービスを作成
+// verificationKeysには鍵ローテーションで署名に使わなくなった鍵を指定し、発行済みトークンの検証に使用する
+func NewAsymmetricService(signingKey *Key, verificationKeys []*Key, expiresIn time.Duration) (*Service, error) {
+	if signingKey == nil || !signingKey.CanSign() {
+		return nil, fmt.Errorf("signing key must be a private key")
+	}
+
+	keys := map[string]*Key{signingKey.ID: signingKey}
+	for _, key := range verificationKeys {
+		keys[key.ID] = key
+	}
+
+	return &Service{
+		expiresIn:        expiresIn,
+		signingKey:       signingKey,
+		verificationKeys: keys,
+	}, nil
+}
+
+// ExpiresIn トークンの有効期間を取得
+func (s *Service) ExpiresIn() time.Duration {
+	return s.expiresIn
+}
+
 // GenerateToken ユーザー用の新しいJWTトークンを作成（複数ロール対応）
 func (s *Service) GenerateToken(userID uuid.UUID, email string, permissions []string, primaryRoleID *uuid.UUID, activeRoles []RoleInfo, highestRole *RoleInfo) (string, error) {
 	claims := CustomClaims{
@@ -60,6 +89,12 @@ func (s *Service) GenerateToken(userID uuid.UUID, email string, permissions []st
 		},
 	}
 
+	if s.signingKey != nil {
+		token := jwt.NewWithClaims(s.signingKey.SigningMethod(), claims)
+		token.Header["kid"] = s.signingKey.ID
+		return token.SignedString(s.signingKey.privateKey)
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(s.secretKey)
 }
@@ -76,12 +111,7 @@ func (s *Service) GenerateTokenLegacy(userID uuid.UUID, email string, permission
 
 // ValidateToken JWTトークンを検証・解析
 func (s *Service) ValidateToken(tokenString string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return s.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, s.keyFunc)
 
 	if err != nil {
 		return nil, err
@@ -94,6 +124,49 @@ func (s *Service) ValidateToken(tokenString string) (*CustomClaims, error) {
 	return nil, fmt.Errorf("invalid token")
 }
 
+// JWKS 検証用公開鍵の一覧を取得（HS256の場合は空）
+func (s *Service) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if s.signingKey == nil {
+		return set
+	}
+
+	// 署名鍵を先頭にし、以降は検証専用鍵（kid順）
+	set.Keys = append(set.Keys, s.signingKey.JWK())
+	kids := make([]string, 0, len(s.verificationKeys))
+	for kid := range s.verificationKeys {
+		if kid != s.signingKey.ID {
+			kids = append(kids, kid)
+		}
+	}
+	sort.Strings(kids)
+	for _, kid := range kids {
+		set.Keys = append(set.Keys, s.verificationKeys[kid].JWK())
+	}
+	return set
+}
+
+// keyFunc トークンのアルゴリズム・kidに対応する検証鍵を返す
+func (s *Service) keyFunc(token *jwt.Token) (interface{}, error) {
+	if s.signingKey == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.secretKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.verificationKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %q", kid)
+	}
+	// 鍵と異なるアルゴリズム（alg混同攻撃）は拒否
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.publicKey, nil
+}
+
 // GetTokenID トークンクレームからJTI（JWT ID）を抽出
 func (s *Service) GetTokenID(tokenString string) (string, error) {
 	claims, err := s.ValidateToken(tokenString)
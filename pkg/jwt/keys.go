@@ -0,0 +1,156 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 非対称鍵の署名アルゴリズム
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const minRSAKeyBits = 2048
+
+// Key 非対称鍵（署名鍵は秘密鍵を、検証専用鍵は公開鍵のみを保持）
+type Key struct {
+	ID         string // JWTヘッダーのkid（RFC 7638 の JWK Thumbprint）
+	Algorithm  string
+	privateKey crypto.PrivateKey
+	publicKey  crypto.PublicKey
+}
+
+// NewKey 秘密鍵または公開鍵から鍵を作成（RSA→RS256、Ed25519→EdDSA）
+func NewKey(key interface{}) (*Key, error) {
+	k := &Key{}
+	switch typed := key.(type) {
+	case *rsa.PrivateKey:
+		k.privateKey, k.publicKey = typed, &typed.PublicKey
+	case *rsa.PublicKey:
+		k.publicKey = typed
+	case ed25519.PrivateKey:
+		k.privateKey, k.publicKey = typed, typed.Public()
+	case ed25519.PublicKey:
+		k.publicKey = typed
+	default:
+		return nil, fmt.Errorf("unsupported key type: %T", key)
+	}
+
+	switch pub := k.publicKey.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		k.Algorithm = AlgorithmRS256
+	case ed25519.PublicKey:
+		k.Algorithm = AlgorithmEdDSA
+	}
+	k.ID = k.thumbprint()
+	return k, nil
+}
+
+// LoadKeyFile PEMファイルから鍵を読み込む
+// 秘密鍵（PKCS#8・PKCS#1）は署名鍵、公開鍵（PKIX）はローテーション後の検証専用鍵として使用する
+func LoadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM data in %s", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q in %s", block.Type, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key in %s: %w", path, err)
+	}
+
+	return NewKey(parsed)
+}
+
+// CanSign 署名に使用できる鍵（秘密鍵を保持）かチェック
+func (k *Key) CanSign() bool {
+	return k.privateKey != nil
+}
+
+// SigningMethod 鍵のアルゴリズムに対応する署名方式を取得
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// =============================================================================
+// JWKS
+// =============================================================================
+
+// JWK 公開鍵のJSON Web Key表現（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKSet JWKの集合（/.well-known/jwks.json のレスポンス）
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK 公開鍵をJWK形式で取得
+func (k *Key) JWK() JWK {
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+	switch pub := k.publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
+
+// thumbprint RFC 7638 の JWK Thumbprint（SHA-256）を計算
+func (k *Key) thumbprint() string {
+	jwk := k.JWK()
+	var canonical string
+	switch jwk.Kty {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, jwk.Crv, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
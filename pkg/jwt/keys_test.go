@@ -0,0 +1,156 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRSAKey テスト用のRSA署名鍵を作成
+func newTestRSAKey(t *testing.T) *Key {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := NewKey(privateKey)
+	require.NoError(t, err)
+	return key
+}
+
+// newTestEd25519Key テスト用のEd25519署名鍵を作成
+func newTestEd25519Key(t *testing.T) *Key {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := NewKey(privateKey)
+	require.NoError(t, err)
+	return key
+}
+
+func TestAsymmetricService_SignAndValidate(t *testing.T) {
+	for name, key := range map[string]*Key{
+		AlgorithmRS256: newTestRSAKey(t),
+		AlgorithmEdDSA: newTestEd25519Key(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, err := NewAsymmetricService(key, nil, time.Hour)
+			require.NoError(t, err)
+
+			userID := uuid.New()
+			tokenString, err := service.GenerateTokenSimple(userID, "test@example.com", []string{"user:read"})
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(tokenString, &CustomClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, name, parsed.Header["alg"])
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+
+			claims, err := service.ValidateToken(tokenString)
+			require.NoError(t, err)
+			assert.Equal(t, userID, claims.UserID)
+		})
+	}
+}
+
+func TestAsymmetricService_KeyRotation(t *testing.T) {
+	oldKey := newTestRSAKey(t)
+	newKey := newTestEd25519Key(t)
+
+	oldService, err := NewAsymmetricService(oldKey, nil, time.Hour)
+	require.NoError(t, err)
+	issued, err := oldService.GenerateTokenSimple(uuid.New(), "test@example.com", nil)
+	require.NoError(t, err)
+
+	// 旧鍵は公開鍵のみ検証用に残す
+	retired, err := NewKey(oldKey.publicKey)
+	require.NoError(t, err)
+	assert.False(t, retired.CanSign())
+	assert.Equal(t, oldKey.ID, retired.ID, "kidは公開鍵から決まる")
+
+	rotated, err := NewAsymmetricService(newKey, []*Key{retired}, time.Hour)
+	require.NoError(t, err)
+	_, err = rotated.ValidateToken(issued)
+	assert.NoError(t, err, "ローテーション前に発行したトークンも検証可能")
+
+	withoutOld, err := NewAsymmetricService(newKey, nil, time.Hour)
+	require.NoError(t, err)
+	_, err = withoutOld.ValidateToken(issued)
+	assert.Error(t, err, "検証鍵から外した鍵のトークンは拒否")
+
+	jwks := rotated.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, newKey.ID, jwks.Keys[0].Kid, "署名鍵が先頭")
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+	assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+}
+
+func TestAsymmetricService_RejectsAlgorithmConfusion(t *testing.T) {
+	key := newTestRSAKey(t)
+	service, err := NewAsymmetricService(key, nil, time.Hour)
+	require.NoError(t, err)
+
+	// 公開鍵をHMACシークレットとして使ったトークン
+	publicDER, err := x509.MarshalPKIXPublicKey(key.publicKey)
+	require.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, CustomClaims{UserID: uuid.New()})
+	forged.Header["kid"] = key.ID
+	tokenString, err := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	require.NoError(t, err)
+
+	_, err = service.ValidateToken(tokenString)
+	assert.Error(t, err)
+
+	// HS256のサービスはJWKSを公開しない
+	assert.Empty(t, NewService("secret", time.Hour).JWKS().Keys)
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+		return path
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	private, err := LoadKeyFile(writePEM("private.pem", "PRIVATE KEY", pkcs8))
+	require.NoError(t, err)
+	assert.True(t, private.CanSign())
+	assert.Equal(t, AlgorithmRS256, private.Algorithm)
+
+	pkcs1, err := LoadKeyFile(writePEM("pkcs1.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)))
+	require.NoError(t, err)
+	assert.Equal(t, private.ID, pkcs1.ID)
+
+	public, err := LoadKeyFile(writePEM("public.pem", "PUBLIC KEY", publicDER))
+	require.NoError(t, err)
+	assert.False(t, public.CanSign())
+	assert.Equal(t, private.ID, public.ID)
+
+	_, err = NewAsymmetricService(public, nil, time.Hour)
+	assert.Error(t, err, "公開鍵では署名できない")
+
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = NewKey(smallKey)
+	assert.Error(t, err, "2048ビット未満のRSA鍵は拒否")
+
+	_, err = LoadKeyFile(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+}
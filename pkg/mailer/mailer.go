@@ -0,0 +1,132 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message 送信するメール
+type Message struct {
+	To      []string
+	Subject string
+	Body    string // プレーンテキスト
+}
+
+// Mailer メール送信インターフェース
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// =============================================================================
+// SMTP送信
+// =============================================================================
+
+// SMTPMailer SMTPサーバー経由でメールを送信
+type SMTPMailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer 新しいSMTP送信を作成（usernameが空の場合は認証しない）
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send SMTPサーバーにメールを送信
+func (m *SMTPMailer) Send(msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("mailer: no recipients")
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+	if err := smtp.SendMail(addr, auth, m.from, msg.To, formatMessage(m.from, msg)); err != nil {
+		return fmt.Errorf("mailer: failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// ファイル・ログ出力（ローカル開発・テスト用）
+// =============================================================================
+
+// WriterMailer メールを送信せず、整形した本文をWriterに書き出す
+type WriterMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+// NewLogMailer 標準出力等のWriterにメールを書き出す送信を作成
+func NewLogMailer(w io.Writer, from string) *WriterMailer {
+	return &WriterMailer{w: w, from: from}
+}
+
+// NewFileMailer 指定ファイルにメールを追記する送信を作成
+func NewFileMailer(path, from string) (*WriterMailer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: failed to open mail file: %w", err)
+	}
+	return &WriterMailer{w: file, from: from}, nil
+}
+
+// Send メールをWriterに書き出す
+func (m *WriterMailer) Send(msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("mailer: no recipients")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.w.Write(append(formatMessage(m.from, msg), "\r\n\r\n"...)); err != nil {
+		return fmt.Errorf("mailer: failed to write mail: %w", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// formatMessage RFC 5322 形式のメッセージを組み立てる
+// 本文は7bitで中継されるよう quoted-printable でエンコードする
+func formatMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(msg.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Subject) + "\r\n") // 非ASCIIの件名はエンコード
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	b.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&b)
+	body.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n"))) // strings.Builderへの書き込みは失敗しない
+	body.Close()
+	return []byte(b.String())
+}
@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"bytes"
+	"io"
+	"mime/quotedprintable"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterMailer_Send(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewLogMailer(&buf, "noreply@example.com")
+
+	err := m.Send(Message{
+		To:      []string{"alice@example.com"},
+		Subject: "パスワード再設定",
+		Body:    "line1\nline2",
+	})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "From: noreply@example.com\r\n")
+	assert.Contains(t, out, "To: alice@example.com\r\n")
+	assert.Contains(t, out, "Subject: =?UTF-8?b?", "非ASCIIの件名はBエンコード")
+	assert.Contains(t, out, "Content-Transfer-Encoding: quoted-printable\r\n")
+	assert.Contains(t, out, "\r\n\r\nline1\r\nline2")
+}
+
+func TestFormatMessage_EncodesBody(t *testing.T) {
+	body := "以下のURLからパスワードを再設定してください。\nhttps://example.com/reset?token=" + strings.Repeat("a", 100)
+	msg := string(formatMessage("noreply@example.com", Message{To: []string{"alice@example.com"}, Subject: "test", Body: body}))
+
+	_, encoded, found := strings.Cut(msg, "\r\n\r\n")
+	require.True(t, found)
+	for _, line := range strings.Split(encoded, "\r\n") {
+		assert.LessOrEqual(t, len(line), 76, "1行は76文字以下")
+		for _, r := range line {
+			assert.Less(t, r, rune(0x80), "本文は7bit")
+		}
+	}
+
+	decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(encoded)))
+	require.NoError(t, err)
+	assert.Equal(t, strings.ReplaceAll(body, "\n", "\r\n"), string(decoded))
+}
+
+func TestWriterMailer_NoRecipients(t *testing.T) {
+	m := NewLogMailer(&bytes.Buffer{}, "noreply@example.com")
+	assert.Error(t, m.Send(Message{Subject: "test"}))
+}
+
+func TestNewFileMailer_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m, err := NewFileMailer(path, "noreply@example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, m.Send(Message{To: []string{"a@example.com"}, Subject: "first", Body: "1"}))
+	require.NoError(t, m.Send(Message{To: []string{"b@example.com"}, Subject: "second", Body: "2"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Subject: first")
+	assert.Contains(t, string(data), "Subject: second")
+}
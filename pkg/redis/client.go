@@ -0,0 +1,341 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Error サーバーが返したエラー応答
+type Error string
+
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+// ErrUnavailable 接続に失敗した直後のため、再接続を待たずに失敗したことを示すエラー
+var ErrUnavailable = errors.New("redis: server unavailable, retrying later")
+
+const (
+	defaultMaxIdleConns = 10
+	minRetryBackoff     = 100 * time.Millisecond
+	maxRetryBackoff     = 30 * time.Second
+)
+
+// Client Redis互換サーバー（RESP2プロトコル）の最小限のクライアント
+// コマンドごとに接続プールから接続を取り出して並行に実行し、通信エラーになった接続は破棄する
+// 接続に失敗した場合は指数バックオフの間、接続を試みずに ErrUnavailable を返す（サーバー停止中に呼び出し元を待たせない）
+type Client struct {
+	addr         string
+	password     string
+	db           int
+	timeout      time.Duration
+	maxIdleConns int
+
+	mu           sync.Mutex
+	idle         []*conn
+	closed       bool
+	dialFailures int
+	retryAt      time.Time
+}
+
+// conn プールする1本の接続
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// NewClient 新しいクライアントを作成（接続は最初のコマンド実行時に行う）
+// passwordが空の場合はAUTH、dbが0の場合はSELECTを送信しない
+func NewClient(addr, password string, db int, timeout time.Duration) *Client {
+	return &Client{
+		addr:         addr,
+		password:     password,
+		db:           db,
+		timeout:      timeout,
+		maxIdleConns: defaultMaxIdleConns,
+	}
+}
+
+// Do コマンドを実行して応答を返す
+// 応答は string（ステータス・バルク文字列）、int64、nil、[]interface{} のいずれか
+func (c *Client) Do(args ...string) (interface{}, error) {
+	cn, err := c.getConn()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.roundTrip(cn, args)
+	if err != nil {
+		if _, ok := err.(Error); !ok {
+			// 応答の途中で失敗した接続は再利用しない
+			cn.Close()
+			return nil, err
+		}
+	}
+	c.putConn(cn)
+	return reply, err
+}
+
+// Ping サーバーへの疎通を確認
+func (c *Client) Ping() error {
+	_, err := c.Do("PING")
+	return err
+}
+
+// MGet 複数のキーの値を取得（存在しないキーはnil）
+func (c *Client) MGet(keys ...string) ([]*string, error) {
+	reply, err := c.Do(append([]string{"MGET"}, keys...)...)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != len(keys) {
+		return nil, fmt.Errorf("redis: unexpected MGET reply %T", reply)
+	}
+
+	values := make([]*string, len(items))
+	for i, item := range items {
+		if value, ok := item.(string); ok {
+			values[i] = &value
+		}
+	}
+	return values, nil
+}
+
+// Set 値を設定（ttlが0以下の場合は期限なし）
+func (c *Client) Set(key, value string, ttl time.Duration) error {
+	args := []string{"SET", key, value}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := c.Do(args...)
+	return err
+}
+
+// Del キーを削除（削除した件数を返す）
+func (c *Client) Del(keys ...string) (int64, error) {
+	reply, err := c.Do(append([]string{"DEL"}, keys...)...)
+	if err != nil {
+		return 0, err
+	}
+	return toInt(reply)
+}
+
+// Incr キーの値を1増やす（増加後の値を返す）
+func (c *Client) Incr(key string) (int64, error) {
+	reply, err := c.Do("INCR", key)
+	if err != nil {
+		return 0, err
+	}
+	return toInt(reply)
+}
+
+// Close プール中の接続を閉じる（以降のコマンドは失敗する）
+func (c *Client) Close() error {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.closed = true
+	c.mu.Unlock()
+
+	var firstErr error
+	for _, cn := range idle {
+		if err := cn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// =============================================================================
+// ヘルパー関数
+// =============================================================================
+
+// getConn プールから接続を取り出す（空の場合は新しく接続する）
+func (c *Client) getConn() (*conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errors.New("redis: client is closed")
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	if time.Now().Before(c.retryAt) {
+		c.mu.Unlock()
+		return nil, ErrUnavailable
+	}
+	c.mu.Unlock()
+
+	// 接続はロックの外で行い、他の呼び出しを待たせない
+	cn, err := c.connect()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		if _, ok := err.(Error); !ok {
+			c.dialFailures++
+			c.retryAt = time.Now().Add(retryBackoff(c.dialFailures))
+		}
+		return nil, err
+	}
+	c.dialFailures = 0
+	c.retryAt = time.Time{}
+	return cn, nil
+}
+
+// putConn 使用後の接続をプールに戻す（上限を超える場合は閉じる）
+func (c *Client) putConn(cn *conn) {
+	c.mu.Lock()
+	if c.closed || len(c.idle) >= c.maxIdleConns {
+		c.mu.Unlock()
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+	c.mu.Unlock()
+}
+
+// connect 接続して認証・データベース選択を行う
+func (c *Client) connect() (*conn, error) {
+	netConn, err := net.DialTimeout("tcp", c.addr, c.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to connect: %w", err)
+	}
+	cn := &conn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if c.password != "" {
+		if _, err := c.roundTrip(cn, []string{"AUTH", c.password}); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	if c.db != 0 {
+		if _, err := c.roundTrip(cn, []string{"SELECT", strconv.Itoa(c.db)}); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// roundTrip コマンドを送信して応答を読み取る
+func (c *Client) roundTrip(cn *conn, args []string) (interface{}, error) {
+	if c.timeout > 0 {
+		if err := cn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := cn.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+	return readReply(cn.reader)
+}
+
+// retryBackoff 連続した接続失敗の回数に応じた再接続までの待機時間
+func retryBackoff(failures int) time.Duration {
+	backoff := minRetryBackoff
+	for i := 1; i < failures && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+// encodeCommand コマンドをバルク文字列の配列としてエンコード
+func encodeCommand(args []string) []byte {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	return buf
+}
+
+// readReply 応答を1件読み取る
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", line)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
+
+// readLine CRLFで終わる1行を読み取る（CRLFは含まない）
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed reply line")
+	}
+	return line[:len(line)-2], nil
+}
+
+// toInt 整数応答を変換
+func toInt(reply interface{}) (int64, error) {
+	value, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected integer reply %T", reply)
+	}
+	return value, nil
+}
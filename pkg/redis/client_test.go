@@ -0,0 +1,295 @@
+package redis
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standInServer テスト用のRedis互換サーバー（文字列キーのみ・有効期限あり）
+type standInServer struct {
+	listener net.Listener
+	password string
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+	conns   []net.Conn
+}
+
+func startStandInServer(t *testing.T, password string) *standInServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &standInServer{
+		listener: listener,
+		password: password,
+		values:   make(map[string]string),
+		expires:  make(map[string]time.Time),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.conns = append(server.conns, conn)
+			server.mu.Unlock()
+			go server.serve(conn)
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		server.dropConnections()
+	})
+	return server
+}
+
+func (s *standInServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+// dropConnections 接続中のクライアントを切断
+func (s *standInServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *standInServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authenticated := s.password == ""
+
+	for {
+		reply, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		if len(args) == 0 {
+			return
+		}
+
+		command := strings.ToUpper(args[0])
+		if !authenticated && command != "AUTH" {
+			conn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+			continue
+		}
+		if command == "AUTH" {
+			if len(args) == 2 && args[1] == s.password {
+				authenticated = true
+				conn.Write([]byte("+OK\r\n"))
+			} else {
+				conn.Write([]byte("-WRONGPASS invalid password\r\n"))
+			}
+			continue
+		}
+		conn.Write(s.execute(command, args[1:]))
+	}
+}
+
+func (s *standInServer) execute(command string, args []string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch command {
+	case "PING":
+		return []byte("+PONG\r\n")
+	case "SELECT":
+		return []byte("+OK\r\n")
+	case "SET":
+		s.values[args[0]] = args[1]
+		delete(s.expires, args[0])
+		if len(args) == 4 && strings.ToUpper(args[2]) == "PX" {
+			ms, _ := strconv.Atoi(args[3])
+			s.expires[args[0]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return []byte("+OK\r\n")
+	case "MGET":
+		out := "*" + strconv.Itoa(len(args)) + "\r\n"
+		for _, key := range args {
+			if value, ok := s.get(key); ok {
+				out += "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+			} else {
+				out += "$-1\r\n"
+			}
+		}
+		return []byte(out)
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if _, ok := s.get(key); ok {
+				deleted++
+			}
+			delete(s.values, key)
+			delete(s.expires, key)
+		}
+		return []byte(":" + strconv.Itoa(deleted) + "\r\n")
+	case "INCR":
+		current, _ := s.get(args[0])
+		value, err := strconv.Atoi("0" + current)
+		if err != nil {
+			return []byte("-ERR value is not an integer or out of range\r\n")
+		}
+		s.values[args[0]] = strconv.Itoa(value + 1)
+		return []byte(":" + strconv.Itoa(value+1) + "\r\n")
+	default:
+		return []byte("-ERR unknown command '" + command + "'\r\n")
+	}
+}
+
+// get 有効期限を考慮して値を取得（呼び出し元でロックを取得すること）
+func (s *standInServer) get(key string) (string, bool) {
+	if expiresAt, ok := s.expires[key]; ok && !time.Now().Before(expiresAt) {
+		delete(s.values, key)
+		delete(s.expires, key)
+	}
+	value, ok := s.values[key]
+	return value, ok
+}
+
+func TestClient_Commands(t *testing.T) {
+	server := startStandInServer(t, "secret")
+	client := NewClient(server.addr(), "secret", 1, time.Second)
+	defer client.Close()
+
+	require.NoError(t, client.Ping())
+
+	t.Run("SETとMGET", func(t *testing.T) {
+		require.NoError(t, client.Set("a", "1", 0))
+		require.NoError(t, client.Set("b", "値\r\n改行を含む", 0))
+
+		values, err := client.MGet("a", "missing", "b")
+		require.NoError(t, err)
+		require.Len(t, values, 3)
+		assert.Equal(t, "1", *values[0])
+		assert.Nil(t, values[1])
+		assert.Equal(t, "値\r\n改行を含む", *values[2])
+	})
+
+	t.Run("有効期限付きのSET", func(t *testing.T) {
+		require.NoError(t, client.Set("short", "x", 20*time.Millisecond))
+		time.Sleep(40 * time.Millisecond)
+
+		values, err := client.MGet("short")
+		require.NoError(t, err)
+		assert.Nil(t, values[0])
+	})
+
+	t.Run("DELとINCR", func(t *testing.T) {
+		deleted, err := client.Del("a", "missing")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		value, err := client.Incr("counter")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), value)
+		value, err = client.Incr("counter")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), value)
+	})
+
+	t.Run("エラー応答", func(t *testing.T) {
+		_, err := client.Do("UNKNOWN")
+		require.Error(t, err)
+		_, isReplyError := err.(Error)
+		assert.True(t, isReplyError)
+
+		require.NoError(t, client.Ping(), "エラー応答の後も接続を使用できる")
+	})
+}
+
+func TestClient_Authentication(t *testing.T) {
+	server := startStandInServer(t, "secret")
+
+	client := NewClient(server.addr(), "wrong", 0, time.Second)
+	defer client.Close()
+
+	err := client.Ping()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WRONGPASS")
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	server := startStandInServer(t, "")
+	client := NewClient(server.addr(), "", 0, time.Second)
+	defer client.Close()
+
+	require.NoError(t, client.Set("key", "value", 0))
+
+	server.dropConnections()
+	assert.Error(t, client.Ping(), "切断された接続でのコマンドは失敗")
+
+	values, err := client.MGet("key")
+	require.NoError(t, err, "次のコマンドで再接続")
+	assert.Equal(t, "value", *values[0])
+}
+
+func TestClient_ConnectionPool(t *testing.T) {
+	server := startStandInServer(t, "")
+	client := NewClient(server.addr(), "", 0, time.Second)
+	defer client.Close()
+
+	// 実行中のコマンドが接続を使用している間も、別の接続で並行に実行する
+	busy, err := client.getConn()
+	require.NoError(t, err)
+	require.NoError(t, client.Ping())
+	client.putConn(busy)
+
+	client.mu.Lock()
+	assert.Len(t, client.idle, 2, "使用後の接続はプールに戻して再利用")
+	client.mu.Unlock()
+}
+
+func TestClient_RetryBackoff(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachable := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	client := NewClient(unreachable, "", 0, 500*time.Millisecond)
+	defer client.Close()
+
+	err = client.Ping()
+	require.Error(t, err)
+	assert.NotEqual(t, ErrUnavailable, err)
+
+	started := time.Now()
+	assert.Equal(t, ErrUnavailable, client.Ping(), "バックオフ中は接続を試みない")
+	assert.Less(t, time.Since(started), 10*time.Millisecond)
+
+	t.Run("待機時間は失敗のたびに延長", func(t *testing.T) {
+		assert.Equal(t, minRetryBackoff, retryBackoff(1))
+		assert.Equal(t, 2*minRetryBackoff, retryBackoff(2))
+		assert.Equal(t, maxRetryBackoff, retryBackoff(100))
+	})
+
+	t.Run("バックオフ後に復旧したサーバーへ再接続", func(t *testing.T) {
+		server := startStandInServer(t, "")
+		client.mu.Lock()
+		client.addr = server.addr()
+		client.retryAt = time.Now()
+		client.mu.Unlock()
+
+		require.NoError(t, client.Ping())
+		client.mu.Lock()
+		assert.Zero(t, client.dialFailures)
+		client.mu.Unlock()
+	})
+}
@@ -0,0 +1,84 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret RFC 6238 付録Bのテスト用シークレット（SHA1）
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateCode_RFC6238Vectors(t *testing.T) {
+	// 8桁の期待値の下6桁
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := GenerateCode(rfcSecret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "unix=%d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	t.Run("現在のコード", func(t *testing.T) {
+		step, ok := Validate(secret, code, now, 1)
+		assert.True(t, ok)
+		assert.Equal(t, Step(now), step)
+	})
+
+	t.Run("前後1ステップの時刻ずれを許容", func(t *testing.T) {
+		previous, err := GenerateCode(secret, now.Add(-Period))
+		require.NoError(t, err)
+		step, ok := Validate(secret, previous, now, 1)
+		assert.True(t, ok)
+		assert.Equal(t, Step(now)-1, step)
+
+		_, ok = Validate(secret, previous, now, 0)
+		assert.Equal(t, previous == code, ok)
+	})
+
+	t.Run("桁数不正・不一致", func(t *testing.T) {
+		_, ok := Validate(secret, "12345", now, 1)
+		assert.False(t, ok)
+
+		_, ok = Validate(secret, "abcdef", now, 1)
+		assert.False(t, ok)
+	})
+
+	t.Run("不正なシークレット", func(t *testing.T) {
+		_, ok := Validate("not-base32!", code, now, 1)
+		assert.False(t, ok)
+	})
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("ERP Access Control", "user@example.com", "JBSWY3DPEHPK3PXP")
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/ERP Access Control:user@example.com", parsed.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", parsed.Query().Get("secret"))
+	assert.Equal(t, "ERP Access Control", parsed.Query().Get("issuer"))
+	assert.Equal(t, "6", parsed.Query().Get("digits"))
+}
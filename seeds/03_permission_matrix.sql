@@ -0,0 +1,8 @@
+-- =============================================================================
+-- ERP Access Control - Permission Matrix Seeds
+-- =============================================================================
+-- 01_test_data.sql で作成したロールへ標準の権限マトリックス（ワイルドカードを含む）を登録
+-- マイグレーション実行時点ではロールが存在しないため、ロール作成後に改めて取り込む
+-- 取り込み処理は migrations/17_import_permission_matrix.sql で定義（冪等）
+
+SELECT import_legacy_permission_matrix();
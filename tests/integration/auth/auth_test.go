@@ -165,7 +165,7 @@ func (s *AuthTestSuite) TestChangePasswordSuccess() {
 	// パスワード変更リクエストの作成
 	req, err := integration.CreateTestRequest("POST", "/api/v1/auth/change-password", handlers.ChangePasswordRequest{
 		CurrentPassword: s.testUser.Password,
-		NewPassword:     "newpassword123",
+		NewPassword:     "NewPassword123",
 	})
 	require.NoError(s.T(), err)
 	req.Header.Set("Authorization", "Bearer "+loginData.AccessToken)
@@ -179,7 +179,7 @@ func (s *AuthTestSuite) TestChangePasswordSuccess() {
 	// 新しいパスワードでログインできることを確認
 	newLoginReq, err := integration.CreateTestRequest("POST", "/api/v1/auth/login", handlers.LoginRequest{
 		Email:    s.testUser.Email,
-		Password: "newpassword123",
+		Password: "NewPassword123",
 	})
 	require.NoError(s.T(), err)
 
@@ -191,7 +191,7 @@ func (s *AuthTestSuite) TestChangePasswordUnauthorized() {
 	// 認証トークンなしでリクエスト
 	req, err := integration.CreateTestRequest("POST", "/api/v1/auth/change-password", handlers.ChangePasswordRequest{
 		CurrentPassword: s.testUser.Password,
-		NewPassword:     "newpassword123",
+		NewPassword:     "NewPassword123",
 	})
 	require.NoError(s.T(), err)
 
@@ -225,7 +225,7 @@ func (s *AuthTestSuite) TestChangePasswordIncorrectCurrent() {
 	// 誤った現在のパスワードでリクエスト
 	req, err := integration.CreateTestRequest("POST", "/api/v1/auth/change-password", handlers.ChangePasswordRequest{
 		CurrentPassword: "wrongpassword",
-		NewPassword:     "newpassword123",
+		NewPassword:     "NewPassword123",
 	})
 	require.NoError(s.T(), err)
 	req.Header.Set("Authorization", "Bearer "+loginData.AccessToken)
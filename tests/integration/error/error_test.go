@@ -73,7 +73,7 @@ func (s *ErrorTestSuite) TestAuthorizationError() {
 	// 認証なしでパスワード変更リクエスト
 	req, err := integration.CreateTestRequest("POST", "/api/v1/auth/change-password", handlers.ChangePasswordRequest{
 		CurrentPassword: "password123",
-		NewPassword:     "newpassword123",
+		NewPassword:     "NewPassword123",
 	})
 	require.NoError(s.T(), err)
 
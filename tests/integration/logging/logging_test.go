@@ -146,7 +146,7 @@ func (s *LoggingTestSuite) TestSensitiveDataMasking() {
 	// パスワード変更のリクエスト
 	req, err := integration.CreateTestRequest("POST", "/api/v1/auth/change-password", handlers.ChangePasswordRequest{
 		CurrentPassword: "password123",
-		NewPassword:     "newpassword123",
+		NewPassword:     "NewPassword123",
 	})
 	require.NoError(s.T(), err)
 
@@ -163,7 +163,7 @@ func (s *LoggingTestSuite) TestSensitiveDataMasking() {
 	// センシティブ情報がマスクされていることを確認
 	logData := string(s.logBuf.Bytes())
 	require.NotContains(s.T(), logData, "password123")
-	require.NotContains(s.T(), logData, "newpassword123")
+	require.NotContains(s.T(), logData, "NewPassword123")
 }
 
 func (s *LoggingTestSuite) TestErrorLogging() {
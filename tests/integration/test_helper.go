@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -78,12 +79,14 @@ func SetupTestEnvironment(t *testing.T) {
 		TestJWTService,
 		permissionService,
 		revocationService,
+		services.NewRefreshTokenService(TestDB, 24*time.Hour),
 	)
 
 	// テスト用ミドルウェア
 	authMiddleware := middleware.NewAuthMiddleware(
 		TestJWTService,
 		revocationService,
+		permissionService,
 		TestLogger,
 	)
 